@@ -0,0 +1,147 @@
+import { AstDocument, AST_SCHEMA_VERSION, exportFile } from './astExport';
+import { JavaFileGenerator, JavaFileGenerationOptions } from './javaFileGenerator';
+import { GoSyntaxError } from './goBodyParser';
+import { extractType } from './goFileParser';
+import { SourcePosition } from './goParser';
+import { ResolvedBody } from './javaBodyGenerator';
+import { ConversionDiagnostic } from './javaGenerator';
+import * as TreeSitterGoParser from './treeSitterGoParser';
+
+export type { Rewriter, RewriteContext, RewriteResult } from './javaBodyGenerator';
+export type { GoExpr, GoStmt } from './goBodyParser';
+export type { ConversionDiagnostic } from './javaGenerator';
+export type { AstDocument, AstFile, AstNode, AstPackage } from './astExport';
+export { AST_SCHEMA_VERSION } from './astExport';
+
+/**
+ * Programmatic entry point for embedding the converter in other tools.
+ * Works on strings only: no VS Code, gopls or filesystem access.
+ */
+
+/**
+ * Error raised when the Go source cannot be converted
+ */
+export class ConversionError extends Error {
+    /** Zero-based position of the syntax error, when known */
+    readonly pos?: SourcePosition;
+    readonly cause: unknown;
+
+    constructor(message: string, cause: unknown, pos?: SourcePosition) {
+        super(message);
+        this.name = 'ConversionError';
+        this.cause = cause;
+        this.pos = pos;
+    }
+}
+
+/**
+ * Java generated for a Go file, with what did not convert cleanly
+ */
+export interface ConversionResult {
+    java: string;
+    /**
+     * One entry per construct left as a TODO (severity `unsupported`) or converted with
+     * different behavior (`degraded`), in the order they were generated
+     */
+    diagnostics: ConversionDiagnostic[];
+}
+
+const DEFAULT_OPTIONS: JavaFileGenerationOptions = {
+    isStatic: true,
+    addComments: true,
+    handleErrorsAsExceptions: true,
+    includeConstructors: true,
+    includeGettersSetters: true,
+    includeComments: true,
+    includeJsonAnnotations: true,
+    addLearningHints: true
+};
+
+/**
+ * Convert the source of a Go file to Java.
+ * @param goSource Contents of a .go file
+ * @param options Overrides for the generation options
+ * @returns The generated Java source
+ * @throws ConversionError with line/column information when the Go source does not parse
+ */
+export async function convert(goSource: string, options: Partial<JavaFileGenerationOptions> = {}): Promise<string> {
+    try {
+        await TreeSitterGoParser.checkSyntax(goSource);
+        const goFile = await TreeSitterGoParser.parseFile(goSource);
+        return JavaFileGenerator.generateJavaFile(goFile, { ...DEFAULT_OPTIONS, ...options });
+    } catch (error) {
+        throw conversionError(error);
+    }
+}
+
+/**
+ * Convert the source of a Go file to Java, collecting a diagnostic for every construct
+ * that does not convert cleanly rather than leaving them to be found in the output
+ * @param goSource Contents of a .go file
+ * @param options Overrides for the generation options
+ * @returns The generated Java source and its diagnostics
+ * @throws ConversionError with line/column information when the Go source does not parse
+ */
+export async function convertWithDiagnostics(goSource: string, options: Partial<JavaFileGenerationOptions> = {}): Promise<ConversionResult> {
+    const diagnostics: ConversionDiagnostic[] = [];
+    const java = await convert(goSource, { ...options, diagnostics });
+    options.diagnostics?.push(...diagnostics);
+    return { java, diagnostics };
+}
+
+/**
+ * The syntax tree the Java of a Go file is generated from, as JSON for other emitters:
+ * its declarations and function bodies, the Go type resolved for each expression and
+ * the Java type and name chosen for each declaration, laid out as described in astExport.ts
+ * @param goSource Contents of a .go file
+ * @param options Overrides for the generation options, deciding the Java types and names
+ * @returns A document of one package holding the file, tagged with AST_SCHEMA_VERSION
+ * @throws ConversionError with line/column information when the Go source does not parse
+ */
+export async function exportAst(goSource: string, options: Partial<JavaFileGenerationOptions> = {}): Promise<AstDocument> {
+    try {
+        await TreeSitterGoParser.checkSyntax(goSource);
+        const goFile = await TreeSitterGoParser.parseFile(goSource);
+        // The types are the ones resolved while generating the Java, which is dropped
+        const diagnostics: ConversionDiagnostic[] = [];
+        const resolvedBodies: ResolvedBody[] = [];
+        const generation = { ...DEFAULT_OPTIONS, ...options, diagnostics, resolvedBodies };
+        JavaFileGenerator.generateJavaFile(goFile, generation);
+        const file = exportFile(goFile, generation, resolvedBodies, diagnostics);
+        return { schemaVersion: AST_SCHEMA_VERSION, packages: [{ name: goFile.packageName, dir: '', files: [file] }] };
+    } catch (error) {
+        throw conversionError(error);
+    }
+}
+
+/**
+ * Convert one type of a Go file to Java: the type with its methods, plus the declarations
+ * of the types it references and the functions, constants and variables its methods use.
+ * @param goSource Contents of a .go file
+ * @param typeName Name of a struct, interface or defined type the file declares
+ * @param options Overrides for the generation options
+ * @returns The generated Java source
+ * @throws ConversionError when the Go source does not parse or does not declare the type
+ */
+export async function convertType(goSource: string, typeName: string, options: Partial<JavaFileGenerationOptions> = {}): Promise<string> {
+    try {
+        await TreeSitterGoParser.checkSyntax(goSource);
+        const part = extractType(await TreeSitterGoParser.parseFile(goSource), typeName);
+        if (!part) {
+            throw new ConversionError(`Go source declares no type '${typeName}'`, undefined);
+        }
+        return JavaFileGenerator.generateJavaFile(part, { ...DEFAULT_OPTIONS, ...options });
+    } catch (error) {
+        throw conversionError(error);
+    }
+}
+
+function conversionError(error: unknown): ConversionError {
+    if (error instanceof ConversionError) {
+        return error;
+    }
+    if (error instanceof GoSyntaxError) {
+        return new ConversionError(`Could not parse Go source: ${error.message}`, error, error.pos);
+    }
+    return new ConversionError(`Could not convert Go source: ${error instanceof Error ? error.message : String(error)}`, error);
+}
@@ -0,0 +1,204 @@
+import { lookupStdlibType } from './conversionContext';
+import { GoBodyParser, GoExpr, GoSyntaxError } from './goBodyParser';
+import { GoField, GoFile, GoVariable } from './goFileParser';
+import { GoFunction, GoFunctionParser, GoType } from './goParser';
+import { JavaBodyGenerator, ResolvedBody } from './javaBodyGenerator';
+import { ConversionDiagnostic, JavaCodeGenerator, JavaGenerationOptions } from './javaGenerator';
+
+/**
+ * JSON form of what the converter builds from Go before it emits any Java: the parsed
+ * declarations, function bodies as syntax trees, the Go type it resolved for each
+ * expression and the Java type and name it chose for each Go one. Tools emitting another
+ * language can build on this front end instead of a Go parser of their own.
+ *
+ * Nodes keep the fields of the parser's types (GoFile, GoStmt, GoExpr, GoType); what the
+ * converter adds is named apart from them:
+ * - `java` on a type: the Java type it becomes (`[]int` → `List<Integer>`)
+ * - `javaName` on a function, method, field, constant or variable: its Java name
+ * - `goType` on an expression: its Go type, when the converter can tell
+ * - `body` on a function: `{source, stmts, comments}`, or `{source, error}` when it does not parse
+ * - `valueExpr` on a constant or variable: its initializer parsed as an expression
+ * Positions are zero-based `{line, character}`, in the file except within a `valueExpr`, where
+ * they are in the initializer; statement spans are offsets into the body source.
+ */
+
+/** Version of the JSON layout, raised whenever a field is removed or changes meaning */
+export const AST_SCHEMA_VERSION = 1;
+
+/** A node of the dump: the parser's fields, plus what the converter resolved */
+export type AstNode = { [field: string]: unknown };
+
+export interface AstDocument {
+    schemaVersion: number;
+    packages: AstPackage[];
+}
+
+export interface AstPackage {
+    name: string;
+    /** Directory relative to the converted one, '' for itself */
+    dir: string;
+    files: AstFile[];
+}
+
+export interface AstFile {
+    /** Path relative to the converted directory; absent for a single source */
+    path?: string;
+    /** The parsed GoFile */
+    file: AstNode;
+    /** What did not convert cleanly to Java, for emitters facing the same limits */
+    diagnostics: ConversionDiagnostic[];
+}
+
+/**
+ * Dump of a parsed Go file
+ * @param options Options the file was generated with, which decide its Java types and names
+ * @param bodies Bodies recorded through options.resolvedBodies while generating it
+ */
+export function exportFile(goFile: GoFile, options: JavaGenerationOptions, bodies: ResolvedBody[], diagnostics: ConversionDiagnostic[], path?: string): AstFile {
+    // Defined types resolve as the file generator resolves them for the whole package; typing
+    // initializers again reports nothing new
+    const scoped: JavaGenerationOptions = {
+        ...options,
+        underlyingTypes: JavaCodeGenerator.underlyingTypeBindings(options, options.packageFile || goFile),
+        diagnostics: undefined,
+        resolvedBodies: undefined
+    };
+    const exporter = new AstExporter(scoped, bodies, options.packageFile || goFile);
+    return { path, file: exporter.file(goFile), diagnostics };
+}
+
+class AstExporter {
+    private types = new Map<GoExpr, GoType>();
+
+    constructor(private readonly options: JavaGenerationOptions, private readonly bodies: ResolvedBody[], private readonly scope: GoFile) {}
+
+    file(goFile: GoFile): AstNode {
+        return {
+            packageName: goFile.packageName,
+            imports: this.json(goFile.imports),
+            structs: goFile.structs.map(s => ({
+                ...this.node(s),
+                fields: s.fields.map(f => this.field(f)),
+                methods: s.methods.map(m => this.func(m))
+            })),
+            interfaces: goFile.interfaces.map(t => ({
+                ...this.node(t),
+                methods: t.methods.map(m => ({ ...this.node(m), javaName: JavaCodeGenerator.memberName(m.name, this.options) }))
+            })),
+            namedTypes: goFile.namedTypes.map(t => ({ ...this.node(t), methods: t.methods.map(m => this.func(m)) })),
+            functions: goFile.functions.map(f => this.func(f)),
+            variables: goFile.variables.map(v => this.variable(v)),
+            constants: goFile.constants.map(v => this.variable(v))
+        };
+    }
+
+    private func(goFunc: GoFunction): AstNode {
+        return { ...this.node(goFunc), javaName: JavaCodeGenerator.memberName(goFunc.name, this.options), body: this.body(goFunc) };
+    }
+
+    /**
+     * The body the generator translated, with its types; one it skipped is parsed here, untyped
+     */
+    private body(goFunc: GoFunction): AstNode | undefined {
+        if (goFunc.body === undefined) {
+            return undefined;
+        }
+        const resolved = this.bodies.find(b => b.function === goFunc)
+            || this.bodies.find(b => b.function.name === goFunc.name && b.function.body === goFunc.body
+                && b.function.bodyPosition?.line === goFunc.bodyPosition?.line);
+        let body = resolved?.body;
+        if (!body) {
+            try {
+                body = GoBodyParser.parseBody(goFunc.body, goFunc.bodyPosition);
+            } catch (error) {
+                if (error instanceof GoSyntaxError) {
+                    return { source: goFunc.body, error: { message: error.message, pos: error.pos } };
+                }
+                throw error;
+            }
+        }
+        resolved?.types.forEach((type, expr) => this.types.set(expr, type));
+        return { source: body.source, stmts: this.json(body.stmts), comments: this.json(body.comments) };
+    }
+
+    private field(field: GoField): AstNode {
+        return { ...this.node(field), javaName: JavaCodeGenerator.memberName(field.name, this.options) };
+    }
+
+    private variable(variable: GoVariable): AstNode {
+        const javaName = variable.isConst
+            ? JavaCodeGenerator.constantName(variable.name, this.options)
+            : JavaCodeGenerator.memberName(variable.name, this.options);
+        let valueExpr: unknown;
+        if (variable.value !== undefined) {
+            try {
+                const expr = GoBodyParser.parseExpression(variable.value);
+                // An untyped declaration takes its type from the initializer, as in the generated field
+                const type = variable.type
+                    || JavaBodyGenerator.translateExpression(variable.value, this.options, this.scope)?.type;
+                if (type) {
+                    this.types.set(expr, type);
+                }
+                valueExpr = this.json(expr);
+            } catch (error) {
+                if (!(error instanceof GoSyntaxError)) {
+                    throw error;
+                }
+            }
+        }
+        return { ...this.node(variable), javaName, valueExpr };
+    }
+
+    /**
+     * The fields of a declaration but its body text and nested declarations, which get their own nodes
+     */
+    private node(value: object): AstNode {
+        const node: AstNode = {};
+        for (const [key, field] of Object.entries(value)) {
+            if (key !== 'body' && key !== 'methods' && key !== 'fields') {
+                node[key] = this.json(field);
+            }
+        }
+        return node;
+    }
+
+    /**
+     * A plain copy of value, each type given its Java type and each expression its Go type
+     */
+    private json(value: unknown): unknown {
+        if (Array.isArray(value)) {
+            return value.map(v => this.json(v));
+        }
+        if (value === null || typeof value !== 'object') {
+            return value;
+        }
+        const node: AstNode = {};
+        for (const [key, field] of Object.entries(value)) {
+            if (field !== undefined) {
+                node[key] = this.json(field);
+            }
+        }
+        if (AstExporter.isGoType(value)) {
+            node.java = this.javaType(value);
+        }
+        const type = this.types.get(value as GoExpr);
+        if (type) {
+            node.goType = this.json(type);
+        }
+        return node;
+    }
+
+    private javaType(type: GoType): string {
+        if (type.isVariadic) {
+            return `${this.javaType(GoFunctionParser.elementTypeOf(type))}...`;
+        }
+        // Standard library types as the generators map them (time.Time → Instant)
+        const stdlib = !type.isSlice && !type.isMap ? lookupStdlibType(type.name, type.packagePath) : undefined;
+        return stdlib ? stdlib.javaType : JavaCodeGenerator.toJavaType(type, this.options);
+    }
+
+    private static isGoType(value: object): value is GoType {
+        const type = value as Partial<GoType>;
+        return typeof type.name === 'string' && typeof type.isPointer === 'boolean' && typeof type.isSlice === 'boolean';
+    }
+}
@@ -0,0 +1,916 @@
+#!/usr/bin/env node
+import { spawnSync } from 'child_process';
+import * as fs from 'fs';
+import * as os from 'os';
+import * as path from 'path';
+import { CONFIG_FILE_NAMES, loadConfig, loadConfigFile } from './config';
+import { AstDocument, AST_SCHEMA_VERSION, exportFile } from './astExport';
+import { CACHE_FILE_NAME, emptyCache, loadCache, outputsIntact, packageCacheId, packageCacheKey, removeStaleOutputs, saveCache, sha256 } from './conversionCache';
+import { GoFile, GoFileParser, GoInterface, GoStruct } from './goFileParser';
+import { GoFunction, JAVA_KEYWORDS, SourcePosition } from './goParser';
+import { JavaFileGenerator, JavaFileGenerationOptions } from './javaFileGenerator';
+import { JavaFormat } from './javaFormatter';
+import { ResolvedBody } from './javaBodyGenerator';
+import { ConversionDiagnostic, JavaCodeGenerator } from './javaGenerator';
+import * as TreeSitterGoParser from './treeSitterGoParser';
+
+/**
+ * Command-line entry point for converting Go sources outside VS Code.
+ *
+ * Usage: go-to-java convert-dir <path> [options]
+ */
+
+const USAGE = `Usage: go-to-java convert-dir <path> [options]
+
+Converts every .go file under <path> into a .java file, mirroring the
+directory tree under the output directory.
+
+Settings are read from ${CONFIG_FILE_NAMES.join(', ')} in the working
+directory if present; the options below override them. Packages whose
+sources and settings are unchanged since the last run, as recorded in
+<out>/${CACHE_FILE_NAME}, are not converted again.
+
+Options:
+  --config <file>            Read settings from this file instead
+  --out <dir>                Output directory (default: ./java-out)
+  --include-tests            Also convert _test.go files
+  --junit                    Convert Go tests into JUnit 5 tests (implies --include-tests)
+  --java-package <name>      Root Java package (default: the input directory's name)
+  --layout <name>            mirror (default) or maven: src/main/java, one file per public type
+  --top-level-type           Make a file's only type its top-level class
+  --static-factories         Move NewT functions into class T as static factories
+  --value-methods            Generate equals/hashCode/toString for structs
+  --java-version <n>         Targeted Java release (default: 11); 17+ turns value types into records
+  --parser <name>            tree-sitter (default) or regex
+  --slice-strategy <name>    list (default) or array
+  --int-type <name>          Java type for Go int: int (default) or long
+  --exception-class <name>   Exception thrown for Go errors (default: Exception)
+  --result-records           Return (value, error) as a record instead of throwing
+  --shared-result            With --result-records, share one generic Result<T> record
+  --empty-collections        Start zero-valued slices and maps empty instead of null
+  --nil-matches-empty        Let s == nil on a slice also hold for an empty one
+  --experimental-concurrency Convert goroutines to executor tasks and channels to BlockingQueues
+  --embedding <name>         Embedded structs as fields: composition (default) or inheritance
+  --defined-types <name>     Defined types over primitives: underlying (default) or wrapper classes
+  --nullability <name>       Nullability annotations: none (default), jsr305 or jetbrains
+  --enums                    Turn typed iota constant groups into Java enums
+  --string-enums             Turn groups of string constants into Java enums with values
+  --java-naming              Rename methods and fields to camelCase, constants to SCREAMING_SNAKE_CASE
+  --infer-implements         Declare the interfaces a struct satisfies with implements
+  --duration-millis          Declare time.Duration constants as long milliseconds
+  --builder                  Give structs with many fields a nested Builder
+  --builder-min-fields <n>   Fewest fields for a builder (default: 4)
+  --annotate-origin          End each translated statement with its Go line: // go:user.go:42
+  --dry-run                  Write nothing; print the unsupported constructs as JSON instead
+  --emit-ast json            Write nothing; print the parsed and type-resolved Go, with the Java
+                             types chosen for it, as JSON for other emitters
+  --force                    Convert every package, even those unchanged since the last run
+  --strict                   Fail when any construct does not convert cleanly, for CI
+  --javac                    Compile the written files with javac to check they are valid Java
+  --format <name>            Layout of the written files: internal (default), google-java-format
+                             (if on PATH, else internal) or none
+  --javabeans                Generate JavaBeans accessors for exported fields
+  --no-json-annotations      Do not emit Jackson annotations for json tags
+  -h, --help                 Show this help`;
+
+interface ConvertDirOptions {
+    inputDir: string;
+    outputDir: string;
+    includeTests: boolean;
+    /** Root Java package; subdirectories append segments */
+    javaPackage?: string;
+    parser: 'regex' | 'tree-sitter';
+    /** Report diagnostics instead of writing Java files */
+    dryRun: boolean;
+    /** Print the syntax trees the Java is generated from instead of writing it */
+    emitAst?: 'json';
+    /** Compile the written files with javac */
+    javac: boolean;
+    /** Convert every package, ignoring the cache of the last run */
+    force: boolean;
+    /** Count every diagnostic as a failure */
+    strict: boolean;
+    /** Formatter of the written files */
+    format: JavaFormat;
+    /** Output tree: the input's directories, or src/main/java with one file per public type */
+    layout: 'mirror' | 'maven';
+    generation: JavaFileGenerationOptions;
+}
+
+/** A diagnostic as printed by --dry-run, and to stderr otherwise; line and column are one-based */
+interface ReportedDiagnostic {
+    file: string;
+    line?: number;
+    column?: number;
+    severity: ConversionDiagnostic['severity'] | 'error';
+    construct: string;
+    message: string;
+    scope?: string;
+}
+
+/** Java classes generated for one Go package */
+interface GeneratedPackage {
+    outputs: { outputDir: string; className: string; content: string }[];
+    /** Diagnostics per Go file, by relative path */
+    diagnostics: Map<string, ConversionDiagnostic[]>;
+}
+
+/** A successfully parsed source file */
+interface ParsedSource {
+    /** Path relative to the input directory */
+    relativePath: string;
+    goFile: GoFile;
+}
+
+/** A source file read from the input directory; unchanged files are only parsed when their package is converted */
+interface SourceFile {
+    relativePath: string;
+    content: string;
+    /** SHA-256 of the content */
+    hash: string;
+    packageName: string;
+    goFile?: GoFile;
+}
+
+/** Files of one Go package: same directory and same package clause */
+interface GoPackage<T = ParsedSource> {
+    relativeDir: string;
+    name: string;
+    sources: T[];
+}
+
+export async function main(argv: string[]): Promise<number> {
+    const [command, ...rest] = argv;
+    if (!command || command === '-h' || command === '--help') {
+        console.log(USAGE);
+        return command ? 0 : 1;
+    }
+    if (command !== 'convert-dir') {
+        console.error(`Unknown command '${command}'\n\n${USAGE}`);
+        return 1;
+    }
+
+    let options: ConvertDirOptions;
+    try {
+        options = parseConvertDirArgs(rest);
+    } catch (error) {
+        console.error(`${error instanceof Error ? error.message : String(error)}\n\n${USAGE}`);
+        return 1;
+    }
+    return convertDirectory(options);
+}
+
+function parseConvertDirArgs(args: string[]): ConvertDirOptions {
+    const value = (i: number, flag: string): string => {
+        if (i >= args.length || args[i].startsWith('--')) {
+            throw new Error(`Missing value for ${flag}`);
+        }
+        return args[i];
+    };
+
+    // The config file supplies the defaults the flags override
+    const configFlag = args.indexOf('--config');
+    const config = configFlag >= 0 ? loadConfigFile(value(configFlag + 1, '--config')) : loadConfig(process.cwd());
+
+    let inputDir: string | undefined;
+    let outputDir = config.out;
+    let includeTests = config.includeTests;
+    let junit = config.junit;
+    let dryRun = false;
+    let emitAst: 'json' | undefined;
+    let javac = false;
+    let force = false;
+    let strict = false;
+    let format = config.format;
+    let layout = config.layout;
+    let javaPackage = config.javaPackage;
+    let topLevelType = config.topLevelType;
+    let staticFactories = config.staticFactories;
+    let valueMethods = config.valueMethods;
+    let javaVersion = config.javaVersion;
+    let parser = config.parser;
+    let sliceStrategy = config.sliceStrategy;
+    let intType = config.intType;
+    let exceptionClass = config.exceptionClass;
+    let javaBeans = config.javaBeans;
+    let errorResultRecords = config.errorResultRecords;
+    let sharedResultRecord = config.sharedResultRecord;
+    let includeJsonAnnotations = config.jsonAnnotations;
+    let emptyCollections = config.emptyCollections;
+    let nilMatchesEmpty = config.nilMatchesEmpty;
+    let experimentalConcurrency = config.experimentalConcurrency;
+    let embedding = config.embedding;
+    let definedTypes = config.definedTypes;
+    let nullability = config.nullability;
+    let enums = config.enums;
+    let stringEnums = config.stringEnums;
+    let javaNaming = config.javaNaming;
+    let inferImplements = config.inferImplements;
+    let durationMillis = config.durationMillis;
+    let builder = config.builder;
+    let builderMinFields = config.builderMinFields;
+    let annotateOrigin = config.annotateOrigin;
+
+    for (let i = 0; i < args.length; i++) {
+        const arg = args[i];
+        switch (arg) {
+            case '--config':
+                i++;
+                break;
+            case '--out':
+                outputDir = value(++i, arg);
+                break;
+            case '--include-tests':
+                includeTests = true;
+                break;
+            case '--junit':
+                junit = true;
+                break;
+            case '--annotate-origin':
+                annotateOrigin = true;
+                break;
+            case '--dry-run':
+                dryRun = true;
+                break;
+            case '--emit-ast': {
+                const name = value(++i, arg);
+                if (name !== 'json') {
+                    throw new Error(`Unknown AST format '${name}'`);
+                }
+                emitAst = name;
+                break;
+            }
+            case '--force':
+                force = true;
+                break;
+            case '--strict':
+                strict = true;
+                break;
+            case '--javac':
+                javac = true;
+                break;
+            case '--format': {
+                const name = value(++i, arg);
+                if (name !== 'internal' && name !== 'google-java-format' && name !== 'none') {
+                    throw new Error(`Unknown formatter '${name}'`);
+                }
+                format = name;
+                break;
+            }
+            case '--layout': {
+                const name = value(++i, arg);
+                if (name !== 'mirror' && name !== 'maven') {
+                    throw new Error(`Unknown layout '${name}'`);
+                }
+                layout = name;
+                break;
+            }
+            case '--java-package':
+                javaPackage = value(++i, arg);
+                if (!JavaFileGenerator.isValidPackageName(javaPackage)) {
+                    throw new Error(`Invalid Java package name '${javaPackage}'`);
+                }
+                break;
+            case '--top-level-type':
+                topLevelType = true;
+                break;
+            case '--static-factories':
+                staticFactories = true;
+                break;
+            case '--value-methods':
+                valueMethods = true;
+                break;
+            case '--java-version': {
+                const version = value(++i, arg);
+                if (!/^\d+$/.test(version) || Number(version) < 8) {
+                    throw new Error(`Invalid Java version '${version}'`);
+                }
+                javaVersion = Number(version);
+                break;
+            }
+            case '--parser': {
+                const name = value(++i, arg);
+                if (name !== 'regex' && name !== 'tree-sitter') {
+                    throw new Error(`Unknown parser '${name}'`);
+                }
+                parser = name;
+                break;
+            }
+            case '--slice-strategy': {
+                const name = value(++i, arg);
+                if (name !== 'list' && name !== 'array') {
+                    throw new Error(`Unknown slice strategy '${name}'`);
+                }
+                sliceStrategy = name;
+                break;
+            }
+            case '--int-type': {
+                const name = value(++i, arg);
+                if (name !== 'int' && name !== 'long') {
+                    throw new Error(`Unknown int type '${name}'`);
+                }
+                intType = name;
+                break;
+            }
+            case '--exception-class':
+                exceptionClass = value(++i, arg);
+                break;
+            case '--result-records':
+                errorResultRecords = true;
+                break;
+            case '--shared-result':
+                sharedResultRecord = true;
+                break;
+            case '--javabeans':
+                javaBeans = true;
+                break;
+            case '--no-json-annotations':
+                includeJsonAnnotations = false;
+                break;
+            case '--empty-collections':
+                emptyCollections = true;
+                break;
+            case '--nil-matches-empty':
+                nilMatchesEmpty = true;
+                break;
+            case '--experimental-concurrency':
+                experimentalConcurrency = true;
+                break;
+            case '--embedding': {
+                const name = value(++i, arg);
+                if (name !== 'composition' && name !== 'inheritance') {
+                    throw new Error(`Unknown embedding strategy '${name}'`);
+                }
+                embedding = name;
+                break;
+            }
+            case '--defined-types': {
+                const name = value(++i, arg);
+                if (name !== 'underlying' && name !== 'wrapper') {
+                    throw new Error(`Unknown defined type strategy '${name}'`);
+                }
+                definedTypes = name;
+                break;
+            }
+            case '--nullability': {
+                const name = value(++i, arg);
+                if (name !== 'none' && name !== 'jsr305' && name !== 'jetbrains') {
+                    throw new Error(`Unknown nullability annotations '${name}'`);
+                }
+                nullability = name;
+                break;
+            }
+            case '--enums':
+                enums = true;
+                break;
+            case '--string-enums':
+                stringEnums = true;
+                break;
+            case '--java-naming':
+                javaNaming = true;
+                break;
+            case '--infer-implements':
+                inferImplements = true;
+                break;
+            case '--duration-millis':
+                durationMillis = true;
+                break;
+            case '--builder':
+                builder = true;
+                break;
+            case '--builder-min-fields': {
+                const count = value(++i, arg);
+                if (!/^\d+$/.test(count)) {
+                    throw new Error(`Invalid field count '${count}'`);
+                }
+                builderMinFields = Number(count);
+                break;
+            }
+            default:
+                if (arg.startsWith('-')) {
+                    throw new Error(`Unknown option '${arg}'`);
+                }
+                if (inputDir) {
+                    throw new Error(`Unexpected argument '${arg}'`);
+                }
+                inputDir = arg;
+        }
+    }
+
+    if (!inputDir) {
+        throw new Error('Missing <path>');
+    }
+    if (javac && dryRun) {
+        throw new Error('--javac needs the files --dry-run does not write');
+    }
+    if (emitAst && (dryRun || javac)) {
+        throw new Error(`--emit-ast writes no files and prints the AST instead of ${dryRun ? 'diagnostics' : 'compiling'}`);
+    }
+
+    return {
+        inputDir: path.resolve(inputDir),
+        outputDir: path.resolve(outputDir),
+        includeTests: includeTests || junit,
+        javaPackage,
+        parser,
+        dryRun,
+        emitAst,
+        javac,
+        force,
+        strict,
+        format,
+        layout,
+        generation: {
+            isStatic: true,
+            addComments: true,
+            handleErrorsAsExceptions: true,
+            includeConstructors: true,
+            includeGettersSetters: true,
+            includeJsonAnnotations,
+            javaBeans,
+            includeComments: true,
+            addLearningHints: true,
+            exceptionClass,
+            sliceStrategy,
+            intType,
+            errorResultRecords,
+            sharedResultRecord,
+            emptyCollections,
+            nilMatchesEmpty,
+            experimentalConcurrency,
+            embedding,
+            definedTypes,
+            nullability,
+            enums,
+            stringEnums,
+            junit,
+            javaNaming,
+            inferImplements,
+            durationMillis,
+            builder,
+            builderMinFields,
+            annotateOrigin,
+            stdlibCalls: config.stdlibCalls,
+            topLevelType,
+            staticFactories,
+            valueMethods,
+            javaVersion,
+            format
+        }
+    };
+}
+
+/**
+ * Convert a directory tree. Files that fail to parse are reported and skipped.
+ * Every diagnostic is printed to stderr as `file:line:column: severity: message`, or with
+ * --dry-run, where nothing is written, to stdout as a JSON array.
+ * With --javac the written files are compiled, and compile errors count as a failure;
+ * with --strict so does every diagnostic.
+ * A package whose cache key and written files match the cache of the last run is skipped,
+ * unless --force is given, and its diagnostics are repeated from the cache; --dry-run
+ * neither reads nor writes the cache.
+ * With --emit-ast nothing is written either, and every package is converted to print
+ * the syntax trees it was converted from, while diagnostics still go to stderr.
+ * @returns Process exit code: 0 when every file converted, 1 otherwise
+ */
+async function convertDirectory(options: ConvertDirOptions): Promise<number> {
+    if (!fs.existsSync(options.inputDir) || !fs.statSync(options.inputDir).isDirectory()) {
+        console.error(`Not a directory: ${options.inputDir}`);
+        return 1;
+    }
+
+    const goPaths = findGoFiles(options.inputDir, options.includeTests);
+    // Dry runs and AST dumps write nothing, so they neither need nor update the cache
+    const writes = !options.dryRun && !options.emitAst;
+    const previous = !writes || options.force ? emptyCache() : loadCache(options.outputDir);
+    const sources: SourceFile[] = [];
+    const report: ReportedDiagnostic[] = [];
+    let failures = 0;
+
+    for (const relativePath of goPaths) {
+        try {
+            const content = fs.readFileSync(path.join(options.inputDir, relativePath), 'utf8');
+            const hash = sha256(content);
+            // A file unchanged since the last run is grouped by its cached package name
+            const cached = Object.values(previous.packages).find(entry => entry.sources[relativePath] === hash);
+            const goFile = cached ? undefined : await parseSource(content, options);
+            sources.push({ relativePath, content, hash, packageName: cached?.name || goFile!.packageName || 'main', goFile });
+        } catch (error) {
+            failures++;
+            report.push({ file: relativePath, severity: 'error', construct: 'File', message: error instanceof Error ? error.message : String(error) });
+        }
+    }
+
+    // Everything besides the sources that the Java depends on, for the cache keys
+    const settings = { ...options, dryRun: undefined, emitAst: undefined, javac: undefined, force: undefined, strict: undefined };
+    const ast: AstDocument = { schemaVersion: AST_SCHEMA_VERSION, packages: [] };
+    const cache = emptyCache();
+    const written: string[] = [];
+    const kept: string[] = [];
+    const writtenBy = new Map<string, string[]>();
+    // Cache entries and removals name the files relative to the output directory
+    const outputPath = (file: string) => path.relative(options.outputDir, file).split(path.sep).join('/');
+    let unchanged = 0;
+    for (const group of groupPackages(sources)) {
+        const id = packageCacheId(group.relativeDir, group.name);
+        const hashes = Object.fromEntries(group.sources.map(s => [s.relativePath, s.hash]));
+        const key = packageCacheKey(settings, hashes);
+        const cached = previous.packages[id];
+        if (cached?.key === key && outputsIntact(options.outputDir, cached)) {
+            cache.packages[id] = cached;
+            kept.push(...Object.keys(cached.outputs).map(file => path.join(options.outputDir, file)));
+            for (const source of group.sources) {
+                report.push(...(cached.diagnostics[source.relativePath] || []).map(d => toReported(source.relativePath, d)));
+            }
+            unchanged += group.sources.length;
+            continue;
+        }
+        try {
+            const parsed: ParsedSource[] = [];
+            for (const source of group.sources) {
+                parsed.push({ relativePath: source.relativePath, goFile: source.goFile || await parseSource(source.content, options) });
+            }
+            const pkg: GoPackage = { relativeDir: group.relativeDir, name: group.name, sources: parsed };
+            // The bodies are recorded as the Java is generated, with the types resolved for it
+            const bodies: ResolvedBody[] = [];
+            const generated = generatePackage(pkg, options.emitAst ? { ...options, generation: { ...options.generation, resolvedBodies: bodies } } : options);
+            for (const source of pkg.sources) {
+                report.push(...generated.diagnostics.get(source.relativePath)!.map(d => toReported(source.relativePath, d)));
+            }
+            if (options.emitAst) {
+                const packageOptions = { ...options.generation, packageFile: mergePackage(pkg) };
+                ast.packages.push({
+                    name: pkg.name,
+                    dir: pkg.relativeDir.split(path.sep).join('/'),
+                    files: pkg.sources.map(s => exportFile(s.goFile, packageOptions, bodies,
+                        generated.diagnostics.get(s.relativePath)!, s.relativePath.split(path.sep).join('/')))
+                });
+            }
+            if (writes) {
+                const files = writePackage(generated);
+                written.push(...files);
+                writtenBy.set(id, files);
+                // Classes the package no longer has would otherwise stay behind
+                if (cached) {
+                    removeStaleOutputs(options.outputDir, cached, files.map(outputPath));
+                }
+                const diagnostics = Object.fromEntries([...generated.diagnostics].filter(([, list]) => list.length > 0));
+                cache.packages[id] = { key, name: group.name, sources: hashes, outputs: {}, diagnostics };
+            }
+        } catch (error) {
+            failures++;
+            const message = error instanceof Error ? error.message : String(error);
+            report.push({ file: group.relativeDir || '.', severity: 'error', construct: 'Package', message: `package ${group.name}: ${message}` });
+        }
+    }
+
+    // Diagnostics only fail a --strict run; errors already count as failures
+    const unclean = report.filter(d => d.severity !== 'error').length;
+    const failed = failures > 0 || (options.strict && unclean > 0);
+    if (options.dryRun) {
+        console.log(JSON.stringify(report, null, 2));
+        return failed ? 1 : 0;
+    }
+    if (options.emitAst) {
+        report.forEach(d => console.error(formatReported(d)));
+        console.log(JSON.stringify(ast, null, 2));
+        return failed ? 1 : 0;
+    }
+    report.forEach(d => console.error(formatReported(d)));
+
+    const skipped = unchanged > 0 ? `; ${unchanged} unchanged since the last run` : '';
+    console.log(`Converted ${sources.length - unchanged} of ${goPaths.length} Go files into ${written.length} Java files in ${options.outputDir}${skipped}`);
+    if (options.format === 'google-java-format' && written.length > 0) {
+        formatWithGoogleJavaFormat(written);
+    }
+    // Outputs are recorded as formatted, so the next run finds them intact
+    for (const [id, files] of writtenBy) {
+        cache.packages[id].outputs = Object.fromEntries(files.map(file => [outputPath(file), sha256(fs.readFileSync(file))]));
+    }
+    // So would the files of a package whose sources are all gone
+    for (const [id, entry] of Object.entries(previous.packages)) {
+        if (!cache.packages[id] && Object.keys(entry.sources).every(file => !goPaths.includes(file))) {
+            removeStaleOutputs(options.outputDir, entry, []);
+        }
+    }
+    saveCache(options.outputDir, cache);
+    if (options.javac && written.length + kept.length > 0 && !compileJava([...written, ...kept])) {
+        failures++;
+    }
+    if (failures > 0) {
+        console.error(`${failures} error(s)`);
+    }
+    if (options.strict && unclean > 0) {
+        console.error(`${unclean} construct(s) did not convert cleanly`);
+    }
+    return failed || failures > 0 ? 1 : 0;
+}
+
+/**
+ * List .go files below root (relative paths, sorted), skipping the
+ * directories the go tool ignores: hidden, `_`-prefixed, testdata and vendor
+ */
+function findGoFiles(root: string, includeTests: boolean): string[] {
+    const result: string[] = [];
+    const walk = (relativeDir: string) => {
+        const entries = fs.readdirSync(path.join(root, relativeDir), { withFileTypes: true })
+            .sort((a, b) => a.name.localeCompare(b.name));
+        for (const entry of entries) {
+            const relativePath = path.join(relativeDir, entry.name);
+            if (entry.isDirectory()) {
+                if (!/^[._]/.test(entry.name) && entry.name !== 'testdata' && entry.name !== 'vendor') {
+                    walk(relativePath);
+                }
+            } else if (entry.isFile() && entry.name.endsWith('.go')) {
+                if (includeTests || !entry.name.endsWith('_test.go')) {
+                    result.push(relativePath);
+                }
+            }
+        }
+    };
+    walk('');
+    return result;
+}
+
+async function parseSource(content: string, options: ConvertDirOptions): Promise<GoFile> {
+    return options.parser === 'tree-sitter'
+        ? TreeSitterGoParser.parseFile(content)
+        : GoFileParser.parseFile(content);
+}
+
+function groupPackages(sources: SourceFile[]): GoPackage<SourceFile>[] {
+    const packages = new Map<string, GoPackage<SourceFile>>();
+    for (const source of sources) {
+        const relativeDir = path.dirname(source.relativePath) === '.' ? '' : path.dirname(source.relativePath);
+        const name = source.packageName;
+        const key = `${relativeDir}\0${name}`;
+        if (!packages.has(key)) {
+            packages.set(key, { relativeDir, name, sources: [] });
+        }
+        packages.get(key)!.sources.push(source);
+    }
+    return [...packages.values()];
+}
+
+/**
+ * A diagnostic as a compiler would print it: `worker.go:12:2: unsupported: message`
+ */
+function formatReported(diagnostic: ReportedDiagnostic): string {
+    const location = [diagnostic.file, diagnostic.line, diagnostic.column].filter(part => part !== undefined).join(':');
+    return `${location}: ${diagnostic.severity}: ${diagnostic.message}`;
+}
+
+function toReported(file: string, diagnostic: ConversionDiagnostic): ReportedDiagnostic {
+    return {
+        file,
+        line: diagnostic.pos && diagnostic.pos.line + 1,
+        column: diagnostic.pos && diagnostic.pos.character + 1,
+        severity: diagnostic.severity,
+        construct: diagnostic.construct,
+        message: diagnostic.message,
+        scope: diagnostic.scope
+    };
+}
+
+/**
+ * Generate one Java class per Go file of the package, plus a class holding the
+ * package-level constants and variables merged across all of its files, and a static
+ * initializer running its init functions.
+ * Every class static-imports its siblings, so cross-file references resolve unqualified.
+ */
+function generatePackage(pkg: GoPackage, options: ConvertDirOptions): GeneratedPackage {
+    const merged = mergePackage(pkg);
+
+    // Mirror the input tree, rooted at --java-package or the input directory's own name
+    const packageSegments = pkg.relativeDir.split(path.sep).filter(Boolean);
+    // _test.go files may declare an external `foo_test` package next to `foo`
+    if (pkg.sources.some(s => s.relativePath.endsWith('_test.go')) && pkg.name.endsWith('_test')) {
+        packageSegments.push(pkg.name);
+    }
+    const javaSegments = [
+        ...(options.javaPackage ? options.javaPackage.split('.') : [toJavaPackageSegment(path.basename(options.inputDir))]),
+        ...packageSegments.map(toJavaPackageSegment)
+    ];
+    const javaPackage = javaSegments.join('.');
+    // The maven layout keeps test sources apart from main ones
+    const outputDir = (test: boolean) => options.layout === 'maven'
+        ? path.join(options.outputDir, 'src', test ? 'test' : 'main', 'java', ...javaSegments)
+        : path.join(options.outputDir, ...javaSegments);
+
+    // Constants, vars and init functions move to the package class; resolve names against the whole package
+    const fileOnly = pkg.sources.map((s): GoFile => ({
+        ...s.goFile,
+        constants: [],
+        variables: [],
+        functions: s.goFile.functions.filter(f => !JavaCodeGenerator.isInitFunction(f))
+    }));
+    // Go runs the inits of a package file by file in the order the files are given, each file's in declaration order
+    const inits = merged.functions.filter(f => JavaCodeGenerator.isInitFunction(f));
+
+    // Java allows one public top-level type per file, so the maven layout gives each exported
+    // type its own file; unexported types stay nested in the file's class with its functions
+    const splitTypes = fileOnly.map(f => options.layout === 'maven'
+        ? [...f.structs, ...f.interfaces.filter(t => !t.typeTerms)].filter(t => /^[A-Z]/.test(t.name))
+        : []);
+    // Constraint interfaces only bound type parameters and generate nothing
+    const rest = fileOnly.map((f, i): GoFile => ({
+        ...f,
+        structs: f.structs.filter(s => !splitTypes[i].includes(s)),
+        interfaces: f.interfaces.filter(t => !splitTypes[i].includes(t) && !t.typeTerms)
+    }));
+    // Enums join their constants in the package class, with the methods of their type
+    const enums = JavaCodeGenerator.enumTypes(options.generation, merged);
+    // A file whose types all moved out needs no class of its own, unless functions remain
+    const hasFileClass = rest.map((f, i) => splitTypes[i].length === 0
+        || f.structs.length + f.interfaces.length > 0
+        || f.namedTypes.some(t => !t.isAlias && !enums.has(t.name))
+        || f.functions.some(func => !JavaCodeGenerator.factoryOwner(func, options.generation, merged)));
+
+    // A nested class may not share its enclosing class's name (user.go usually declares User)
+    const typeNames = new Set([
+        ...[...merged.structs, ...merged.interfaces].map(t => t.name),
+        // So may the enums, struct copies and wrappers defined types become
+        ...merged.namedTypes.filter(t => JavaCodeGenerator.namedTypeForm(t, options.generation, merged) !== 'underlying').map(t => t.name)
+    ]);
+    const classNames = pkg.sources.map((s, i) => {
+        const promoted = JavaFileGenerator.promotedType(rest[i], options.generation);
+        if (promoted) {
+            return promoted.name;
+        }
+        const className = JavaFileGenerator.classNameForFile(path.basename(s.relativePath));
+        return typeNames.has(className) ? `${className}File` : className;
+    });
+    const hasPackageFields = merged.constants.length > 0 || merged.variables.length > 0 || inits.length > 0;
+    let packageClassName = JavaFileGenerator.classNameForFile(pkg.name) + 'Package';
+    while (classNames.includes(packageClassName) || typeNames.has(packageClassName)) {
+        packageClassName += '_';
+    }
+    const fileClasses = classNames.filter((_, i) => hasFileClass[i]);
+    const allClasses = hasPackageFields ? [...fileClasses, packageClassName] : fileClasses;
+    // Like Go, only tests see the functions of _test.go files
+    const testClasses = classNames.filter((_, i) => pkg.sources[i].relativePath.endsWith('_test.go'));
+    const visibleClasses = (test: boolean) => test ? allClasses : allClasses.filter(c => !testClasses.includes(c));
+
+    const outputs: GeneratedPackage['outputs'] = [];
+    const diagnostics = new Map<string, ConversionDiagnostic[]>();
+    pkg.sources.forEach((source, i) => {
+        diagnostics.set(source.relativePath, []);
+        const test = source.relativePath.endsWith('_test.go');
+        const generate = (goFile: GoFile, className: string, topLevelType?: boolean) => {
+            const content = JavaFileGenerator.generateJavaFile(goFile, {
+                ...options.generation,
+                topLevelType: topLevelType ?? options.generation.topLevelType,
+                diagnostics: diagnostics.get(source.relativePath),
+                sourceFile: path.basename(source.relativePath),
+                packageName: javaPackage,
+                className,
+                staticImports: visibleClasses(test).filter(c => c !== className).map(c => `${javaPackage}.${c}`),
+                packageFile: { ...merged, imports: source.goFile.imports }
+            });
+            outputs.push({ outputDir: outputDir(test), className, content });
+        };
+        for (const type of splitTypes[i]) {
+            const isStruct = fileOnly[i].structs.includes(type as GoStruct);
+            generate({
+                ...fileOnly[i],
+                structs: isStruct ? [type as GoStruct] : [],
+                interfaces: isStruct ? [] : [type as GoInterface],
+                namedTypes: [],
+                functions: []
+            }, type.name, true);
+        }
+        if (hasFileClass[i]) {
+            generate(rest[i], classNames[i]);
+        }
+    });
+
+    if (hasPackageFields) {
+        const packageFields: GoFile = {
+            packageName: pkg.name,
+            imports: merged.imports,
+            structs: [],
+            interfaces: [],
+            namedTypes: [],
+            functions: inits,
+            variables: merged.variables,
+            constants: merged.constants
+        };
+        const packageDiagnostics: ConversionDiagnostic[] = [];
+        const content = JavaFileGenerator.generateJavaFile(packageFields, {
+            ...options.generation,
+            diagnostics: packageDiagnostics,
+            packageName: javaPackage,
+            className: packageClassName,
+            staticImports: visibleClasses(false).filter(c => c !== packageClassName).map(c => `${javaPackage}.${c}`),
+            packageFile: merged
+        });
+        outputs.push({ outputDir: outputDir(false), className: packageClassName, content });
+
+        // Package-class diagnostics are scoped to a variable, a constant or an init; file them under its Go file
+        for (const diagnostic of packageDiagnostics) {
+            const source = pkg.sources.find(s => [...s.goFile.variables, ...s.goFile.constants].some(v => v.name === diagnostic.scope))
+                || pkg.sources.find(s => s.goFile.functions.some(f => JavaCodeGenerator.isInitFunction(f) && initSpans(f, diagnostic.pos)))
+                || pkg.sources[0];
+            diagnostics.get(source.relativePath)!.push(diagnostic);
+        }
+    }
+
+    return { outputs, diagnostics };
+}
+
+/**
+ * The declarations of all files of a package, which names in any of them resolve against
+ */
+function mergePackage(pkg: GoPackage): GoFile {
+    return {
+        packageName: pkg.name,
+        imports: pkg.sources.flatMap(s => s.goFile.imports),
+        structs: pkg.sources.flatMap(s => s.goFile.structs),
+        interfaces: pkg.sources.flatMap(s => s.goFile.interfaces),
+        namedTypes: pkg.sources.flatMap(s => s.goFile.namedTypes),
+        functions: pkg.sources.flatMap(s => s.goFile.functions),
+        variables: pkg.sources.flatMap(s => s.goFile.variables),
+        constants: pkg.sources.flatMap(s => s.goFile.constants)
+    };
+}
+
+/**
+ * Whether a position lies within the body of an init function
+ */
+function initSpans(init: GoFunction, pos?: SourcePosition): boolean {
+    if (!pos || !init.bodyPosition || init.body === undefined) {
+        return false;
+    }
+    const lastLine = init.bodyPosition.line + init.body.split('\n').length - 1;
+    return pos.line >= init.bodyPosition.line && pos.line <= lastLine;
+}
+
+/**
+ * Write a generated package's classes
+ * @returns Paths of the written files
+ */
+function writePackage(generated: GeneratedPackage): string[] {
+    return generated.outputs.map(output => {
+        const file = path.join(output.outputDir, `${output.className}.java`);
+        fs.mkdirSync(output.outputDir, { recursive: true });
+        fs.writeFileSync(file, output.content.endsWith('\n') ? output.content : output.content + '\n');
+        return file;
+    });
+}
+
+/**
+ * Reformat the written files in place with google-java-format in its AOSP style, whose
+ * four-space indentation matches the internal formatter. When it is not on PATH or cannot
+ * format a file, a warning is printed and the internally formatted files are kept.
+ */
+function formatWithGoogleJavaFormat(files: string[]): void {
+    const result = spawnSync('google-java-format', ['--aosp', '--replace', ...files], { encoding: 'utf8' });
+    if (result.error) {
+        const reason = (result.error as NodeJS.ErrnoException).code === 'ENOENT' ? 'google-java-format is not on PATH' : result.error.message;
+        console.warn(`Using the internal formatter: ${reason}`);
+    } else if (result.status !== 0) {
+        console.warn(`google-java-format failed, keeping the internal formatting:\n${result.stderr}${result.stdout}`);
+    } else {
+        console.log(`google-java-format formatted ${files.length} Java files`);
+    }
+}
+
+/**
+ * Compile the written files with javac into a scratch directory, as a check that
+ * the generated code is valid Java. Skipped with a warning when javac is not on PATH;
+ * libraries such as Jackson are found through the CLASSPATH environment variable.
+ * @returns false when javac reported errors, which are printed
+ */
+function compileJava(files: string[]): boolean {
+    const classesDir = fs.mkdtempSync(path.join(os.tmpdir(), 'go-to-java-'));
+    try {
+        const result = spawnSync('javac', ['-d', classesDir, ...files], { encoding: 'utf8' });
+        if (result.error) {
+            const reason = (result.error as NodeJS.ErrnoException).code === 'ENOENT' ? 'javac is not on PATH' : result.error.message;
+            console.warn(`Skipping the compile check: ${reason}`);
+            return true;
+        }
+        if (result.status !== 0) {
+            console.error(`javac failed:\n${result.stderr}${result.stdout}`);
+            return false;
+        }
+        console.log(`javac compiled ${files.length} Java files`);
+        return true;
+    } finally {
+        fs.rmSync(classesDir, { recursive: true, force: true });
+    }
+}
+
+function toJavaPackageSegment(name: string): string {
+    const segment = name.toLowerCase().replace(/[^a-z0-9_]/g, '_');
+    if (/^\d/.test(segment) || JAVA_KEYWORDS.has(segment)) {
+        return `_${segment}`;
+    }
+    return segment;
+}
+
+if (require.main === module) {
+    main(process.argv.slice(2)).then(code => {
+        process.exitCode = code;
+    });
+}
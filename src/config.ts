@@ -0,0 +1,279 @@
+import * as fs from 'fs';
+import * as path from 'path';
+import { IntType, SliceStrategy } from './goParser';
+import { JavaFileGenerator } from './javaFileGenerator';
+import { JavaFormat } from './javaFormatter';
+import { DefinedTypeStrategy, EmbeddingStrategy } from './javaGenerator';
+import { Nullability } from './javaImports';
+import { StdlibCallMappings } from './conversionContext';
+
+/**
+ * Project-wide conversion settings of `convert-dir`, read from a `.go2java.yaml`
+ * (or `.go2java.yml` / `.go2java.json`) in the working directory. Keys are named after
+ * the extension's `goToJava.*` settings; command-line flags override them.
+ */
+export interface Config {
+    /** Output directory, relative to the working directory */
+    out: string;
+    includeTests: boolean;
+    junit: boolean;
+    /** Root Java package; defaults to the input directory's name */
+    javaPackage?: string;
+    layout: 'mirror' | 'maven';
+    parser: 'regex' | 'tree-sitter';
+    sliceStrategy: SliceStrategy;
+    intType: IntType;
+    exceptionClass: string;
+    errorResultRecords: boolean;
+    sharedResultRecord: boolean;
+    jsonAnnotations: boolean;
+    javaBeans: boolean;
+    emptyCollections: boolean;
+    nilMatchesEmpty: boolean;
+    experimentalConcurrency: boolean;
+    embedding: EmbeddingStrategy;
+    definedTypes: DefinedTypeStrategy;
+    nullability: Nullability;
+    enums: boolean;
+    stringEnums: boolean;
+    javaNaming: boolean;
+    inferImplements: boolean;
+    durationMillis: boolean;
+    builder: boolean;
+    builderMinFields: number;
+    annotateOrigin: boolean;
+    /** Java translations of standard library calls, by import path and function name */
+    stdlibCalls: StdlibCallMappings;
+    topLevelType: boolean;
+    staticFactories: boolean;
+    valueMethods: boolean;
+    javaVersion: number;
+    format: JavaFormat;
+}
+
+export const DEFAULT_CONFIG: Config = {
+    out: 'java-out',
+    includeTests: false,
+    junit: false,
+    layout: 'mirror',
+    parser: 'tree-sitter',
+    sliceStrategy: 'list',
+    intType: 'int',
+    exceptionClass: 'Exception',
+    errorResultRecords: false,
+    sharedResultRecord: false,
+    jsonAnnotations: true,
+    javaBeans: false,
+    emptyCollections: false,
+    nilMatchesEmpty: false,
+    experimentalConcurrency: false,
+    embedding: 'composition',
+    definedTypes: 'underlying',
+    nullability: 'none',
+    enums: false,
+    stringEnums: false,
+    javaNaming: false,
+    inferImplements: false,
+    durationMillis: false,
+    builder: false,
+    builderMinFields: 4,
+    annotateOrigin: false,
+    stdlibCalls: {},
+    topLevelType: false,
+    staticFactories: false,
+    valueMethods: false,
+    javaVersion: 11,
+    format: 'internal'
+};
+
+/** Config file names looked for in the working directory, first match wins */
+export const CONFIG_FILE_NAMES = ['.go2java.yaml', '.go2java.yml', '.go2java.json'];
+
+/** Accepted values per key: a JSON type, or the allowed strings */
+const CONFIG_SCHEMA: { [key in keyof Config]-?: 'boolean' | 'string' | 'number' | 'object' | string[] } = {
+    out: 'string',
+    includeTests: 'boolean',
+    junit: 'boolean',
+    javaPackage: 'string',
+    layout: ['mirror', 'maven'],
+    parser: ['regex', 'tree-sitter'],
+    sliceStrategy: ['list', 'array'],
+    intType: ['int', 'long'],
+    exceptionClass: 'string',
+    errorResultRecords: 'boolean',
+    sharedResultRecord: 'boolean',
+    jsonAnnotations: 'boolean',
+    javaBeans: 'boolean',
+    emptyCollections: 'boolean',
+    nilMatchesEmpty: 'boolean',
+    experimentalConcurrency: 'boolean',
+    embedding: ['composition', 'inheritance'],
+    definedTypes: ['underlying', 'wrapper'],
+    nullability: ['none', 'jsr305', 'jetbrains'],
+    enums: 'boolean',
+    stringEnums: 'boolean',
+    javaNaming: 'boolean',
+    inferImplements: 'boolean',
+    durationMillis: 'boolean',
+    builder: 'boolean',
+    builderMinFields: 'number',
+    annotateOrigin: 'boolean',
+    stdlibCalls: 'object',
+    topLevelType: 'boolean',
+    staticFactories: 'boolean',
+    valueMethods: 'boolean',
+    javaVersion: 'number',
+    format: ['internal', 'google-java-format', 'none']
+};
+
+/**
+ * The config of a directory: its config file merged over the defaults,
+ * or the defaults when it has none
+ */
+export function loadConfig(dir: string): Config {
+    const file = CONFIG_FILE_NAMES.map(name => path.join(dir, name)).find(f => fs.existsSync(f));
+    return file ? loadConfigFile(file) : { ...DEFAULT_CONFIG };
+}
+
+/**
+ * Read and validate a config file; JSON if it ends in .json, YAML otherwise.
+ * Unknown keys and values of the wrong type are errors naming the file.
+ */
+export function loadConfigFile(file: string): Config {
+    const name = path.basename(file);
+    let text: string;
+    try {
+        text = fs.readFileSync(file, 'utf8');
+    } catch {
+        throw new Error(`Cannot read config file ${file}`);
+    }
+    let raw: unknown;
+    if (file.endsWith('.json')) {
+        try {
+            raw = JSON.parse(text);
+        } catch (error) {
+            throw new Error(`${name}: ${error instanceof Error ? error.message : String(error)}`);
+        }
+        if (typeof raw !== 'object' || raw === null || Array.isArray(raw)) {
+            throw new Error(`${name}: expected an object of settings`);
+        }
+    } else {
+        raw = parseYaml(text, name);
+    }
+    return validateConfig(raw as { [key: string]: unknown }, name);
+}
+
+function validateConfig(raw: { [key: string]: unknown }, name: string): Config {
+    const config: Config = { ...DEFAULT_CONFIG };
+    for (const [key, value] of Object.entries(raw)) {
+        if (!(key in CONFIG_SCHEMA)) {
+            throw new Error(`${name}: unknown key '${key}'; known keys are ${Object.keys(CONFIG_SCHEMA).join(', ')}`);
+        }
+        const expected = CONFIG_SCHEMA[key as keyof Config];
+        if (Array.isArray(expected)) {
+            if (typeof value !== 'string' || !expected.includes(value)) {
+                throw new Error(`${name}: '${key}' must be one of ${expected.join(', ')}, not ${JSON.stringify(value)}`);
+            }
+        } else if (expected === 'object' ? typeof value !== 'object' || value === null || Array.isArray(value) : typeof value !== expected) {
+            throw new Error(`${name}: '${key}' must be a${expected === 'object' ? 'n' : ''} ${expected}, not ${JSON.stringify(value)}`);
+        }
+        (config as unknown as { [key: string]: unknown })[key] = value;
+    }
+    if (config.javaPackage !== undefined && !JavaFileGenerator.isValidPackageName(config.javaPackage)) {
+        throw new Error(`${name}: invalid Java package name '${config.javaPackage}'`);
+    }
+    if (!Number.isInteger(config.javaVersion) || config.javaVersion < 8) {
+        throw new Error(`${name}: invalid Java version ${config.javaVersion}`);
+    }
+    if (!Number.isInteger(config.builderMinFields) || config.builderMinFields < 0) {
+        throw new Error(`${name}: invalid field count ${config.builderMinFields}`);
+    }
+    for (const [call, mapping] of Object.entries(config.stdlibCalls)) {
+        const valid = typeof mapping === 'string' || (typeof mapping === 'object' && mapping !== null
+            && typeof mapping.java === 'string' && ['undefined', 'string'].includes(typeof mapping.returns));
+        if (!valid || !/^[\w./-]+\.\w+$/.test(call)) {
+            throw new Error(`${name}: invalid stdlibCalls entry '${call}'; expected "package.Func": "<Java template>" or { "java": ..., "returns": ... }`);
+        }
+    }
+    return config;
+}
+
+/**
+ * The subset of YAML a config needs: one `key: value` per line, with string, number and
+ * true/false values, `#` comments and an optional `---` document start. A key without a
+ * value opens a map of the indented `key: value` lines below it (`stdlibCalls:`).
+ */
+function parseYaml(text: string, name: string): { [key: string]: unknown } {
+    const result: { [key: string]: unknown } = {};
+    let nested: { key: string; map: { [key: string]: unknown } } | undefined;
+    text.split(/\r?\n/).forEach((line, i) => {
+        const content = stripYamlComment(line).trimEnd();
+        if (!content.trim() || content === '---') {
+            return;
+        }
+        if (/^\s/.test(content)) {
+            const entry = content.trim().match(/^([\w./-]+)\s*:\s+(.+)$/);
+            if (!nested || !entry) {
+                throw new Error(`${name}:${i + 1}: ${nested ? `expected 'key: value' under '${nested.key}'` : 'unexpected indentation'}, found '${line.trim()}'`);
+            }
+            if (entry[1] in nested.map) {
+                throw new Error(`${name}:${i + 1}: duplicate key '${entry[1]}' in '${nested.key}'`);
+            }
+            nested.map[entry[1]] = parseYamlScalar(entry[2]);
+            return;
+        }
+        const match = content.match(/^([A-Za-z_][\w-]*)\s*:\s*(.*)$/);
+        if (!match) {
+            throw new Error(`${name}:${i + 1}: expected 'key: value', found '${line.trim()}'`);
+        }
+        const [, key, value] = match;
+        if (key in result) {
+            throw new Error(`${name}:${i + 1}: duplicate key '${key}'`);
+        }
+        if (value) {
+            result[key] = parseYamlScalar(value);
+            nested = undefined;
+        } else {
+            nested = { key, map: {} };
+            result[key] = nested.map;
+        }
+    });
+    return result;
+}
+
+/**
+ * A line without its comment: a `#` at the start or after whitespace, outside a quoted
+ * value (`pattern: "a #b"` keeps its value). Quotes open a value only where it starts.
+ */
+function stripYamlComment(line: string): string {
+    let quote: string | undefined;
+    for (let i = 0; i < line.length; i++) {
+        const ch = line[i];
+        if (quote) {
+            if (ch === '\\' && quote === '"') {
+                i++;
+            } else if (ch === quote) {
+                quote = undefined;
+            }
+        } else if (i > 0 && /\s/.test(line[i - 1]) && (ch === '"' || ch === '\'')) {
+            quote = ch;
+        } else if (ch === '#' && (i === 0 || /\s/.test(line[i - 1]))) {
+            return line.slice(0, i);
+        }
+    }
+    return line;
+}
+
+function parseYamlScalar(value: string): unknown {
+    const quoted = value.match(/^(["'])(.*)\1$/);
+    if (quoted) {
+        return quoted[1] === '"' ? JSON.parse(value) : quoted[2].replace(/''/g, '\'');
+    }
+    if (value === 'true' || value === 'false') {
+        return value === 'true';
+    }
+    if (/^-?\d+(\.\d+)?$/.test(value)) {
+        return Number(value);
+    }
+    return value;
+}
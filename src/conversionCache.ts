@@ -0,0 +1,133 @@
+import { createHash } from 'crypto';
+import * as fs from 'fs';
+import * as path from 'path';
+import { ConversionDiagnostic } from './javaGenerator';
+
+/**
+ * What `convert-dir` remembers between runs in `<out>/.go2java-cache`, so that packages
+ * whose sources have not changed are not converted again. A package is the unit: a class
+ * depends on every file of its package, so a change to one file converts all of them.
+ *
+ * The file is JSON. Readers take the fields they know and ignore the rest, and later
+ * versions only add fields, so a cache written by a newer converter still reads; one that
+ * cannot be read at all is treated as empty and rewritten.
+ */
+export interface ConversionCache {
+    /** Layout version of the file */
+    version: number;
+    /** Cached packages, by directory and package name (see packageCacheId) */
+    packages: { [id: string]: CachedPackage };
+}
+
+export interface CachedPackage {
+    /** Hash of the converter version, the conversion options and every source of the package */
+    key: string;
+    /** Go package name, so an unchanged file is grouped without parsing it */
+    name: string;
+    /** SHA-256 of each source, by path relative to the input directory */
+    sources: { [relativePath: string]: string };
+    /** SHA-256 of each written file as it was left on disk, by path relative to the output directory */
+    outputs: { [relativePath: string]: string };
+    /** What the conversion reported, by source path; sources without diagnostics are left out */
+    diagnostics: { [relativePath: string]: ConversionDiagnostic[] };
+}
+
+export const CACHE_FILE_NAME = '.go2java-cache';
+
+const CACHE_VERSION = 1;
+
+/** Id of a package's entry: its directory and package name, as a directory may hold `foo` and `foo_test` */
+export function packageCacheId(relativeDir: string, name: string): string {
+    return `${relativeDir.split(path.sep).join('/') || '.'}:${name}`;
+}
+
+export function sha256(content: string | Buffer): string {
+    return createHash('sha256').update(content).digest('hex');
+}
+
+/**
+ * The converter's own version, from its package.json; part of every cache key, so that
+ * upgrading the converter converts everything again
+ */
+export function converterVersion(): string {
+    try {
+        return JSON.parse(fs.readFileSync(path.join(__dirname, '..', 'package.json'), 'utf8')).version || 'unknown';
+    } catch {
+        return 'unknown';
+    }
+}
+
+/**
+ * Cache key of a package: changes with the converter, the options or any of its sources
+ * @param options Everything besides the sources that the generated Java depends on
+ */
+export function packageCacheKey(options: unknown, sources: { [relativePath: string]: string }): string {
+    const files = Object.keys(sources).sort().map(file => [file, sources[file]]);
+    return sha256(JSON.stringify([converterVersion(), options, files]));
+}
+
+/**
+ * Read the cache of an output directory; missing or unreadable caches are empty
+ */
+export function loadCache(outputDir: string): ConversionCache {
+    const cache = emptyCache();
+    let raw: unknown;
+    try {
+        raw = JSON.parse(fs.readFileSync(path.join(outputDir, CACHE_FILE_NAME), 'utf8'));
+    } catch {
+        return cache;
+    }
+    const packages = (raw as { packages?: unknown })?.packages;
+    if (typeof packages !== 'object' || packages === null) {
+        return cache;
+    }
+    const isHashes = (value: unknown) => typeof value === 'object' && value !== null
+        && Object.values(value).every(hash => typeof hash === 'string');
+    const isDiagnostics = (value: unknown) => typeof value === 'object' && value !== null
+        && Object.values(value).every(list => Array.isArray(list)
+            && list.every(d => typeof d?.severity === 'string' && typeof d.construct === 'string' && typeof d.message === 'string'));
+    // Entries from before diagnostics were cached are converted again, to report theirs
+    for (const [id, entry] of Object.entries(packages as { [id: string]: Partial<CachedPackage> })) {
+        if (typeof entry?.key === 'string' && typeof entry.name === 'string' && isHashes(entry.sources) && isHashes(entry.outputs)
+            && isDiagnostics(entry.diagnostics)) {
+            cache.packages[id] = { key: entry.key, name: entry.name, sources: entry.sources!, outputs: entry.outputs!, diagnostics: entry.diagnostics! };
+        }
+    }
+    return cache;
+}
+
+export function emptyCache(): ConversionCache {
+    return { version: CACHE_VERSION, packages: {} };
+}
+
+export function saveCache(outputDir: string, cache: ConversionCache): void {
+    fs.mkdirSync(outputDir, { recursive: true });
+    fs.writeFileSync(path.join(outputDir, CACHE_FILE_NAME), JSON.stringify(cache, null, 2) + '\n');
+}
+
+/**
+ * Delete the files a cached package wrote that are not among those still written for it,
+ * like the class of a struct since removed from the package
+ * @param kept Paths to keep, relative to the output directory
+ */
+export function removeStaleOutputs(outputDir: string, entry: CachedPackage, kept: string[]): void {
+    const keep = new Set(kept);
+    for (const file of Object.keys(entry.outputs)) {
+        if (!keep.has(file)) {
+            fs.rmSync(path.join(outputDir, file), { force: true });
+        }
+    }
+}
+
+/**
+ * Whether the files a cached package wrote are still there as they were left
+ */
+export function outputsIntact(outputDir: string, entry: CachedPackage): boolean {
+    return Object.entries(entry.outputs).every(([file, hash]) => {
+        try {
+            return sha256(fs.readFileSync(path.join(outputDir, file))) === hash;
+        } catch {
+            return false;
+        }
+    });
+}
@@ -233,6 +233,90 @@ export const STDLIB_TYPE_MAPPINGS: Map<string, StdlibTypeMapping> = new Map([
     }],
 ]);
 
+/**
+ * Java translation of a call into a Go standard library function
+ */
+export interface StdlibCallMapping {
+    /** Java expression; `$1`, `$2`, ... stand for the arguments and `$*` for all of them */
+    java: string;
+
+    /** Java expression used instead when Go's int maps to long (goToJava.intType) */
+    javaForLong?: string;
+
+    /** Go result types, as in a signature (`int, error`); without them the result's type is unknown */
+    returns?: string;
+}
+
+/**
+ * Call mappings as configured (goToJava.stdlibCalls), keyed by import path and function
+ * name (`strings.Fields`, `path/filepath.Join`): a Java template alone, or a full mapping
+ */
+export type StdlibCallMappings = { [call: string]: string | StdlibCallMapping };
+
+/**
+ * Mapping of common Go stdlib functions to Java expressions, keyed by import path and
+ * function name. A call with no entry here or in the configured mappings is left as a TODO.
+ * fmt's print functions, fmt.Sprintf, fmt.Errorf and errors.New are translated by the
+ * body generator itself.
+ */
+export const STDLIB_CALL_MAPPINGS: Map<string, StdlibCallMapping> = new Map([
+    // strings package
+    ['strings.Contains', { java: '$1.contains($2)', returns: 'bool' }],
+    ['strings.HasPrefix', { java: '$1.startsWith($2)', returns: 'bool' }],
+    ['strings.HasSuffix', { java: '$1.endsWith($2)', returns: 'bool' }],
+    ['strings.EqualFold', { java: '$1.equalsIgnoreCase($2)', returns: 'bool' }],
+    ['strings.Index', { java: '$1.indexOf($2)', returns: 'int' }],
+    ['strings.LastIndex', { java: '$1.lastIndexOf($2)', returns: 'int' }],
+    ['strings.ToUpper', { java: '$1.toUpperCase()', returns: 'string' }],
+    ['strings.ToLower', { java: '$1.toLowerCase()', returns: 'string' }],
+    ['strings.TrimSpace', { java: '$1.trim()', returns: 'string' }],
+    ['strings.ReplaceAll', { java: '$1.replace($2, $3)', returns: 'string' }],
+    // Takes a List or an array alike
+    ['strings.Join', { java: 'String.join($2, $1)', returns: 'string' }],
+
+    // strconv package; Java's parse methods throw NumberFormatException where Go returns an error
+    ['strconv.Itoa', { java: 'Integer.toString($1)', javaForLong: 'Long.toString($1)', returns: 'string' }],
+    ['strconv.Atoi', { java: 'Integer.parseInt($1)', javaForLong: 'Long.parseLong($1)', returns: 'int, error' }],
+    ['strconv.FormatInt', { java: 'Long.toString($1, $2)', returns: 'string' }],
+    ['strconv.ParseInt', { java: 'Long.parseLong($1, $2)', returns: 'int64, error' }],
+    ['strconv.FormatBool', { java: 'String.valueOf($1)', returns: 'string' }],
+    ['strconv.ParseFloat', { java: 'Double.parseDouble($1)', returns: 'float64, error' }],
+
+    // math package
+    ['math.Abs', { java: 'Math.abs($1)', returns: 'float64' }],
+    ['math.Max', { java: 'Math.max($1, $2)', returns: 'float64' }],
+    ['math.Min', { java: 'Math.min($1, $2)', returns: 'float64' }],
+    ['math.Sqrt', { java: 'Math.sqrt($1)', returns: 'float64' }],
+    ['math.Pow', { java: 'Math.pow($1, $2)', returns: 'float64' }],
+    ['math.Floor', { java: 'Math.floor($1)', returns: 'float64' }],
+    ['math.Ceil', { java: 'Math.ceil($1)', returns: 'float64' }],
+
+    // unicode package
+    ['unicode.IsDigit', { java: 'Character.isDigit($1)', returns: 'bool' }],
+    ['unicode.IsLetter', { java: 'Character.isLetter($1)', returns: 'bool' }],
+    ['unicode.IsSpace', { java: 'Character.isWhitespace($1)', returns: 'bool' }],
+    ['unicode.IsUpper', { java: 'Character.isUpperCase($1)', returns: 'bool' }],
+    ['unicode.IsLower', { java: 'Character.isLowerCase($1)', returns: 'bool' }],
+    ['unicode.ToUpper', { java: 'Character.toUpperCase($1)', returns: 'rune' }],
+    ['unicode.ToLower', { java: 'Character.toLowerCase($1)', returns: 'rune' }],
+
+    // time package
+    ['time.Now', { java: 'Instant.now()', returns: 'time.Time' }],
+    ['time.Since', { java: 'Duration.between($1, Instant.now())', returns: 'time.Duration' }],
+
+    // os and reflect packages
+    ['os.Exit', { java: 'System.exit($1)' }],
+    ['reflect.DeepEqual', { java: 'Objects.deepEquals($1, $2)', returns: 'bool' }],
+]);
+
+/**
+ * Whether an import path names a standard library package: only module paths
+ * have a dot in their first element (`github.com/...`, `golang.org/x/...`)
+ */
+export function isStdlibImport(path: string): boolean {
+    return !path.split('/')[0].includes('.');
+}
+
 /**
  * Create a ConversionContext from a GoFile and optional dependency graph.
  */
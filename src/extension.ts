@@ -1,12 +1,14 @@
 import * as vscode from 'vscode';
-import { GoFunctionParser, GoFunction } from './goParser';
+import { GoFunctionParser, GoFunction, IntType, SliceStrategy } from './goParser';
 import { JavaCodeGenerator, JavaGenerationOptions } from './javaGenerator';
+import { Nullability } from './javaImports';
 import { GoToJavaHoverProvider } from './hoverProvider';
 import { JavaPreviewProvider } from './previewProvider';
 import { findFunctionHeader } from './functionLocator';
 import * as TreeSitterGoParser from './treeSitterGoParser';
 import { TypeEnricher } from './typeEnricher';
 import { typeCache } from './typeCache';
+import { StdlibCallMappings } from './conversionContext';
 
 export function activate(context: vscode.ExtensionContext) {
     // Register hover provider
@@ -16,8 +18,10 @@ export function activate(context: vscode.ExtensionContext) {
     );
     context.subscriptions.push(hoverProvider);
 
-    // Register preview provider
-    const previewProvider = new JavaPreviewProvider();
+    // Register preview provider; its diagnostics mark what the preview could not convert cleanly
+    const previewDiagnostics = vscode.languages.createDiagnosticCollection('goToJava');
+    context.subscriptions.push(previewDiagnostics);
+    const previewProvider = new JavaPreviewProvider(previewDiagnostics);
     context.subscriptions.push(
         vscode.workspace.registerTextDocumentContentProvider(
             JavaPreviewProvider.scheme,
@@ -37,6 +41,21 @@ export function activate(context: vscode.ExtensionContext) {
     // Track active previews for refresh
     const activePreviewUris = new Map<string, vscode.Uri>();
 
+    // A closed preview no longer marks its Go file
+    context.subscriptions.push(
+        vscode.workspace.onDidCloseTextDocument((doc) => {
+            if (doc.uri.scheme !== JavaPreviewProvider.scheme) {
+                return;
+            }
+            for (const [source, previewUri] of activePreviewUris) {
+                if (previewUri.toString() === doc.uri.toString()) {
+                    activePreviewUris.delete(source);
+                    previewProvider.clearDiagnostics(vscode.Uri.parse(source));
+                }
+            }
+        })
+    );
+
     // Command: Preview file as Java
     context.subscriptions.push(
         vscode.commands.registerCommand('goToJava.previewFile', async () => {
@@ -138,9 +157,15 @@ export function activate(context: vscode.ExtensionContext) {
         }
 
         const parserChoice = config.get<'regex' | 'tree-sitter'>('parser', 'tree-sitter');
-        let goFunction = parserChoice === 'tree-sitter'
-            ? await TreeSitterGoParser.parseFunction(selectedText)
-            : GoFunctionParser.parseFunction(selectedText);
+        let goFunction: GoFunction | null;
+        try {
+            goFunction = parserChoice === 'tree-sitter'
+                ? await TreeSitterGoParser.parseFunction(selectedText)
+                : GoFunctionParser.parseFunction(selectedText);
+        } catch (error) {
+            vscode.window.showErrorMessage(error instanceof Error ? error.message : String(error));
+            return;
+        }
         if (!goFunction) {
             vscode.window.showErrorMessage('Could not parse Go function. Please select a valid function definition.');
             return;
@@ -168,7 +193,17 @@ export function activate(context: vscode.ExtensionContext) {
             isStatic: true,
             addComments: true,
             handleErrorsAsExceptions: true,
-            addLearningHints: true
+            addLearningHints: true,
+            exceptionClass: config.get<string>('exceptionClass', 'Exception'),
+            sliceStrategy: config.get<SliceStrategy>('sliceStrategy', 'list'),
+            intType: config.get<IntType>('intType', 'int'),
+            errorResultRecords: config.get<boolean>('errorResultRecords', false),
+            sharedResultRecord: config.get<boolean>('sharedResultRecord', false),
+            emptyCollections: config.get<boolean>('emptyCollections', false),
+            nilMatchesEmpty: config.get<boolean>('nilMatchesEmpty', false),
+            experimentalConcurrency: config.get<boolean>('experimentalConcurrency', false),
+            nullability: config.get<Nullability>('nullability', 'none'),
+            stdlibCalls: config.get<StdlibCallMappings>('stdlibCalls', {})
         };
 
         let javaCode: string;
@@ -0,0 +1,1573 @@
+import { GoParameter, GoType, SourcePosition } from './goParser';
+
+/**
+ * Token produced by the Go body scanner
+ */
+export interface GoToken {
+    kind: 'ident' | 'keyword' | 'int' | 'float' | 'imag' | 'char' | 'string' | 'op' | 'semi' | 'eof';
+    value: string;
+    pos: SourcePosition;
+    /** Offset of the token in the scanned text */
+    offset: number;
+}
+
+/**
+ * Comment found while scanning a body
+ */
+export interface GoComment {
+    text: string;
+    pos: SourcePosition;
+    /** Offset of the comment in the scanned text */
+    offset: number;
+    isBlock: boolean;
+}
+
+/**
+ * Syntax error raised while scanning or parsing Go code
+ */
+export class GoSyntaxError extends Error {
+    readonly pos: SourcePosition;
+
+    constructor(message: string, pos: SourcePosition) {
+        super(`${pos.line + 1}:${pos.character + 1}: ${message}`);
+        this.name = 'GoSyntaxError';
+        this.pos = pos;
+    }
+}
+
+// ═══════════════════════════════════════════════════════════════
+// AST for function bodies (modelled after go/ast)
+// ═══════════════════════════════════════════════════════════════
+
+export interface GoTypeExpr {
+    kind: 'TypeExpr';
+    typeKind: 'named' | 'pointer' | 'slice' | 'array' | 'map' | 'chan' | 'func' | 'struct' | 'interface';
+    /** Go source text of the type */
+    text: string;
+    /** Flattened type in the same shape the signature parsers produce */
+    type: GoType;
+    elem?: GoTypeExpr;
+    key?: GoTypeExpr;
+    length?: GoExpr;
+    params?: GoParameter[];
+    results?: GoType[];
+    fields?: GoParameter[];
+    pos: SourcePosition;
+}
+
+export interface GoIdent { kind: 'Ident'; name: string; pos: SourcePosition; }
+export interface GoBasicLit { kind: 'BasicLit'; litKind: 'INT' | 'FLOAT' | 'IMAG' | 'CHAR' | 'STRING'; value: string; pos: SourcePosition; }
+export interface GoCompositeLit { kind: 'CompositeLit'; type?: GoTypeExpr; elts: GoExpr[]; pos: SourcePosition; }
+export interface GoFuncLit { kind: 'FuncLit'; type: GoTypeExpr; body: GoBlockStmt; pos: SourcePosition; }
+export interface GoParenExpr { kind: 'Paren'; x: GoExpr; pos: SourcePosition; }
+export interface GoSelectorExpr { kind: 'Selector'; x: GoExpr; sel: string; pos: SourcePosition; }
+export interface GoIndexExpr { kind: 'Index'; x: GoExpr; index: GoExpr; pos: SourcePosition; }
+export interface GoSliceExpr { kind: 'SliceExpr'; x: GoExpr; low?: GoExpr; high?: GoExpr; max?: GoExpr; pos: SourcePosition; }
+export interface GoTypeAssertExpr { kind: 'TypeAssert'; x: GoExpr; type?: GoTypeExpr; pos: SourcePosition; }
+export interface GoCallExpr { kind: 'Call'; fun: GoExpr; args: GoExpr[]; ellipsis: boolean; pos: SourcePosition; }
+export interface GoStarExpr { kind: 'Star'; x: GoExpr; pos: SourcePosition; }
+export interface GoUnaryExpr { kind: 'Unary'; op: string; x: GoExpr; pos: SourcePosition; }
+export interface GoBinaryExpr { kind: 'Binary'; op: string; x: GoExpr; y: GoExpr; pos: SourcePosition; }
+export interface GoKeyValueExpr { kind: 'KeyValue'; key: GoExpr; value: GoExpr; pos: SourcePosition; }
+
+export type GoExpr =
+    | GoIdent
+    | GoBasicLit
+    | GoCompositeLit
+    | GoFuncLit
+    | GoParenExpr
+    | GoSelectorExpr
+    | GoIndexExpr
+    | GoSliceExpr
+    | GoTypeAssertExpr
+    | GoCallExpr
+    | GoStarExpr
+    | GoUnaryExpr
+    | GoBinaryExpr
+    | GoKeyValueExpr
+    | GoTypeExpr;
+
+interface StmtBase {
+    pos: SourcePosition;
+    /** Start and end offsets of the statement in the parsed text */
+    span: [number, number];
+}
+
+export interface GoExprStmt extends StmtBase { kind: 'ExprStmt'; x: GoExpr; }
+export interface GoAssignStmt extends StmtBase { kind: 'AssignStmt'; lhs: GoExpr[]; tok: string; rhs: GoExpr[]; }
+export interface GoIncDecStmt extends StmtBase { kind: 'IncDecStmt'; x: GoExpr; tok: '++' | '--'; }
+export interface GoSendStmt extends StmtBase { kind: 'SendStmt'; chan: GoExpr; value: GoExpr; }
+export interface GoValueSpec { names: string[]; type?: GoTypeExpr; values: GoExpr[]; }
+export interface GoDeclStmt extends StmtBase { kind: 'DeclStmt'; tok: 'var' | 'const' | 'type'; specs: GoValueSpec[]; }
+export interface GoReturnStmt extends StmtBase { kind: 'ReturnStmt'; results: GoExpr[]; }
+export interface GoBranchStmt extends StmtBase { kind: 'BranchStmt'; tok: 'break' | 'continue' | 'goto' | 'fallthrough'; label?: string; }
+export interface GoBlockStmt extends StmtBase { kind: 'BlockStmt'; stmts: GoStmt[]; }
+export interface GoIfStmt extends StmtBase { kind: 'IfStmt'; init?: GoStmt; cond: GoExpr; body: GoBlockStmt; else?: GoIfStmt | GoBlockStmt; }
+export interface GoForStmt extends StmtBase { kind: 'ForStmt'; init?: GoStmt; cond?: GoExpr; post?: GoStmt; body: GoBlockStmt; }
+export interface GoRangeStmt extends StmtBase { kind: 'RangeStmt'; key?: GoExpr; value?: GoExpr; tok?: ':=' | '='; x: GoExpr; body: GoBlockStmt; }
+export interface GoCaseClause { list: GoExpr[]; isDefault: boolean; body: GoStmt[]; pos: SourcePosition; }
+export interface GoSwitchStmt extends StmtBase { kind: 'SwitchStmt'; init?: GoStmt; tag?: GoExpr; cases: GoCaseClause[]; }
+export interface GoTypeSwitchStmt extends StmtBase { kind: 'TypeSwitchStmt'; init?: GoStmt; bind?: string; x: GoExpr; cases: GoCaseClause[]; }
+export interface GoCommClause { comm?: GoStmt; isDefault: boolean; body: GoStmt[]; pos: SourcePosition; }
+export interface GoSelectStmt extends StmtBase { kind: 'SelectStmt'; cases: GoCommClause[]; }
+export interface GoLabeledStmt extends StmtBase { kind: 'LabeledStmt'; label: string; stmt: GoStmt; }
+export interface GoDeferStmt extends StmtBase { kind: 'DeferStmt'; call: GoExpr; }
+export interface GoGoStmt extends StmtBase { kind: 'GoStmt'; call: GoExpr; }
+export interface GoEmptyStmt extends StmtBase { kind: 'EmptyStmt'; }
+export interface GoBadStmt extends StmtBase { kind: 'BadStmt'; message: string; }
+
+export type GoStmt =
+    | GoExprStmt
+    | GoAssignStmt
+    | GoIncDecStmt
+    | GoSendStmt
+    | GoDeclStmt
+    | GoReturnStmt
+    | GoBranchStmt
+    | GoBlockStmt
+    | GoIfStmt
+    | GoForStmt
+    | GoRangeStmt
+    | GoSwitchStmt
+    | GoTypeSwitchStmt
+    | GoSelectStmt
+    | GoLabeledStmt
+    | GoDeferStmt
+    | GoGoStmt
+    | GoEmptyStmt
+    | GoBadStmt;
+
+/**
+ * Parsed function body
+ */
+export interface GoBody {
+    stmts: GoStmt[];
+    comments: GoComment[];
+    /** The text that was parsed (statement spans index into it) */
+    source: string;
+}
+
+/**
+ * Visit every statement in stmts, including statements nested in blocks,
+ * loops, if/else chains, switch/select clauses and labeled statements
+ */
+export function walkStmts(stmts: GoStmt[], visit: (stmt: GoStmt) => void): void {
+    for (const stmt of stmts) {
+        visit(stmt);
+        switch (stmt.kind) {
+            case 'BlockStmt':
+                walkStmts(stmt.stmts, visit);
+                break;
+            case 'IfStmt':
+                walkStmts(stmt.init ? [stmt.init, stmt.body] : [stmt.body], visit);
+                if (stmt.else) {
+                    walkStmts([stmt.else], visit);
+                }
+                break;
+            case 'ForStmt':
+                walkStmts([stmt.init, stmt.post, stmt.body].filter((s): s is GoStmt => !!s), visit);
+                break;
+            case 'RangeStmt':
+                walkStmts([stmt.body], visit);
+                break;
+            case 'SwitchStmt':
+            case 'TypeSwitchStmt':
+                if (stmt.init) {
+                    walkStmts([stmt.init], visit);
+                }
+                stmt.cases.forEach(c => walkStmts(c.body, visit));
+                break;
+            case 'SelectStmt':
+                stmt.cases.forEach(c => walkStmts(c.comm ? [c.comm, ...c.body] : c.body, visit));
+                break;
+            case 'LabeledStmt':
+                walkStmts([stmt.stmt], visit);
+                break;
+        }
+    }
+}
+
+/**
+ * Visit every expression of the statements, including those inside function literals
+ */
+export function walkExprs(stmts: GoStmt[], visit: (expr: GoExpr) => void): void {
+    const walk = (e?: GoExpr): void => {
+        if (!e) {
+            return;
+        }
+        visit(e);
+        switch (e.kind) {
+            case 'CompositeLit':
+                e.elts.forEach(walk);
+                break;
+            case 'FuncLit':
+                walkExprs(e.body.stmts, visit);
+                break;
+            case 'Paren':
+            case 'Selector':
+            case 'TypeAssert':
+            case 'Star':
+            case 'Unary':
+                walk(e.x);
+                break;
+            case 'Index':
+                walk(e.x);
+                walk(e.index);
+                break;
+            case 'SliceExpr':
+                [e.x, e.low, e.high, e.max].forEach(walk);
+                break;
+            case 'Call':
+                [e.fun, ...e.args].forEach(walk);
+                break;
+            case 'Binary':
+                walk(e.x);
+                walk(e.y);
+                break;
+            case 'KeyValue':
+                walk(e.key);
+                walk(e.value);
+                break;
+        }
+    };
+    walkStmts(stmts, stmt => {
+        switch (stmt.kind) {
+            case 'ExprStmt':
+            case 'IncDecStmt':
+                walk(stmt.x);
+                break;
+            case 'AssignStmt':
+                [...stmt.lhs, ...stmt.rhs].forEach(walk);
+                break;
+            case 'SendStmt':
+                walk(stmt.chan);
+                walk(stmt.value);
+                break;
+            case 'DeclStmt':
+                stmt.specs.forEach(spec => spec.values.forEach(walk));
+                break;
+            case 'ReturnStmt':
+                stmt.results.forEach(walk);
+                break;
+            case 'IfStmt':
+            case 'ForStmt':
+                walk(stmt.cond);
+                break;
+            case 'RangeStmt':
+                [stmt.key, stmt.value, stmt.x].forEach(walk);
+                break;
+            case 'SwitchStmt':
+                walk(stmt.tag);
+                stmt.cases.forEach(c => c.list.forEach(walk));
+                break;
+            case 'TypeSwitchStmt':
+                walk(stmt.x);
+                break;
+            case 'DeferStmt':
+            case 'GoStmt':
+                walk(stmt.call);
+                break;
+        }
+    });
+}
+
+const KEYWORDS = new Set([
+    'break', 'case', 'chan', 'const', 'continue', 'default', 'defer', 'else',
+    'fallthrough', 'for', 'func', 'go', 'goto', 'if', 'import', 'interface',
+    'map', 'package', 'range', 'return', 'select', 'struct', 'switch', 'type', 'var'
+]);
+
+// Longest operators first so the scanner can match greedily
+const OPERATORS = [
+    '<<=', '>>=', '&^=', '...',
+    '&&', '||', '<-', '++', '--', '==', '!=', '<=', '>=', ':=',
+    '+=', '-=', '*=', '/=', '%=', '&=', '|=', '^=', '<<', '>>', '&^',
+    '+', '-', '*', '/', '%', '&', '|', '^', '<', '>', '=', '!', '~',
+    '(', ')', '[', ']', '{', '}', ',', ';', '.', ':'
+];
+
+const NUMBER_RE = /^(?:0[xX][0-9a-fA-F_]*(?:\.[0-9a-fA-F_]*)?(?:[pP][+-]?\d[\d_]*)?|0[bB][01_]+|0[oO][0-7_]+|(?:\d[\d_]*(?:\.[\d_]*)?|\.\d[\d_]*)(?:[eE][+-]?\d[\d_]*)?)i?/;
+
+const BINARY_PRECEDENCE: { [op: string]: number } = {
+    '||': 1,
+    '&&': 2,
+    '==': 3, '!=': 3, '<': 3, '<=': 3, '>': 3, '>=': 3,
+    '+': 4, '-': 4, '|': 4, '^': 4,
+    '*': 5, '/': 5, '%': 5, '<<': 5, '>>': 5, '&': 5, '&^': 5
+};
+
+const ASSIGN_OPS = new Set(['=', ':=', '+=', '-=', '*=', '/=', '%=', '&=', '|=', '^=', '<<=', '>>=', '&^=']);
+
+/**
+ * Scan Go source into tokens, applying Go's automatic semicolon insertion.
+ * Positions are offset by origin so they line up with the enclosing file.
+ */
+export function scanGo(text: string, origin: SourcePosition = { line: 0, character: 0 }): { tokens: GoToken[], comments: GoComment[] } {
+    const tokens: GoToken[] = [];
+    const comments: GoComment[] = [];
+    let i = 0;
+    let line = origin.line;
+    let lineStart = 0;
+
+    const posAt = (offset: number): SourcePosition => ({
+        line,
+        character: offset - lineStart + (line === origin.line ? origin.character : 0)
+    });
+
+    const needsSemi = (): boolean => {
+        const last = tokens[tokens.length - 1];
+        if (!last) return false;
+        switch (last.kind) {
+            case 'ident':
+            case 'int':
+            case 'float':
+            case 'imag':
+            case 'char':
+            case 'string':
+                return true;
+            case 'keyword':
+                return ['break', 'continue', 'fallthrough', 'return'].includes(last.value);
+            case 'op':
+                return [')', ']', '}', '++', '--'].includes(last.value);
+            default:
+                return false;
+        }
+    };
+
+    const newline = (offset: number) => {
+        if (needsSemi()) {
+            tokens.push({ kind: 'semi', value: '\n', pos: posAt(offset), offset });
+        }
+        line++;
+        lineStart = offset + 1;
+    };
+
+    while (i < text.length) {
+        const ch = text[i];
+
+        if (ch === '\n') {
+            newline(i);
+            i++;
+            continue;
+        }
+        if (ch === ' ' || ch === '\t' || ch === '\r') {
+            i++;
+            continue;
+        }
+
+        // Comments
+        if (ch === '/' && text[i + 1] === '/') {
+            const end = text.indexOf('\n', i);
+            const stop = end === -1 ? text.length : end;
+            comments.push({ text: text.slice(i, stop), pos: posAt(i), offset: i, isBlock: false });
+            i = stop;
+            continue;
+        }
+        if (ch === '/' && text[i + 1] === '*') {
+            const end = text.indexOf('*/', i + 2);
+            if (end === -1) {
+                throw new GoSyntaxError('comment not terminated', posAt(i));
+            }
+            const commentText = text.slice(i, end + 2);
+            comments.push({ text: commentText, pos: posAt(i), offset: i, isBlock: true });
+            for (let j = i; j < end; j++) {
+                if (text[j] === '\n') {
+                    newline(j);
+                }
+            }
+            i = end + 2;
+            continue;
+        }
+
+        const start = i;
+        const pos = posAt(i);
+
+        // Identifiers and keywords
+        if (/[A-Za-z_À-￿]/.test(ch)) {
+            while (i < text.length && /[A-Za-z0-9_À-￿]/.test(text[i])) {
+                i++;
+            }
+            const word = text.slice(start, i);
+            tokens.push({ kind: KEYWORDS.has(word) ? 'keyword' : 'ident', value: word, pos, offset: start });
+            continue;
+        }
+
+        // Numbers
+        if (/[0-9]/.test(ch) || (ch === '.' && /[0-9]/.test(text[i + 1] || ''))) {
+            const match = text.slice(i).match(NUMBER_RE);
+            const value = match ? match[0] : ch;
+            i += value.length;
+            let kind: GoToken['kind'] = 'int';
+            if (value.endsWith('i')) {
+                kind = 'imag';
+            } else if (/^0[xX]/.test(value) ? /[.pP]/.test(value) : /[.eE]/.test(value)) {
+                kind = 'float';
+            }
+            tokens.push({ kind, value, pos, offset: start });
+            continue;
+        }
+
+        // Interpreted strings and runes
+        if (ch === '"' || ch === '\'') {
+            i++;
+            while (i < text.length && text[i] !== ch) {
+                if (text[i] === '\\') {
+                    i++;
+                }
+                if (text[i] === '\n') {
+                    throw new GoSyntaxError('string literal not terminated', pos);
+                }
+                i++;
+            }
+            if (i >= text.length) {
+                throw new GoSyntaxError('string literal not terminated', pos);
+            }
+            i++;
+            tokens.push({ kind: ch === '"' ? 'string' : 'char', value: text.slice(start, i), pos, offset: start });
+            continue;
+        }
+
+        // Raw strings may span lines
+        if (ch === '`') {
+            const end = text.indexOf('`', i + 1);
+            if (end === -1) {
+                throw new GoSyntaxError('raw string literal not terminated', pos);
+            }
+            tokens.push({ kind: 'string', value: text.slice(start, end + 1), pos, offset: start });
+            for (let j = i; j < end; j++) {
+                if (text[j] === '\n') {
+                    line++;
+                    lineStart = j + 1;
+                }
+            }
+            i = end + 1;
+            continue;
+        }
+
+        const op = OPERATORS.find(o => text.startsWith(o, i));
+        if (!op) {
+            throw new GoSyntaxError(`unexpected character '${ch}'`, pos);
+        }
+        tokens.push({ kind: op === ';' ? 'semi' : 'op', value: op, pos, offset: start });
+        i += op.length;
+    }
+
+    if (needsSemi()) {
+        tokens.push({ kind: 'semi', value: '\n', pos: posAt(text.length), offset: text.length });
+    }
+    tokens.push({ kind: 'eof', value: '', pos: posAt(text.length), offset: text.length });
+
+    return { tokens, comments };
+}
+
+/**
+ * Find the offset of the closing brace matching the opening brace at openIndex.
+ * Skips strings, runes and comments. Returns -1 when unbalanced.
+ */
+export function findMatchingBrace(text: string, openIndex: number): number {
+    let depth = 0;
+    let i = openIndex;
+    while (i < text.length) {
+        const ch = text[i];
+        if (ch === '/' && text[i + 1] === '/') {
+            const end = text.indexOf('\n', i);
+            i = end === -1 ? text.length : end;
+            continue;
+        }
+        if (ch === '/' && text[i + 1] === '*') {
+            const end = text.indexOf('*/', i + 2);
+            i = end === -1 ? text.length : end + 2;
+            continue;
+        }
+        if (ch === '"' || ch === '\'') {
+            i++;
+            while (i < text.length && text[i] !== ch && text[i] !== '\n') {
+                if (text[i] === '\\') i++;
+                i++;
+            }
+            i++;
+            continue;
+        }
+        if (ch === '`') {
+            const end = text.indexOf('`', i + 1);
+            i = end === -1 ? text.length : end + 1;
+            continue;
+        }
+        if (ch === '{') {
+            depth++;
+        } else if (ch === '}') {
+            depth--;
+            if (depth === 0) {
+                return i;
+            }
+        }
+        i++;
+    }
+    return -1;
+}
+
+/**
+ * Find the brace that opens a function body in text starting with `func`.
+ * Braces inside the signature (interface{}, struct{}) are skipped.
+ */
+export function findBodyOpenBrace(text: string): number {
+    let parenDepth = 0;
+    let i = 0;
+    while (i < text.length) {
+        const ch = text[i];
+        if (ch === '(' || ch === '[') {
+            parenDepth++;
+        } else if (ch === ')' || ch === ']') {
+            parenDepth--;
+        } else if (ch === '{') {
+            const before = text.slice(0, i).trimEnd();
+            if (parenDepth === 0 && !/\b(interface|struct)$/.test(before)) {
+                return i;
+            }
+            const close = findMatchingBrace(text, i);
+            if (close === -1) {
+                return -1;
+            }
+            i = close + 1;
+            continue;
+        }
+        i++;
+    }
+    return -1;
+}
+
+/**
+ * Recursive-descent parser for Go function bodies.
+ * Covers the statement and expression grammar of the Go spec; anything it
+ * cannot make sense of becomes a BadStmt so conversion can continue.
+ */
+export class GoBodyParser {
+    private tokens: GoToken[];
+    private index = 0;
+    private source: string;
+    /** When < 0 we are in a control clause and `T {` is not a composite literal */
+    private exprLev = 0;
+
+    private constructor(tokens: GoToken[], source: string) {
+        this.tokens = tokens;
+        this.source = source;
+    }
+
+    /**
+     * Parse the statements of a function body (text between the braces).
+     * @param body Body text without the surrounding braces
+     * @param origin Position of the body text in the enclosing file
+     */
+    static parseBody(body: string, origin?: SourcePosition): GoBody {
+        const { tokens, comments } = scanGo(body, origin);
+        const parser = new GoBodyParser(tokens, body);
+        const stmts = parser.parseStmtList(true);
+        return { stmts, comments, source: body };
+    }
+
+    /**
+     * Parse a single Go expression
+     */
+    static parseExpression(text: string, origin?: SourcePosition): GoExpr {
+        const { tokens } = scanGo(text, origin);
+        const parser = new GoBodyParser(tokens, text);
+        const expr = parser.parseExpr();
+        parser.skipSemis();
+        if (parser.peek().kind !== 'eof') {
+            throw new GoSyntaxError(`unexpected '${parser.peek().value}' after expression`, parser.peek().pos);
+        }
+        return expr;
+    }
+
+    // ───────────────────────────── token helpers ─────────────────────────────
+
+    private peek(ahead: number = 0): GoToken {
+        return this.tokens[Math.min(this.index + ahead, this.tokens.length - 1)];
+    }
+
+    private next(): GoToken {
+        const tok = this.peek();
+        if (tok.kind !== 'eof') {
+            this.index++;
+        }
+        return tok;
+    }
+
+    private is(value: string, ahead: number = 0): boolean {
+        const tok = this.peek(ahead);
+        if (value === ';') {
+            return tok.kind === 'semi';
+        }
+        return (tok.kind === 'op' || tok.kind === 'keyword') && tok.value === value;
+    }
+
+    private accept(value: string): boolean {
+        if (this.is(value)) {
+            this.next();
+            return true;
+        }
+        return false;
+    }
+
+    private expect(value: string): GoToken {
+        if (!this.is(value)) {
+            const tok = this.peek();
+            throw new GoSyntaxError(`expected '${value}', found '${tok.value || tok.kind}'`, tok.pos);
+        }
+        return this.next();
+    }
+
+    private expectIdent(): GoToken {
+        const tok = this.peek();
+        if (tok.kind !== 'ident') {
+            throw new GoSyntaxError(`expected identifier, found '${tok.value || tok.kind}'`, tok.pos);
+        }
+        return this.next();
+    }
+
+    private skipSemis(): void {
+        while (this.peek().kind === 'semi') {
+            this.next();
+        }
+    }
+
+    private lastEnd(): number {
+        const prev = this.tokens[this.index - 1];
+        return prev ? prev.offset + (prev.kind === 'semi' ? 0 : prev.value.length) : 0;
+    }
+
+    private textBetween(start: number, end: number): string {
+        return this.source.slice(start, end);
+    }
+
+    // ───────────────────────────── statements ─────────────────────────────
+
+    private parseStmtList(topLevel: boolean = false): GoStmt[] {
+        const stmts: GoStmt[] = [];
+        this.skipSemis();
+        while (this.peek().kind !== 'eof' && !this.is('}') && !this.is('case') && !this.is('default')) {
+            const startIndex = this.index;
+            const startLev = this.exprLev;
+            try {
+                const stmt = this.parseStmt();
+                if (!this.is(';') && !this.is('}') && !this.is('case') && !this.is('default') && this.peek().kind !== 'eof') {
+                    const tok = this.peek();
+                    throw new GoSyntaxError(`unexpected '${tok.value}' at end of statement`, tok.pos);
+                }
+                stmts.push(stmt);
+            } catch (error) {
+                if (!(error instanceof GoSyntaxError)) {
+                    throw error;
+                }
+                this.index = startIndex;
+                this.exprLev = startLev;
+                stmts.push(this.recoverBadStmt(error.message));
+            }
+            this.skipSemis();
+            if (topLevel && this.is('}')) {
+                // Stray closing brace at the top level: record it and move on
+                const tok = this.next();
+                stmts.push({ kind: 'BadStmt', message: "unexpected '}'", pos: tok.pos, span: [tok.offset, tok.offset + 1] });
+                this.skipSemis();
+            }
+        }
+        return stmts;
+    }
+
+    /**
+     * Skip to the end of the current statement, keeping braces balanced
+     */
+    private recoverBadStmt(message: string): GoBadStmt {
+        const start = this.peek();
+        let depth = 0;
+        while (this.peek().kind !== 'eof') {
+            const tok = this.peek();
+            if (tok.kind === 'op' && (tok.value === '{' || tok.value === '(' || tok.value === '[')) {
+                depth++;
+            } else if (tok.kind === 'op' && (tok.value === '}' || tok.value === ')' || tok.value === ']')) {
+                if (depth === 0) break;
+                depth--;
+            } else if (tok.kind === 'semi' && depth === 0) {
+                break;
+            }
+            this.next();
+        }
+        if (this.index === this.tokens.indexOf(start)) {
+            this.next();
+        }
+        return { kind: 'BadStmt', message, pos: start.pos, span: [start.offset, this.lastEnd()] };
+    }
+
+    private finishStmt<T extends GoStmt>(stmt: T): T {
+        stmt.span[1] = this.lastEnd();
+        return stmt;
+    }
+
+    private parseStmt(): GoStmt {
+        const tok = this.peek();
+        const base = { pos: tok.pos, span: [tok.offset, tok.offset] as [number, number] };
+
+        if (tok.kind === 'keyword') {
+            switch (tok.value) {
+                case 'var':
+                case 'const':
+                case 'type':
+                    return this.finishStmt(this.parseDeclStmt(base));
+                case 'return': {
+                    this.next();
+                    const results = this.is(';') || this.is('}') ? [] : this.parseExprList();
+                    return this.finishStmt({ kind: 'ReturnStmt', results, ...base });
+                }
+                case 'break':
+                case 'continue':
+                case 'goto':
+                case 'fallthrough': {
+                    this.next();
+                    const label = this.peek().kind === 'ident' ? this.next().value : undefined;
+                    return this.finishStmt({ kind: 'BranchStmt', tok: tok.value as GoBranchStmt['tok'], label, ...base });
+                }
+                case 'if':
+                    return this.finishStmt(this.parseIfStmt());
+                case 'for':
+                    return this.finishStmt(this.parseForStmt());
+                case 'switch':
+                    return this.finishStmt(this.parseSwitchStmt());
+                case 'select':
+                    return this.finishStmt(this.parseSelectStmt());
+                case 'defer': {
+                    this.next();
+                    const call = this.parseExpr();
+                    return this.finishStmt({ kind: 'DeferStmt', call, ...base });
+                }
+                case 'go': {
+                    this.next();
+                    const call = this.parseExpr();
+                    return this.finishStmt({ kind: 'GoStmt', call, ...base });
+                }
+                case 'func':
+                case 'struct':
+                case 'map':
+                case 'chan':
+                case 'interface':
+                    return this.finishStmt(this.parseSimpleStmt(true));
+            }
+        }
+
+        if (this.is('{')) {
+            return this.finishStmt(this.parseBlock());
+        }
+        if (tok.kind === 'semi') {
+            return this.finishStmt({ kind: 'EmptyStmt', ...base });
+        }
+
+        return this.finishStmt(this.parseSimpleStmt(true));
+    }
+
+    private parseBlock(): GoBlockStmt {
+        const open = this.expect('{');
+        const stmts = this.parseStmtList();
+        this.expect('}');
+        return { kind: 'BlockStmt', stmts, pos: open.pos, span: [open.offset, this.lastEnd()] };
+    }
+
+    private parseDeclStmt(base: StmtBase): GoDeclStmt {
+        const tok = this.next().value as 'var' | 'const' | 'type';
+        const specs: GoValueSpec[] = [];
+        if (this.accept('(')) {
+            this.skipSemis();
+            while (!this.is(')') && this.peek().kind !== 'eof') {
+                specs.push(this.parseValueSpec(tok));
+                this.skipSemis();
+            }
+            this.expect(')');
+        } else {
+            specs.push(this.parseValueSpec(tok));
+        }
+        return { kind: 'DeclStmt', tok, specs, ...base };
+    }
+
+    private parseValueSpec(tok: 'var' | 'const' | 'type'): GoValueSpec {
+        if (tok === 'type') {
+            const name = this.expectIdent().value;
+            this.accept('=');
+            const type = this.parseType();
+            return { names: [name], type, values: [] };
+        }
+        const names = [this.expectIdent().value];
+        while (this.accept(',')) {
+            names.push(this.expectIdent().value);
+        }
+        let type: GoTypeExpr | undefined;
+        if (!this.is('=') && !this.is(';') && !this.is(')')) {
+            type = this.parseType();
+        }
+        const values = this.accept('=') ? this.parseExprList() : [];
+        return { names, type, values };
+    }
+
+    /**
+     * Parse a simple statement (expression, send, inc/dec, assignment, short var decl).
+     * Returns a RangeStmt marker shape when `range` follows an assignment inside a for header.
+     */
+    private parseSimpleStmt(labelOk: boolean, rangeOk: boolean = false): GoStmt {
+        const start = this.peek();
+        const base = { pos: start.pos, span: [start.offset, start.offset] as [number, number] };
+
+        if (rangeOk && this.is('range')) {
+            // for range x { ... }
+            this.next();
+            const x = this.parseExpr();
+            return { kind: 'RangeStmt', x, body: this.emptyBlock(), ...base };
+        }
+
+        const lhs = this.parseExprList();
+        const tok = this.peek();
+
+        if (tok.kind === 'op' && ASSIGN_OPS.has(tok.value)) {
+            this.next();
+            if (rangeOk && this.is('range') && (tok.value === '=' || tok.value === ':=')) {
+                this.next();
+                const x = this.parseExpr();
+                return {
+                    kind: 'RangeStmt',
+                    key: lhs[0],
+                    value: lhs[1],
+                    tok: tok.value as ':=' | '=',
+                    x,
+                    body: this.emptyBlock(),
+                    ...base
+                };
+            }
+            const rhs = this.parseExprList();
+            return { kind: 'AssignStmt', lhs, tok: tok.value, rhs, ...base };
+        }
+
+        if (lhs.length > 1) {
+            throw new GoSyntaxError(`expected assignment after expression list, found '${tok.value || tok.kind}'`, tok.pos);
+        }
+        const x = lhs[0];
+
+        if (labelOk && this.is(':') && x.kind === 'Ident') {
+            this.next();
+            this.skipSemis();
+            if (this.is('}')) {
+                return { kind: 'LabeledStmt', label: x.name, stmt: { kind: 'EmptyStmt', ...base }, ...base };
+            }
+            const stmt = this.parseStmt();
+            return { kind: 'LabeledStmt', label: x.name, stmt, ...base };
+        }
+        if (this.is('<-')) {
+            this.next();
+            const value = this.parseExpr();
+            return { kind: 'SendStmt', chan: x, value, ...base };
+        }
+        if (this.is('++') || this.is('--')) {
+            const op = this.next().value as '++' | '--';
+            return { kind: 'IncDecStmt', x, tok: op, ...base };
+        }
+        return { kind: 'ExprStmt', x, ...base };
+    }
+
+    private emptyBlock(): GoBlockStmt {
+        return { kind: 'BlockStmt', stmts: [], pos: this.peek().pos, span: [this.peek().offset, this.peek().offset] };
+    }
+
+    private parseIfStmt(): GoIfStmt {
+        const ifTok = this.expect('if');
+        const outer = this.exprLev;
+        this.exprLev = -1;
+        let init: GoStmt | undefined;
+        let cond: GoExpr | undefined;
+        if (!this.is(';')) {
+            const stmt = this.parseSimpleStmt(false);
+            if (this.is(';')) {
+                init = stmt;
+            } else if (stmt.kind === 'ExprStmt') {
+                cond = stmt.x;
+            } else {
+                throw new GoSyntaxError('expected condition in if statement', stmt.pos);
+            }
+        }
+        if (!cond) {
+            this.expect(';');
+            cond = this.parseExpr();
+        }
+        this.exprLev = outer;
+        const body = this.parseBlock();
+        let elseStmt: GoIfStmt | GoBlockStmt | undefined;
+        if (this.accept('else')) {
+            elseStmt = this.is('if') ? this.parseIfStmt() : this.parseBlock();
+        }
+        return {
+            kind: 'IfStmt',
+            init,
+            cond,
+            body,
+            else: elseStmt,
+            pos: ifTok.pos,
+            span: [ifTok.offset, this.lastEnd()]
+        };
+    }
+
+    private parseForStmt(): GoForStmt | GoRangeStmt {
+        const forTok = this.expect('for');
+        const base = { pos: forTok.pos, span: [forTok.offset, forTok.offset] as [number, number] };
+        const outer = this.exprLev;
+        this.exprLev = -1;
+
+        let init: GoStmt | undefined;
+        let cond: GoExpr | undefined;
+        let post: GoStmt | undefined;
+
+        if (!this.is('{')) {
+            let first: GoStmt | undefined;
+            if (!this.is(';')) {
+                first = this.parseSimpleStmt(false, true);
+            }
+            if (first && first.kind === 'RangeStmt') {
+                this.exprLev = outer;
+                const body = this.parseBlock();
+                return { ...first, body, ...base, span: [forTok.offset, this.lastEnd()] };
+            }
+            if (this.is(';')) {
+                // Three-clause loop
+                this.next();
+                init = first;
+                if (!this.is(';')) {
+                    cond = this.parseExpr();
+                }
+                this.expect(';');
+                if (!this.is('{')) {
+                    post = this.parseSimpleStmt(false);
+                }
+            } else if (first) {
+                if (first.kind !== 'ExprStmt') {
+                    throw new GoSyntaxError('expected for loop condition', first.pos);
+                }
+                cond = first.x;
+            }
+        }
+
+        this.exprLev = outer;
+        const body = this.parseBlock();
+        return { kind: 'ForStmt', init, cond, post, body, ...base, span: [forTok.offset, this.lastEnd()] };
+    }
+
+    private parseSwitchStmt(): GoSwitchStmt | GoTypeSwitchStmt {
+        const switchTok = this.expect('switch');
+        const base = { pos: switchTok.pos, span: [switchTok.offset, switchTok.offset] as [number, number] };
+        const outer = this.exprLev;
+        this.exprLev = -1;
+
+        let init: GoStmt | undefined;
+        let tagStmt: GoStmt | undefined;
+        if (!this.is('{')) {
+            if (!this.is(';')) {
+                tagStmt = this.parseSimpleStmt(false);
+            }
+            if (this.accept(';')) {
+                init = tagStmt;
+                tagStmt = undefined;
+                if (!this.is('{')) {
+                    tagStmt = this.parseSimpleStmt(false);
+                }
+            }
+        }
+        this.exprLev = outer;
+
+        // Detect type switches: `x.(type)` or `v := x.(type)`
+        let typeSwitch: { bind?: string, x: GoExpr } | undefined;
+        if (tagStmt) {
+            if (tagStmt.kind === 'ExprStmt' && tagStmt.x.kind === 'TypeAssert' && !tagStmt.x.type) {
+                typeSwitch = { x: tagStmt.x.x };
+            } else if (tagStmt.kind === 'AssignStmt' && tagStmt.tok === ':=' && tagStmt.rhs.length === 1
+                && tagStmt.rhs[0].kind === 'TypeAssert' && !tagStmt.rhs[0].type && tagStmt.lhs[0].kind === 'Ident') {
+                typeSwitch = { bind: tagStmt.lhs[0].name, x: tagStmt.rhs[0].x };
+            }
+        }
+
+        this.expect('{');
+        this.skipSemis();
+        const cases: GoCaseClause[] = [];
+        while (this.is('case') || this.is('default')) {
+            const caseTok = this.next();
+            const isDefault = caseTok.value === 'default';
+            let list: GoExpr[] = [];
+            if (!isDefault) {
+                list = typeSwitch ? this.parseTypeList() : this.parseExprList();
+            }
+            this.expect(':');
+            const body = this.parseStmtList();
+            cases.push({ list, isDefault, body, pos: caseTok.pos });
+        }
+        this.expect('}');
+        const span: [number, number] = [switchTok.offset, this.lastEnd()];
+
+        if (typeSwitch) {
+            return { kind: 'TypeSwitchStmt', init, bind: typeSwitch.bind, x: typeSwitch.x, cases, ...base, span };
+        }
+        if (tagStmt && tagStmt.kind !== 'ExprStmt') {
+            throw new GoSyntaxError('switch expression must be an expression', tagStmt.pos);
+        }
+        return { kind: 'SwitchStmt', init, tag: tagStmt ? (tagStmt as GoExprStmt).x : undefined, cases, ...base, span };
+    }
+
+    private parseTypeList(): GoExpr[] {
+        const list: GoExpr[] = [];
+        do {
+            if (this.peek().kind === 'ident' && this.peek().value === 'nil') {
+                const tok = this.next();
+                list.push({ kind: 'Ident', name: 'nil', pos: tok.pos });
+            } else {
+                list.push(this.parseType());
+            }
+        } while (this.accept(','));
+        return list;
+    }
+
+    private parseSelectStmt(): GoSelectStmt {
+        const selectTok = this.expect('select');
+        this.expect('{');
+        this.skipSemis();
+        const cases: GoCommClause[] = [];
+        while (this.is('case') || this.is('default')) {
+            const caseTok = this.next();
+            const isDefault = caseTok.value === 'default';
+            let comm: GoStmt | undefined;
+            if (!isDefault) {
+                comm = this.finishStmt(this.parseSimpleStmt(false));
+            }
+            this.expect(':');
+            const body = this.parseStmtList();
+            cases.push({ comm, isDefault, body, pos: caseTok.pos });
+        }
+        this.expect('}');
+        return { kind: 'SelectStmt', cases, pos: selectTok.pos, span: [selectTok.offset, this.lastEnd()] };
+    }
+
+    // ───────────────────────────── expressions ─────────────────────────────
+
+    private parseExprList(): GoExpr[] {
+        const list = [this.parseExpr()];
+        while (this.accept(',')) {
+            list.push(this.parseExpr());
+        }
+        return list;
+    }
+
+    parseExpr(): GoExpr {
+        return this.parseBinaryExpr(1);
+    }
+
+    private parseBinaryExpr(minPrec: number): GoExpr {
+        let x = this.parseUnaryExpr();
+        while (true) {
+            const tok = this.peek();
+            const prec = tok.kind === 'op' ? BINARY_PRECEDENCE[tok.value] : undefined;
+            if (prec === undefined || prec < minPrec) {
+                return x;
+            }
+            this.next();
+            const y = this.parseBinaryExpr(prec + 1);
+            x = { kind: 'Binary', op: tok.value, x, y, pos: tok.pos };
+        }
+    }
+
+    private parseUnaryExpr(): GoExpr {
+        const tok = this.peek();
+        if (tok.kind === 'op') {
+            switch (tok.value) {
+                case '+':
+                case '-':
+                case '!':
+                case '^':
+                case '&':
+                case '~': {
+                    this.next();
+                    const x = this.parseUnaryExpr();
+                    return { kind: 'Unary', op: tok.value, x, pos: tok.pos };
+                }
+                case '<-': {
+                    this.next();
+                    if (this.is('chan')) {
+                        // <-chan T in expression position
+                        const elem = this.parseType();
+                        return this.makeTypeExpr('chan', `<-${elem.text}`, { ...elem.type, name: 'chan' }, tok.pos, { elem });
+                    }
+                    const x = this.parseUnaryExpr();
+                    return { kind: 'Unary', op: '<-', x, pos: tok.pos };
+                }
+                case '*': {
+                    this.next();
+                    const x = this.parseUnaryExpr();
+                    return { kind: 'Star', x, pos: tok.pos };
+                }
+            }
+        }
+        return this.parsePrimaryExpr();
+    }
+
+    private parseOperand(): GoExpr {
+        const tok = this.peek();
+        switch (tok.kind) {
+            case 'ident':
+                this.next();
+                return { kind: 'Ident', name: tok.value, pos: tok.pos };
+            case 'int':
+            case 'float':
+            case 'imag':
+            case 'char':
+            case 'string':
+                this.next();
+                return {
+                    kind: 'BasicLit',
+                    litKind: tok.kind.toUpperCase() as GoBasicLit['litKind'],
+                    value: tok.value,
+                    pos: tok.pos
+                };
+        }
+
+        if (this.is('(')) {
+            this.next();
+            const outer = this.exprLev;
+            this.exprLev++;
+            const x = this.parseTypeOrExpr();
+            this.exprLev = outer;
+            this.expect(')');
+            return { kind: 'Paren', x, pos: tok.pos };
+        }
+
+        if (this.is('func')) {
+            const type = this.parseType();
+            if (this.is('{')) {
+                const outer = this.exprLev;
+                this.exprLev = 0;
+                const body = this.parseBlock();
+                this.exprLev = outer;
+                return { kind: 'FuncLit', type, body, pos: tok.pos };
+            }
+            return type;
+        }
+
+        if (this.is('[') || this.is('map') || this.is('chan') || this.is('struct') || this.is('interface')) {
+            return this.parseType();
+        }
+
+        throw new GoSyntaxError(`unexpected '${tok.value || tok.kind}' in expression`, tok.pos);
+    }
+
+    private parseTypeOrExpr(): GoExpr {
+        if (this.is('*')) {
+            const star = this.next();
+            const x = this.parseTypeOrExpr();
+            return { kind: 'Star', x, pos: star.pos };
+        }
+        return this.parseExpr();
+    }
+
+    private parsePrimaryExpr(): GoExpr {
+        let x = this.parseOperand();
+        while (true) {
+            const tok = this.peek();
+            if (this.is('.')) {
+                this.next();
+                if (this.accept('(')) {
+                    if (this.accept('type')) {
+                        this.expect(')');
+                        x = { kind: 'TypeAssert', x, pos: tok.pos };
+                    } else {
+                        const type = this.parseType();
+                        this.expect(')');
+                        x = { kind: 'TypeAssert', x, type, pos: tok.pos };
+                    }
+                } else {
+                    const sel = this.expectIdent();
+                    x = { kind: 'Selector', x, sel: sel.value, pos: sel.pos };
+                }
+            } else if (this.is('[')) {
+                x = this.parseIndexOrSlice(x);
+            } else if (this.is('(')) {
+                x = this.parseCall(x);
+            } else if (this.is('{') && this.isLiteralType(x) && (this.exprLev >= 0 || !this.isTypeName(x))) {
+                x = this.parseCompositeLit(this.toTypeExpr(x));
+            } else {
+                return x;
+            }
+        }
+    }
+
+    private parseIndexOrSlice(x: GoExpr): GoExpr {
+        const open = this.expect('[');
+        const outer = this.exprLev;
+        this.exprLev++;
+        const parts: (GoExpr | undefined)[] = [undefined];
+        let colons = 0;
+        if (!this.is(':')) {
+            parts[0] = this.parseTypeOrExpr();
+        }
+        while (this.accept(':')) {
+            colons++;
+            parts.push(this.is(':') || this.is(']') ? undefined : this.parseExpr());
+        }
+        const typeArgs: GoExpr[] = [];
+        if (colons === 0) {
+            while (this.accept(',')) {
+                typeArgs.push(this.parseTypeOrExpr());
+            }
+        }
+        this.exprLev = outer;
+        this.expect(']');
+        if (colons > 0) {
+            return { kind: 'SliceExpr', x, low: parts[0], high: parts[1], max: parts[2], pos: open.pos };
+        }
+        if (!parts[0]) {
+            throw new GoSyntaxError('expected operand', open.pos);
+        }
+        if (typeArgs.length > 0) {
+            // Generic instantiation with several type arguments: keep as a named type
+            const args = [parts[0], ...typeArgs].map(a => this.toTypeExpr(a));
+            const text = this.textOfExpr(x) + '[' + args.map(a => a.text).join(', ') + ']';
+            return this.makeTypeExpr('named', text, { ...this.simpleType(this.textOfExpr(x)), typeArgs: args.map(a => a.type) }, open.pos);
+        }
+        return { kind: 'Index', x, index: parts[0], pos: open.pos };
+    }
+
+    private parseCall(fun: GoExpr): GoCallExpr {
+        const open = this.expect('(');
+        const outer = this.exprLev;
+        this.exprLev++;
+        const args: GoExpr[] = [];
+        let ellipsis = false;
+        this.skipSemis();
+        while (!this.is(')')) {
+            args.push(this.parseTypeOrExpr());
+            if (this.accept('...')) {
+                ellipsis = true;
+            }
+            if (!this.accept(',')) {
+                break;
+            }
+            this.skipSemis();
+        }
+        this.skipSemis();
+        this.exprLev = outer;
+        this.expect(')');
+        return { kind: 'Call', fun, args, ellipsis, pos: open.pos };
+    }
+
+    private parseCompositeLit(type: GoTypeExpr | undefined): GoCompositeLit {
+        const open = this.expect('{');
+        const outer = this.exprLev;
+        this.exprLev = 1;
+        const elts: GoExpr[] = [];
+        this.skipSemis();
+        while (!this.is('}')) {
+            let elt = this.parseElement();
+            if (this.accept(':')) {
+                const value = this.parseElement();
+                elt = { kind: 'KeyValue', key: elt, value, pos: elt.pos };
+            }
+            elts.push(elt);
+            if (!this.accept(',')) {
+                break;
+            }
+            this.skipSemis();
+        }
+        this.skipSemis();
+        this.exprLev = outer;
+        this.expect('}');
+        return { kind: 'CompositeLit', type, elts, pos: type ? type.pos : open.pos };
+    }
+
+    private parseElement(): GoExpr {
+        if (this.is('{')) {
+            // Elided type in nested composite literal
+            return this.parseCompositeLit(undefined);
+        }
+        return this.parseExpr();
+    }
+
+    private isTypeName(x: GoExpr): boolean {
+        return x.kind === 'Ident' || (x.kind === 'Selector' && x.x.kind === 'Ident');
+    }
+
+    private isLiteralType(x: GoExpr): boolean {
+        switch (x.kind) {
+            case 'Ident':
+                return true;
+            case 'Selector':
+                return x.x.kind === 'Ident';
+            case 'Index':
+                // Generic type instantiation: Stack[int]{}
+                return this.isTypeName(x.x);
+            case 'TypeExpr':
+                return x.typeKind === 'slice' || x.typeKind === 'array' || x.typeKind === 'map' || x.typeKind === 'struct' || x.typeKind === 'named';
+            default:
+                return false;
+        }
+    }
+
+    // ───────────────────────────── types ─────────────────────────────
+
+    private makeTypeExpr(
+        typeKind: GoTypeExpr['typeKind'],
+        text: string,
+        type: GoType,
+        pos: SourcePosition,
+        extra: Partial<GoTypeExpr> = {}
+    ): GoTypeExpr {
+        return { kind: 'TypeExpr', typeKind, text, type, pos, ...extra };
+    }
+
+    private simpleType(name: string): GoType {
+        return { name, isPointer: false, isSlice: false, isMap: false, isVariadic: false };
+    }
+
+    private sliceType(elementType: GoType): GoType {
+        return { name: elementType.name, isPointer: false, isSlice: true, isMap: false, isVariadic: false, elementType };
+    }
+
+    /**
+     * Convert an expression that denotes a type (Ident, Selector, Index) to a TypeExpr
+     */
+    toTypeExpr(x: GoExpr): GoTypeExpr {
+        if (x.kind === 'TypeExpr') {
+            return x;
+        }
+        if (x.kind === 'Star') {
+            const elem = this.toTypeExpr(x.x);
+            return this.makeTypeExpr('pointer', `*${elem.text}`, { ...elem.type, isPointer: true }, x.pos, { elem });
+        }
+        const text = this.textOfExpr(x);
+        if (x.kind === 'Index') {
+            // Generic instantiation: Stack[int]
+            const arg = this.toTypeExpr(x.index);
+            return this.makeTypeExpr('named', text, { ...this.simpleType(this.textOfExpr(x.x)), typeArgs: [arg.type] }, x.pos);
+        }
+        return this.makeTypeExpr('named', text, this.simpleType(text), x.pos);
+    }
+
+    private textOfExpr(x: GoExpr): string {
+        switch (x.kind) {
+            case 'Ident':
+                return x.name;
+            case 'Selector':
+                return `${this.textOfExpr(x.x)}.${x.sel}`;
+            case 'Index':
+                return `${this.textOfExpr(x.x)}[${this.textOfExpr(x.index)}]`;
+            case 'TypeExpr':
+                return x.text;
+            case 'Star':
+                return `*${this.textOfExpr(x.x)}`;
+            case 'BasicLit':
+                return x.value;
+            default:
+                return '';
+        }
+    }
+
+    private parseType(): GoTypeExpr {
+        const tok = this.peek();
+
+        if (tok.kind === 'ident') {
+            this.next();
+            let text = tok.value;
+            if (this.is('.') && this.peek(1).kind === 'ident') {
+                this.next();
+                text += '.' + this.next().value;
+            }
+            if (this.is('[') && !this.is(']', 1)) {
+                // Generic instantiation: Stack[T]
+                this.next();
+                const args = [this.parseType()];
+                while (this.accept(',')) {
+                    args.push(this.parseType());
+                }
+                this.expect(']');
+                return this.makeTypeExpr('named', `${text}[${args.map(a => a.text).join(', ')}]`,
+                    { ...this.simpleType(text), typeArgs: args.map(a => a.type) }, tok.pos);
+            }
+            return this.makeTypeExpr('named', text, this.simpleType(text), tok.pos);
+        }
+
+        if (this.accept('(')) {
+            const inner = this.parseType();
+            this.expect(')');
+            return inner;
+        }
+
+        if (this.is('*')) {
+            this.next();
+            const elem = this.parseType();
+            return this.makeTypeExpr('pointer', `*${elem.text}`, { ...elem.type, isPointer: true }, tok.pos, { elem });
+        }
+
+        if (this.is('[')) {
+            this.next();
+            if (this.accept(']')) {
+                const elem = this.parseType();
+                return this.makeTypeExpr('slice', `[]${elem.text}`, this.sliceType(elem.type), tok.pos, { elem });
+            }
+            let length: GoExpr | undefined;
+            let lengthText = '...';
+            if (!this.accept('...')) {
+                const outer = this.exprLev;
+                this.exprLev = 1;
+                length = this.parseExpr();
+                this.exprLev = outer;
+                lengthText = this.textOfExpr(length);
+            }
+            this.expect(']');
+            const elem = this.parseType();
+            return this.makeTypeExpr('array', `[${lengthText}]${elem.text}`, this.sliceType(elem.type), tok.pos, { elem, length });
+        }
+
+        if (this.is('map')) {
+            this.next();
+            this.expect('[');
+            const key = this.parseType();
+            this.expect(']');
+            const elem = this.parseType();
+            return this.makeTypeExpr('map', `map[${key.text}]${elem.text}`, {
+                name: 'Map',
+                isPointer: false,
+                isSlice: false,
+                isMap: true,
+                isVariadic: false,
+                keyType: key.type,
+                valueType: elem.type
+            }, tok.pos, { key, elem });
+        }
+
+        if (this.is('chan')) {
+            this.next();
+            let prefix = 'chan ';
+            if (this.accept('<-')) {
+                prefix = 'chan<- ';
+            }
+            const elem = this.parseType();
+            return this.makeTypeExpr('chan', prefix + elem.text, { ...this.simpleType(prefix + elem.text), valueType: elem.type }, tok.pos, { elem });
+        }
+
+        if (this.is('<-')) {
+            this.next();
+            this.expect('chan');
+            const elem = this.parseType();
+            return this.makeTypeExpr('chan', `<-chan ${elem.text}`, { ...this.simpleType(`<-chan ${elem.text}`), valueType: elem.type }, tok.pos, { elem });
+        }
+
+        if (this.is('func')) {
+            this.next();
+            const params = this.parseParameterList();
+            const results = this.parseResults();
+            const text = this.textBetween(tok.offset, this.lastEnd());
+            return this.makeTypeExpr('func', text, this.simpleType(text.replace(/\s+/g, ' ')), tok.pos, {
+                params,
+                results: results.map(r => r.type)
+            });
+        }
+
+        if (this.is('struct')) {
+            this.next();
+            this.expect('{');
+            const fields: GoParameter[] = [];
+            this.skipSemis();
+            while (!this.is('}')) {
+                const names = [this.expectIdent().value];
+                while (this.accept(',')) {
+                    names.push(this.expectIdent().value);
+                }
+                const fieldType = this.parseType();
+                if (this.peek().kind === 'string') {
+                    this.next();
+                }
+                names.forEach(name => fields.push({ name, type: fieldType.type }));
+                this.skipSemis();
+            }
+            this.expect('}');
+            const text = this.textBetween(tok.offset, this.lastEnd());
+            return this.makeTypeExpr('struct', text, this.simpleType('struct{}'), tok.pos, { fields });
+        }
+
+        if (this.is('interface')) {
+            this.next();
+            this.expect('{');
+            let depth = 1;
+            while (depth > 0 && this.peek().kind !== 'eof') {
+                const t = this.next();
+                if (t.kind === 'op' && t.value === '{') depth++;
+                if (t.kind === 'op' && t.value === '}') depth--;
+            }
+            const text = this.textBetween(tok.offset, this.lastEnd());
+            const name = /^interface\s*\{\s*\}$/.test(text) ? 'interface{}' : text;
+            return this.makeTypeExpr('interface', text, this.simpleType(name), tok.pos);
+        }
+
+        throw new GoSyntaxError(`expected type, found '${tok.value || tok.kind}'`, tok.pos);
+    }
+
+    /**
+     * Parse a parameter list `(a, b int, c ...string)` following Go's grouping rules
+     */
+    private parseParameterList(): GoParameter[] {
+        this.expect('(');
+        const entries: { name?: string, type?: GoTypeExpr, variadic: boolean }[] = [];
+        this.skipSemis();
+        while (!this.is(')')) {
+            const variadic = this.accept('...');
+            const first = this.peek();
+            if (!variadic && first.kind === 'ident' && !this.is('.', 1) && !this.is(',', 1) && !this.is(')', 1)) {
+                // name Type
+                this.next();
+                const isVariadic = this.accept('...');
+                entries.push({ name: first.value, type: this.parseType(), variadic: isVariadic });
+            } else if (!variadic && first.kind === 'ident' && (this.is(',', 1) || this.is(')', 1))) {
+                // lone identifier: either a name (grouped) or a type
+                this.next();
+                entries.push({ name: first.value, variadic: false });
+            } else {
+                entries.push({ type: this.parseType(), variadic });
+            }
+            if (!this.accept(',')) {
+                break;
+            }
+            this.skipSemis();
+        }
+        this.skipSemis();
+        this.expect(')');
+
+        const named = entries.some(e => e.name !== undefined && e.type !== undefined);
+        const params: GoParameter[] = [];
+        let pending: string[] = [];
+        for (const entry of entries) {
+            if (named) {
+                if (!entry.type) {
+                    pending.push(entry.name!);
+                    continue;
+                }
+                const type = { ...entry.type.type, isVariadic: entry.variadic, isSlice: entry.type.type.isSlice || entry.variadic };
+                for (const name of [...pending, entry.name || '']) {
+                    params.push({ name, type });
+                }
+                pending = [];
+            } else {
+                const goType = entry.type ? entry.type.type : this.simpleType(entry.name!);
+                params.push({ name: '', type: { ...goType, isVariadic: entry.variadic, isSlice: goType.isSlice || entry.variadic } });
+            }
+        }
+        return params;
+    }
+
+    private parseResults(): GoParameter[] {
+        if (this.is('(')) {
+            return this.parseParameterList();
+        }
+        const tok = this.peek();
+        const startsType = tok.kind === 'ident' || this.is('*') || this.is('[') || this.is('map')
+            || this.is('chan') || this.is('func') || this.is('struct') || this.is('interface') || this.is('<-');
+        if (!startsType) {
+            return [];
+        }
+        return [{ name: '', type: this.parseType().type }];
+    }
+}
@@ -1,10 +1,13 @@
-import { GoFunction, GoFunctionParser, GoParameter, GoType, SourcePosition, SourceRange } from './goParser';
+import { GoFunction, GoFunctionParser, GoParameter, GoType, GoTypeParam, SourcePosition, SourceRange } from './goParser';
+import { findBodyOpenBrace, findMatchingBrace } from './goBodyParser';
 
 export interface GoFile {
     packageName: string;
     imports: GoImport[];
     structs: GoStruct[];
     interfaces: GoInterface[];
+    /** Aliases and defined types other than structs and interfaces */
+    namedTypes: GoNamedType[];
     functions: GoFunction[];
     variables: GoVariable[];
     constants: GoConstant[];
@@ -27,6 +30,10 @@ export interface GoStruct {
     range?: SourceRange;
     /** Embedded types (anonymous fields) */
     embeddedTypes?: GoType[];
+    /** Doc comment text, without comment markers */
+    doc?: string;
+    /** Type parameters of a generic struct (`type Stack[T any] struct`) */
+    typeParams?: GoTypeParam[];
 }
 
 export interface GoField {
@@ -40,6 +47,8 @@ export interface GoField {
     typePosition?: SourcePosition;
     /** Whether this is an embedded/anonymous field */
     isEmbedded?: boolean;
+    /** Doc comment text, without comment markers */
+    doc?: string;
 }
 
 export interface GoInterface {
@@ -51,6 +60,32 @@ export interface GoInterface {
     range?: SourceRange;
     /** Embedded interfaces */
     embeddedInterfaces?: string[];
+    /** Doc comment text, without comment markers */
+    doc?: string;
+    /** Type parameters of a generic interface */
+    typeParams?: GoTypeParam[];
+    /**
+     * Type terms of a constraint interface (`~int | ~float64` gives `~int`, `~float64`).
+     * Such interfaces only constrain type parameters and have no Java counterpart.
+     */
+    typeTerms?: string[];
+}
+
+/**
+ * A type alias (`type MyInt = int`) or a defined type over anything but a struct or
+ * interface literal (`type Celsius float64`, `type Admin User`)
+ */
+export interface GoNamedType {
+    name: string;
+    underlying: GoType;
+    /** Whether the declaration has an `=`: the position go/ast records in TypeSpec.Assign */
+    isAlias: boolean;
+    /** Methods declared on a defined type; an alias cannot declare any */
+    methods: GoFunction[];
+    /** Position of the type name in source */
+    namePosition?: SourcePosition;
+    /** Doc comment text, without comment markers */
+    doc?: string;
 }
 
 export interface GoMethodSignature {
@@ -59,6 +94,8 @@ export interface GoMethodSignature {
     returnTypes: GoType[];
     /** Position of the method name in source */
     namePosition?: SourcePosition;
+    /** Doc comment text, without comment markers */
+    doc?: string;
 }
 
 export interface GoVariable {
@@ -71,10 +108,26 @@ export interface GoVariable {
     namePosition?: SourcePosition;
     /** Position of the type in source (for LSP queries) */
     typePosition?: SourcePosition;
+    /** Doc comment text, without comment markers */
+    doc?: string;
+    /** Value of iota for a constant declared as exactly `iota`, directly or by implicit repetition */
+    iota?: number;
 }
 
 export type GoConstant = GoVariable;
 
+/**
+ * A const/var spec as written in source, before iota and implicit repetition are applied
+ */
+export interface GoValueSpecText {
+    names: string[];
+    type?: GoType;
+    values: string[];
+    doc?: string;
+    /** Position of each name in source */
+    namePositions?: SourcePosition[];
+}
+
 /**
  * Build a map of import aliases to full import paths.
  * Useful for resolving qualified types like "http.Request" to "net/http.Request"
@@ -114,6 +167,360 @@ export function resolveQualifiedType(typeName: string, importMap: Map<string, st
     };
 }
 
+/**
+ * The part of a file one type needs, for converting that type alone: the type with its
+ * methods, the types its fields, signatures and methods name (declarations only, as they
+ * are converted with their own methods), the package functions its methods call, and the
+ * constants and variables any of those use or that are of one of those types (enum members).
+ * Declarations keep their order in the file.
+ * @returns undefined when the file declares no type of that name
+ */
+export function extractType(goFile: GoFile, typeName: string): GoFile | undefined {
+    const struct = goFile.structs.find(s => s.name === typeName);
+    const iface = goFile.interfaces.find(i => i.name === typeName);
+    const named = goFile.namedTypes.find(t => t.name === typeName);
+    if (!struct && !iface && !named) {
+        return undefined;
+    }
+    const functionText = (fn: GoFunction | GoMethodSignature): string[] => [
+        ...fn.parameters.map(p => p.type.name),
+        ...fn.returnTypes.map(t => t.name),
+        ...('body' in fn ? [...(fn.typeParams || []).map(p => p.constraint), fn.body || ''] : [])
+    ];
+    const texts = struct ? [...struct.fields.map(f => f.type.name), ...struct.methods.flatMap(functionText)]
+        : iface ? [...iface.methods.flatMap(functionText), ...(iface.embeddedInterfaces || []), ...(iface.typeTerms || [])]
+        : [named!.underlying.name, ...named!.methods.flatMap(functionText)];
+    const referenced = referencedNames(texts);
+    const functions = goFile.functions.filter(f => !f.isMethod && referenced.has(f.name));
+    functions.flatMap(functionText).forEach(text => referencedNames([text]).forEach(name => referenced.add(name)));
+
+    // Values are followed through the values they are initialized with
+    const values = [...goFile.constants, ...goFile.variables];
+    const declaredTypes = new Set([...goFile.structs, ...goFile.interfaces, ...goFile.namedTypes].map(t => t.name));
+    const usedValues = new Set<GoVariable>();
+    let added = true;
+    while (added) {
+        added = false;
+        for (const value of values) {
+            const ofUsedType = !!value.type && declaredTypes.has(value.type.name) && (value.type.name === typeName || referenced.has(value.type.name));
+            if (!usedValues.has(value) && (referenced.has(value.name) || ofUsedType)) {
+                usedValues.add(value);
+                referencedNames([value.value || '', value.type?.name || '']).forEach(name => referenced.add(name));
+                added = true;
+            }
+        }
+    }
+    const isHelper = (name: string) => name !== typeName && referenced.has(name);
+    return {
+        packageName: goFile.packageName,
+        imports: goFile.imports,
+        structs: goFile.structs.filter(s => s === struct || isHelper(s.name)).map(s => s === struct ? s : { ...s, methods: [] }),
+        interfaces: goFile.interfaces.filter(i => i === iface || isHelper(i.name)),
+        namedTypes: goFile.namedTypes.filter(t => t === named || isHelper(t.name)).map(t => t === named ? t : { ...t, methods: [] }),
+        functions,
+        variables: goFile.variables.filter(v => usedValues.has(v)),
+        constants: goFile.constants.filter(c => usedValues.has(c))
+    };
+}
+
+/**
+ * Identifiers that Go source text names unqualified: not selectors (`x.Name`, `pkg.Type`)
+ * and not inside string literals or comments
+ */
+function referencedNames(texts: string[]): Set<string> {
+    const names = new Set<string>();
+    for (const text of texts) {
+        const code = text.replace(/\/\/.*$|\/\*[\s\S]*?\*\/|"(?:[^"\\\n]|\\.)*"|`[^`]*`|'(?:[^'\\\n]|\\.)*'/gm, ' ');
+        for (const match of code.matchAll(/(?<![.\w])[A-Za-z_]\w*/g)) {
+            names.add(match[0]);
+        }
+    }
+    return names;
+}
+
+/**
+ * Read the doc comment directly above a declaration line, like go/ast's CommentGroup:
+ * consecutive `//` lines or one `/* ... *\/` block with no blank line in between.
+ * Compiler directives such as `//go:generate` are not part of the doc.
+ * @param lines Source lines
+ * @param declLine Zero-based line of the declaration
+ */
+export function extractDocComment(lines: string[], declLine: number): string | undefined {
+    const docLines: string[] = [];
+    let i = declLine - 1;
+
+    if (i >= 0 && lines[i].trim().endsWith('*/') && !lines[i].trim().startsWith('//')) {
+        // Block comment: walk back to its opening /*
+        const block: string[] = [];
+        while (i >= 0) {
+            block.unshift(lines[i].trim());
+            if (lines[i].includes('/*')) {
+                break;
+            }
+            i--;
+        }
+        if (i < 0 || !block[0].startsWith('/*')) {
+            return undefined;
+        }
+        const text = block.join('\n').replace(/^\/\*+/, '').replace(/\*+\/$/, '');
+        docLines.push(...text.split('\n').map(line => line.replace(/^\s*\*? ?/, '').trimEnd()));
+    } else {
+        while (i >= 0 && lines[i].trim().startsWith('//')) {
+            const line = lines[i].trim();
+            if (!/^\/\/(go:|line |export |extern )/.test(line)) {
+                docLines.unshift(line.replace(/^\/\/ ?/, '').trimEnd());
+            }
+            i--;
+        }
+    }
+
+    // Drop leading and trailing blank lines
+    while (docLines.length > 0 && !docLines[0].trim()) {
+        docLines.shift();
+    }
+    while (docLines.length > 0 && !docLines[docLines.length - 1].trim()) {
+        docLines.pop();
+    }
+    return docLines.length > 0 ? docLines.join('\n') : undefined;
+}
+
+/**
+ * Parse a raw struct tag (e.g. `json:"name,omitempty" db:"name"`) into key/value pairs,
+ * following the conventions of Go's reflect.StructTag
+ */
+export function parseStructTag(tag: string): Map<string, string> {
+    const values = new Map<string, string>();
+    const pattern = /([^\s:"]+):"((?:[^"\\]|\\.)*)"/g;
+    let match: RegExpExecArray | null;
+    while ((match = pattern.exec(tag)) !== null) {
+        if (!values.has(match[1])) {
+            values.set(match[1], match[2].replace(/\\(.)/g, '$1'));
+        }
+    }
+    return values;
+}
+
+/**
+ * Infer the Go type of an untyped literal (int, float64, string, rune or bool). Integers
+ * past 32 bits are int64 and runes past 16 bits int32, so that their Java types hold them.
+ */
+export function inferTypeFromLiteral(value: string): GoType | undefined {
+    const simple = (name: string): GoType => ({ name, isPointer: false, isSlice: false, isMap: false, isVariadic: false });
+    const trimmed = value.trim();
+    if (/^"(?:[^"\\]|\\.)*"$/.test(trimmed) || /^`[^`]*`$/.test(trimmed)) {
+        return simple('string');
+    }
+    if (/^'(?:[^'\\]|\\.+)'$/.test(trimmed)) {
+        const wide = /^'\\U([\da-fA-F]{8})'$/.exec(trimmed);
+        return simple((wide ? parseInt(wide[1], 16) : trimmed.codePointAt(1)!) > 0xFFFF ? 'int32' : 'rune');
+    }
+    if (trimmed === 'true' || trimmed === 'false') {
+        return simple('bool');
+    }
+    if (/^-?(?:0[xX][0-9a-fA-F_]+|0[bB][01_]+|0[oO]?[0-7_]*|[1-9][\d_]*)$/.test(trimmed)) {
+        const value = evaluateIntegerConstant(trimmed);
+        return simple(value !== undefined && (value > 2147483647n || value < -2147483648n) ? 'int64' : 'int');
+    }
+    if (/^-?(?:\d[\d_]*\.[\d_]*|\.\d[\d_]*|\d[\d_]*(?=[eE]))(?:[eE][+-]?\d+)?$/.test(trimmed)
+        || /^-?0[xX][\da-fA-F_]*\.?[\da-fA-F_]*[pP][+-]?\d+$/.test(trimmed)) {
+        return simple('float64');
+    }
+    return undefined;
+}
+
+/**
+ * Evaluate an untyped integer constant expression such as `1 << (10 * 2)`.
+ * Returns undefined for anything but integer literals, parentheses and
+ * Go's arithmetic and bitwise operators.
+ */
+export function evaluateIntegerConstant(text: string): bigint | undefined {
+    const tokens = text.match(/0[xX][0-9a-fA-F_]+|0[bB][01_]+|0[oO][0-7_]+|\d[\d_]*|<<|>>|&\^|[-+*/%&|^()]|\S/g) || [];
+    let pos = 0;
+
+    const operand = (): bigint | undefined => {
+        const token = tokens[pos++];
+        if (token === undefined) {
+            return undefined;
+        }
+        if (token === '(') {
+            const value = binary(4);
+            return tokens[pos++] === ')' ? value : undefined;
+        }
+        if (token === '-' || token === '+' || token === '^') {
+            const value = operand();
+            if (value === undefined) return undefined;
+            return token === '-' ? -value : token === '^' ? ~value : value;
+        }
+        if (!/^\d/.test(token)) {
+            return undefined;
+        }
+        const digits = token.replace(/_/g, '');
+        // Go's 0755 is octal
+        return BigInt(/^0\d/.test(digits) ? '0o' + digits.slice(1) : digits);
+    };
+
+    // Go has two precedence levels for these operators: 5 (* / % << >> & &^) binds tighter than 4 (+ - | ^)
+    const binary = (level: 4 | 5): bigint | undefined => {
+        const operators = level === 5 ? ['*', '/', '%', '<<', '>>', '&', '&^'] : ['+', '-', '|', '^'];
+        let left = level === 5 ? operand() : binary(5);
+        while (left !== undefined && pos < tokens.length && operators.includes(tokens[pos])) {
+            const op = tokens[pos++];
+            const right = level === 5 ? operand() : binary(5);
+            if (right === undefined) return undefined;
+            switch (op) {
+                case '*': left = left * right; break;
+                case '/': case '%':
+                    if (right === 0n) return undefined;
+                    left = op === '/' ? left / right : left % right;
+                    break;
+                case '<<': case '>>':
+                    if (right < 0n) return undefined;
+                    left = op === '<<' ? left << right : left >> right;
+                    break;
+                case '&': left = left & right; break;
+                case '&^': left = left & ~right; break;
+                case '+': left = left + right; break;
+                case '-': left = left - right; break;
+                case '|': left = left | right; break;
+                case '^': left = left ^ right; break;
+            }
+        }
+        return left;
+    };
+
+    const value = binary(4);
+    return pos === tokens.length ? value : undefined;
+}
+
+/**
+ * Turn const/var specs into variables. In a const block a spec without values
+ * repeats the previous expression list and type, and iota is the spec's index.
+ * Integer expressions, with iota or literal arithmetic such as `30 * 1000`, are
+ * folded to each constant's value. Blank (_) names are skipped.
+ */
+export function expandValueSpecs(specs: GoValueSpecText[], isConst: boolean): GoVariable[] {
+    const vars: GoVariable[] = [];
+    let previous: GoValueSpecText | undefined;
+
+    specs.forEach((spec, iota) => {
+        let type = spec.type;
+        let values = spec.values;
+        if (isConst) {
+            if (values.length === 0 && previous) {
+                type = previous.type;
+                values = previous.values;
+            } else {
+                previous = spec;
+            }
+        }
+
+        spec.names.forEach((name, i) => {
+            if (name === '_') {
+                return;
+            }
+            let value = values[i];
+            let valueType = type;
+            const isIota = isConst && value?.trim() === 'iota';
+            // A lone literal (0xFF, -5) is kept as written
+            if (value !== undefined && isConst && (/\biota\b/.test(value) || !/^[-+]?[\w.]+$/.test(value.trim()))) {
+                value = value.replace(/\biota\b/g, String(iota));
+                const folded = evaluateIntegerConstant(value);
+                if (folded !== undefined) {
+                    value = folded.toString();
+                    if (!valueType && (folded > 2147483647n || folded < -2147483648n)) {
+                        valueType = { name: 'int64', isPointer: false, isSlice: false, isMap: false, isVariadic: false };
+                    }
+                }
+            }
+            vars.push({
+                name,
+                type: valueType || (value !== undefined ? inferTypeFromLiteral(value) : undefined),
+                isConst,
+                exported: /^[A-Z]/.test(name),
+                value,
+                doc: spec.doc,
+                namePosition: spec.namePositions?.[i],
+                ...(isIota ? { iota } : {})
+            });
+        });
+    });
+
+    return vars;
+}
+
+/**
+ * Split text on a separator that is not nested in brackets or string literals
+ */
+function splitTopLevel(text: string, separator: string): string[] {
+    const parts: string[] = [];
+    let depth = 0;
+    let start = 0;
+    for (let i = 0; i < text.length; i++) {
+        const ch = text[i];
+        if (ch === '"' || ch === '\'' || ch === '`') {
+            i++;
+            while (i < text.length && text[i] !== ch) {
+                if (text[i] === '\\' && ch !== '`') i++;
+                i++;
+            }
+        } else if ('([{'.includes(ch)) {
+            depth++;
+        } else if (')]}'.includes(ch)) {
+            depth--;
+        } else if (depth === 0 && text.startsWith(separator, i)) {
+            parts.push(text.slice(start, i).trim());
+            start = i + separator.length;
+        }
+    }
+    parts.push(text.slice(start).trim());
+    return parts.filter(Boolean);
+}
+
+/**
+ * Net count of open brackets in a line of Go code, ignoring strings and comments
+ */
+function bracketBalance(line: string): number {
+    let balance = 0;
+    for (let i = 0; i < line.length; i++) {
+        const ch = line[i];
+        if (ch === '/' && line[i + 1] === '/') {
+            break;
+        }
+        if (ch === '"' || ch === '\'' || ch === '`') {
+            i++;
+            while (i < line.length && line[i] !== ch) {
+                if (line[i] === '\\' && ch !== '`') i++;
+                i++;
+            }
+        } else if ('([{'.includes(ch)) {
+            balance++;
+        } else if (')]}'.includes(ch)) {
+            balance--;
+        }
+    }
+    return balance;
+}
+
+/**
+ * Remove a trailing // comment that is not part of a string literal
+ */
+function stripLineComment(line: string): string {
+    for (let i = 0; i < line.length; i++) {
+        const ch = line[i];
+        if (ch === '/' && line[i + 1] === '/') {
+            return line.slice(0, i).trimEnd();
+        }
+        if (ch === '"' || ch === '\'' || ch === '`') {
+            i++;
+            while (i < line.length && line[i] !== ch) {
+                if (line[i] === '\\' && ch !== '`') i++;
+                i++;
+            }
+        }
+    }
+    return line;
+}
+
 export class GoFileParser {
     /**
      * Parse an entire Go file into structured data
@@ -125,6 +532,7 @@ export class GoFileParser {
             imports: [],
             structs: [],
             interfaces: [],
+            namedTypes: [],
             functions: [],
             variables: [],
             constants: []
@@ -158,10 +566,16 @@ export class GoFileParser {
             // Parse type declarations (struct or interface)
             if (line.startsWith('type ')) {
                 const result = this.parseTypeDeclaration(lines, i);
+                const doc = extractDocComment(lines, i);
                 if (result.struct) {
+                    result.struct.doc = doc;
                     goFile.structs.push(result.struct);
                 } else if (result.interface) {
+                    result.interface.doc = doc;
                     goFile.interfaces.push(result.interface);
+                } else if (result.namedType) {
+                    result.namedType.doc = doc;
+                    goFile.namedTypes.push(result.namedType);
                 }
                 i = result.nextLine;
                 continue;
@@ -171,12 +585,14 @@ export class GoFileParser {
             if (line.startsWith('func ')) {
                 const { func, nextLine } = this.parseFunction(lines, i);
                 if (func) {
+                    func.doc = extractDocComment(lines, i);
                     // Check if this is a method (has receiver)
                     if (func.isMethod && func.receiver) {
                         const receiverTypeName = func.receiver.type.name.replace('*', '');
-                        const struct = goFile.structs.find(s => s.name === receiverTypeName);
-                        if (struct) {
-                            struct.methods.push(func);
+                        const owner = goFile.structs.find(s => s.name === receiverTypeName)
+                            || goFile.namedTypes.find(t => t.name === receiverTypeName && !t.isAlias);
+                        if (owner) {
+                            owner.methods.push(func);
                         } else {
                             // Method for unknown struct, add as regular function
                             goFile.functions.push(func);
@@ -190,22 +606,18 @@ export class GoFileParser {
             }
 
             // Parse variable declarations
-            if (line.startsWith('var ')) {
-                const variable = this.parseVariable(line, false);
-                if (variable) {
-                    goFile.variables.push(variable);
-                }
-                i++;
+            if (line.startsWith('var ') || line.startsWith('var(')) {
+                const { variables, nextLine } = this.parseValueDeclaration(lines, i, false);
+                goFile.variables.push(...variables);
+                i = nextLine;
                 continue;
             }
 
             // Parse constant declarations
-            if (line.startsWith('const ')) {
-                const constant = this.parseVariable(line, true);
-                if (constant) {
-                    goFile.constants.push(constant);
-                }
-                i++;
+            if (line.startsWith('const ') || line.startsWith('const(')) {
+                const { variables, nextLine } = this.parseValueDeclaration(lines, i, true);
+                goFile.constants.push(...variables);
+                i = nextLine;
                 continue;
             }
 
@@ -269,30 +681,63 @@ export class GoFileParser {
     }
 
     /**
-     * Parse type declaration (struct or interface)
+     * Parse type declaration (struct, interface, alias or defined type)
      */
     private static parseTypeDeclaration(lines: string[], startLine: number): {
         struct?: GoStruct,
         interface?: GoInterface,
+        namedType?: GoNamedType,
         nextLine: number
     } {
         const line = lines[startLine].trim();
 
+        // Type parameters of a generic type: `type Stack[T any] struct`, but not `type Grid [4]int`
+        const generic = line.match(/^type\s+(\w+)\s*\[(?=\s*\w+(?:\s*,\s*\w+)*\s+[^\]\s])/);
+        let typeParams: GoTypeParam[] | undefined;
+        let declared = line;
+        if (generic) {
+            const close = GoFunctionParser['findClosingBracket'](line, generic[0].length - 1);
+            if (close !== -1) {
+                typeParams = GoFunctionParser.parseTypeParams(line.substring(generic[0].length, close));
+                declared = `type ${generic[1]} ${line.substring(close + 1).trim()}`;
+            }
+        }
+
         // Check for struct
-        const structMatch = line.match(/^type\s+(\w+)\s+struct\s*\{?/);
+        const structMatch = declared.match(/^type\s+(\w+)\s+struct\s*\{?/);
         if (structMatch) {
             const name = structMatch[1];
-            return this.parseStruct(name, lines, startLine);
+            const result = this.parseStruct(name, lines, startLine);
+            result.struct.typeParams = typeParams;
+            return result;
         }
 
         // Check for interface
-        const interfaceMatch = line.match(/^type\s+(\w+)\s+interface\s*\{?/);
+        const interfaceMatch = declared.match(/^type\s+(\w+)\s+interface\s*\{?/);
         if (interfaceMatch) {
             const name = interfaceMatch[1];
-            return this.parseInterface(name, lines, startLine);
+            const result = this.parseInterface(name, lines, startLine);
+            result.interface.typeParams = typeParams;
+            return result;
         }
 
-        // Type alias (not supported in MVP)
+        // Alias (`type MyInt = int`) or defined type (`type Celsius float64`)
+        const namedMatch = declared.match(/^type\s+(\w+)\s*(=)?\s*([^\s{].*?)\s*(?:\/\/.*)?$/);
+        if (namedMatch && !typeParams && !/[{(]$/.test(namedMatch[3])) {
+            const nameIndex = lines[startLine].indexOf(namedMatch[1]);
+            return {
+                namedType: {
+                    name: namedMatch[1],
+                    underlying: GoFunctionParser['parseType'](namedMatch[3]),
+                    isAlias: !!namedMatch[2],
+                    methods: [],
+                    namePosition: { line: startLine, character: nameIndex >= 0 ? nameIndex : 0 }
+                },
+                nextLine: startLine + 1
+            };
+        }
+
+        // Anything else (generic defined types, `type (` groups) is skipped
         return { nextLine: startLine + 1 };
     }
 
@@ -357,6 +802,7 @@ export class GoFileParser {
             // Parse field (including embedded fields)
             const field = this.parseField(line, i, lines[i]);
             if (field) {
+                field.doc = extractDocComment(lines, i);
                 if (field.isEmbedded && struct.embeddedTypes) {
                     struct.embeddedTypes.push(field.type);
                 }
@@ -379,8 +825,8 @@ export class GoFileParser {
      * Parse struct field (including embedded/anonymous fields)
      */
     private static parseField(line: string, lineNumber: number, rawLine: string): GoField | null {
-        // First, try to match a named field: fieldName type `tag`
-        const namedMatch = line.match(/^(\w+)\s+([^\s`]+)(?:\s+`([^`]+)`)?/);
+        // First, try to match a named field: fieldName type `tag`; channel types contain a space
+        const namedMatch = line.match(/^(\w+)\s+((?:<-\s*)?chan(?:\s*<-)?\s+[^\s`]+|[^\s`]+)(?:\s+`([^`]+)`)?/);
         if (namedMatch) {
             const name = namedMatch[1];
             const typeStr = namedMatch[2];
@@ -475,6 +921,7 @@ export class GoFileParser {
         // Parse methods until closing brace
         let braceCount = 1;
         let methodBuffer = '';
+        let methodStartLine = i;
 
         while (i < lines.length && braceCount > 0) {
             const line = lines[i].trim();
@@ -495,13 +942,32 @@ export class GoFileParser {
                 continue;
             }
 
+            // Type terms of a constraint: ~int | ~float64
+            const terms = stripLineComment(line);
+            if (!methodBuffer && (/[~|]/.test(terms) || GoFunctionParser.isBuiltinType(terms))) {
+                iface.typeTerms = [...(iface.typeTerms || []), ...terms.split('|').map(t => t.trim()).filter(Boolean)];
+                i++;
+                continue;
+            }
+
+            // Embedded interface: Reader, io.Closer
+            if (!methodBuffer && /^[A-Za-z_][\w.]*$/.test(terms)) {
+                iface.embeddedInterfaces = [...(iface.embeddedInterfaces || []), terms];
+                i++;
+                continue;
+            }
+
             // Accumulate method signature (may be multi-line)
+            if (!methodBuffer) {
+                methodStartLine = i;
+            }
             methodBuffer += ' ' + line;
 
             // Check if we have a complete method signature
             if (line.includes(')') || i === lines.length - 1) {
                 const method = this.parseMethodSignature(methodBuffer.trim());
                 if (method) {
+                    method.doc = extractDocComment(lines, methodStartLine);
                     iface.methods.push(method);
                 }
                 methodBuffer = '';
@@ -547,69 +1013,120 @@ export class GoFileParser {
         func: GoFunction | null,
         nextLine: number
     } {
-        let i = startLine;
-        let funcText = '';
+        const text = lines.slice(startLine).join('\n');
+        const braceIndex = findBodyOpenBrace(text);
+        if (braceIndex === -1) {
+            // Declaration without a body
+            return { func: GoFunctionParser.parseFunction(lines[startLine]), nextLine: startLine + 1 };
+        }
 
-        // Collect function signature (up to opening brace or end of signature)
-        while (i < lines.length) {
-            const line = lines[i];
-            funcText += line;
+        // Signature may span several lines; the body ends at the matching brace
+        const signature = text.substring(0, braceIndex).replace(/\s*\n\s*/g, ' ').trim();
+        let closeIndex = findMatchingBrace(text, braceIndex);
+        if (closeIndex === -1) {
+            closeIndex = text.length;
+        }
 
-            if (line.includes('{')) {
-                // Found opening brace, function signature is complete
-                // Remove everything after the opening brace
-                const braceIndex = funcText.indexOf('{');
-                funcText = funcText.substring(0, braceIndex).trim();
+        const func = GoFunctionParser.parseFunction(signature);
+        if (func) {
+            const nameIndex = lines[startLine].search(new RegExp(`\\b${func.name}\\s*[[(]`));
+            func.namePosition = { line: startLine, character: Math.max(nameIndex, 0) };
+            const beforeBody = text.substring(0, braceIndex + 1).split('\n');
+            func.body = text.substring(braceIndex + 1, closeIndex);
+            func.bodyPosition = {
+                line: startLine + beforeBody.length - 1,
+                character: beforeBody[beforeBody.length - 1].length
+            };
+        }
+
+        const linesConsumed = text.substring(0, closeIndex).split('\n').length;
+        return { func, nextLine: startLine + linesConsumed };
+    }
+
+    /**
+     * Parse a var/const declaration, either a single spec or a parenthesized block
+     */
+    private static parseValueDeclaration(lines: string[], startLine: number, isConst: boolean): {
+        variables: GoVariable[],
+        nextLine: number
+    } {
+        const keyword = isConst ? 'const' : 'var';
+        const first = stripLineComment(lines[startLine].trim()).substring(keyword.length).trim();
+        const specs: GoValueSpecText[] = [];
+        let i = startLine;
 
-                // Skip function body (count braces to find closing brace)
-                let braceCount = 1;
+        // Join continuation lines of multi-line initializers (e.g. composite literals)
+        const readLogicalLine = (text: string): string => {
+            let balance = bracketBalance(text);
+            while (balance > 0 && i + 1 < lines.length) {
                 i++;
-                while (i < lines.length && braceCount > 0) {
-                    if (lines[i].includes('{')) {
-                        braceCount++;
-                    }
-                    if (lines[i].includes('}')) {
-                        braceCount--;
-                    }
-                    i++;
-                }
-                break;
+                const next = stripLineComment(lines[i].trim());
+                text += ' ' + next;
+                balance += bracketBalance(next);
             }
+            return text;
+        };
 
+        if (!first.startsWith('(')) {
+            const spec = this.parseValueSpec(readLogicalLine(first));
+            if (spec) {
+                spec.doc = extractDocComment(lines, startLine);
+                spec.namePositions = this.namePositions(spec.names, lines[startLine], startLine);
+                specs.push(spec);
+            }
+            return { variables: expandValueSpecs(specs, isConst), nextLine: i + 1 };
+        }
+
+        let inner = first.substring(1).trim();
+        while (i < lines.length) {
+            if (inner === ')' || inner.startsWith(')')) {
+                break;
+            }
+            if (inner) {
+                const specLine = i;
+                const spec = this.parseValueSpec(readLogicalLine(inner));
+                if (spec) {
+                    spec.doc = extractDocComment(lines, specLine);
+                    spec.namePositions = this.namePositions(spec.names, lines[specLine], specLine);
+                    specs.push(spec);
+                }
+            }
             i++;
+            inner = i < lines.length ? stripLineComment(lines[i].trim()) : '';
         }
 
-        const func = GoFunctionParser.parseFunction(funcText);
-        return { func, nextLine: i };
+        return { variables: expandValueSpecs(specs, isConst), nextLine: i + 1 };
     }
 
     /**
-     * Parse variable or constant declaration
+     * Positions of a spec's names on the line declaring them
      */
-    private static parseVariable(line: string, isConst: boolean): GoVariable | null {
-        // Match: var/const name type = value
-        // or: var/const name = value
-        const keyword = isConst ? 'const' : 'var';
-        const pattern = new RegExp(`^${keyword}\\s+(\\w+)(?:\\s+([^=\\s]+))?(?:\\s*=\\s*(.+))?`);
-        const match = line.match(pattern);
+    private static namePositions(names: string[], line: string, lineNumber: number): SourcePosition[] {
+        return names.map(name => ({
+            line: lineNumber,
+            character: Math.max(line.search(new RegExp(`\\b${name}\\b`)), 0)
+        }));
+    }
 
+    /**
+     * Parse one spec: names [type] [= values]
+     */
+    private static parseValueSpec(text: string): GoValueSpecText | null {
+        const assignIndex = text.search(/(?<![=!<>:])=(?!=)/);
+        const left = (assignIndex === -1 ? text : text.substring(0, assignIndex)).trim();
+        const right = assignIndex === -1 ? '' : text.substring(assignIndex + 1).trim();
+
+        const match = left.match(/^(\w+(?:\s*,\s*\w+)*)\s*(.*)$/);
         if (!match) {
             return null;
         }
 
-        const name = match[1];
-        const typeStr = match[2];
-        const value = match[3];
-
-        // Check if variable is exported
-        const exported = name.charAt(0) === name.charAt(0).toUpperCase();
-
+        const names = match[1].split(',').map(n => n.trim());
+        const typeStr = match[2].trim();
         return {
-            name,
+            names,
             type: typeStr ? GoFunctionParser['parseType'](typeStr) : undefined,
-            isConst,
-            exported,
-            value
+            values: right ? splitTopLevel(right, ',') : []
         };
     }
 }
@@ -1,3 +1,15 @@
+import { findBodyOpenBrace, findMatchingBrace } from './goBodyParser';
+
+/** Reserved words that cannot name a Java package, class or variable */
+export const JAVA_KEYWORDS = new Set([
+    'abstract', 'assert', 'boolean', 'break', 'byte', 'case', 'catch', 'char', 'class', 'const',
+    'continue', 'default', 'do', 'double', 'else', 'enum', 'extends', 'final', 'finally', 'float',
+    'for', 'goto', 'if', 'implements', 'import', 'instanceof', 'int', 'interface', 'long', 'native',
+    'new', 'package', 'private', 'protected', 'public', 'return', 'short', 'static', 'strictfp',
+    'super', 'switch', 'synchronized', 'this', 'throw', 'throws', 'transient', 'try', 'void',
+    'volatile', 'while', 'true', 'false', 'null', '_'
+]);
+
 /** Source position for LSP queries */
 export interface SourcePosition {
     line: number;
@@ -14,6 +26,8 @@ export interface GoFunction {
     name: string;
     parameters: GoParameter[];
     returnTypes: GoType[];
+    /** Names of named results, parallel to returnTypes (`(n int, err error)`) */
+    returnNames?: string[];
     isMethod: boolean;
     receiver?: GoParameter;
     hasErrorReturn: boolean;
@@ -23,6 +37,23 @@ export interface GoFunction {
     startPosition?: SourcePosition;
     /** Full range of the function signature */
     signatureRange?: SourceRange;
+    /** Source text between the body braces (absent for signature-only input) */
+    body?: string;
+    /** Position of the first character after the opening body brace */
+    bodyPosition?: SourcePosition;
+    /** Doc comment text, without comment markers */
+    doc?: string;
+    /** Type parameters of a generic function (`[T any, U any]`) */
+    typeParams?: GoTypeParam[];
+}
+
+/**
+ * Type parameter of a generic function or type
+ */
+export interface GoTypeParam {
+    name: string;
+    /** Constraint as written in Go: `any`, `comparable`, `Number`, `~int | ~float64` */
+    constraint: string;
 }
 
 export interface GoParameter {
@@ -42,6 +73,10 @@ export interface GoType {
     isVariadic: boolean;
     keyType?: GoType;
     valueType?: GoType;
+    /** Element type of a slice or variadic parameter (e.g. map[string]int for []map[string]int) */
+    elementType?: GoType;
+    /** Type arguments of an instantiated generic type (`int` for Stack[int]) */
+    typeArgs?: GoType[];
     /** Position of this type reference in source (for LSP queries) */
     position?: SourcePosition;
     // Semantic fields (enriched by gopls)
@@ -59,6 +94,12 @@ export interface GoType {
     fullImportPath?: string;
 }
 
+/** How Go slices are represented in Java: List<T> (growable) or T[] */
+export type SliceStrategy = 'list' | 'array';
+
+/** Java type for Go's platform-sized int and uint */
+export type IntType = 'int' | 'long';
+
 /** @deprecated Use SourcePosition instead */
 export interface TypeSourcePosition {
     line: number;
@@ -77,6 +118,7 @@ export class GoFunctionParser {
         'uint16': 'int',
         'uint32': 'long',
         'uint64': 'long',
+        'uintptr': 'long',
         'float32': 'float',
         'float64': 'double',
         'bool': 'boolean',
@@ -85,9 +127,21 @@ export class GoFunctionParser {
         'byte': 'byte',
         'error': 'Exception',
         'interface{}': 'Object',
+        'struct{}': 'Object',
         'any': 'Object'
     };
 
+    private static readonly FUNCTIONAL_METHODS: { [key: string]: string } = {
+        'Runnable': 'run',
+        'Supplier': 'get',
+        'Consumer': 'accept',
+        'BiConsumer': 'accept',
+        'Function': 'apply',
+        'BiFunction': 'apply',
+        'Predicate': 'test',
+        'BiPredicate': 'test'
+    };
+
     private static readonly BOXED_TYPE_MAP: { [key: string]: string } = {
         'int': 'Integer',
         'byte': 'Byte',
@@ -100,11 +154,22 @@ export class GoFunctionParser {
     };
 
     static parseFunction(text: string): GoFunction | null {
-        const cleanText = text.trim();
+        let cleanText = text.trim();
         if (!cleanText.startsWith('func')) {
             return null;
         }
 
+        // Split off the body when the text contains the whole declaration
+        let body: string | undefined;
+        const braceIndex = findBodyOpenBrace(cleanText);
+        if (braceIndex !== -1) {
+            const closeIndex = findMatchingBrace(cleanText, braceIndex);
+            if (closeIndex !== -1) {
+                body = cleanText.substring(braceIndex + 1, closeIndex);
+            }
+            cleanText = cleanText.substring(0, braceIndex).trim();
+        }
+
         // Parse receiver for methods
         const receiverMatch = cleanText.match(/^func\s+\(([^)]+)\)\s+(\w+)/);
         const isMethod = !!receiverMatch;
@@ -125,33 +190,97 @@ export class GoFunctionParser {
             afterReceiver = cleanText.substring(4); // Remove "func "
         }
         
-        // Match function name and parameters more carefully
-        const funcMatch = afterReceiver.match(/(\w+)\s*\(([^)]*)\)(.*)/);
-        
+        // Match function name and parameters more carefully; func-typed parameters nest parentheses
+        const funcMatch = afterReceiver.match(/(\w+)\s*([[(])/);
         if (!funcMatch) {
             return null;
         }
+        let openIndex = funcMatch.index! + funcMatch[0].length - 1;
+        let typeParams: GoTypeParam[] | undefined;
+        if (funcMatch[2] === '[') {
+            const typeParamsEnd = this.findClosingBracket(afterReceiver, openIndex);
+            openIndex = typeParamsEnd === -1 ? -1 : afterReceiver.indexOf('(', typeParamsEnd);
+            if (openIndex === -1) {
+                return null;
+            }
+            typeParams = this.parseTypeParams(afterReceiver.substring(funcMatch.index! + funcMatch[0].length, typeParamsEnd));
+        }
+        const closeIndex = this.findClosingBracket(afterReceiver, openIndex, '(', ')');
+        if (closeIndex === -1) {
+            return null;
+        }
 
         const name = funcMatch[1];
-        const paramsStr = funcMatch[2];
-        const returnPart = funcMatch[3].trim();
+        const paramsStr = afterReceiver.substring(openIndex + 1, closeIndex);
+        const returnPart = afterReceiver.substring(closeIndex + 1).trim();
 
         // Extract return types
         const returnTypes = this.parseReturnTypes(returnPart);
+        const returnNames = this.parseNamedResults(returnPart)?.map(p => p.name);
 
         const parameters = this.parseParameters(paramsStr);
+        this.validateVariadicParameters(name, parameters);
         const hasErrorReturn = returnTypes.some(t => t.name === 'error');
 
         return {
             name,
             parameters,
             returnTypes,
+            returnNames,
             isMethod,
             receiver,
-            hasErrorReturn
+            hasErrorReturn,
+            body,
+            typeParams
         };
     }
 
+    /**
+     * Parse a type parameter list without its brackets: `K comparable, V any`.
+     * Names share a constraint like parameters share a type (`K, V any`).
+     */
+    static parseTypeParams(text: string): GoTypeParam[] {
+        const params: GoTypeParam[] = [];
+        let pendingNames: string[] = [];
+        for (const segment of this.splitTopLevel(text)) {
+            const match = segment.match(/^(\w+)\s+(.+)$/s);
+            if (!match) {
+                pendingNames.push(segment);
+                continue;
+            }
+            for (const name of [...pendingNames, match[1]]) {
+                params.push({ name, constraint: match[2].replace(/\s+/g, ' ') });
+            }
+            pendingNames = [];
+        }
+        return params;
+    }
+
+    /**
+     * Results of a `(n int, err error)` result list, or undefined when the results are unnamed
+     */
+    private static parseNamedResults(returnStr: string): GoParameter[] | undefined {
+        const trimmed = returnStr.trim().replace(/^:/, '').trim();
+        if (!trimmed.startsWith('(') || !trimmed.endsWith(')')) {
+            return undefined;
+        }
+        const inner = trimmed.slice(1, -1);
+        const isNamed = this.splitTopLevel(inner).some(item => /^\w+\s+\S/.test(item.trim()) && !/^(chan|func)\b/.test(item.trim()));
+        return isNamed ? this.parseParameters(inner) : undefined;
+    }
+
+    /**
+     * Go only allows the final parameter to be variadic (...T)
+     * @throws Error naming the offending parameter otherwise
+     */
+    static validateVariadicParameters(funcName: string, parameters: GoParameter[]): void {
+        const misplaced = parameters.findIndex((p, i) => p.type.isVariadic && i !== parameters.length - 1);
+        if (misplaced !== -1) {
+            const paramName = parameters[misplaced].name || `#${misplaced + 1}`;
+            throw new Error(`Invalid Go function '${funcName}': only the final parameter can be variadic, but '${paramName}' uses ...`);
+        }
+    }
+
     private static parseParameters(paramsStr: string): GoParameter[] {
         const trimmed = paramsStr.trim();
         if (!trimmed) {
@@ -162,7 +291,7 @@ export class GoFunctionParser {
         let pendingNames: string[] = [];
 
         // Split on commas at top level to respect Go's grouped syntax (a, b, c int)
-        const segments = trimmed.split(',').map(s => s.trim()).filter(Boolean);
+        const segments = this.splitTopLevel(trimmed);
 
         for (const segment of segments) {
             const tokens = segment.split(/\s+/).filter(Boolean);
@@ -172,9 +301,10 @@ export class GoFunctionParser {
                 continue;
             }
 
-            // Last token is the type (covers variadic ...T, slices, maps)
-            const typeStr = tokens.slice(-1).join(' ');
-            const names = [...pendingNames, ...tokens.slice(0, -1)];
+            // The type runs from the second token to the end of the segment
+            // (covers variadic ...T, slices, maps, func(a int) bool and Pair[K, V])
+            const typeStr = tokens.slice(1).join(' ');
+            const names = [...pendingNames, tokens[0]];
             pendingNames = [];
 
             const type = this.parseType(typeStr);
@@ -193,6 +323,12 @@ export class GoFunctionParser {
             return returnTypes;
         }
 
+        // Named results group like parameters: "(x, y int)" declares two ints
+        const named = this.parseNamedResults(returnStr);
+        if (named) {
+            return named.map(p => p.type);
+        }
+
         // Remove leading ':' if present
         let cleanReturnStr = returnStr.startsWith(':') ? returnStr.substring(1).trim() : returnStr.trim();
 
@@ -202,17 +338,11 @@ export class GoFunctionParser {
         }
 
         // Split by commas for multiple return values
-        const returnList = cleanReturnStr.split(',').map(s => s.trim()).filter(s => s);
+        const returnList = this.splitTopLevel(cleanReturnStr);
 
+        // Named results were handled above, so each item is a whole type (func(a int) bool, Pair[K, V])
         for (const returnItem of returnList) {
-            // Handle named returns: "res int" -> type is last token
-            const tokens = returnItem.split(/\s+/).filter(Boolean);
-            if (tokens.length === 0) {
-                continue;
-            }
-            const typeToken = tokens[tokens.length - 1];
-            const type = this.parseType(typeToken);
-            returnTypes.push(type);
+            returnTypes.push(this.parseType(returnItem));
         }
 
         return returnTypes;
@@ -220,24 +350,26 @@ export class GoFunctionParser {
 
     private static parseType(typeStr: string): GoType {
         const trimmed = typeStr.trim();
-        
-        const isVariadic = trimmed.startsWith('...');
-        const isPointer = trimmed.startsWith('*');
-        const isSlice = trimmed.startsWith('[]');
-        const isMap = trimmed.startsWith('map[');
-
-        let baseType = trimmed;
-        if (isVariadic) {
-            baseType = baseType.substring(3);
-        } else if (isPointer) {
-            baseType = baseType.substring(1);
-        } else if (isSlice) {
-            baseType = baseType.substring(2);
-        } else if (isMap) {
-            const mapMatch = trimmed.match(/map\[(.+)\](.+)/);
-            if (mapMatch) {
-                const keyType = this.parseType(mapMatch[1]);
-                const valueType = this.parseType(mapMatch[2]);
+
+        // Slices and variadics: keep the element type so nested types such as []map[K]V survive
+        if (trimmed.startsWith('...') || trimmed.startsWith('[]')) {
+            const isVariadic = trimmed.startsWith('...');
+            const elementType = this.parseType(trimmed.substring(isVariadic ? 3 : 2));
+            return {
+                name: elementType.name,
+                isPointer: false,
+                isSlice: true,
+                isMap: false,
+                isVariadic,
+                elementType
+            };
+        }
+
+        if (trimmed.startsWith('map[')) {
+            const keyEnd = this.findClosingBracket(trimmed, 3);
+            if (keyEnd !== -1) {
+                const keyType = this.parseType(trimmed.substring(4, keyEnd));
+                const valueType = this.parseType(trimmed.substring(keyEnd + 1));
                 return {
                     name: 'Map',
                     isPointer: false,
@@ -250,25 +382,278 @@ export class GoFunctionParser {
             }
         }
 
+        const isPointer = trimmed.startsWith('*');
+        const name = isPointer ? trimmed.substring(1) : trimmed;
+        // Generic instantiation: Stack[int], Pair[string, int]
+        const generic = name.match(/^([\w.]+)\[(.*)\]$/s);
         return {
-            name: baseType,
+            name: generic ? generic[1] : name,
             isPointer,
-            isSlice: isSlice || isVariadic,
+            isSlice: false,
             isMap: false,
-            isVariadic
+            isVariadic: false,
+            ...(generic ? { typeArgs: this.splitTopLevel(generic[2]).map(arg => this.parseType(arg)) } : {})
+        };
+    }
+
+    /**
+     * Index of the ']' matching the '[' at openIndex, or -1
+     */
+    private static findClosingBracket(text: string, openIndex: number, open = '[', close = ']'): number {
+        let depth = 0;
+        for (let i = openIndex; i < text.length; i++) {
+            if (text[i] === open) {
+                depth++;
+            } else if (text[i] === close) {
+                depth--;
+                if (depth === 0) {
+                    return i;
+                }
+            }
+        }
+        return -1;
+    }
+
+    /**
+     * Split a parameter or result list on the commas not nested in brackets
+     */
+    private static splitTopLevel(text: string): string[] {
+        const items: string[] = [];
+        let depth = 0;
+        let start = 0;
+        for (let i = 0; i < text.length; i++) {
+            if ('([{'.includes(text[i])) {
+                depth++;
+            } else if (')]}'.includes(text[i])) {
+                depth--;
+            } else if (text[i] === ',' && depth === 0) {
+                items.push(text.substring(start, i));
+                start = i + 1;
+            }
+        }
+        items.push(text.substring(start));
+        return items.map(s => s.trim()).filter(Boolean);
+    }
+
+    /**
+     * Parameter and result types of a func type such as `func(a, b int) bool`;
+     * undefined for any other type
+     */
+    static funcSignature(goType?: GoType): { parameters: GoType[], results: GoType[] } | undefined {
+        if (!goType || goType.isSlice || goType.isMap || !/^func\s*\(/.test(goType.name)) {
+            return undefined;
+        }
+        const text = goType.name.replace(/\s+/g, ' ');
+        const openIndex = text.indexOf('(');
+        const closeIndex = this.findClosingBracket(text, openIndex, '(', ')');
+        if (closeIndex === -1) {
+            return undefined;
+        }
+        // A parameter list groups and names like a result list
+        return {
+            parameters: this.parseReturnTypes(text.substring(openIndex, closeIndex + 1)),
+            results: this.parseReturnTypes(text.substring(closeIndex + 1))
         };
     }
 
-    static convertGoTypeToJava(goType: GoType, needsBoxing: boolean = false): string {
+    /**
+     * java.util.function interface for a func type: Runnable, Supplier, Consumer, Function,
+     * Predicate or their two-argument forms. Undefined for other types, and for func types
+     * with more than two parameters, several results or variadic parameters.
+     */
+    static functionalInterface(goType: GoType, sliceStrategy: SliceStrategy = 'list', intType: IntType = 'int'): string | undefined {
+        const signature = this.funcSignature(goType);
+        if (!signature || signature.parameters.length > 2 || signature.results.length > 1
+            || signature.parameters.some(p => p.isVariadic)) {
+            return undefined;
+        }
+        const args = signature.parameters.map(p => this.convertGoTypeToJava(p, true, sliceStrategy, intType));
+        const result = signature.results[0];
+        const withArgs = (name: string, extra: string[] = []) => [...args, ...extra].length > 0 ? `${name}<${[...args, ...extra].join(', ')}>` : name;
+        if (!result) {
+            return withArgs(['Runnable', 'Consumer', 'BiConsumer'][args.length]);
+        }
+        if (result.name === 'bool' && !result.isSlice && !result.isMap && !result.isPointer && args.length > 0) {
+            return withArgs(['', 'Predicate', 'BiPredicate'][args.length]);
+        }
+        return withArgs(['Supplier', 'Function', 'BiFunction'][args.length], [this.convertGoTypeToJava(result, true, sliceStrategy, intType)]);
+    }
+
+    /**
+     * Method a functional interface from functionalInterface is invoked through
+     */
+    static functionalMethod(javaType: string): string | undefined {
+        return this.FUNCTIONAL_METHODS[javaType.replace(/<.*$/, '')];
+    }
+
+    /**
+     * Element type of a slice or variadic parameter
+     */
+    static elementTypeOf(goType: GoType): GoType {
+        return goType.elementType || { ...goType, isSlice: false, isVariadic: false };
+    }
+
+    /**
+     * Element type of a channel type (`chan int`, `<-chan int`, `chan<- int`), or
+     * undefined for any other type
+     */
+    static channelElement(goType: GoType): GoType | undefined {
+        if (goType.isSlice || goType.isMap || goType.isPointer) {
+            return undefined;
+        }
+        const match = goType.name.match(/^(?:<-\s*chan|chan\s*<-|chan)\s+(.+)$/);
+        return match ? goType.valueType || this.parseType(match[1]) : undefined;
+    }
+
+    /**
+     * []byte is raw binary data, which Java keeps in byte[] regardless of the slice strategy
+     */
+    static isByteSlice(goType: GoType): boolean {
+        if (!goType.isSlice || goType.isVariadic) {
+            return false;
+        }
+        const element = this.elementTypeOf(goType);
+        return (element.name === 'byte' || element.name === 'uint8')
+            && !element.isSlice && !element.isMap && !element.isPointer;
+    }
+
+    /**
+     * Go unsigned types whose values may not fit the signed Java type they map to
+     */
+    static isLossyUnsigned(goType: GoType): boolean {
+        return ['uint', 'uint8', 'uint64', 'uintptr'].includes(goType.name)
+            && !goType.isSlice && !goType.isMap && !goType.isPointer;
+    }
+
+    /**
+     * Replace type parameters by the types bound to them: []T with T = int gives []int
+     */
+    static substituteTypeParams(goType: GoType, bindings: Map<string, GoType>): GoType {
+        const bound = bindings.get(goType.name);
+        if (bound && !goType.isSlice && !goType.isMap && !goType.typeArgs) {
+            return { ...bound, isPointer: bound.isPointer || goType.isPointer, isVariadic: goType.isVariadic };
+        }
+        if (this.funcSignature(goType)) {
+            // Func types keep their Go text: func(T) U
+            const name = goType.name.replace(/\b\w+\b/g, word => bindings.has(word) ? this.typeText(bindings.get(word)!) : word);
+            return { ...goType, name };
+        }
+        const substitute = (t?: GoType) => t && this.substituteTypeParams(t, bindings);
+        return {
+            ...goType,
+            name: goType.isSlice && goType.elementType ? substitute(goType.elementType)!.name : goType.name,
+            elementType: substitute(goType.elementType),
+            keyType: substitute(goType.keyType),
+            valueType: substitute(goType.valueType),
+            typeArgs: goType.typeArgs?.map(t => substitute(t)!)
+        };
+    }
+
+    /**
+     * Go source text of a type: *User, []map[string]int, Stack[T]
+     */
+    static typeText(goType: GoType): string {
+        const pointer = goType.isPointer ? '*' : '';
+        if (goType.isSlice) {
+            return `${goType.isVariadic ? '...' : '[]'}${this.typeText(this.elementTypeOf(goType))}`;
+        }
+        if (goType.isMap && goType.keyType && goType.valueType) {
+            return `map[${this.typeText(goType.keyType)}]${this.typeText(goType.valueType)}`;
+        }
+        const args = goType.typeArgs ? `[${goType.typeArgs.map(t => this.typeText(t)).join(', ')}]` : '';
+        return `${pointer}${goType.name}${args}`;
+    }
+
+    /**
+     * Bind the type parameters named in typeParams by matching a parameter type against
+     * the type of the argument passed for it (T in []T against []int binds T = int)
+     */
+    static inferTypeArgs(paramType: GoType, argType: GoType, typeParams: Set<string>, bindings: Map<string, GoType>): void {
+        if (typeParams.has(paramType.name) && !paramType.isSlice && !paramType.isMap && !paramType.typeArgs) {
+            if (!bindings.has(paramType.name)) {
+                bindings.set(paramType.name, { ...argType, isPointer: argType.isPointer && !paramType.isPointer, isVariadic: false });
+            }
+            return;
+        }
+        const pairs: [GoType | undefined, GoType | undefined][] = [
+            [paramType.isSlice ? this.elementTypeOf(paramType) : undefined, argType.isSlice ? this.elementTypeOf(argType) : undefined],
+            [paramType.keyType, argType.keyType],
+            [paramType.valueType, argType.valueType],
+            ...(paramType.typeArgs || []).map((t, i) => [t, argType.typeArgs?.[i]] as [GoType, GoType | undefined])
+        ];
+        for (const [param, arg] of pairs) {
+            if (param && arg) {
+                this.inferTypeArgs(param, arg, typeParams, bindings);
+            }
+        }
+        // func(T) U against func(int) string
+        const paramSignature = this.funcSignature(paramType);
+        const argSignature = this.funcSignature(argType);
+        if (paramSignature && argSignature) {
+            [...paramSignature.parameters, ...paramSignature.results].forEach((t, i) => {
+                const arg = [...argSignature.parameters, ...argSignature.results][i];
+                if (arg) {
+                    this.inferTypeArgs(t, arg, typeParams, bindings);
+                }
+            });
+        }
+    }
+
+    /**
+     * Predeclared integer and floating-point types
+     */
+    static isNumericType(name: string): boolean {
+        return /^(u?int(8|16|32|64)?|uintptr|float(32|64)|byte|rune)$/.test(name);
+    }
+
+    /**
+     * Predeclared Go types, as opposed to types declared in Go code
+     */
+    static isBuiltinType(name: string): boolean {
+        return name in this.TYPE_MAP || name === 'complex64' || name === 'complex128';
+    }
+
+    static convertGoTypeToJava(
+        goType: GoType,
+        needsBoxing: boolean = false,
+        sliceStrategy: SliceStrategy = 'list',
+        intType: IntType = 'int'
+    ): string {
         if (goType.isMap && goType.keyType && goType.valueType) {
-            const keyJava = this.convertGoTypeToJava(goType.keyType, true);
-            const valueJava = this.convertGoTypeToJava(goType.valueType, true);
+            const keyJava = this.convertGoTypeToJava(goType.keyType, true, sliceStrategy, intType);
+            const valueJava = this.convertGoTypeToJava(goType.valueType, true, sliceStrategy, intType);
             return `Map<${keyJava}, ${valueJava}>`;
         }
 
-        let baseType = this.TYPE_MAP[goType.name] || goType.name;
+        const functional = this.functionalInterface(goType, sliceStrategy, intType);
+        if (functional) {
+            return functional;
+        }
+
+        // Channels are queues whose put and take block like a send and a receive
+        const channelElement = this.channelElement(goType);
+        if (channelElement) {
+            return `BlockingQueue<${this.convertGoTypeToJava(channelElement, true, sliceStrategy, intType)}>`;
+        }
+
+        let baseType = (goType.name === 'int' || goType.name === 'uint') && intType === 'long'
+            ? 'long'
+            : this.TYPE_MAP[goType.name] || goType.name;
+        if (goType.typeArgs && !goType.isSlice) {
+            baseType += `<${goType.typeArgs.map(t => this.convertGoTypeToJava(t, true, sliceStrategy, intType)).join(', ')}>`;
+        }
 
         if (goType.isSlice) {
+            if (sliceStrategy === 'array' || this.isByteSlice(goType)) {
+                // Arrays hold primitives directly, so the element type is not boxed
+                const element = goType.elementType
+                    ? this.convertGoTypeToJava(goType.elementType, false, sliceStrategy, intType)
+                    : baseType;
+                return `${element}[]`;
+            }
+            if (goType.elementType) {
+                return `List<${this.convertGoTypeToJava(goType.elementType, true, sliceStrategy, intType)}>`;
+            }
             const boxedBase = this.BOXED_TYPE_MAP[baseType] || baseType;
             return `List<${boxedBase}>`;
         }
@@ -292,8 +677,28 @@ export class GoFunctionParser {
 
     static toJavaMethodName(goName: string): string {
         if (goName.length === 0) return goName;
-        
-        return goName.charAt(0).toLowerCase() + goName.slice(1);
+
+        // A leading initialism is lowercased whole: URLPath → urlPath, ID → id
+        const leading = /^[A-Z]+(?![a-z])/.exec(goName)?.[0] || goName.charAt(0);
+        return this.escapeJavaKeyword(leading.toLowerCase() + goName.slice(leading.length));
+    }
+
+    /**
+     * A Go identifier that is a Java reserved word (`double`, `assert`) with `_` appended
+     */
+    static escapeJavaKeyword(name: string): string {
+        return JAVA_KEYWORDS.has(name) && name !== '_' ? `${name}_` : name;
+    }
+
+    /**
+     * Convert a Go constant name to Java UPPER_SNAKE_CASE
+     */
+    static toJavaConstantName(goName: string): string {
+        // Word boundaries: fooBar → FOO_BAR, HTTPServer → HTTP_SERVER; acronyms like KB stay intact
+        return goName
+            .replace(/([a-z\d])([A-Z])/g, '$1_$2')
+            .replace(/([A-Z]+)([A-Z][a-z])/g, '$1_$2')
+            .toUpperCase();
     }
 
     static toJavaClassName(goName: string): string {
@@ -1,8 +1,10 @@
 import * as vscode from 'vscode';
-import { GoFunctionParser } from './goParser';
+import { GoFunction, GoFunctionParser, IntType, SliceStrategy } from './goParser';
 import { JavaCodeGenerator } from './javaGenerator';
+import { Nullability } from './javaImports';
 import { findFunctionHeader } from './functionLocator';
 import * as TreeSitterGoParser from './treeSitterGoParser';
+import { StdlibCallMappings } from './conversionContext';
 
 export class GoToJavaHoverProvider implements vscode.HoverProvider {
     async provideHover(
@@ -27,9 +29,15 @@ export class GoToJavaHoverProvider implements vscode.HoverProvider {
         }
 
         const parserChoice = config.get<'regex' | 'tree-sitter'>('parser', 'tree-sitter');
-        const goFunc = parserChoice === 'tree-sitter'
-            ? await TreeSitterGoParser.parseFunction(header.text)
-            : GoFunctionParser.parseFunction(header.text);
+        let goFunc: GoFunction | null;
+        try {
+            goFunc = parserChoice === 'tree-sitter'
+                ? await TreeSitterGoParser.parseFunction(header.text)
+                : GoFunctionParser.parseFunction(header.text);
+        } catch (error) {
+            // Invalid signatures (e.g. a non-final variadic) get no hover
+            return undefined;
+        }
         if (!goFunc) {
             return undefined;
         }
@@ -38,7 +46,17 @@ export class GoToJavaHoverProvider implements vscode.HoverProvider {
         const options = {
             isStatic: true,
             addComments: false,
-            handleErrorsAsExceptions: true
+            handleErrorsAsExceptions: true,
+            exceptionClass: config.get<string>('exceptionClass', 'Exception'),
+            sliceStrategy: config.get<SliceStrategy>('sliceStrategy', 'list'),
+            intType: config.get<IntType>('intType', 'int'),
+            errorResultRecords: config.get<boolean>('errorResultRecords', false),
+            sharedResultRecord: config.get<boolean>('sharedResultRecord', false),
+            emptyCollections: config.get<boolean>('emptyCollections', false),
+            nilMatchesEmpty: config.get<boolean>('nilMatchesEmpty', false),
+            experimentalConcurrency: config.get<boolean>('experimentalConcurrency', false),
+            nullability: config.get<Nullability>('nullability', 'none'),
+            stdlibCalls: config.get<StdlibCallMappings>('stdlibCalls', {})
         };
 
         let javaPreview: string;
@@ -0,0 +1,5345 @@
+import { GoFunction, GoFunctionParser, GoParameter, GoType, GoTypeParam, SourcePosition } from './goParser';
+import { GoConstant, GoField, GoFile, GoMethodSignature, GoNamedType, GoStruct } from './goFileParser';
+import {
+    GoAssignStmt,
+    GoBlockStmt,
+    GoBody,
+    GoBodyParser,
+    GoCallExpr,
+    GoCaseClause,
+    GoComment,
+    GoCompositeLit,
+    GoDeclStmt,
+    GoDeferStmt,
+    GoExpr,
+    GoForStmt,
+    GoFuncLit,
+    GoGoStmt,
+    GoIdent,
+    GoIfStmt,
+    GoLabeledStmt,
+    GoRangeStmt,
+    GoReturnStmt,
+    GoSelectorExpr,
+    GoStarExpr,
+    GoStmt,
+    GoSwitchStmt,
+    GoSyntaxError,
+    GoTypeAssertExpr,
+    GoTypeExpr,
+    GoTypeSwitchStmt,
+    walkExprs,
+    walkStmts
+} from './goBodyParser';
+import { DEFAULT_JAVA_VERSION, JavaCodeGenerator, JavaGenerationOptions } from './javaGenerator';
+import { STDLIB_CALL_MAPPINGS, StdlibCallMapping, isStdlibImport, lookupStdlibType } from './conversionContext';
+import { goFloatValue, goIntegerValue, goRuneValue, javaCharLiteral, javaFloatLiteral, javaIntegerLiteral, javaStringLiteral } from './javaLiterals';
+
+/**
+ * A variable visible while translating a body
+ */
+interface LocalVariable {
+    javaName: string;
+    type?: GoType;
+    /** A named result of the function, returned by a bare `return` */
+    isResult?: boolean;
+    /** Captured by a closure and reassigned, so held in a one-element array */
+    boxed?: boolean;
+    /** Declared by a statement left as a TODO, so there is no Java local to refer to */
+    unconverted?: boolean;
+    /** The value recover() returned, which is the caught RuntimeException holding the panic's text */
+    recovered?: boolean;
+}
+
+/**
+ * `v, err := f()` where f throws in Java, with the `if err != nil` check that follows it
+ */
+interface ErrorCall {
+    assign: GoAssignStmt;
+    call: GoCallExpr;
+    callee: GoFunction;
+    /** Go name of the error variable; `_` when the error is discarded */
+    err: string;
+    /** Body of the `if err != nil` check */
+    handler?: GoBlockStmt;
+    /** `return ..., err` right after the call */
+    passOn?: GoReturnStmt;
+    /** Whether statements after the check read err, which then stays a local, nil once the call succeeded */
+    keepErr?: boolean;
+}
+
+/**
+ * A deferred closure calling recover(), run in a catch of the panic's exception
+ */
+interface RecoverHandler {
+    kind: 'Recover';
+    /** Go name the recovered value is bound to, if any */
+    name?: string;
+    /** Statements run once a panic was recovered */
+    stmts: GoStmt[];
+    /** What the function returns after recovering: its zero values when its results are
+     *  unnamed, nothing for the bare return of named results */
+    results: GoExpr[];
+}
+
+/**
+ * Rows of a test table: a slice literal of anonymous structs, `[]struct{ in string; want int }{...}`
+ */
+interface TestTable {
+    fields: GoParameter[];
+    /** Value of every field per row, in field order; undefined where a keyed row leaves one out */
+    rows: (GoExpr | undefined)[][];
+}
+
+/**
+ * A table-driven test: a table ranged over, running the statements for each row directly
+ * or as a subtest, `t.Run(tt.name, func(t *testing.T) { ... })`
+ */
+interface TableTest extends TestTable {
+    /** The row variable, whose fields the statements read */
+    row: string;
+    /** The *testing.T the statements use: the test's own or the subtest's */
+    t: GoParameter;
+    stmts: GoStmt[];
+    /** Source range of the statements, for their comments */
+    span: [number, number];
+    /** Field naming the subtest */
+    nameField?: number;
+}
+
+/**
+ * A table-driven test as the parts of a JUnit parameterized test
+ */
+export interface ParameterizedTest {
+    /** Java parameters of the test method, one per field of a row */
+    parameters: string[];
+    /** Java arguments for each row */
+    rows: string[][];
+    /** Index of the parameter naming each case, for the display name */
+    nameIndex?: number;
+    /** Indented Java lines of the method body */
+    body: string[];
+}
+
+/**
+ * A function body as the converter parsed it, with the Go types it resolved for its
+ * expressions while translating them
+ */
+export interface ResolvedBody {
+    function: GoFunction;
+    body: GoBody;
+    /** Go type of each expression whose type the converter could tell */
+    types: Map<GoExpr, GoType>;
+}
+
+/**
+ * A custom translation for statements and expressions of function bodies, consulted
+ * before the default translation of every node. Registered rewriters run in order and
+ * the first that handles a node wins; when none does, the node is translated as usual.
+ * A node may be offered more than once: statements the converter would translate together
+ * with their neighbours are offered on their own first, and that translation is dropped
+ * before the statement is translated for real. rewrite should therefore have no side
+ * effects and give the same result for the same node.
+ */
+export interface Rewriter {
+    /**
+     * Java for node: a statement's lines, as many as it needs, or one expression.
+     * An empty replacement of a handled statement drops the statement.
+     * @param context Default translations, for the parts of node the rewrite keeps
+     */
+    rewrite(node: GoStmt | GoExpr, context: RewriteContext): RewriteResult;
+}
+
+export interface RewriteResult {
+    replacement: string;
+    /** Whether replacement stands for the node; when false the next rewriter is asked */
+    handled: boolean;
+}
+
+/**
+ * What a rewriter can ask the converter while rewriting a node
+ */
+export interface RewriteContext {
+    /**
+     * Java for an expression, rewritten by the registered rewriters like any other;
+     * the node being rewritten itself gets its default translation
+     */
+    expr(e: GoExpr): string;
+    /** Go type of an expression, when the converter can tell */
+    typeOf(e: GoExpr): GoType | undefined;
+}
+
+/**
+ * Raised when an expression has no Java translation yet.
+ * The enclosing statement is emitted as a TODO comment instead.
+ */
+class UnsupportedConstructError extends Error {}
+
+const INDENT = '    ';
+
+/**
+ * Java operator precedence (higher binds tighter). Go groups operators
+ * differently (e.g. `&` binds like `*`), so binary expressions are
+ * parenthesised based on these levels.
+ */
+const JAVA_PRECEDENCE: { [op: string]: number } = {
+    '||': 3,
+    '&&': 4,
+    '|': 5,
+    '^': 6,
+    '&': 7, '&^': 7,
+    '==': 8, '!=': 8,
+    '<': 9, '<=': 9, '>': 9, '>=': 9,
+    '<<': 10, '>>': 10,
+    '+': 11, '-': 11,
+    '*': 12, '/': 12, '%': 12
+};
+const UNARY_PRECEDENCE = 13;
+const PRIMARY_PRECEDENCE = 14;
+/** Lambdas bind loosest, like assignments */
+const LAMBDA_PRECEDENCE = 1;
+
+/** Go fmt verbs that String.format spells differently; verbs absent from both tables are unsupported */
+const FORMAT_VERB_MAP: { [verb: string]: string } = { v: 's', t: 'b', F: 'f', w: 's' };
+const JAVA_FORMAT_VERBS = new Set(['s', 'd', 'f', 'e', 'E', 'g', 'G', 'x', 'X', 'o', 'c', '%']);
+/** %[argIndex] flags width .precision verb */
+const FORMAT_VERB = /%(\[\d+\])?([-+# 0]*)(\*|\d+)?(?:\.(\*|\d*))?([a-zA-Z%])/g;
+
+/** Java types a switch statement accepts as its selector (long is not one) */
+const SWITCHABLE_JAVA_TYPES = new Set(['byte', 'short', 'char', 'int', 'Byte', 'Short', 'Character', 'Integer', 'String']);
+
+/** Go integer types a Go 1.22 range loop counts up to */
+const INTEGER_RANGE_TYPES = new Set(['int', 'int8', 'int16', 'int32', 'int64', 'uint', 'uint8', 'uint16', 'uint32', 'uint64', 'uintptr', 'byte', 'rune']);
+
+/** Java primitives a Go numeric conversion can cast to */
+const JAVA_NUMERIC_TYPES = new Set(['byte', 'short', 'char', 'int', 'long', 'float', 'double']);
+
+/** Java primitives that arithmetic widens past, so a computed value needs a cast back */
+const NARROW_JAVA_TYPES = new Set(['byte', 'short', 'char', 'float']);
+
+/** Zero values that stay of their type when boxed, as Integer 0 is not a Long */
+const BOXABLE_ZERO: { [javaType: string]: string } = { long: '0L', float: '0.0f', byte: '(byte) 0', short: '(short) 0' };
+
+/** Memo of appendedFields, which parses every body of the file */
+const APPENDED_FIELDS = new WeakMap<GoFile, Set<string>>();
+
+/** Constraints whose type parameters Java bounds by Comparable<T> */
+const ORDERED_CONSTRAINTS = new Set(['cmp.Ordered', 'constraints.Ordered']);
+
+/** Builtin functions translated or reported by builtin(); len, append, make, panic and recover have their own */
+const BUILTIN_FUNCTIONS = new Set(['delete', 'clear', 'cap', 'min', 'max', 'println', 'print', 'new', 'copy', 'complex', 'real', 'imag']);
+
+const BUILTIN_TYPE_NAMES = new Set([
+    'int', 'int8', 'int16', 'int32', 'int64',
+    'uint', 'uint8', 'uint16', 'uint32', 'uint64', 'uintptr',
+    'float32', 'float64', 'complex64', 'complex128',
+    'bool', 'string', 'rune', 'byte', 'error', 'any'
+]);
+
+/** canThrow results per file, so the call graph of a file is analyzed once */
+const THROW_ANALYSIS = new WeakMap<GoFile, Map<GoFunction, boolean>>();
+
+/** blocksOnChannels results per file */
+const BLOCKING_ANALYSIS = new WeakMap<GoFile, Map<GoFunction, boolean>>();
+
+/** Stand-in function for translating package-level expressions */
+const PACKAGE_SCOPE: GoFunction = {
+    name: '',
+    parameters: [],
+    returnTypes: [],
+    isMethod: false,
+    hasErrorReturn: false
+};
+
+/**
+ * Translates Go function bodies into Java statements.
+ * Statements without a Java equivalent yet are kept as TODO comments
+ * showing the original Go code.
+ */
+export class JavaBodyGenerator {
+    /** Function whose returns are being translated: the declared function, or a function literal in it */
+    private goFunc: GoFunction;
+    /** The declared function the body belongs to */
+    private readonly enclosing: GoFunction;
+    private options: JavaGenerationOptions;
+    private goFile?: GoFile;
+    private source: string;
+    private scopes: Map<string, LocalVariable>[] = [];
+    private lines: string[] = [];
+    private depth = 1;
+    /** Locals some function literal captures although they are reassigned */
+    private reassignedCaptures = new Set<string>();
+    /** Locals assigned after their declaration, which Java lambdas cannot capture */
+    private reassigned = new Set<string>();
+    /** Locals grown with `s = append(s, ...)`, which start empty rather than null */
+    private appendTargets = new Set<string>();
+    /** Comments of the body in source order; those before nextComment have been placed */
+    private comments: GoComment[] = [];
+    private nextComment = 0;
+    /** Row variable of a table-driven test whose fields are parameters of the Java method */
+    private row?: string;
+    /** Local classes declared for the rows of test tables */
+    private localStructs: GoStruct[] = [];
+    /** Nodes a rewriter is rewriting, which translate the default way meanwhile */
+    private rewriting = new Set<GoStmt | GoExpr>();
+    /** Build slice and map literals as unmodifiable collections rather than growable copies */
+    private immutableLiterals = false;
+    /** Give every integer literal an `L`, for constant arithmetic assigned to a long */
+    private longLiterals = false;
+    /** List collecting the deferred calls of a function that defers inside a loop or block */
+    private deferList?: { name: string; callable: boolean };
+
+    private constructor(goFunc: GoFunction, options: JavaGenerationOptions, source: string, goFile?: GoFile) {
+        this.goFunc = goFunc;
+        this.enclosing = goFunc;
+        this.options = options;
+        this.source = source;
+        this.goFile = options.packageFile || goFile;
+    }
+
+    /**
+     * Translate the body of a Go function into indented Java lines.
+     * Returns undefined when the function was parsed without its body.
+     * @param goFunc The function whose body should be translated
+     * @param options Generation options of the enclosing method
+     * @param goFile Enclosing file, used to resolve functions, fields and constants
+     */
+    static generateBody(goFunc: GoFunction, options: JavaGenerationOptions, goFile?: GoFile): string[] | undefined {
+        if (goFunc.body === undefined) {
+            return undefined;
+        }
+
+        let body: GoBody;
+        try {
+            body = GoBodyParser.parseBody(goFunc.body, goFunc.bodyPosition);
+        } catch (error) {
+            if (error instanceof GoSyntaxError) {
+                options.diagnostics?.push({
+                    severity: 'unsupported',
+                    construct: 'FunctionBody',
+                    message: `Could not parse Go function body (${error.message})`,
+                    pos: error.pos,
+                    scope: JavaCodeGenerator.scopeName(goFunc)
+                });
+                return [`${INDENT}// TODO: Could not parse Go function body (${error.message})`];
+            }
+            throw error;
+        }
+
+        if (options.resolvedBodies) {
+            // A rewriter that handles nothing sees every expression, with its type in scope
+            const types = new Map<GoExpr, GoType>();
+            const recorder: Rewriter = {
+                rewrite: (node, context) => {
+                    const type = node.kind.endsWith('Stmt') ? undefined : context.typeOf(node as GoExpr);
+                    if (type) {
+                        types.set(node as GoExpr, type);
+                    }
+                    return { replacement: '', handled: false };
+                }
+            };
+            options.resolvedBodies.push({ function: goFunc, body, types });
+            options = { ...options, rewriters: [...(options.rewriters || []), recorder] };
+        }
+        const generator = new JavaBodyGenerator(goFunc, options, body.source, goFile);
+        generator.comments = body.comments;
+        return generator.generate(body.stmts);
+    }
+
+    /**
+     * Translate a table-driven test into the parts of a JUnit parameterized test: the fields of
+     * a row become the test's parameters (`tt.want` → `want`) and each row their arguments.
+     * Returns undefined when the body is not just the table and the loop over it, or a row does
+     * not translate; the test then keeps the loop over a local type for the rows.
+     * @param goFunc A test function, `TestXxx(t *testing.T)`
+     */
+    static generateParameterizedTest(goFunc: GoFunction, options: JavaGenerationOptions, goFile?: GoFile): ParameterizedTest | undefined {
+        if (goFunc.body === undefined) {
+            return undefined;
+        }
+        let body: GoBody;
+        try {
+            body = GoBodyParser.parseBody(goFunc.body, goFunc.bodyPosition);
+        } catch (error) {
+            if (error instanceof GoSyntaxError) {
+                return undefined;
+            }
+            throw error;
+        }
+        const table = this.matchTableTest(body.stmts, goFunc.parameters[0]);
+        if (!table) {
+            return undefined;
+        }
+
+        const generator = new JavaBodyGenerator({ ...goFunc, parameters: [table.t, ...table.fields] }, options, body.source, goFile);
+        generator.row = table.row;
+        generator.comments = body.comments.filter(c => c.offset >= table.span[0] && c.offset < table.span[1]);
+        generator.scopes.push(new Map());
+        let rows: string[][];
+        try {
+            rows = table.rows.map(row => row.map((value, i) => generator.fieldValue(value, table.fields[i].type)));
+        } catch (error) {
+            if (error instanceof UnsupportedConstructError) {
+                return undefined;
+            }
+            throw error;
+        }
+        generator.scopes.pop();
+        return {
+            parameters: table.fields.map(f => `${generator.javaType(f.type)} ${generator.toJavaLocalName(f.name)}`),
+            rows,
+            nameIndex: table.nameField,
+            body: generator.generate(table.stmts)
+        };
+    }
+
+    /**
+     * Recognize a test body that is only a table and a range loop over its rows:
+     * `tests := []struct{...}{...}; for _, tt := range tests { ... }`, or the literal ranged
+     * over in place. The loop may only read fields of the row, and the rows must translate
+     * apart from the loop, so the statements can run once per row as a method of their own.
+     */
+    private static matchTableTest(stmts: GoStmt[], t: GoParameter): TableTest | undefined {
+        const loop = stmts[stmts.length - 1];
+        if (stmts.length > 2 || loop?.kind !== 'RangeStmt' || loop.tok !== ':=' || loop.value?.kind !== 'Ident'
+            || !(loop.key?.kind === 'Ident' && loop.key.name === '_')) {
+            return undefined;
+        }
+        let literal = loop.x;
+        let tableName: string | undefined;
+        if (stmts.length === 2) {
+            const decl = stmts[0];
+            if (decl.kind !== 'AssignStmt' || decl.tok !== ':=' || decl.lhs.length !== 1 || decl.rhs.length !== 1
+                || decl.lhs[0].kind !== 'Ident' || loop.x.kind !== 'Ident' || loop.x.name !== decl.lhs[0].name) {
+                return undefined;
+            }
+            literal = decl.rhs[0];
+            tableName = decl.lhs[0].name;
+        }
+        const table = this.testTable(literal);
+        if (!table) {
+            return undefined;
+        }
+
+        const row = loop.value.name;
+        let test: TableTest = { ...table, row, t, stmts: loop.body.stmts, span: loop.body.span };
+        const only = loop.body.stmts.length === 1 ? loop.body.stmts[0] : undefined;
+        const subtest = only?.kind === 'ExprStmt' ? this.subtest(only.x, t.name) : undefined;
+        if (subtest) {
+            const name = subtest.name;
+            const nameField = name.kind === 'Selector' && name.x.kind === 'Ident' && name.x.name === row
+                ? table.fields.findIndex(f => f.name === name.sel) : -1;
+            test = {
+                ...test,
+                t: subtest.fn.type.params![0],
+                stmts: subtest.fn.body.stmts,
+                span: subtest.fn.body.span,
+                nameField: nameField >= 0 ? nameField : undefined
+            };
+        }
+
+        // Each field becomes a parameter, which must not collide with a local
+        const javaNames = test.fields.map(f => f.name.charAt(0).toLowerCase() + f.name.slice(1));
+        const declared = this.declaredNames(test.stmts);
+        if (new Set(javaNames).size !== javaNames.length
+            || javaNames.some(name => name === test.t.name || declared.has(name))) {
+            return undefined;
+        }
+        let rowReads = 0;
+        let fieldReads = 0;
+        let outsideReads = 0;
+        walkExprs(test.stmts, e => {
+            if (e.kind === 'Ident') {
+                rowReads += e.name === row ? 1 : 0;
+                outsideReads += e.name === tableName || (e.name === t.name && test.t.name !== t.name) ? 1 : 0;
+            } else if (e.kind === 'Selector' && e.x.kind === 'Ident' && e.x.name === row && table.fields.some(f => f.name === e.sel)) {
+                fieldReads++;
+            }
+        });
+        return rowReads === fieldReads && outsideReads === 0 && !declared.has(row) ? test : undefined;
+    }
+
+    /**
+     * The fields and rows of a test table literal; undefined for any other expression,
+     * or rows mixing positional and keyed fields
+     */
+    private static testTable(e: GoExpr): TestTable | undefined {
+        const fields = e.kind === 'CompositeLit' && e.type?.typeKind === 'slice' && e.type.elem?.typeKind === 'struct'
+            ? e.type.elem.fields : undefined;
+        if (!fields?.length || e.kind !== 'CompositeLit' || fields.some(f => f.name === '_')) {
+            return undefined;
+        }
+        const rows: (GoExpr | undefined)[][] = [];
+        for (const elt of e.elts) {
+            if (elt.kind !== 'CompositeLit' || (elt.type && elt.type.typeKind !== 'struct')) {
+                return undefined;
+            }
+            if (elt.elts.every(v => v.kind !== 'KeyValue') && elt.elts.length === fields.length) {
+                rows.push(elt.elts);
+                continue;
+            }
+            const values = new Map<string, GoExpr>();
+            for (const v of elt.elts) {
+                if (v.kind !== 'KeyValue' || v.key.kind !== 'Ident' || !fields.some(f => f.name === (v.key as GoIdent).name)) {
+                    return undefined;
+                }
+                values.set(v.key.name, v.value);
+            }
+            rows.push(fields.map(f => values.get(f.name)));
+        }
+        return { fields, rows };
+    }
+
+    /**
+     * `t.Run(name, func(t *testing.T) { ... })` on the given *testing.T
+     */
+    private static subtest(e: GoExpr, t: string): { name: GoExpr; fn: GoFuncLit } | undefined {
+        if (e.kind !== 'Call' || e.fun.kind !== 'Selector' || e.fun.sel !== 'Run' || e.fun.x.kind !== 'Ident'
+            || e.fun.x.name !== t || e.args.length !== 2 || e.args[1].kind !== 'FuncLit') {
+            return undefined;
+        }
+        const fn = e.args[1];
+        const params = fn.type.params || [];
+        return params.length === 1 && JavaCodeGenerator.isTestingT(params[0].type) && !fn.type.results?.length
+            ? { name: e.args[0], fn }
+            : undefined;
+    }
+
+    /**
+     * Java for the zero value of a struct type held by value (`new Bag(null, 0)`), for fields
+     * and package variables; undefined for other types or a struct whose literal does not convert
+     */
+    static structZeroValue(type: GoType, options: JavaGenerationOptions, goFile?: GoFile): string | undefined {
+        const generator = new JavaBodyGenerator(PACKAGE_SCOPE, options, '', goFile);
+        generator.scopes.push(new Map());
+        try {
+            return generator.structZero(type);
+        } catch (error) {
+            if (error instanceof UnsupportedConstructError) {
+                return undefined;
+            }
+            throw error;
+        }
+    }
+
+    /**
+     * Translate a standalone Go expression such as a package-level initializer.
+     * Returns undefined when the expression has no Java translation yet.
+     * @param text Go source of the expression
+     * @param options Generation options
+     * @param goFile Enclosing file, used to resolve constants and functions
+     * @param declared Type the expression is assigned to, which Go constants take
+     */
+    static translateExpression(
+        text: string,
+        options: JavaGenerationOptions,
+        goFile?: GoFile,
+        immutable = false,
+        declared?: GoType
+    ): { code: string, type?: GoType } | undefined {
+        let expr: GoExpr;
+        try {
+            expr = GoBodyParser.parseExpression(text);
+        } catch (error) {
+            if (error instanceof GoSyntaxError) {
+                return undefined;
+            }
+            throw error;
+        }
+
+        const generator = new JavaBodyGenerator(PACKAGE_SCOPE, options, text, goFile);
+        generator.scopes.push(new Map());
+        generator.immutableLiterals = immutable;
+        try {
+            return { code: generator.constantAs(expr, declared), type: generator.typeOf(expr) };
+        } catch (error) {
+            if (error instanceof UnsupportedConstructError) {
+                return undefined;
+            }
+            throw error;
+        }
+    }
+
+    /**
+     * The map type and Java keys and values of a Go map literal, for a package variable
+     * filled in a static initializer block. Undefined for any other expression, or when
+     * an entry does not translate.
+     */
+    static translateMapEntries(text: string, options: JavaGenerationOptions, goFile?: GoFile): { type: GoType; entries: [string, string][] } | undefined {
+        let expr: GoExpr;
+        try {
+            expr = GoBodyParser.parseExpression(text);
+        } catch (error) {
+            if (error instanceof GoSyntaxError) {
+                return undefined;
+            }
+            throw error;
+        }
+        const type = expr.kind === 'CompositeLit' ? expr.type?.type : undefined;
+        if (expr.kind !== 'CompositeLit' || !type) {
+            return undefined;
+        }
+        const generator = new JavaBodyGenerator(PACKAGE_SCOPE, options, text, goFile);
+        generator.scopes.push(new Map());
+        const map = generator.underlying(type);
+        if (!map.isMap) {
+            return undefined;
+        }
+        try {
+            return { type, entries: generator.mapEntries(expr, map) };
+        } catch (error) {
+            if (error instanceof UnsupportedConstructError) {
+                return undefined;
+            }
+            throw error;
+        }
+    }
+
+    /**
+     * Whether a function or method of goFile assigns the package variable name (or a local of
+     * the same name, which is not told apart). Functions whose body does not parse count as assigning it.
+     */
+    static assignsPackageVariable(name: string, goFile: GoFile): boolean {
+        const functions = [
+            ...goFile.functions,
+            ...goFile.structs.flatMap(s => s.methods),
+            ...goFile.namedTypes.flatMap(t => t.methods)
+        ];
+        return functions.some(goFunc => {
+            if (goFunc.body === undefined) {
+                return false;
+            }
+            let stmts: GoStmt[];
+            try {
+                stmts = GoBodyParser.parseBody(goFunc.body).stmts;
+            } catch (error) {
+                return true;
+            }
+            let assigns = false;
+            walkStmts(stmts, stmt => {
+                assigns = assigns || (stmt.kind === 'AssignStmt' && stmt.tok !== ':='
+                    && stmt.lhs.some(target => target.kind === 'Ident' && target.name === name));
+            });
+            return assigns;
+        });
+    }
+
+    /**
+     * Names of the struct fields the functions of goFile grow in place, `x.F = append(x.F, ...)`.
+     * Java cannot add to a null list, so these start empty. Fields of different structs
+     * sharing a name are not told apart.
+     */
+    static appendedFields(goFile: GoFile): Set<string> {
+        const cached = APPENDED_FIELDS.get(goFile);
+        if (cached) {
+            return cached;
+        }
+        const fields = new Set<string>();
+        const functions = [
+            ...goFile.functions,
+            ...goFile.structs.flatMap(s => s.methods),
+            ...goFile.namedTypes.flatMap(t => t.methods)
+        ];
+        for (const goFunc of functions) {
+            let stmts: GoStmt[];
+            try {
+                stmts = goFunc.body === undefined ? [] : GoBodyParser.parseBody(goFunc.body).stmts;
+            } catch (error) {
+                continue;
+            }
+            walkStmts(stmts, stmt => {
+                if (stmt.kind !== 'AssignStmt' || stmt.tok !== '=' || stmt.lhs.length !== 1 || stmt.rhs.length !== 1) {
+                    return;
+                }
+                const [target, value] = [stmt.lhs[0], stmt.rhs[0]];
+                if (target.kind === 'Selector' && target.x.kind === 'Ident' && value.kind === 'Call'
+                    && value.fun.kind === 'Ident' && value.fun.name === 'append') {
+                    const grown = value.args[0];
+                    if (grown?.kind === 'Selector' && grown.sel === target.sel && grown.x.kind === 'Ident' && grown.x.name === target.x.name) {
+                        fields.add(target.sel);
+                    }
+                }
+            });
+        }
+        APPENDED_FIELDS.set(goFile, fields);
+        return fields;
+    }
+
+    /**
+     * Whether the functions of goFile may change the slice, map or struct held by a package
+     * variable: they assign to it or its elements, or hand it anywhere but to an index, a
+     * range loop, a field read, len or cap, or a standard library call that does not sort
+     * or modify its arguments. Functions whose body does not parse count as changing it.
+     */
+    static modifiesPackageVariable(name: string, goFile: GoFile): boolean {
+        const functions = [
+            ...goFile.functions,
+            ...goFile.structs.flatMap(s => s.methods),
+            ...goFile.namedTypes.flatMap(t => t.methods)
+        ];
+        const packages = new Map(goFile.imports.map(imp => [imp.alias || imp.path.split('/').pop()!, imp.path]));
+        const root = (e: GoExpr): GoExpr => e.kind === 'Index' || e.kind === 'Selector' || e.kind === 'Paren' || e.kind === 'Star'
+            ? root(e.x) : e;
+        return functions.some(goFunc => {
+            if (goFunc.body === undefined) {
+                return false;
+            }
+            let stmts: GoStmt[];
+            try {
+                stmts = GoBodyParser.parseBody(goFunc.body).stmts;
+            } catch (error) {
+                return true;
+            }
+            const reads = new Set<GoExpr>();
+            const targets = new Set<GoExpr>();
+            const calledFuns = new Set<GoExpr>();
+            walkStmts(stmts, stmt => {
+                if (stmt.kind === 'AssignStmt' && stmt.tok !== ':=') {
+                    stmt.lhs.forEach(target => targets.add(root(target)));
+                } else if (stmt.kind === 'IncDecStmt') {
+                    targets.add(root(stmt.x));
+                } else if (stmt.kind === 'RangeStmt') {
+                    reads.add(stmt.x);
+                }
+            });
+            const uses: GoExpr[] = [];
+            walkExprs(stmts, e => {
+                switch (e.kind) {
+                    case 'Ident':
+                        if (e.name === name) {
+                            uses.push(e);
+                        }
+                        break;
+                    case 'Index':
+                        reads.add(e.x);
+                        break;
+                    case 'Selector':
+                        if (!calledFuns.has(e)) {
+                            reads.add(e.x);
+                        }
+                        break;
+                    case 'Call': {
+                        calledFuns.add(e.fun);
+                        const fun = e.fun;
+                        const builtin = fun.kind === 'Ident' && (fun.name === 'len' || fun.name === 'cap');
+                        const stdlib = fun.kind === 'Selector' && fun.x.kind === 'Ident' && packages.has(fun.x.name)
+                            && !['sort', 'slices', 'maps'].includes(packages.get(fun.x.name)!);
+                        if (builtin || stdlib) {
+                            e.args.forEach(arg => reads.add(arg));
+                        }
+                        break;
+                    }
+                }
+            });
+            return uses.some(use => targets.has(use) || !reads.has(use));
+        });
+    }
+
+    /**
+     * Whether a value-receiver method assigns to fields of its receiver copy.
+     * Such mutations are visible to the caller in Java but not in Go, except in the
+     * methods that work on a copy of this (copiesValueReceiver).
+     */
+    static mutatesValueReceiver(goFunc: GoFunction): boolean {
+        return !!goFunc.receiver && !goFunc.receiver.type.isPointer && this.assignsToReceiver(goFunc)
+            && !this.copiesValueReceiver(goFunc);
+    }
+
+    /**
+     * The string each constant of an enum prints as, when the Go String() method only looks
+     * it up: a switch returning a string literal per constant (`switch d { case Sunday:
+     * return "Sunday" }`), or an index into a string slice, array or map literal written in
+     * place or held by a package variable (`return names[d]`), after any guards for values
+     * out of range. The strings are Go literals; undefined when the method does anything
+     * else or leaves a constant out.
+     * @param members Constants of the enum, in iota order
+     * @param goFile Declarations to search, normally the whole package
+     */
+    static enumDisplayNames(stringer: GoFunction, members: GoConstant[], goFile?: GoFile): Map<GoConstant, string> | undefined {
+        const receiver = stringer.receiver?.name;
+        if (!receiver || stringer.body === undefined) {
+            return undefined;
+        }
+        let stmts: GoStmt[];
+        try {
+            stmts = GoBodyParser.parseBody(stringer.body).stmts;
+        } catch (error) {
+            return undefined;
+        }
+        // d, (d) or a conversion such as int(d)
+        const isReceiver = (e: GoExpr): boolean => e.kind === 'Paren' ? isReceiver(e.x)
+            : e.kind === 'Call' && e.args.length === 1 && e.fun.kind === 'Ident' && GoFunctionParser.isBuiltinType(e.fun.name) ? isReceiver(e.args[0])
+            : e.kind === 'Ident' && e.name === receiver;
+        const literal = (e?: GoExpr) => e?.kind === 'BasicLit' && e.litKind === 'STRING' ? e.value : undefined;
+        const member = (e: GoExpr) => e.kind === 'Ident' ? members.find(m => m.name === e.name) : undefined;
+        const names = new Map<GoConstant, string>();
+
+        const [first] = stmts;
+        if (first?.kind === 'SwitchStmt' && !first.init && first.tag && isReceiver(first.tag)) {
+            for (const clause of first.cases) {
+                const [result] = clause.body;
+                const name = clause.body.length === 1 && result.kind === 'ReturnStmt' && result.results.length === 1 ? literal(result.results[0]) : undefined;
+                for (const constant of clause.list.map(member)) {
+                    if (!constant || name === undefined) {
+                        return undefined;
+                    }
+                    names.set(constant, name);
+                }
+            }
+        } else {
+            const last = stmts[stmts.length - 1];
+            const guards = stmts.slice(0, -1).every(s => s.kind === 'IfStmt' && !s.else && s.body.stmts[s.body.stmts.length - 1]?.kind === 'ReturnStmt');
+            if (!guards || last?.kind !== 'ReturnStmt' || last.results.length !== 1) {
+                return undefined;
+            }
+            const lookup = last.results[0];
+            if (lookup.kind !== 'Index' || !isReceiver(lookup.index)) {
+                return undefined;
+            }
+            let table = lookup.x;
+            if (table.kind === 'Ident') {
+                const name = table.name;
+                const variable = goFile?.variables.find(v => v.name === name);
+                try {
+                    table = variable?.value ? GoBodyParser.parseExpression(variable.value) : table;
+                } catch (error) {
+                    return undefined;
+                }
+            }
+            if (table.kind !== 'CompositeLit') {
+                return undefined;
+            }
+            // Positional tables may go on past the constants, keyed ones name them
+            const keyed = table.elts.some(e => e.kind === 'KeyValue');
+            for (const [i, element] of table.elts.slice(0, keyed ? undefined : members.length).entries()) {
+                const constant = element.kind === 'KeyValue' ? member(element.key) : keyed ? undefined : members[i];
+                const name = literal(element.kind === 'KeyValue' ? element.value : element);
+                if (!constant || name === undefined) {
+                    return undefined;
+                }
+                names.set(constant, name);
+            }
+        }
+        return members.every(m => names.has(m)) ? names : undefined;
+    }
+
+    /**
+     * Whether a value-receiver method changes fields of its receiver and returns it, the
+     * fluent `func (c Config) WithPort(p int) Config { c.Port = p; return c }`. The Java
+     * method starts from a copy of this, so that like in Go the caller's value stays as it was.
+     */
+    static copiesValueReceiver(goFunc: GoFunction): boolean {
+        const receiver = goFunc.receiver;
+        if (!receiver?.name || receiver.type.isPointer || goFunc.body === undefined || !this.assignsToReceiver(goFunc)) {
+            return false;
+        }
+        let stmts: GoStmt[];
+        try {
+            stmts = GoBodyParser.parseBody(goFunc.body).stmts;
+        } catch (error) {
+            return false;
+        }
+        if (this.assignedNames(stmts).has(receiver.name)) {
+            // A receiver the body reassigns is a local already
+            return false;
+        }
+        let returned = false;
+        walkStmts(stmts, s => {
+            returned = returned || (s.kind === 'ReturnStmt' && s.results.some(r => r.kind === 'Ident' && r.name === receiver.name));
+        });
+        return returned;
+    }
+
+    /**
+     * Whether the Java method for goFunc can throw: some return hands back a non-nil error,
+     * either built in place or obtained from a call that can throw. Calls are followed
+     * through the functions and methods of goFile; anything else is assumed to fail,
+     * as are functions parsed without a body.
+     * @param goFile Declarations to follow calls into, normally the whole package
+     */
+    static canThrow(goFunc: GoFunction, goFile?: GoFile): boolean {
+        if (!goFunc.hasErrorReturn) {
+            return false;
+        }
+        const cache = goFile ? THROW_ANALYSIS.get(goFile) || new Map<GoFunction, boolean>() : new Map<GoFunction, boolean>();
+        if (goFile) {
+            THROW_ANALYSIS.set(goFile, cache);
+        }
+        return this.analyzeThrows(goFunc, goFile, cache);
+    }
+
+    private static analyzeThrows(goFunc: GoFunction, goFile: GoFile | undefined, cache: Map<GoFunction, boolean>): boolean {
+        const known = cache.get(goFunc);
+        if (known !== undefined) {
+            return known;
+        }
+        if (goFunc.body === undefined) {
+            return true;
+        }
+        let stmts: GoStmt[];
+        try {
+            stmts = GoBodyParser.parseBody(goFunc.body).stmts;
+        } catch (error) {
+            return true;
+        }
+        // A recursive call contributes nothing beyond the function's other returns
+        cache.set(goFunc, false);
+
+        const calleeThrows = (call: GoCallExpr): boolean => {
+            const callee = this.resolveStatically(call.fun, goFunc, goFile);
+            return !callee || this.analyzeThrows(callee, goFile, cache);
+        };
+
+        // Where each local error variable gets its value
+        const assignments = new Map<string, GoExpr[]>();
+        const record = (names: GoExpr[], values: GoExpr[]) => {
+            names.forEach((name, i) => {
+                if (name.kind !== 'Ident' || name.name === '_') {
+                    return;
+                }
+                const sources = assignments.get(name.name) || [];
+                // `a, err := f()` takes err from f's results
+                sources.push(values.length === names.length ? values[i] : values[0]);
+                assignments.set(name.name, sources);
+            });
+        };
+        // Named results start out as nil
+        (goFunc.returnNames || []).forEach(name => record([{ kind: 'Ident', name, pos: { line: 0, character: 0 } }],
+            [{ kind: 'Ident', name: 'nil', pos: { line: 0, character: 0 } }]));
+        walkStmts(stmts, stmt => {
+            if (stmt.kind === 'AssignStmt' && (stmt.tok === ':=' || stmt.tok === '=')) {
+                record(stmt.lhs, stmt.rhs);
+            } else if (stmt.kind === 'DeclStmt') {
+                stmt.specs.forEach(spec => record(
+                    spec.names.map((name): GoExpr => ({ kind: 'Ident', name, pos: stmt.pos })),
+                    spec.values.length > 0 ? spec.values : spec.names.map((): GoExpr => ({ kind: 'Ident', name: 'nil', pos: stmt.pos }))));
+            }
+        });
+
+        const seen = new Set<string>();
+        const errorValueThrows = (e: GoExpr): boolean => {
+            if (e.kind === 'Paren') {
+                return errorValueThrows(e.x);
+            }
+            if (e.kind === 'Ident') {
+                if (e.name === 'nil') {
+                    return false;
+                }
+                const sources = assignments.get(e.name);
+                if (!sources || goFunc.parameters.some(p => p.name === e.name)) {
+                    // Parameters and package-level errors are non-nil as far as we know
+                    return true;
+                }
+                if (seen.has(e.name)) {
+                    return false;
+                }
+                seen.add(e.name);
+                return sources.some(errorValueThrows);
+            }
+            if (e.kind === 'Call' && !this.buildsError(e)) {
+                return calleeThrows(e);
+            }
+            return true;
+        };
+
+        const arity = goFunc.returnTypes.length;
+        let throws = false;
+        walkStmts(stmts, stmt => {
+            if (throws || stmt.kind !== 'ReturnStmt') {
+                return;
+            }
+            const results = this.resultValues(stmt, goFunc);
+            if (results.length === arity) {
+                throws = errorValueThrows(results[arity - 1]);
+            } else if (stmt.results.length === 1 && stmt.results[0].kind === 'Call') {
+                // return f(...) passes on all of f's results
+                throws = calleeThrows(stmt.results[0]);
+            }
+        });
+        // A deferred closure assigning the named error result (e.g. after recover()) replaces the returned one
+        const errorResult = goFunc.returnNames?.[arity - 1];
+        walkStmts(stmts, stmt => {
+            if (throws || !errorResult || stmt.kind !== 'DeferStmt' || stmt.call.kind !== 'Call' || stmt.call.fun.kind !== 'FuncLit') {
+                return;
+            }
+            walkStmts(stmt.call.fun.body.stmts, s => {
+                if (!throws && s.kind === 'AssignStmt' && s.tok === '=') {
+                    const i = s.lhs.findIndex(l => l.kind === 'Ident' && l.name === errorResult);
+                    throws = i >= 0 && errorValueThrows(s.rhs.length === s.lhs.length ? s.rhs[i] : s.rhs[0]);
+                }
+            });
+        });
+        cache.set(goFunc, throws);
+        return throws;
+    }
+
+    /**
+     * Values a return statement returns: a bare `return` returns the named results
+     */
+    private static resultValues(ret: GoReturnStmt, goFunc: GoFunction): GoExpr[] {
+        if (ret.results.length > 0 || !goFunc.returnNames) {
+            return ret.results;
+        }
+        return goFunc.returnNames.map((name): GoExpr => ({ kind: 'Ident', name, pos: ret.pos }));
+    }
+
+    /**
+     * errors.New(...) or fmt.Errorf(...), unless a local shadows the package
+     */
+    private static buildsError(call: GoCallExpr): boolean {
+        return call.fun.kind === 'Selector' && call.fun.x.kind === 'Ident'
+            && ((call.fun.x.name === 'errors' && call.fun.sel === 'New') || (call.fun.x.name === 'fmt' && call.fun.sel === 'Errorf'));
+    }
+
+    /**
+     * Function or method of goFile a call refers to, without type information:
+     * methods on the receiver resolve exactly, other selectors only when the method name is unambiguous
+     */
+    private static resolveStatically(fun: GoExpr, caller: GoFunction, goFile?: GoFile): GoFunction | undefined {
+        if (fun.kind === 'Ident') {
+            return goFile?.functions.find(f => f.name === fun.name && !f.isMethod);
+        }
+        if (fun.kind !== 'Selector') {
+            return undefined;
+        }
+        if (fun.x.kind === 'Ident' && fun.x.name === caller.receiver?.name) {
+            const receiverType = caller.receiver.type.name.replace(/^\*/, '');
+            const own = goFile?.structs.find(st => st.name === receiverType)?.methods.find(m => m.name === fun.sel)
+                || (caller.name === fun.sel ? caller : undefined);
+            if (own) {
+                return own;
+            }
+            // Otherwise promoted from an embedded struct
+        }
+        const candidates = (goFile?.structs || []).flatMap(st => st.methods.filter(m => m.name === fun.sel));
+        return candidates.length === 1 ? candidates[0] : undefined;
+    }
+
+    /**
+     * Whether a function starts goroutines, which run on the executor of its class
+     */
+    static startsGoroutines(goFunc: GoFunction): boolean {
+        if (goFunc.body === undefined) {
+            return false;
+        }
+        let stmts: GoStmt[];
+        try {
+            stmts = GoBodyParser.parseBody(goFunc.body).stmts;
+        } catch (error) {
+            return false;
+        }
+        let starts = false;
+        walkStmts(stmts, s => {
+            starts = starts || s.kind === 'GoStmt';
+        });
+        return starts;
+    }
+
+    /**
+     * Whether the Java method for goFunc waits on a channel: it sends, receives or ranges over
+     * one, itself or in a function or method of goFile it calls. BlockingQueue's put and
+     * take throw InterruptedException, which such a method declares. Goroutines it starts
+     * wait in tasks of their own and do not count.
+     * @param goFile Declarations to follow calls into, normally the whole package
+     */
+    static blocksOnChannels(goFunc: GoFunction, goFile?: GoFile): boolean {
+        return this.analyzeBlocking(goFunc, goFile, this.blockingAnalysis(goFile));
+    }
+
+    /**
+     * Results of blocksOnChannels so far, per file
+     */
+    private static blockingAnalysis(goFile?: GoFile): Map<GoFunction, boolean> {
+        const cache = goFile ? BLOCKING_ANALYSIS.get(goFile) || new Map<GoFunction, boolean>() : new Map<GoFunction, boolean>();
+        if (goFile) {
+            BLOCKING_ANALYSIS.set(goFile, cache);
+        }
+        return cache;
+    }
+
+    private static analyzeBlocking(goFunc: GoFunction, goFile: GoFile | undefined, cache: Map<GoFunction, boolean>): boolean {
+        const known = cache.get(goFunc);
+        if (known !== undefined) {
+            return known;
+        }
+        if (goFunc.body === undefined) {
+            return false;
+        }
+        let stmts: GoStmt[];
+        try {
+            stmts = GoBodyParser.parseBody(goFunc.body).stmts;
+        } catch (error) {
+            return false;
+        }
+        // A recursive call contributes nothing beyond the function's other statements
+        cache.set(goFunc, false);
+        const blocks = this.blocksIn(stmts, goFunc, goFile, cache);
+        cache.set(goFunc, blocks);
+        return blocks;
+    }
+
+    /**
+     * Whether statements of caller wait on a channel, outside the goroutines they start
+     */
+    private static blocksIn(stmts: GoStmt[], caller: GoFunction, goFile: GoFile | undefined, cache: Map<GoFunction, boolean>): boolean {
+        // Channels a range may loop over: parameters, locals made as channels and receiver fields
+        const channels = new Set(caller.parameters.filter(p => GoFunctionParser.channelElement(p.type)).map(p => p.name));
+        const fields = goFile?.structs.find(st => st.name === caller.receiver?.type.name.replace(/^\*/, ''))?.fields || [];
+        const started = new Set<GoExpr>();
+        let blocks = false;
+        walkStmts(stmts, s => {
+            if (s.kind === 'GoStmt') {
+                started.add(s.call);
+            } else if (s.kind === 'SendStmt') {
+                blocks = true;
+            } else if (s.kind === 'AssignStmt' && s.tok === ':=') {
+                s.rhs.forEach((value, i) => {
+                    const target = s.lhs[i];
+                    if (target?.kind === 'Ident' && value.kind === 'Call' && value.fun.kind === 'Ident' && value.fun.name === 'make'
+                        && value.args[0]?.kind === 'TypeExpr' && value.args[0].typeKind === 'chan') {
+                        channels.add(target.name);
+                    }
+                });
+            } else if (s.kind === 'DeclStmt') {
+                s.specs.filter(spec => spec.type?.typeKind === 'chan').forEach(spec => spec.names.forEach(name => channels.add(name)));
+            }
+        });
+        walkStmts(stmts, s => {
+            if (s.kind !== 'RangeStmt') {
+                return;
+            }
+            const field = s.x.kind === 'Selector' && s.x.x.kind === 'Ident' && s.x.x.name === caller.receiver?.name
+                ? fields.find(f => f.name === (s.x as GoSelectorExpr).sel) : undefined;
+            blocks = blocks || (s.x.kind === 'Ident' && channels.has(s.x.name)) || (!!field && !!GoFunctionParser.channelElement(field.type));
+        });
+
+        // What the goroutines run is left out
+        const inGoroutines = new Set<GoExpr>();
+        walkExprs([...started].map((call): GoStmt => ({ kind: 'ExprStmt', x: call, pos: call.pos, span: [0, 0] })), e => inGoroutines.add(e));
+        walkExprs(stmts, e => {
+            if (blocks || inGoroutines.has(e)) {
+                return;
+            }
+            if (e.kind === 'Unary' && e.op === '<-') {
+                blocks = true;
+            } else if (e.kind === 'Call') {
+                const callee = this.resolveStatically(e.fun, caller, goFile);
+                blocks = !!callee && this.analyzeBlocking(callee, goFile, cache);
+            }
+        });
+        return blocks;
+    }
+
+    /**
+     * Whether a method assigns to its receiver or the receiver's fields
+     */
+    static assignsToReceiver(goFunc: GoFunction): boolean {
+        const receiver = goFunc.receiver;
+        if (!receiver || !receiver.name || goFunc.body === undefined) {
+            return false;
+        }
+
+        let stmts: GoStmt[];
+        try {
+            stmts = GoBodyParser.parseBody(goFunc.body).stmts;
+        } catch (error) {
+            return false;
+        }
+
+        // u.Field = ... (or u = ...) writes to the copy; u.items[i] = ... writes through a shared slice/map
+        const writesCopy = (target: GoExpr): boolean => {
+            switch (target.kind) {
+                case 'Ident':
+                    return target.name === receiver.name;
+                case 'Selector':
+                case 'Paren':
+                    return writesCopy(target.x);
+                default:
+                    return false;
+            }
+        };
+
+        let mutates = false;
+        walkStmts(stmts, stmt => {
+            if (stmt.kind === 'AssignStmt' && stmt.tok !== ':=') {
+                mutates = mutates || stmt.lhs.some(writesCopy);
+            } else if (stmt.kind === 'IncDecStmt') {
+                mutates = mutates || writesCopy(stmt.x);
+            }
+        });
+        return mutates;
+    }
+
+    private generate(stmts: GoStmt[]): string[] {
+        const scope = new Map<string, LocalVariable>();
+        const receiver = this.goFunc.receiver;
+        // Java cannot assign to this, so a receiver the body reassigns starts as a local copy of it
+        const receiverCopy = !!receiver?.name && JavaBodyGenerator.assignedNames(stmts).has(receiver.name);
+        if (receiver && receiver.name) {
+            scope.set(receiver.name, {
+                javaName: receiverCopy ? this.toJavaLocalName(receiver.name) : 'this',
+                type: receiver.type
+            });
+        }
+        for (const param of this.goFunc.parameters) {
+            if (param.name && param.name !== '_') {
+                scope.set(param.name, { javaName: this.toJavaLocalName(param.name), type: param.type });
+            }
+        }
+        this.scopes.push(scope);
+        if (receiver && receiverCopy) {
+            this.emit(`${this.javaType(receiver.type)} ${scope.get(receiver.name)!.javaName} = this;`);
+        } else if (receiver && JavaBodyGenerator.copiesValueReceiver(this.goFunc)) {
+            this.emitReceiverCopy(receiver, scope.get(receiver.name)!);
+        }
+        this.reassignedCaptures = JavaBodyGenerator.reassignedCaptures(stmts);
+        this.reassigned = new Set([...JavaBodyGenerator.assignedNames(stmts), ...this.reassignedCaptures]);
+        this.appendTargets = JavaBodyGenerator.appendTargets(stmts);
+        this.declareNamedResults(stmts);
+        if (JavaBodyGenerator.defersInBlocks(stmts)) {
+            this.emitDeferList(stmts);
+        } else {
+            this.emitStmts(stmts, true);
+        }
+        this.scopes.pop();
+
+        // A trailing bare return (e.g. from `return nil`) is implicit in Java
+        if (this.lines.length > 0 && this.lines[this.lines.length - 1] === `${INDENT}return;`) {
+            this.lines.pop();
+        }
+        this.emitCommentsBefore(this.source.length);
+        return this.lines;
+    }
+
+    /**
+     * Names that a function literal refers to without declaring them, and that are
+     * assigned after their declaration. Java lambdas only capture effectively final locals.
+     */
+    private static reassignedCaptures(stmts: GoStmt[]): Set<string> {
+        const assigned = this.assignedNames(stmts);
+        const captured = new Set<string>();
+        walkExprs(stmts, e => {
+            if (e.kind !== 'FuncLit') {
+                return;
+            }
+            this.assignedNames(e.body.stmts).forEach(name => assigned.add(name));
+            const declared = this.declaredIn(e);
+            walkExprs(e.body.stmts, inner => {
+                if (inner.kind === 'Ident' && !declared.has(inner.name)) {
+                    captured.add(inner.name);
+                }
+            });
+        });
+        return new Set([...captured].filter(name => assigned.has(name)));
+    }
+
+    /**
+     * Names of the slices the statements grow in place, `s = append(s, ...)`
+     */
+    private static appendTargets(stmts: GoStmt[]): Set<string> {
+        const targets = new Set<string>();
+        walkStmts(stmts, s => {
+            if (s.kind === 'AssignStmt' && s.tok === '=' && s.lhs.length === 1 && s.lhs[0].kind === 'Ident'
+                && s.rhs.length === 1 && s.rhs[0].kind === 'Call' && s.rhs[0].fun.kind === 'Ident'
+                && s.rhs[0].fun.name === 'append' && s.rhs[0].args[0]?.kind === 'Ident'
+                && s.rhs[0].args[0].name === s.lhs[0].name) {
+                targets.add(s.lhs[0].name);
+            }
+        });
+        return targets;
+    }
+
+    /**
+     * Names assigned after their declaration (`x = ...`, `x++`, `for x = range`), outside function literals
+     */
+    private static assignedNames(stmts: GoStmt[]): Set<string> {
+        const assigned = new Set<string>();
+        walkStmts(stmts, s => {
+            const targets = s.kind === 'AssignStmt' && s.tok !== ':=' ? s.lhs
+                : s.kind === 'IncDecStmt' ? [s.x]
+                : s.kind === 'RangeStmt' && s.tok === '=' ? [s.key, s.value]
+                : [];
+            for (const target of targets) {
+                if (target?.kind === 'Ident') {
+                    assigned.add(target.name);
+                }
+            }
+        });
+        return assigned;
+    }
+
+    /**
+     * Names a function literal declares: its parameters and the locals of its body
+     */
+    private static declaredIn(fn: GoFuncLit): Set<string> {
+        return new Set([...(fn.type.params || []).map(p => p.name), ...this.declaredNames(fn.body.stmts)]);
+    }
+
+    /**
+     * Names the statements declare as locals
+     */
+    private static declaredNames(stmts: GoStmt[]): Set<string> {
+        const declared = new Set<string>();
+        walkStmts(stmts, s => {
+            if (s.kind === 'AssignStmt' && s.tok === ':=') {
+                s.lhs.forEach(e => e.kind === 'Ident' && declared.add(e.name));
+            } else if (s.kind === 'DeclStmt') {
+                s.specs.forEach(spec => spec.names.forEach(name => declared.add(name)));
+            } else if (s.kind === 'RangeStmt' && s.tok === ':=') {
+                [s.key, s.value].forEach(e => e?.kind === 'Ident' && declared.add(e.name));
+            }
+        });
+        return declared;
+    }
+
+    /**
+     * Declare the named results the body uses, at their zero values: all of them when a bare
+     * return hands them back, except an error the body never mentions. A named error is
+     * declared with the exception class, so a bare return can throw it.
+     */
+    private declareNamedResults(stmts: GoStmt[]): void {
+        const names = this.goFunc.returnNames || [];
+        const bareReturn = (() => {
+            let found = false;
+            walkStmts(stmts, s => found = found || (s.kind === 'ReturnStmt' && s.results.length === 0));
+            return found;
+        })();
+        names.forEach((name, i) => {
+            const type = this.goFunc.returnTypes[i];
+            if (!name || name === '_' || ((!bareReturn || type.name === 'error') && !this.mentions(stmts, name))) {
+                return;
+            }
+            const javaType = type.name === 'error' && !type.isSlice && !type.isMap
+                ? JavaCodeGenerator.getExceptionClass(this.options)
+                : this.javaType(type);
+            const variable: LocalVariable = { javaName: this.toJavaLocalName(name), type, isResult: true };
+            this.scopes[this.scopes.length - 1].set(name, variable);
+            this.emit(`${javaType} ${variable.javaName} = ${this.structZero(type) ?? JavaCodeGenerator.getDefaultValue(javaType, this.options)};`);
+        });
+    }
+
+    // ═══════════════════════════════════════════════════════════════
+    // Statements
+    // ═══════════════════════════════════════════════════════════════
+
+    private emit(text: string): void {
+        this.lines.push(INDENT.repeat(this.depth) + text);
+    }
+
+    /**
+     * @param functionLevel Whether stmts run until the function returns, so a defer
+     *        among them can guard the statements after it
+     */
+    private emitStmts(stmts: GoStmt[], functionLevel = false): void {
+        for (let i = 0; i < stmts.length; i++) {
+            const stmt = stmts[i];
+            this.emitCommentsBefore(stmt.span[0]);
+            if (functionLevel && stmt.kind === 'DeferStmt' && !this.deferList && !this.rewrites(stmt)) {
+                const mark = this.lines.length;
+                this.emitDefer(stmt, stmts.slice(i + 1));
+                this.annotateOrigin(mark, stmt);
+                return;
+            }
+            const mark = this.lines.length;
+            // A statement a rewriter handles is not taken into an error check
+            const errorCall = this.rewrites(stmts[i]) || this.rewrites(stmts[i + 1]) ? undefined : this.matchErrorCall(stmts[i], stmts[i + 1]);
+            if (errorCall) {
+                const checked = errorCall.handler || errorCall.passOn ? 2 : 1;
+                errorCall.keepErr = errorCall.err !== '_' && this.mentions(stmts.slice(i + checked), errorCall.err);
+            }
+            if (errorCall && this.tryEmit(() => this.emitErrorCall(errorCall))) {
+                if (errorCall.handler || errorCall.passOn) {
+                    i++;
+                }
+            } else {
+                this.emitStmt(stmts[i]);
+            }
+            this.annotateOrigin(mark, stmt);
+            this.placeCommentsWithin(mark, stmts[i].span[1]);
+        }
+    }
+
+    /**
+     * With annotateOrigin, end the first line of code a statement produced (the opening line
+     * of a block, past a brace scoping it and comments moved above it), or its TODO, with the
+     * statement's Go line. Only bodies parsed at their place in the file know it.
+     */
+    private annotateOrigin(mark: number, stmt: GoStmt): void {
+        if (!this.options.annotateOrigin || !this.enclosing.bodyPosition) {
+            return;
+        }
+        const isCode = (line: string) => {
+            const text = line.trim();
+            return text !== '{' && (text.startsWith('// TODO: ') || !/^(\/\/|\/\*|\*)/.test(text));
+        };
+        const first = this.lines.slice(mark).findIndex(isCode);
+        if (first >= 0) {
+            // A lambda is one entry spanning several lines
+            const [opening, ...rest] = this.lines[mark + first].split('\n');
+            this.lines[mark + first] = [JavaCodeGenerator.withOrigin(opening, stmt.pos, this.options), ...rest].join('\n');
+        }
+    }
+
+    /**
+     * Emit the comments that start before offset on lines of their own
+     */
+    private emitCommentsBefore(offset: number): void {
+        while (this.nextComment < this.comments.length && this.comments[this.nextComment].offset < offset) {
+            this.lines.push(...this.commentLines(this.comments[this.nextComment++]));
+        }
+    }
+
+    /**
+     * Place the comments of the statements emitted from mark, which end at offset:
+     * those left inside them (e.g. in a multi-line call) go above, and one that
+     * follows on the statement's last line stays at the end of its last Java line
+     */
+    private placeCommentsWithin(mark: number, end: number): void {
+        const inside: string[] = [];
+        while (this.nextComment < this.comments.length && this.comments[this.nextComment].offset < end) {
+            inside.push(...this.commentLines(this.comments[this.nextComment++]));
+        }
+        this.lines.splice(mark, 0, ...inside);
+
+        const trailing = this.comments[this.nextComment];
+        if (trailing && this.lines.length > mark && !trailing.text.includes('\n')
+            && /^[ \t]*$/.test(this.source.slice(end, trailing.offset))) {
+            this.lines[this.lines.length - 1] += ` ${trailing.text}`;
+            this.nextComment++;
+        }
+    }
+
+    /**
+     * Skip the comments before offset, which a TODO already quotes
+     */
+    private skipCommentsBefore(offset: number): void {
+        while (this.nextComment < this.comments.length && this.comments[this.nextComment].offset < offset) {
+            this.nextComment++;
+        }
+    }
+
+    /**
+     * A comment as Java lines at the current depth. Continuation lines of a block
+     * comment keep their indentation relative to the line the comment starts on.
+     */
+    private commentLines(comment: GoComment): string[] {
+        const indent = INDENT.repeat(this.depth);
+        const lineStart = this.source.lastIndexOf('\n', comment.offset - 1) + 1;
+        const base = this.source.slice(lineStart, comment.offset).match(/^[ \t]*/)![0].replace(/\t/g, INDENT).length;
+        return comment.text.split('\n').map((line, i) => {
+            const expanded = line.replace(/\t/g, INDENT);
+            const text = i === 0 ? line : expanded.slice(Math.min(base, expanded.length - expanded.trimStart().length));
+            return text.trim() ? indent + text.trimEnd() : '';
+        });
+    }
+
+    /**
+     * Run an emitter, discarding its output when it hits an unsupported construct
+     * @returns Whether the emitter succeeded
+     */
+    private tryEmit(emitter: () => void): boolean {
+        const mark = this.lines.length;
+        const reported = this.options.diagnostics?.length || 0;
+        const scopes = this.scopes.length;
+        const depth = this.depth;
+        const comment = this.nextComment;
+        try {
+            emitter();
+            return true;
+        } catch (error) {
+            if (!(error instanceof UnsupportedConstructError)) {
+                throw error;
+            }
+            this.lines.length = mark;
+            this.truncateDiagnostics(reported);
+            this.scopes.length = scopes;
+            this.depth = depth;
+            this.nextComment = comment;
+            return false;
+        }
+    }
+
+    private emitStmt(stmt: GoStmt): void {
+        const mark = this.lines.length;
+        const reported = this.options.diagnostics?.length || 0;
+        const scopes = this.scopes.length;
+        const depth = this.depth;
+        try {
+            this.emitStmtUnchecked(stmt);
+        } catch (error) {
+            if (!(error instanceof UnsupportedConstructError)) {
+                throw error;
+            }
+            // The whole statement becomes one TODO, replacing any reported inside it
+            this.lines.length = mark;
+            this.truncateDiagnostics(reported);
+            this.scopes.length = scopes;
+            this.depth = depth;
+            this.skipCommentsBefore(stmt.span[1]);
+            this.emitUnsupported(stmt, error.message);
+            // The variables it declares do not exist in Java, so statements using them are TODOs too
+            const declared = stmt.kind === 'AssignStmt' && stmt.tok === ':=' ? stmt.lhs.flatMap(e => e.kind === 'Ident' ? [e.name] : [])
+                : stmt.kind === 'DeclStmt' && stmt.tok === 'var' ? stmt.specs.flatMap(spec => spec.names)
+                : [];
+            declared.filter(name => name !== '_').forEach(name => this.declare(name).unconverted = true);
+        }
+    }
+
+    private truncateDiagnostics(length: number): void {
+        if (this.options.diagnostics) {
+            this.options.diagnostics.length = length;
+        }
+    }
+
+    private emitStmtUnchecked(stmt: GoStmt): void {
+        const rewritten = this.rewrite(stmt);
+        if (rewritten !== undefined) {
+            if (rewritten) {
+                rewritten.split('\n').forEach(line => this.emit(line));
+            }
+            return;
+        }
+        switch (stmt.kind) {
+            case 'ExprStmt':
+                if (this.isBuiltinCall(stmt.x, 'panic')) {
+                    this.emitPanic(stmt.x);
+                    return;
+                }
+                if (this.isBuiltinCall(stmt.x, 'close')) {
+                    this.emitClose(stmt.x);
+                    return;
+                }
+                if (this.isTestingCall(stmt.x, 'Helper')) {
+                    // JUnit reports the line of the failed assertion itself
+                    return;
+                }
+                if (this.isTestingCall(stmt.x, 'Run')) {
+                    this.emitSubtest(stmt.x);
+                    return;
+                }
+                this.emit(`${this.expr(stmt.x)};`);
+                return;
+            case 'AssignStmt':
+                this.emitAssign(stmt.lhs, stmt.tok, stmt.rhs);
+                return;
+            case 'IncDecStmt':
+                this.emitCompoundAssign(stmt.x, stmt.tok === '++' ? '+' : '-', { kind: 'BasicLit', litKind: 'INT', value: '1', pos: stmt.pos }, stmt.tok);
+                return;
+            case 'DeclStmt':
+                this.emitDecl(stmt);
+                return;
+            case 'ReturnStmt':
+                if (stmt.results.length === 0 && (this.goFunc.returnNames || []).some(n => !n || n === '_')) {
+                    throw new UnsupportedConstructError('Bare return with blank named results is not converted yet');
+                }
+                // An error result the body never touches is still nil
+                this.emitReturn(JavaBodyGenerator.resultValues(stmt, this.goFunc).map((e, i) =>
+                    stmt.results.length === 0 && e.kind === 'Ident' && !this.lookup(e.name)?.isResult
+                        ? { kind: 'Ident', name: 'nil', pos: e.pos } : e));
+                return;
+            case 'BranchStmt':
+                if (stmt.tok === 'break' || stmt.tok === 'continue') {
+                    this.emit(stmt.label ? `${stmt.tok} ${stmt.label};` : `${stmt.tok};`);
+                    return;
+                }
+                if (stmt.tok === 'goto') {
+                    throw new UnsupportedConstructError("Java has no 'goto'; restructure the jump as a labeled break or continue");
+                }
+                throw new UnsupportedConstructError(`'${stmt.tok}' has no direct Java equivalent`);
+            case 'BlockStmt':
+                this.emit('{');
+                this.emitBlockContents(stmt);
+                this.emit('}');
+                return;
+            case 'IfStmt':
+                this.emitIf(stmt);
+                return;
+            case 'ForStmt':
+                this.emitFor(stmt);
+                return;
+            case 'EmptyStmt':
+                return;
+            case 'BadStmt':
+                this.emitUnsupported(stmt, `Could not parse Go statement (${stmt.message})`);
+                return;
+            case 'RangeStmt':
+                this.emitRange(stmt);
+                return;
+            case 'SwitchStmt':
+                this.emitScoped(!!stmt.init, () => {
+                    if (stmt.init) {
+                        this.emitStmtUnchecked(stmt.init);
+                    }
+                    this.emitSwitch(stmt);
+                });
+                return;
+            case 'TypeSwitchStmt':
+                this.emitScoped(!!stmt.init, () => {
+                    if (stmt.init) {
+                        this.emitStmtUnchecked(stmt.init);
+                    }
+                    this.emitTypeSwitch(stmt);
+                });
+                return;
+            case 'SelectStmt': {
+                // The TODO names each case, so the choice can be rebuilt by hand (e.g. with poll timeouts)
+                const cases = stmt.cases.map(c => c.isDefault || !c.comm ? 'default' : this.source.slice(c.comm.span[0], c.comm.span[1]).trim());
+                throw new UnsupportedConstructError(`Select statements are not converted yet (cases: ${cases.join('; ')})`);
+            }
+            case 'LabeledStmt':
+                this.emitLabeled(stmt);
+                return;
+            case 'DeferStmt':
+                // Go runs it when the function returns, not when the enclosing block ends
+                if (!this.deferList) {
+                    throw new UnsupportedConstructError('Defer inside a function literal is not converted yet');
+                }
+                this.emitDeferredTask(stmt, this.deferList);
+                return;
+            case 'GoStmt':
+                if (!this.options.experimentalConcurrency) {
+                    throw new UnsupportedConstructError('Goroutines are only converted with experimental concurrency support');
+                }
+                this.emitGoroutine(stmt);
+                return;
+            case 'SendStmt':
+                this.emit(`${this.send(stmt.chan, stmt.value)};`);
+                return;
+        }
+    }
+
+    /**
+     * `Outer: for ...` keeps its label, which `break Outer` and `continue Outer` name as in
+     * Go. A loop's label goes on the Java loop itself, past any locals its translation declares
+     * first, so that continue can name it; a switch's goes on the switch, the if/else chain or
+     * the block it becomes, all of which a labeled break can leave.
+     */
+    private emitLabeled(stmt: GoLabeledStmt): void {
+        const mark = this.lines.length;
+        this.emitStmtUnchecked(stmt.stmt);
+        const start = stmt.stmt.kind === 'ForStmt' || stmt.stmt.kind === 'RangeStmt' ? /^(for|while|do)\b/ : /^(switch|if)\b|^\{(\s|$)/;
+        const i = this.lines.findIndex((line, n) => n >= mark && start.test(line.trim()));
+        // Otherwise only a goto could name the label
+        if (i >= 0) {
+            const indent = this.lines[i].match(/^\s*/)![0];
+            this.lines[i] = `${indent}${stmt.label}: ${this.lines[i].trimStart()}`;
+        }
+    }
+
+    /**
+     * Start a value receiver as a shallow copy of this, field by field like Go's copy:
+     * `Config c = new Config(this.host, this.port);`
+     */
+    private emitReceiverCopy(receiver: GoParameter, variable: LocalVariable): void {
+        const struct = this.structOf(receiver.type);
+        const pos = { line: 0, character: 0 };
+        const copy: GoCompositeLit = {
+            kind: 'CompositeLit',
+            elts: (struct?.fields || []).map((f): GoExpr => ({
+                kind: 'KeyValue',
+                key: { kind: 'Ident', name: f.name, pos },
+                value: { kind: 'Selector', x: { kind: 'Ident', name: receiver.name, pos }, sel: f.name, pos },
+                pos
+            })),
+            pos
+        };
+        let value: string;
+        try {
+            if (!struct) {
+                throw new UnsupportedConstructError(`Receiver type '${receiver.type.name}' is not a struct of the file`);
+            }
+            value = this.compositeLit(copy, receiver.type);
+        } catch (error) {
+            if (!(error instanceof UnsupportedConstructError)) {
+                throw error;
+            }
+            // The method then changes this, as without the copy
+            this.emit('// value receiver: mutations not reflected in caller');
+            this.reportDegraded('ValueReceiver', `Value receiver mutation not preserved: the receiver could not be copied (${error.message})`, this.goFunc.namePosition || pos);
+            return;
+        }
+        variable.javaName = this.toJavaLocalName(receiver.name);
+        this.emit(`${this.javaType(receiver.type)} ${variable.javaName} = ${value};`);
+    }
+
+    /**
+     * `s = append(s, ...)` grows s in place: Java lists are mutable, and arrays are copied
+     * into a longer one inline, so that a method converted on its own needs no helper
+     */
+    private emitAppend(target: GoExpr, call: GoCallExpr): void {
+        const type = this.typeOf(target);
+        if (!type || !type.isSlice) {
+            throw new UnsupportedConstructError('append to a value with unknown type is not converted yet');
+        }
+        const element = GoFunctionParser.elementTypeOf(type);
+        const values = call.args.slice(1);
+        const spread = call.ellipsis ? values[0] : undefined;
+        const spreadType = spread && this.typeOf(spread);
+        if (spread && (!spreadType || !spreadType.isSlice)) {
+            throw new UnsupportedConstructError('append of a spread value that is not a slice is not converted yet');
+        }
+        const code = this.expr(target, PRIMARY_PRECEDENCE);
+
+        if (this.isList(type)) {
+            if (!spread) {
+                values.forEach(v => this.emit(`${code}.add(${this.exprAs(v, element)});`));
+            } else if (this.isList(spreadType!)) {
+                this.emit(`${code}.addAll(${this.expr(spread)});`);
+            } else if (/^[a-z]+$/.test(this.javaType(element))) {
+                throw new UnsupportedConstructError('append of a primitive array to a list is not converted yet');
+            } else {
+                this.emit(`Collections.addAll(${code}, ${this.expr(spread)});`);
+            }
+            return;
+        }
+
+        // The values are read before the array grows, in case they refer to it
+        const reads = (e: GoExpr) => {
+            let found = false;
+            walkExprs([{ kind: 'ExprStmt', x: e, pos: e.pos, span: [0, 0] }], inner => found = found || this.sameVariable(inner, target));
+            return found;
+        };
+        const javaValues = values.map(v => {
+            if (!reads(v)) {
+                return spread ? this.expr(v, PRIMARY_PRECEDENCE) : this.exprAs(v, element);
+            }
+            const valueType = spread ? spreadType! : element;
+            const name = this.freshName('value');
+            const copy = spread ? `${this.expr(v, PRIMARY_PRECEDENCE)}.clone()` : this.exprAs(v, element);
+            this.emit(`${this.javaType(valueType)} ${this.declare(name, valueType).javaName} = ${copy};`);
+            return name;
+        });
+        if (spread) {
+            const other = javaValues[0];
+            this.emit(`${code} = Arrays.copyOf(${code}, ${code}.length + ${other}.length);`);
+            this.emit(`System.arraycopy(${other}, 0, ${code}, ${code}.length - ${other}.length, ${other}.length);`);
+            return;
+        }
+        this.emit(`${code} = Arrays.copyOf(${code}, ${code}.length + ${javaValues.length});`);
+        javaValues.forEach((v, i) => this.emit(`${code}[${code}.length - ${javaValues.length - i}] = ${v};`));
+    }
+
+    /**
+     * Whether two expressions name the same variable or field: `s` and `s`, `u.items` and `u.items`
+     */
+    private sameVariable(a: GoExpr, b: GoExpr): boolean {
+        if (a.kind === 'Paren') {
+            return this.sameVariable(a.x, b);
+        }
+        if (b.kind === 'Paren') {
+            return this.sameVariable(a, b.x);
+        }
+        if (a.kind === 'Ident' && b.kind === 'Ident') {
+            return a.name === b.name;
+        }
+        return a.kind === 'Selector' && b.kind === 'Selector' && a.sel === b.sel && this.sameVariable(a.x, b.x);
+    }
+
+    /**
+     * Record a construct that converts, but behaves differently in Java
+     */
+    private reportDegraded(construct: string, message: string, pos: SourcePosition): void {
+        this.options.diagnostics?.push({
+            severity: 'degraded',
+            construct,
+            message,
+            pos,
+            scope: JavaCodeGenerator.scopeName(this.enclosing)
+        });
+    }
+
+    /**
+     * Emit a statement that could not be translated as a TODO comment carrying the Go source,
+     * and report it
+     */
+    private emitUnsupported(stmt: GoStmt, reason: string): void {
+        this.options.diagnostics?.push({
+            severity: 'unsupported',
+            construct: stmt.kind,
+            message: reason,
+            pos: stmt.pos,
+            scope: JavaCodeGenerator.scopeName(this.enclosing)
+        });
+        this.emit(`// TODO: ${reason}:`);
+        for (const line of this.sourceLines(stmt.span)) {
+            this.emit(`// ${line}`);
+        }
+    }
+
+    private sourceLines(span: [number, number]): string[] {
+        const lines = this.source.slice(span[0], span[1]).replace(/\t/g, INDENT).split('\n');
+        if (lines.length === 1) {
+            return lines;
+        }
+        const rest = lines.slice(1).filter(l => l.trim());
+        const last = lines[lines.length - 1];
+        const baseIndent = /^\s*[})\]]/.test(last)
+            ? last.length - last.trimStart().length
+            : Math.min(...rest.map(l => l.length - l.trimStart().length)) - INDENT.length;
+        return lines.map((line, i) => i === 0 ? line : line.slice(Math.min(Math.max(baseIndent, 0), line.length - line.trimStart().length)));
+    }
+
+    private emitBlockContents(block: GoBlockStmt, functionLevel = false): void {
+        this.depth++;
+        this.scopes.push(new Map());
+        this.emitStmts(block.stmts, functionLevel);
+        this.emitCommentsBefore(block.span[1]);
+        this.scopes.pop();
+        this.depth--;
+    }
+
+    private emitAssign(lhs: GoExpr[], tok: string, rhs: GoExpr[]): void {
+        if (tok === '=' && lhs.length === 1 && rhs.length === 1 && this.isBuiltinCall(rhs[0], 'append')
+            && rhs[0].args.length > 0 && this.sameVariable(lhs[0], rhs[0].args[0])) {
+            this.emitAppend(lhs[0], rhs[0]);
+            return;
+        }
+        if ((tok === ':=' || tok === '=') && lhs.length === 2 && rhs.length === 1 && rhs[0].kind === 'TypeAssert') {
+            this.emitCommaOkAssertion(lhs[0], lhs[1], tok, rhs[0]);
+            return;
+        }
+        if ((tok === ':=' || tok === '=') && lhs.length > 1 && rhs.length === 1 && rhs[0].kind === 'Call') {
+            this.emitResultUnpacking(lhs, tok, rhs[0]);
+            return;
+        }
+        if (lhs.length === 2 && rhs.length === 1 && rhs[0].kind === 'Unary' && rhs[0].op === '<-') {
+            // A queue cannot be closed, so there is no ok to report
+            throw new UnsupportedConstructError('Receives checking for a closed channel (v, ok := <-ch) are not converted yet');
+        }
+        if (tok === ':=' || tok === '=') {
+            if (lhs.length !== rhs.length) {
+                throw new UnsupportedConstructError('Multi-value assignments are not converted yet');
+            }
+            this.emitParallelAssign(lhs, tok, rhs);
+            return;
+        }
+
+        if (lhs.length !== 1 || rhs.length !== 1) {
+            throw new UnsupportedConstructError('Invalid compound assignment');
+        }
+        this.emitCompoundAssign(lhs[0], tok.slice(0, -1), rhs[0]);
+    }
+
+    /**
+     * `a, b = b, a`: Go evaluates every value before assigning any, so a value reading a
+     * variable an earlier target assigns is saved first, `int tmp = a; a = b; b = tmp;`.
+     * Values are evaluated in order, so calls before a saved value are saved too.
+     */
+    private emitParallelAssign(lhs: GoExpr[], tok: string, rhs: GoExpr[]): void {
+        const reads = (e: GoExpr, names: Set<string>, calls = false) => {
+            let found = false;
+            walkExprs([{ kind: 'ExprStmt', x: e, pos: e.pos, span: [0, 0] }], inner =>
+                found = found || (inner.kind === 'Ident' && names.has(inner.name)) || (calls && inner.kind === 'Call' && !this.isLengthCall(inner)));
+            return found;
+        };
+        // The variable an assignment changes: a for a, a.X and a[i]
+        const root = (e: GoExpr): string | undefined =>
+            e.kind === 'Ident' ? e.name : 'x' in e && (e.kind === 'Paren' || e.kind === 'Selector' || e.kind === 'Index' || e.kind === 'Star') ? root(e.x) : undefined;
+
+        // Variables earlier targets reassign, and those whose contents they change too
+        const rebound = new Set<string>();
+        const changed = new Set<string>();
+        const saved = lhs.map((target, i) => {
+            if (target.kind !== 'Ident' && reads(target, rebound)) {
+                // Go evaluates xs[i] with the i from before the statement
+                throw new UnsupportedConstructError('Parallel assignments whose targets depend on each other are not converted yet');
+            }
+            // A call may read what an earlier target assigned
+            const save = reads(rhs[i], changed, changed.size > 0);
+            const name = root(target);
+            if (name && name !== '_') {
+                changed.add(name);
+                if (target.kind === 'Ident') {
+                    rebound.add(name);
+                }
+            }
+            return save;
+        });
+        const last = saved.lastIndexOf(true);
+        const values = rhs.map((value, i): GoExpr => {
+            if (!saved[i] && !(i < last && reads(value, new Set(), true))) {
+                return value;
+            }
+            const type = (lhs[i].kind === 'Ident' && lhs[i].name === '_' ? undefined : this.typeOf(lhs[i])) || this.typeOf(value);
+            const name = this.freshName('tmp');
+            this.emit(`${type ? this.javaType(type) : 'var'} ${this.declare(name, type).javaName} = ${this.expr(value)};`);
+            return { kind: 'Ident', name, pos: value.pos };
+        });
+        lhs.forEach((target, i) => tok === ':=' ? this.emitShortVarDecl(target, values[i]) : this.emitSimpleAssign(target, values[i]));
+    }
+
+    /**
+     * `len(x)` or `cap(x)` of the builtins, which read nothing but their argument
+     */
+    private isLengthCall(call: GoCallExpr): boolean {
+        return call.fun.kind === 'Ident' && ['len', 'cap'].includes(call.fun.name) && !this.lookup(call.fun.name);
+    }
+
+    /**
+     * `v, err := f()` of a function returning a result record in errorResultRecords mode:
+     * the record goes into a local whose components are unpacked into the targets,
+     * `DivideResult result = divide(a, b); int v = result.value(); String err = result.error();`.
+     * Without result records the error is thrown, and the error calls matchErrorCall
+     * recognizes assign the value alone.
+     */
+    private emitResultUnpacking(lhs: GoExpr[], tok: string, call: GoCallExpr): void {
+        const callee = this.resolveCallee(call.fun);
+        const stdlibName = callee ? undefined : this.packageFunction(call.fun);
+        if (stdlibName && isStdlibImport(stdlibName.slice(0, stdlibName.lastIndexOf('.')))) {
+            throw new UnsupportedConstructError(`${stdlibName} has no Java mapping yet`);
+        }
+        if (!callee || !JavaCodeGenerator.usesResultRecord(callee, this.options) || callee.returnTypes.length !== lhs.length) {
+            throw new UnsupportedConstructError('Multi-value assignments are not converted yet');
+        }
+        const name = this.freshName('result');
+        this.emit(`${JavaCodeGenerator.getResultRecordType(callee, this.options)} ${this.declare(name).javaName} = ${this.expr(call)};`);
+        const values = callee.returnTypes.length - 1;
+        lhs.forEach((target, i) => {
+            if (target.kind === 'Ident' && target.name === '_') {
+                return;
+            }
+            // The record holds the error as its message
+            const component = i === values ? 'error' : values === 1 ? 'value' : `value${i + 1}`;
+            const type = i === values ? this.simpleType('string') : callee.returnTypes[i];
+            this.emitAssignTo(target, tok, type, `${name}.${component}()`);
+        });
+    }
+
+    private emitShortVarDecl(target: GoExpr, value: GoExpr): void {
+        if (target.kind !== 'Ident') {
+            throw new UnsupportedConstructError('Invalid short variable declaration');
+        }
+        if (target.name === '_') {
+            this.emitDiscard(value);
+            return;
+        }
+
+        const existing = this.scopes[this.scopes.length - 1].get(target.name);
+        if (existing) {
+            // Go allows redeclaring in a multi-variable :=; it is a plain assignment then
+            this.emit(`${existing.javaName} = ${this.expr(value)};`);
+            return;
+        }
+        const table = this.options.junit ? JavaBodyGenerator.testTable(value) : undefined;
+        if (table) {
+            this.emitTestTable(target.name, table);
+            return;
+        }
+
+        const type = this.typeOf(value);
+        const javaValue = this.expr(value);
+        this.emitLocal(target.name, type, type ? this.javaType(type) : 'var', javaValue);
+    }
+
+    /**
+     * `v, ok := x.(T)` as an instanceof check feeding ok, and v cast when it holds:
+     * `boolean ok = x instanceof String; String v = ok ? (String) x : "";`
+     */
+    private emitCommaOkAssertion(value: GoExpr, ok: GoExpr, tok: string, assertion: GoTypeAssertExpr): void {
+        if (!assertion.type) {
+            throw new UnsupportedConstructError('Invalid type assertion');
+        }
+        const type = assertion.type.type;
+        let x = assertion.x;
+        let calls = false;
+        walkExprs([{ kind: 'ExprStmt', x, pos: x.pos, span: [0, 0] }], inner => calls = calls || inner.kind === 'Call');
+        if (calls) {
+            // x is read twice, by the check and by the cast
+            const name = this.freshName('value');
+            const valueType = this.typeOf(x);
+            this.emit(`${valueType ? this.javaType(valueType) : 'Object'} ${this.declare(name, valueType).javaName} = ${this.expr(x)};`);
+            x = { kind: 'Ident', name, pos: x.pos };
+        }
+        const isBlank = (e: GoExpr) => e.kind === 'Ident' && e.name === '_';
+        const test = this.instanceOf(x, type);
+        if (!isBlank(ok)) {
+            this.emitAssignTo(ok, tok, this.simpleType('bool'), test);
+        }
+        if (!isBlank(value)) {
+            const javaType = this.javaType(type);
+            const holds = isBlank(ok) ? test : this.expr(ok);
+            this.emitAssignTo(value, tok, type, `${holds} ? ${this.cast(x, type)} : ${this.zeroElement(javaType)}`);
+        }
+    }
+
+    /**
+     * Assign an already translated value to a variable, declaring it for a `:=` that introduces it
+     */
+    private emitAssignTo(target: GoExpr, tok: string, type: GoType, javaValue: string): void {
+        if (target.kind !== 'Ident' && (tok === ':=' || target.kind !== 'Selector')) {
+            throw new UnsupportedConstructError('Assignments to this target are not converted yet');
+        }
+        const existing = target.kind === 'Ident' && this.scopes[this.scopes.length - 1].get(target.name);
+        if (tok === ':=' && target.kind === 'Ident' && !existing) {
+            this.emitLocal(target.name, type, this.javaType(type), javaValue);
+            return;
+        }
+        this.emit(`${existing ? existing.javaName : this.expr(target)} = ${javaValue};`);
+    }
+
+    /**
+     * Declare a local; one that a closure captures and the code reassigns goes into a
+     * one-element array, so the lambda sees and makes updates through `x[0]`
+     */
+    private emitLocal(name: string, type: GoType | undefined, javaType: string, javaValue: string, modifier = ''): void {
+        const variable = this.declare(name, type);
+        if (!this.reassignedCaptures.has(name)) {
+            this.emit(`${modifier}${javaType} ${variable.javaName} = ${javaValue};`);
+            return;
+        }
+        if (javaType === 'var' || javaType.includes('<')) {
+            // Java has no arrays of generic types, nor of inferred ones
+            throw new UnsupportedConstructError(`'${name}' is captured by a closure and reassigned; only locals of simple types are boxed for that`);
+        }
+        this.emit(`${javaType}[] ${variable.javaName} = {${javaValue}};`);
+        variable.javaName += '[0]';
+        variable.boxed = true;
+    }
+
+    private emitSimpleAssign(target: GoExpr, value: GoExpr): void {
+        if (target.kind === 'Ident' && target.name === '_') {
+            this.emitDiscard(value);
+            return;
+        }
+        this.checkAssignable(target);
+        if (target.kind === 'Index') {
+            const containerType = this.typeOf(target.x);
+            if (containerType?.isMap) {
+                const javaValue = containerType.valueType ? this.exprAs(value, containerType.valueType) : this.expr(value);
+                this.emit(`${this.expr(target.x, PRIMARY_PRECEDENCE)}.put(${this.expr(target.index)}, ${javaValue});`);
+                return;
+            }
+            if (containerType && this.isList(containerType)) {
+                const javaValue = this.exprAs(value, GoFunctionParser.elementTypeOf(containerType));
+                this.emit(`${this.expr(target.x, PRIMARY_PRECEDENCE)}.set(${this.expr(target.index)}, ${javaValue});`);
+                return;
+            }
+        }
+        this.emit(`${this.expr(target)} = ${this.constantAs(value, this.typeOf(target))};`);
+    }
+
+    /**
+     * Reject `*p = v`: assigning to the Java reference would not change what the caller sees
+     */
+    private checkAssignable(target: GoExpr): void {
+        if (target.kind === 'Star' || (target.kind === 'Paren' && target.x.kind === 'Star')) {
+            throw new UnsupportedConstructError('Assignments through a pointer are not converted yet');
+        }
+    }
+
+    private emitCompoundAssign(target: GoExpr, op: string, value: GoExpr, incDec?: '++' | '--'): void {
+        this.checkAssignable(target);
+        const typeParam = this.typeParameter(this.typeOf(target));
+        if (typeParam) {
+            throw new UnsupportedConstructError(`'${incDec || `${op}=`}' on values of type parameter ${typeParam.name} is not converted yet; Java generics have no operators`);
+        }
+        if (target.kind === 'Index') {
+            const containerType = this.typeOf(target.x);
+            if (containerType && (containerType.isMap || this.isList(containerType))) {
+                // Collections need an explicit read-modify-write
+                const container = this.expr(target.x, PRIMARY_PRECEDENCE);
+                const key = this.expr(target.index);
+                const current = this.index(target.x, target.index);
+                const unsigned = this.unsignedDivision(op, target);
+                let updated = op === '&^'
+                    ? `${current} & ~${this.expr(value, UNARY_PRECEDENCE)}`
+                    : unsigned
+                    ? `${unsigned}(${current}, ${this.expr(value)})`
+                    : `${current} ${this.shiftOperator(op, target)} ${this.expr(value, (JAVA_PRECEDENCE[op] || 0) + 1)}`;
+                // Java's compound assignments narrow their result back; a read-modify-write must cast
+                const elementType = containerType.isMap ? containerType.valueType : GoFunctionParser.elementTypeOf(containerType);
+                const elementJava = elementType && this.javaType(elementType);
+                if (elementJava && NARROW_JAVA_TYPES.has(elementJava)) {
+                    updated = `(${elementJava}) (${updated})`;
+                }
+                this.emit(`${container}.${containerType.isMap ? 'put' : 'set'}(${key}, ${updated});`);
+                return;
+            }
+        }
+
+        const javaTarget = this.expr(target);
+        const unsigned = incDec ? undefined : this.unsignedDivision(op, target);
+        if (incDec) {
+            this.emit(`${javaTarget}${incDec};`);
+        } else if (op === '&^') {
+            this.emit(`${javaTarget} &= ~${this.expr(value, UNARY_PRECEDENCE)};`);
+        } else if (unsigned) {
+            this.emit(`${javaTarget} = ${unsigned}(${javaTarget}, ${this.expr(value)});`);
+        } else {
+            this.emit(`${javaTarget} ${this.shiftOperator(op, target)}= ${this.expr(value)};`);
+        }
+    }
+
+    /**
+     * `>>` shifts an unsigned Go value held in a Java int or long of the same width with
+     * `>>>`, which shifts in zeros as Go does; other operators are unchanged
+     */
+    private shiftOperator(op: string, operand: GoExpr): string {
+        const type = op === '>>' ? this.typeOf(operand) : undefined;
+        return type && GoFunctionParser.isLossyUnsigned(type) && ['int', 'long'].includes(this.javaType(type)) ? '>>>' : op;
+    }
+
+    /**
+     * The method dividing an unsigned Go value held in a Java int or long of the same width,
+     * which `/` and `%` would divide as signed: `Long.divideUnsigned`, `Long.remainderUnsigned`
+     * and their Integer forms. Undefined for other operators and operands.
+     */
+    private unsignedDivision(op: string, operand: GoExpr): string | undefined {
+        const type = op === '/' || op === '%' ? this.typeOf(operand) : undefined;
+        const javaType = type && GoFunctionParser.isLossyUnsigned(type) ? this.javaType(type) : undefined;
+        if (javaType !== 'int' && javaType !== 'long') {
+            return undefined;
+        }
+        return `${javaType === 'long' ? 'Long' : 'Integer'}.${op === '/' ? 'divideUnsigned' : 'remainderUnsigned'}`;
+    }
+
+    /**
+     * Assignment to the blank identifier: keep side effects, drop the value
+     */
+    private emitDiscard(value: GoExpr): void {
+        if (value.kind === 'Call') {
+            this.emit(`${this.expr(value)};`);
+        }
+    }
+
+    private emitDecl(stmt: GoDeclStmt): void {
+        if (stmt.tok === 'type') {
+            throw new UnsupportedConstructError('Local type declarations are not converted yet');
+        }
+        for (const spec of stmt.specs) {
+            if (spec.values.length > 0 && spec.values.length !== spec.names.length) {
+                throw new UnsupportedConstructError('Multi-value declarations are not converted yet');
+            }
+            spec.names.forEach((name, i) => {
+                const value = spec.values[i];
+                const type = spec.type ? spec.type.type : value ? this.typeOf(value) : undefined;
+                if (name === '_') {
+                    if (value) this.emitDiscard(value);
+                    return;
+                }
+                const javaType = type ? this.javaType(type) : 'var';
+                // A nil slice appended to in place starts empty, since Java cannot add to null
+                const zeroOptions = this.appendTargets.has(name) ? { ...this.options, emptyCollections: true } : this.options;
+                const javaValue = value ? this.constantAs(value, spec.type?.type)
+                    : (type && this.structZero(type)) ?? JavaCodeGenerator.getDefaultValue(javaType, zeroOptions);
+                this.emitLocal(name, type, javaType, javaValue, stmt.tok === 'const' ? 'final ' : '');
+            });
+        }
+    }
+
+    private emitReturn(results: GoExpr[]): void {
+        if (results.length === 0) {
+            this.emit('return;');
+            return;
+        }
+
+        const returnTypes = this.goFunc.returnTypes;
+        if (JavaCodeGenerator.usesResultRecord(this.goFunc, this.options)) {
+            if (results.length !== returnTypes.length) {
+                this.emitRecordPassOn(results);
+                return;
+            }
+            const err = results[results.length - 1];
+            const components = results.slice(0, -1).map((v, i) => this.exprAs(v, returnTypes[i]));
+            const named = err.kind === 'Ident' ? this.lookup(err.name) : undefined;
+            components.push(this.isNil(err) ? 'null'
+                : named?.isResult ? `${named.javaName} == null ? null : ${named.javaName}.getMessage()`
+                : this.errorMessage(err));
+            this.emit(`return ${JavaCodeGenerator.getResultRecordConstructor(this.goFunc, this.options)}(${components.join(', ')});`);
+            return;
+        }
+
+        let values = results;
+        if (results.length === returnTypes.length && this.goFunc.hasErrorReturn) {
+            // A non-nil error becomes a throw; `return val, nil` is a normal return
+            const errorValue = results.find((v, i) => returnTypes[i].name === 'error' && !this.isNil(v));
+            const named = errorValue?.kind === 'Ident' ? this.lookup(errorValue.name) : undefined;
+            if (named?.isResult) {
+                // A named error result may still be nil; if nothing ever sets it, it is
+                if (JavaBodyGenerator.canThrow(this.goFunc, this.goFile) && !this.options.handleErrorsAsExceptions) {
+                    throw new UnsupportedConstructError('Returning a non-nil error requires handleErrorsAsExceptions');
+                }
+                if (JavaCodeGenerator.throwsErrors(this.goFunc, this.options, this.goFile)) {
+                    this.emit(`if (${named.javaName} != null) {`);
+                    this.depth++;
+                    this.emit(`throw ${named.javaName};`);
+                    this.depth--;
+                    this.emit('}');
+                }
+            } else if (errorValue) {
+                if (!this.options.handleErrorsAsExceptions) {
+                    throw new UnsupportedConstructError('Returning a non-nil error requires handleErrorsAsExceptions');
+                }
+                if (this.isNewError(errorValue)) {
+                    this.emit(`throw ${this.exception(errorValue)};`);
+                    return;
+                }
+                // Go evaluates the results in order, so calls before the error run before it is thrown
+                const errorIndex = results.indexOf(errorValue);
+                values = results.map((v, i) => i < errorIndex && returnTypes[i].name !== 'error' && this.hasCall(v)
+                    ? this.hoist(v, returnTypes[i]) : v);
+                this.emitThrowIfSet(errorValue);
+            }
+            values = values.filter((_, i) => returnTypes[i].name !== 'error');
+        }
+
+        if (values.length === 0) {
+            this.emit('return;');
+        } else if (values.length === 1) {
+            this.emit(`return ${this.constantAs(values[0], returnTypes.find(t => t.name !== 'error'))};`);
+        } else {
+            throw new UnsupportedConstructError('Returning multiple values is not converted yet');
+        }
+    }
+
+    /**
+     * `return f(x)` of a function returning a result record, where f returns one too: the
+     * record itself when both functions use the same one, and otherwise a local holding it
+     * whose components make up the record returned,
+     * `ParseResult result = parse(s); return new LoadResult(result.value(), result.error());`
+     */
+    private emitRecordPassOn(results: GoExpr[]): void {
+        const call = results.length === 1 && results[0].kind === 'Call' ? results[0] : undefined;
+        const callee = call && this.resolveCallee(call.fun);
+        if (!call || !callee || !JavaCodeGenerator.usesResultRecord(callee, this.options)
+            || callee.returnTypes.length !== this.goFunc.returnTypes.length) {
+            throw new UnsupportedConstructError('Returning a call result as a result record is not converted yet');
+        }
+        const type = JavaCodeGenerator.getResultRecordType(callee, this.options);
+        if (type === JavaCodeGenerator.getResultRecordType(this.goFunc, this.options)) {
+            this.emit(`return ${this.expr(call)};`);
+            return;
+        }
+        const name = this.freshName('result');
+        this.emit(`${type} ${this.declare(name).javaName} = ${this.expr(call)};`);
+        const values = callee.returnTypes.length - 1;
+        const components = [...callee.returnTypes.slice(0, -1).map((_, i) => values === 1 ? 'value' : `value${i + 1}`), 'error'];
+        this.emit(`return ${JavaCodeGenerator.getResultRecordConstructor(this.goFunc, this.options)}(${components.map(c => `${name}.${c}()`).join(', ')});`);
+    }
+
+    /**
+     * Throw a returned error unless it is nil. A call whose error Java already throws is just
+     * made; any other error value may be nil, so it is thrown only when set.
+     */
+    private emitThrowIfSet(err: GoExpr): void {
+        const callee = err.kind === 'Call' ? this.resolveCallee(err.fun) : undefined;
+        if (callee?.hasErrorReturn && callee.returnTypes.length === 1) {
+            this.emit(`${this.expr(err)};`);
+            return;
+        }
+        let value = err;
+        while (value.kind === 'Paren') {
+            value = value.x;
+        }
+        if (value.kind !== 'Ident' && !(value.kind === 'Selector' && value.x.kind === 'Ident')) {
+            // A computed error is checked and thrown from a local, evaluated once
+            value = this.hoist(value, this.typeOf(value) || this.simpleType('error'), 'err');
+        }
+        this.emit(`if (${this.expr(value)} != null) {`);
+        this.depth++;
+        this.emit(`throw ${this.exception(value)};`);
+        this.depth--;
+        this.emit('}');
+    }
+
+    /**
+     * An error that is never nil: built in place with errors.New, fmt.Errorf or a literal of an
+     * error type, or a sentinel package variable holding one that is never reassigned
+     */
+    private isNewError(e: GoExpr): boolean {
+        if (e.kind === 'Paren') {
+            return this.isNewError(e.x);
+        }
+        if (e.kind === 'Ident') {
+            const sentinel = this.lookup(e.name) ? undefined : this.goFile?.variables.find(v => v.name === e.name);
+            return !!sentinel?.value && /^(errors\.New|fmt\.Errorf)\(/.test(sentinel.value.trim())
+                && !JavaBodyGenerator.assignsPackageVariable(e.name, this.goFile!);
+        }
+        if (e.kind === 'Unary' && e.op === '&') {
+            return e.x.kind === 'CompositeLit';
+        }
+        return e.kind === 'CompositeLit' || (e.kind === 'Call' && !this.lookup('errors') && !this.lookup('fmt') && JavaBodyGenerator.buildsError(e));
+    }
+
+    /**
+     * Whether evaluating e calls a function
+     */
+    private hasCall(e: GoExpr): boolean {
+        let calls = false;
+        walkExprs([{ kind: 'ExprStmt', x: e, pos: e.pos, span: [0, 0] }], inner => calls = calls || inner.kind === 'Call');
+        return calls;
+    }
+
+    /**
+     * Evaluate e into a fresh local, for an expression that must run before others or only once
+     * @returns The local, to use in place of e
+     */
+    private hoist(e: GoExpr, type: GoType | undefined, base: string = 'tmp'): GoIdent {
+        const name = this.freshName(base);
+        this.emit(`${type ? this.javaType(type) : 'var'} ${this.declare(name, type).javaName} = ${type ? this.exprAs(e, type) : this.expr(e)};`);
+        return { kind: 'Ident', name, pos: e.pos };
+    }
+
+    private emitIf(stmt: GoIfStmt): void {
+        const errorCall = stmt.init ? this.matchErrorCall(stmt.init, stmt) : undefined;
+        if (errorCall?.handler) {
+            // Variables of the if header stay scoped to it; a lone error needs no block
+            const scoped = errorCall.assign.tok === ':=' && errorCall.assign.lhs.length > 1
+                && !(errorCall.assign.lhs[0].kind === 'Ident' && errorCall.assign.lhs[0].name === '_');
+            if (this.tryEmit(() => {
+                if (scoped) {
+                    this.emit('{');
+                    this.depth++;
+                    this.scopes.push(new Map());
+                }
+                this.emitErrorCall(errorCall);
+                if (scoped) {
+                    this.scopes.pop();
+                    this.depth--;
+                    this.emit('}');
+                }
+            })) {
+                return;
+            }
+        }
+
+        const hasScopedInit = stmt.init?.kind === 'AssignStmt' && stmt.init.tok === ':=';
+        if (hasScopedInit) {
+            // Keep variables declared in the if header scoped to the if statement
+            this.emit('{');
+            this.depth++;
+            this.scopes.push(new Map());
+        }
+        if (stmt.init) {
+            this.emitStmtUnchecked(stmt.init);
+        }
+        this.emitIfChain(stmt, 'if');
+        if (hasScopedInit) {
+            this.scopes.pop();
+            this.depth--;
+            this.emit('}');
+        }
+    }
+
+    private emitIfChain(stmt: GoIfStmt, keyword: string): void {
+        const assertion = keyword === 'if' ? this.assertion(stmt) : undefined;
+        if (assertion) {
+            this.emit(`${assertion};`);
+            return;
+        }
+        this.emit(`${keyword} (${this.expr(stmt.cond)}) {`);
+        this.emitBlockContents(stmt.body);
+        if (!stmt.else) {
+            this.emit('}');
+            return;
+        }
+        if (stmt.else.kind === 'IfStmt') {
+            if (stmt.else.init) {
+                // else if with its own init statement: run the init inside a plain else block
+                this.emit('} else {');
+                this.depth++;
+                this.scopes.push(new Map());
+                this.emitStmtUnchecked(stmt.else.init);
+                this.emitIfChain(stmt.else, 'if');
+                this.scopes.pop();
+                this.depth--;
+                this.emit('}');
+                return;
+            }
+            this.emitIfChain(stmt.else, '} else if');
+            return;
+        }
+        this.emit('} else {');
+        this.emitBlockContents(stmt.else);
+        this.emit('}');
+    }
+
+    /**
+     * Recognize `v, err := f()` (or `=`, or `err := g()`) calling a function that throws in Java,
+     * followed by `if err != nil { ... }` or `return ..., err`
+     * @param next The statement after the call, or the if statement whose header holds it
+     */
+    private matchErrorCall(stmt: GoStmt, next?: GoStmt): ErrorCall | undefined {
+        if (!this.options.handleErrorsAsExceptions || this.options.errorResultRecords) {
+            return undefined;
+        }
+        if (stmt.kind !== 'AssignStmt' || (stmt.tok !== ':=' && stmt.tok !== '=') || stmt.rhs.length !== 1) {
+            return undefined;
+        }
+        const call = stmt.rhs[0];
+        if (call.kind !== 'Call' || call.ellipsis) {
+            return undefined;
+        }
+        const callee = this.resolveCallee(call.fun);
+        const returnTypes = callee?.returnTypes || [];
+        if (!callee || returnTypes.length === 0 || returnTypes.length > 2 || returnTypes.length !== stmt.lhs.length
+            || returnTypes[returnTypes.length - 1].name !== 'error' || returnTypes[0].name === 'error' && returnTypes.length > 1
+            || !stmt.lhs.every(e => e.kind === 'Ident')) {
+            return undefined;
+        }
+        const errTarget = stmt.lhs[stmt.lhs.length - 1];
+        const err = errTarget.kind === 'Ident' ? errTarget.name : '_';
+
+        if (next?.kind === 'IfStmt' && (!next.init || next.init === stmt) && !next.else
+            && err !== '_' && this.isNilCheck(next.cond, err)) {
+            return { assign: stmt, call, callee, err, handler: next.body };
+        }
+        if (next?.kind === 'ReturnStmt' && err !== '_' && this.passesOn(next, err)) {
+            return { assign: stmt, call, callee, err, passOn: next };
+        }
+        // Without a check, only a discarded error can be translated
+        return err === '_' ? { assign: stmt, call, callee, err } : undefined;
+    }
+
+    /**
+     * Whether cond is `err != nil`
+     */
+    private isNilCheck(cond: GoExpr, err: string): boolean {
+        return cond.kind === 'Binary' && cond.op === '!='
+            && cond.x.kind === 'Ident' && cond.x.name === err && this.isNil(cond.y);
+    }
+
+    /**
+     * Whether a handler only passes the error on (`return ..., err`), which the exception does by itself
+     */
+    private propagates(handler: GoBlockStmt, err: string): boolean {
+        return handler.stmts.length === 1 && handler.stmts[0].kind === 'ReturnStmt' && this.passesOn(handler.stmts[0], err);
+    }
+
+    /**
+     * Whether a return statement hands err to the caller as its error result
+     */
+    private passesOn(ret: GoReturnStmt, err: string): boolean {
+        const results = JavaBodyGenerator.resultValues(ret, this.goFunc);
+        const last = results[results.length - 1];
+        return this.goFunc.hasErrorReturn && results.length === this.goFunc.returnTypes.length
+            && last?.kind === 'Ident' && last.name === err;
+    }
+
+    /**
+     * Translate an error-returning call. A propagated error needs nothing: the Java call throws.
+     * An error handled in place becomes a try/catch whose catch block runs the handler,
+     * and a discarded error an empty catch.
+     */
+    private emitErrorCall(errorCall: ErrorCall): void {
+        const { assign, call, callee, err, handler, passOn } = errorCall;
+        const target = assign.lhs.length === 2 && assign.lhs[0].kind === 'Ident' && assign.lhs[0].name !== '_'
+            ? assign.lhs[0].name
+            : undefined;
+        const callCode = this.expr(call);
+
+        // err read later is nil wherever the code after a successful call reads it
+        const errType = this.simpleType('error');
+        const keptErr = errorCall.keepErr ? this.lookup(err) : undefined;
+        const resetErr = () => {
+            if (keptErr) {
+                this.emit(`${this.identifier(err)} = null;`);
+            } else if (errorCall.keepErr) {
+                this.emit(`${this.javaType(errType)} ${this.declare(err, errType).javaName} = null;`);
+            }
+        };
+
+        // When the Java callee cannot throw, err is always nil and its check is dead code
+        const checked = JavaBodyGenerator.canThrow(callee, this.goFile);
+        if (passOn || !checked || (handler && this.propagates(handler, err))) {
+            if (!target) {
+                this.emit(`${callCode};`);
+            } else if (assign.tok === ':=' && !this.scopes[this.scopes.length - 1].has(target)) {
+                const type = callee.returnTypes[0];
+                this.emit(`${this.javaType(type)} ${this.declare(target, type).javaName} = ${callCode};`);
+            } else {
+                this.emit(`${this.identifier(target)} = ${callCode};`);
+            }
+            resetErr();
+            if (passOn) {
+                // Reaching the return means the call succeeded: err is nil there
+                const nil: GoExpr = { kind: 'Ident', name: 'nil', pos: passOn.pos };
+                this.emitReturn([...JavaBodyGenerator.resultValues(passOn, this.goFunc).slice(0, -1), nil]);
+            }
+            return;
+        }
+
+        // Declare the value before the try so it stays visible after it
+        let assignment = `${callCode};`;
+        if (target) {
+            if (assign.tok === ':=' && !this.scopes[this.scopes.length - 1].has(target)) {
+                const type = callee.returnTypes[0];
+                const javaType = this.javaType(type);
+                const variable = this.declare(target, type);
+                // A handler that always leaves keeps the value definitely assigned
+                const zero = handler && this.leaves(handler.stmts) ? '' : ` = ${JavaCodeGenerator.getDefaultValue(javaType, this.options)}`;
+                this.emit(`${javaType} ${variable.javaName}${zero};`);
+            }
+            assignment = `${this.identifier(target)} = ${callCode};`;
+        }
+
+        const exceptionClass = JavaCodeGenerator.getExceptionClass(this.options);
+        if (errorCall.keepErr && !keptErr) {
+            // Declared before the try, the catch below stores the exception into it
+            resetErr();
+        }
+        this.emit('try {');
+        this.depth++;
+        this.emit(assignment);
+        if (keptErr) {
+            resetErr();
+        }
+        this.depth--;
+        if (!handler) {
+            this.emit(`} catch (${exceptionClass} ignored) {`);
+            this.depth++;
+            this.emit('// Error discarded, as in the Go code');
+            this.depth--;
+            this.emit('}');
+            return;
+        }
+        // Java cannot redeclare a visible local such as a named error result: catch into it instead
+        const outer = this.lookup(err);
+        this.scopes.push(new Map());
+        if (outer) {
+            const caught = this.freshName('e');
+            this.emit(`} catch (${exceptionClass} ${caught}) {`);
+            this.depth++;
+            this.emit(`${outer.javaName} = ${caught};`);
+            this.depth--;
+        } else {
+            const caught = this.declare(err, this.simpleType('error'));
+            this.emit(`} catch (${exceptionClass} ${caught.javaName}) {`);
+        }
+        this.emitBlockContents(handler);
+        this.scopes.pop();
+        this.emit('}');
+    }
+
+    /**
+     * Whether a statement list always ends by leaving it: return, break, continue or panic
+     */
+    private leaves(stmts: GoStmt[]): boolean {
+        const last = stmts[stmts.length - 1];
+        if (!last) {
+            return false;
+        }
+        return last.kind === 'ReturnStmt'
+            || (last.kind === 'BranchStmt' && (last.tok === 'break' || last.tok === 'continue'))
+            || (last.kind === 'ExprStmt' && this.isBuiltinCall(last.x, 'panic'));
+    }
+
+    /**
+     * Whether an expression calls the builtin of that name, not shadowed by a local
+     */
+    private isBuiltinCall(e: GoExpr, name: string): e is GoCallExpr {
+        return e.kind === 'Call' && e.fun.kind === 'Ident' && e.fun.name === name && !this.lookup(name);
+    }
+
+    /**
+     * panic(v) throws an unchecked exception with v's text, which a recover() catches
+     */
+    private emitPanic(call: GoCallExpr): void {
+        if (call.args.length !== 1) {
+            throw new UnsupportedConstructError('panic takes exactly one argument');
+        }
+        const arg = call.args[0];
+        const message = this.isStringType(this.typeOf(arg)) ? this.expr(arg)
+            : this.isErrorValue(arg) ? this.printed(arg)
+            : `String.valueOf(${this.printed(arg)})`;
+        this.emit(`throw new RuntimeException(${message});`);
+    }
+
+    /**
+     * `go f(x)` submits the call to the class's executor, `executor.submit(() -> f(x));`, and
+     * `go func() { ... }()` the literal's body. Go evaluates the arguments when the goroutine
+     * starts, so those that can change later are saved in final locals first. A task that
+     * blocks on a channel returns null, which makes it a Callable that may throw the
+     * InterruptedException of put and take.
+     */
+    private emitGoroutine(stmt: GoGoStmt): void {
+        const call = stmt.call;
+        if (call.kind !== 'Call') {
+            throw new UnsupportedConstructError('go of something other than a call is not converted yet');
+        }
+        let task: string;
+        let blocks: boolean;
+        if (call.fun.kind === 'FuncLit') {
+            if (call.args.length > 0) {
+                throw new UnsupportedConstructError('Goroutines running a function literal with arguments are not converted yet');
+            }
+            task = this.lambda(call.fun);
+            blocks = JavaBodyGenerator.blocksIn(call.fun.body.stmts, this.enclosing, this.goFile, JavaBodyGenerator.blockingAnalysis(this.goFile));
+        } else {
+            const args = call.args.map(arg => this.startValue(arg, 'arg'));
+            const fun = call.fun.kind === 'Selector' && call.fun.x.kind === 'Ident' && this.lookup(call.fun.x.name)
+                ? { ...call.fun, x: this.startValue(call.fun.x, call.fun.x.name) }
+                : call.fun;
+            task = `() -> ${this.expr({ ...call, fun, args })}`;
+            const callee = this.resolveCallee(call.fun);
+            blocks = !!callee && JavaBodyGenerator.blocksOnChannels(callee, this.goFile);
+        }
+        if (blocks) {
+            task = this.callable(task);
+        }
+        this.reportDegraded('Goroutine', 'Goroutines run as tasks of a shared ExecutorService; the program does not wait for them at exit, and a panic inside one is kept in its Future instead of crashing the program', stmt.pos);
+        this.emit(`executor.submit(${task});`);
+    }
+
+    /**
+     * The value of a goroutine or deferred call argument, saved in a final local when it may
+     * change before the task runs or is not effectively final, as Java lambdas require
+     */
+    private startValue(arg: GoExpr, base: string): GoExpr {
+        const local = arg.kind === 'Ident' ? this.lookup(arg.name) : undefined;
+        if (this.isConstant(arg) || (arg.kind === 'Ident' && (local ? !local.boxed && !this.reassigned.has(arg.name) : ['nil', 'true', 'false'].includes(arg.name)))) {
+            return arg;
+        }
+        const type = this.typeOf(arg);
+        if (!type) {
+            throw new UnsupportedConstructError('Goroutine or deferred call argument of unknown type is not converted yet');
+        }
+        const name = this.freshName(base);
+        this.emit(`final ${this.javaType(type)} ${this.declare(name, type).javaName} = ${this.expr(arg)};`);
+        return { kind: 'Ident', name, pos: arg.pos };
+    }
+
+    /**
+     * Turn a task lambda into a Callable by returning null from it, so that it may throw
+     */
+    private callable(lambda: string): string {
+        const [params, body] = [lambda.slice(0, lambda.indexOf(' -> ')), lambda.slice(lambda.indexOf(' -> ') + 4)];
+        const indent = INDENT.repeat(this.depth + 1);
+        if (!body.startsWith('{')) {
+            return `${params} -> {\n${indent}${body};\n${indent}return null;\n${INDENT.repeat(this.depth)}}`;
+        }
+        const lines = body.split('\n').map(line => /^\s*return;$/.test(line) ? line.replace('return;', 'return null;') : line);
+        return `${params} -> ${[...lines.slice(0, -1), `${indent}return null;`, lines[lines.length - 1]].join('\n')}`;
+    }
+
+    /**
+     * `ch <- v` puts v into the queue, waiting like Go for room in a buffered channel or
+     * for a receiver of an unbuffered one
+     */
+    private send(ch: GoExpr, value: GoExpr): string {
+        if (!this.options.experimentalConcurrency) {
+            throw new UnsupportedConstructError('Channel sends are only converted with experimental concurrency support');
+        }
+        const element = this.channelElement(ch);
+        return `${this.expr(ch, PRIMARY_PRECEDENCE)}.put(${element ? this.exprAs(value, element) : this.expr(value)})`;
+    }
+
+    /**
+     * `<-ch` takes the next value from the queue, waiting until a sender puts one
+     */
+    private receive(ch: GoExpr): string {
+        if (!this.options.experimentalConcurrency) {
+            throw new UnsupportedConstructError('Channel receives are only converted with experimental concurrency support');
+        }
+        return `${this.expr(ch, PRIMARY_PRECEDENCE)}.take()`;
+    }
+
+    /**
+     * close(ch) has no BlockingQueue counterpart: the call is kept as a comment, with the
+     * usual replacement of a sentinel value the receivers stop at
+     */
+    private emitClose(call: GoCallExpr): void {
+        if (!this.options.experimentalConcurrency) {
+            throw new UnsupportedConstructError('Closing channels is only converted with experimental concurrency support');
+        }
+        const ch = call.args.length === 1 ? this.expr(call.args[0]) : '';
+        this.reportDegraded('ChannelClose', 'Java queues cannot be closed; receivers waiting on the channel wait forever unless the sender puts a sentinel value they stop at', call.pos);
+        this.emit(`// Warning: close(${ch}) has no BlockingQueue equivalent; put a sentinel value the receivers stop at, or interrupt them`);
+    }
+
+    /**
+     * Element type of a channel-typed expression, or undefined when it is no channel
+     */
+    private channelElement(ch: GoExpr): GoType | undefined {
+        const type = this.typeOf(ch);
+        return type && GoFunctionParser.channelElement(type);
+    }
+
+    /**
+     * Run the statements after a defer in `try`, and the deferred call in `finally`.
+     * Later defers nest inside, so the calls run in reverse order. A deferred recover()
+     * becomes a `catch (RuntimeException r)` instead, after which the function returns
+     * its named results as they stand, or the zero values of unnamed ones, like Go after
+     * a recovered panic.
+     */
+    private emitDefer(stmt: GoDeferStmt, rest: GoStmt[]): void {
+        const mark = this.lines.length;
+        let deferred: string | GoBlockStmt | RecoverHandler;
+        try {
+            deferred = this.deferredCall(stmt, rest);
+        } catch (error) {
+            if (!(error instanceof UnsupportedConstructError)) {
+                throw error;
+            }
+            this.lines.length = mark;
+            this.skipCommentsBefore(stmt.span[1]);
+            this.emitUnsupported(stmt, error.message);
+            this.emitStmts(rest, true);
+            return;
+        }
+
+        // Comments in the deferred call stay where the defer was
+        this.emitCommentsBefore(stmt.span[1]);
+        this.emit('try {');
+        this.emitBlockContents({ kind: 'BlockStmt', stmts: rest, pos: stmt.pos, span: stmt.span }, true);
+        // The try ends the function, so a trailing bare return is implicit here too
+        if (this.lines[this.lines.length - 1] === `${INDENT.repeat(this.depth + 1)}return;`) {
+            this.lines.pop();
+        }
+        if (typeof deferred !== 'string' && deferred.kind === 'Recover') {
+            this.scopes.push(new Map());
+            const caught = this.freshName(deferred.name || 'ignored');
+            if (deferred.name) {
+                this.scopes[this.scopes.length - 1].set(deferred.name, { javaName: caught, type: this.simpleType('any'), recovered: true });
+            }
+            this.emit(`} catch (RuntimeException ${caught}) {`);
+            if (deferred.stmts.length === 0) {
+                this.depth++;
+                this.emit('// Panic discarded, as in the Go code');
+                this.depth--;
+            }
+            this.emitBlockContents({ kind: 'BlockStmt', stmts: deferred.stmts, pos: stmt.pos, span: stmt.span });
+            this.scopes.pop();
+            this.emit('}');
+            if (this.goFunc.returnTypes.length > 0) {
+                this.emitStmt({ kind: 'ReturnStmt', results: deferred.results, pos: stmt.pos, span: stmt.span });
+            }
+            return;
+        }
+        this.emit('} finally {');
+        if (typeof deferred === 'string') {
+            this.depth++;
+            this.emit(`${deferred};`);
+            this.depth--;
+        } else {
+            this.emitBlockContents(deferred);
+        }
+        this.emit('}');
+    }
+
+    /**
+     * Whether a defer sits inside a loop or block rather than directly in the function body
+     */
+    private static defersInBlocks(stmts: GoStmt[]): boolean {
+        let nested = false;
+        walkStmts(stmts, s => nested = nested || (s.kind === 'DeferStmt' && !stmts.includes(s)));
+        return nested;
+    }
+
+    /**
+     * The body of a function deferring inside a loop or block, which may defer any number
+     * of times: each defer adds its call to a list, and a `finally` runs the list backwards
+     * when the function returns, as Go runs its deferred calls. The list holds Callables
+     * when the method throws, so that the calls may too, and Runnables otherwise.
+     */
+    private emitDeferList(stmts: GoStmt[]): void {
+        const callable = JavaCodeGenerator.throwsErrors(this.goFunc, this.options, this.goFile);
+        const name = this.freshName('deferred');
+        this.scopes[this.scopes.length - 1].set(name, { javaName: name, type: this.simpleType('any') });
+        this.emit(`List<${callable ? 'Callable<Void>' : 'Runnable'}> ${name} = new ArrayList<>();`);
+        this.emit('try {');
+        this.deferList = { name, callable };
+        const last = stmts[stmts.length - 1];
+        this.emitBlockContents({ kind: 'BlockStmt', stmts, pos: stmts[0].pos, span: [stmts[0].span[0], last.span[1]] }, true);
+        this.deferList = undefined;
+        if (this.lines[this.lines.length - 1] === `${INDENT.repeat(this.depth + 1)}return;`) {
+            this.lines.pop();
+        }
+        const i = this.freshName('i');
+        this.emit('} finally {');
+        this.emit(`    for (int ${i} = ${name}.size() - 1; ${i} >= 0; ${i}--) {`);
+        this.emit(`        ${name}.get(${i}).${callable ? 'call' : 'run'}();`);
+        this.emit('    }');
+        this.emit('}');
+    }
+
+    /**
+     * `defer f(x)` in a function collecting its deferred calls: `deferred.add(() -> f(x));`.
+     * Go evaluates the arguments and receiver at the defer, so those that can change
+     * later, like a loop's counter, are saved in final locals first, one per iteration.
+     */
+    private emitDeferredTask(stmt: GoDeferStmt, list: { name: string; callable: boolean }): void {
+        const call = stmt.call;
+        if (call.kind !== 'Call') {
+            throw new UnsupportedConstructError('Deferred expression is not a call');
+        }
+        let task: string;
+        if (call.fun.kind === 'FuncLit') {
+            const body = call.fun.body.stmts;
+            if (call.args.length > 0) {
+                throw new UnsupportedConstructError('Deferred function literal with arguments is not converted yet');
+            }
+            if (this.mentions(body, 'recover') && !this.lookup('recover')) {
+                throw new UnsupportedConstructError('recover() in a function deferring inside a loop or block is not converted yet');
+            }
+            const result = (this.goFunc.returnNames || []).find(n => n && !this.isStable({ kind: 'Ident', name: n, pos: stmt.pos }, body));
+            if (result) {
+                throw new UnsupportedConstructError(`Deferred function literal assigning result '${result}' is not converted yet`);
+            }
+            task = this.lambda(call.fun);
+        } else {
+            const args = call.args.map(arg => this.startValue(arg, 'arg'));
+            const fun = call.fun.kind === 'Selector' && call.fun.x.kind === 'Ident' && this.lookup(call.fun.x.name)
+                ? { ...call.fun, x: this.startValue(call.fun.x, call.fun.x.name) }
+                : call.fun;
+            task = `() -> ${this.expr({ ...call, fun, args })}`;
+        }
+        this.emit(`${list.name}.add(${list.callable ? this.callable(task) : task});`);
+    }
+
+    /**
+     * The Java call a defer runs, with the arguments Go evaluates at the defer copied into
+     * final locals first; `defer func() { ... }()` yields the closure body instead, whose
+     * captured variables Go reads when it runs, or its recover handler
+     */
+    private deferredCall(stmt: GoDeferStmt, rest: GoStmt[]): string | GoBlockStmt | RecoverHandler {
+        const call = stmt.call;
+        if (call.kind !== 'Call') {
+            throw new UnsupportedConstructError('Deferred expression is not a call');
+        }
+        if (call.fun.kind === 'FuncLit') {
+            const body = call.fun.body;
+            let returns = false;
+            walkStmts(body.stmts, s => returns = returns || s.kind === 'ReturnStmt');
+            if (call.args.length > 0 || returns) {
+                throw new UnsupportedConstructError('Deferred function literal with arguments or returns is not converted yet');
+            }
+            if (this.mentions(body.stmts, 'recover') && !this.lookup('recover')) {
+                return this.recoverHandler(body.stmts);
+            }
+            // A Java finally runs after the return value is taken, so it cannot change the result
+            const result = (this.goFunc.returnNames || []).find(n => n && !this.isStable({ kind: 'Ident', name: n, pos: stmt.pos }, body.stmts));
+            if (result) {
+                throw new UnsupportedConstructError(`Deferred function literal assigning result '${result}' is not converted yet`);
+            }
+            return body;
+        }
+
+        const args = call.args.map(arg => {
+            if (this.isStable(arg, rest)) {
+                return arg;
+            }
+            const type = this.typeOf(arg);
+            if (!type) {
+                throw new UnsupportedConstructError('Deferred call argument of unknown type is not converted yet');
+            }
+            const name = this.freshName('deferred');
+            this.emit(`final ${this.javaType(type)} ${this.declare(name, type).javaName} = ${this.expr(arg)};`);
+            return { kind: 'Ident', name, pos: arg.pos } as GoExpr;
+        });
+        let fun = call.fun;
+        if (fun.kind === 'Selector' && !this.isStable(fun.x, rest) && fun.x.kind === 'Ident' && this.lookup(fun.x.name)) {
+            // `defer f.Close()` closes the f of the defer even if f is reassigned later
+            const type = this.lookup(fun.x.name)!.type;
+            if (!type) {
+                throw new UnsupportedConstructError('Deferred call on a receiver of unknown type is not converted yet');
+            }
+            const name = this.freshName('deferred');
+            this.emit(`final ${this.javaType(type)} ${this.declare(name, type).javaName} = ${this.expr(fun.x)};`);
+            fun = { ...fun, x: { kind: 'Ident', name, pos: fun.x.pos } };
+        }
+        return this.expr({ ...call, fun, args });
+    }
+
+    /**
+     * Handler of a deferred closure that recovers: `recover()` alone, `if recover() != nil { ... }`,
+     * `if r := recover(); r != nil { ... }`, or `r := recover()` followed by that check.
+     * Go also runs the closure without a panic, where recover() returns nil, so any other
+     * statement outside the check would be lost in a catch.
+     */
+    private recoverHandler(stmts: GoStmt[]): RecoverHandler {
+        const isRecover = (e: GoExpr) => this.isBuiltinCall(e, 'recover') && e.args.length === 0;
+        const binding = (s?: GoStmt) => s?.kind === 'AssignStmt' && s.tok === ':=' && s.lhs.length === 1
+            && s.lhs[0].kind === 'Ident' && s.rhs.length === 1 && isRecover(s.rhs[0]) ? s.lhs[0].name : undefined;
+        // `if <x> != nil { ... }` without init or else, where x is the named variable or recover() itself
+        const check = (s: GoStmt | undefined, name?: string) => s?.kind === 'IfStmt' && !s.else
+            && (!s.init || !!name) && s.cond.kind === 'Binary' && s.cond.op === '!='
+            && s.cond.y.kind === 'Ident' && s.cond.y.name === 'nil'
+            && (name ? s.cond.x.kind === 'Ident' && s.cond.x.name === name : isRecover(s.cond.x))
+            ? s.body.stmts : undefined;
+
+        const [first, second] = stmts;
+        let handler: RecoverHandler | undefined;
+        const names = this.goFunc.returnNames;
+        // Go returns the results as they stand, which for unnamed ones are their zero values
+        const results = names && names.every(n => n) ? [] : this.goFunc.returnTypes.map(t => this.zeroResult(t));
+        if (stmts.length === 1 && first.kind === 'ExprStmt' && isRecover(first.x)) {
+            handler = { kind: 'Recover', stmts: [], results };
+        } else if (stmts.length === 1 && first.kind === 'IfStmt') {
+            const name = binding(first.init);
+            const body = first.init && !name ? undefined : check(first, name);
+            handler = body && { kind: 'Recover', name, stmts: body, results };
+        } else if (stmts.length === 2 && binding(first)) {
+            const body = check(second, binding(first));
+            handler = body && { kind: 'Recover', name: binding(first), stmts: body, results };
+        }
+        if (!handler || this.mentions(handler.stmts, 'recover')) {
+            throw new UnsupportedConstructError('recover() outside `if r := recover(); r != nil { ... }` is not converted yet');
+        }
+        return handler;
+    }
+
+    /**
+     * The zero value of a type as a Go expression: `0`, `""`, `false`, `T{}` for a struct
+     * held by value, and `nil` for the rest
+     */
+    private zeroResult(type: GoType): GoExpr {
+        const javaType = this.javaType(type);
+        const struct = !type.isPointer && !type.isSlice && !type.isMap && this.structOf(this.underlying(type));
+        if (struct && type.typeArgs?.length) {
+            throw new UnsupportedConstructError(`Zero value of generic struct ${type.name} is not converted yet`);
+        }
+        const text = JAVA_NUMERIC_TYPES.has(javaType) ? '0'
+            : javaType === 'boolean' ? 'false'
+            : javaType === 'String' ? '""'
+            : struct ? `${type.name}{}`
+            : 'nil';
+        return GoBodyParser.parseExpression(text);
+    }
+
+    /**
+     * Whether an expression has the same value at the end of the function as now:
+     * constants, and locals the remaining statements never assign
+     */
+    private isStable(e: GoExpr, rest: GoStmt[]): boolean {
+        if (this.isConstant(e)) {
+            return true;
+        }
+        if (e.kind !== 'Ident') {
+            return false;
+        }
+        if (!this.lookup(e.name)) {
+            return ['nil', 'true', 'false'].includes(e.name);
+        }
+        const assigns = (target?: GoExpr) => target?.kind === 'Ident' && target.name === e.name;
+        let assigned = false;
+        walkStmts(rest, s => {
+            if (s.kind === 'AssignStmt') {
+                assigned = assigned || s.lhs.some(assigns);
+            } else if (s.kind === 'IncDecStmt') {
+                assigned = assigned || assigns(s.x);
+            } else if (s.kind === 'RangeStmt' && s.tok === '=') {
+                assigned = assigned || assigns(s.key) || assigns(s.value);
+            }
+        });
+        return !assigned;
+    }
+
+    /**
+     * A check failing the test when it holds, `if got != want { t.Errorf(...) }`, as the JUnit
+     * assertion of the opposite: `assertEquals(want, got, String.format(...))`. Recognizes
+     * (in)equality, also `!reflect.DeepEqual(got, want)`, nil checks and negated conditions.
+     * The expected value is the right operand, unless only the left one looks like it.
+     */
+    private assertion(stmt: GoIfStmt): string | undefined {
+        const only = stmt.body.stmts.length === 1 ? stmt.body.stmts[0] : undefined;
+        if (stmt.else || only?.kind !== 'ExprStmt' || !this.isTestingCall(only.x, 'Error', 'Errorf', 'Fatal', 'Fatalf')) {
+            return undefined;
+        }
+        const message = this.failureMessage(only.x);
+        const assert = (name: string, ...args: string[]) => `${name}(${[...args, ...(message ? [message] : [])].join(', ')})`;
+        const unparen = (e: GoExpr): GoExpr => e.kind === 'Paren' ? unparen(e.x) : e;
+        const expectedFirst = (x: GoExpr, y: GoExpr): [string, string] => {
+            const [actual, expected] = this.isExpectedValue(x) && !this.isExpectedValue(y) ? [y, x] : [x, y];
+            const actualType = this.typeOf(actual);
+            return [actualType ? this.exprAs(expected, actualType) : this.expr(expected), this.expr(actual)];
+        };
+
+        const cond = unparen(stmt.cond);
+        if (cond.kind === 'Binary' && (cond.op === '==' || cond.op === '!=')) {
+            if (this.isNil(cond.x) || this.isNil(cond.y)) {
+                return assert(cond.op === '!=' ? 'assertNull' : 'assertNotNull', this.expr(this.isNil(cond.y) ? cond.x : cond.y));
+            }
+            return assert(cond.op === '!=' ? 'assertEquals' : 'assertNotEquals', ...expectedFirst(cond.x, cond.y));
+        }
+        if (cond.kind === 'Unary' && cond.op === '!') {
+            const x = unparen(cond.x);
+            if (x.kind === 'Call' && this.isPackageCall(x, 'reflect', 'DeepEqual') && x.args.length === 2) {
+                const type = this.typeOf(x.args[0]) || this.typeOf(x.args[1]);
+                return assert(type?.isSlice && !this.isList(type) ? 'assertArrayEquals' : 'assertEquals', ...expectedFirst(x.args[0], x.args[1]));
+            }
+            return assert('assertTrue', this.expr(x));
+        }
+        return undefined;
+    }
+
+    /**
+     * Whether an operand reads as the expected value of a check: a constant, or named
+     * like one (want, expected, tt.want)
+     */
+    private isExpectedValue(e: GoExpr): boolean {
+        const name = e.kind === 'Ident' ? e.name : e.kind === 'Selector' ? e.sel : '';
+        return this.isConstant(e) || /^(want|expect|exp$)/i.test(name);
+    }
+
+    /**
+     * A call of a *testing.T method in junit mode, any method when none is given
+     */
+    private isTestingCall(e: GoExpr, ...methods: string[]): e is GoCallExpr & { fun: GoSelectorExpr } {
+        return !!this.options.junit && e.kind === 'Call' && e.fun.kind === 'Selector'
+            && (methods.length === 0 || methods.includes(e.fun.sel))
+            && JavaCodeGenerator.isTestingT(this.typeOf(e.fun.x));
+    }
+
+    /**
+     * Message of a failing t.Error or t.Fatal call for JUnit: the f variants format it,
+     * the others join their operands with spaces the way fmt.Sprintln does.
+     * Empty when the call has no operands.
+     */
+    private failureMessage(call: GoCallExpr & { fun: GoSelectorExpr }): string {
+        if (call.ellipsis) {
+            throw new UnsupportedConstructError('Spread arguments of a test failure are not converted yet');
+        }
+        if (call.args.length === 0) {
+            return '';
+        }
+        if (call.fun.sel.endsWith('f')) {
+            const [format, ...args] = call.args;
+            return args.length === 0 && format.kind === 'BasicLit' && !format.value.includes('%')
+                ? this.expr(format)
+                : this.stringFormat(format, args);
+        }
+        return this.spaceJoined(call.args);
+    }
+
+    /**
+     * One String of values separated by spaces, as Go's print functions write them;
+     * an error contributes its message
+     */
+    private spaceJoined(args: GoExpr[]): string {
+        const operands = args.map((arg, i) => {
+            if (this.isErrorValue(arg)) {
+                return `${this.expr(arg, PRIMARY_PRECEDENCE)}.getMessage()`;
+            }
+            if (i === 0 && !this.isStringType(this.typeOf(arg))) {
+                return `String.valueOf(${this.printed(arg)})`;
+            }
+            return this.printed(arg, i === 0 ? 0 : JAVA_PRECEDENCE['+'] + 1);
+        });
+        // The separating space goes into a neighbouring string literal: "n =" + " " + n → "n = " + n
+        const isLiteral = (code: string) => /^"([^"\\]|\\.)*"$/.test(code);
+        return operands.reduce((joined, operand, i) => {
+            if (i === 0) {
+                return operand;
+            }
+            if (isLiteral(operands[i - 1]) && joined.endsWith('"')) {
+                return `${joined.slice(0, -1)} "${isLiteral(operand) ? operand.slice(1) : ` + ${operand}`}`;
+            }
+            return isLiteral(operand) ? `${joined} + " ${operand.slice(1)}` : `${joined} + " " + ${operand}`;
+        }, '');
+    }
+
+    private isErrorValue(e: GoExpr): boolean {
+        const type = this.typeOf(e);
+        return type?.name === 'error' && !type.isSlice && !type.isMap;
+    }
+
+    /**
+     * A subtest outside a test table, `t.Run("empty", func(t *testing.T) { ... })`: JUnit has
+     * no subtests, so its statements run in a block of the test, after a comment with its name
+     */
+    private emitSubtest(call: GoCallExpr & { fun: GoSelectorExpr }): void {
+        const subtest = call.fun.x.kind === 'Ident' ? JavaBodyGenerator.subtest(call, call.fun.x.name) : undefined;
+        if (!subtest) {
+            throw new UnsupportedConstructError('Subtests running a function value are not converted yet');
+        }
+        let returns = false;
+        walkStmts(subtest.fn.body.stmts, s => returns = returns || s.kind === 'ReturnStmt');
+        if (returns) {
+            // A return would end the whole test, not just the subtest
+            throw new UnsupportedConstructError('Subtests that return early are not converted yet');
+        }
+        this.emit(`// Subtest ${this.expr(subtest.name)}`);
+        this.emit('{');
+        this.depth++;
+        this.scopes.push(new Map());
+        const t = subtest.fn.type.params![0];
+        if (t.name && t.name !== '_') {
+            this.declare(t.name, t.type);
+        }
+        this.emitStmts(subtest.fn.body.stmts);
+        this.emitCommentsBefore(subtest.fn.body.span[1]);
+        this.scopes.pop();
+        this.depth--;
+        this.emit('}');
+    }
+
+    /**
+     * A test table that is not run as a parameterized test: a local record for its rows
+     * (a local class before Java 16) and a list of them,
+     * `record Case(String in, int want) {}` and `List<Case> tests = List.of(new Case("a", 1), ...)`
+     */
+    private emitTestTable(name: string, table: TestTable): void {
+        let typeName = 'Case';
+        for (let n = 2; this.findStruct(typeName); n++) {
+            typeName = `Case${n}`;
+        }
+        const names = table.fields.map(f => JavaCodeGenerator.memberName(f.name, this.options));
+        const components = table.fields.map((f, i) => `${this.javaType(f.type)} ${names[i]}`).join(', ');
+        const javaVersion = this.options.javaVersion || DEFAULT_JAVA_VERSION;
+        if (javaVersion >= 16) {
+            this.emit(`record ${typeName}(${components}) {}`);
+        } else {
+            this.emit(`class ${typeName} {`);
+            this.depth++;
+            table.fields.forEach((f, i) => this.emit(`final ${this.javaType(f.type)} ${names[i]};`));
+            this.emit(`${typeName}(${components}) {`);
+            this.depth++;
+            names.forEach(n => this.emit(`this.${n} = ${n};`));
+            this.depth--;
+            this.emit('}');
+            this.depth--;
+            this.emit('}');
+        }
+        this.localStructs.push({
+            name: typeName,
+            fields: table.fields.map(f => ({ name: f.name, type: f.type, exported: /^[A-Z]/.test(f.name) })),
+            methods: []
+        });
+
+        const rowType = this.simpleType(typeName);
+        const tableType: GoType = { ...rowType, isSlice: true, elementType: rowType };
+        const rows = table.rows.map(row => `new ${typeName}(${row.map((v, i) => this.fieldValue(v, table.fields[i].type)).join(', ')})`);
+        const list = this.isList(tableType);
+        const javaName = this.declare(name, tableType).javaName;
+        this.emit(`${this.javaType(tableType)} ${javaName} = ${list ? (javaVersion >= 9 ? 'List.of' : 'Arrays.asList') : `new ${typeName}[] `}${list ? '(' : '{'}`);
+        this.depth += 2;
+        rows.forEach((row, i) => this.emit(`${row}${i < rows.length - 1 ? ',' : ''}`));
+        this.depth -= 2;
+        this.emit(list ? ');' : '};');
+    }
+
+    /**
+     * A field value of a struct literal row, the zero value where the row leaves it out.
+     * Left-out collections start empty with emptyCollections, as the field initializers do.
+     */
+    private fieldValue(value: GoExpr | undefined, type: GoType): string {
+        if (value) {
+            return this.exprAs(value, type);
+        }
+        const javaType = this.javaType(type);
+        const empty = JavaCodeGenerator.getDefaultValue(javaType, this.options);
+        return this.structZero(type) ?? (empty.startsWith('new ') ? empty : this.zeroElement(javaType));
+    }
+
+    /**
+     * Zero value of a struct type held by value, which Go never leaves nil: the literal `T{}`
+     */
+    private structZero(type: GoType): string | undefined {
+        if (type.isPointer || type.isSlice || type.isMap || !this.structOf(this.underlying(type))) {
+            return undefined;
+        }
+        return this.compositeLit({ kind: 'CompositeLit', elts: [], pos: { line: 0, character: 0 } }, type);
+    }
+
+    private emitFor(stmt: GoForStmt): void {
+        this.scopes.push(new Map());
+        if (!stmt.init && !stmt.post) {
+            const cond = stmt.cond ? this.expr(stmt.cond) : 'true';
+            this.emit(`while (${cond}) {`);
+        } else {
+            const init = stmt.init ? this.inlineStmt(stmt.init) : '';
+            const cond = stmt.cond ? this.expr(stmt.cond) : '';
+            const post = stmt.post ? this.inlineStmt(stmt.post) : '';
+            this.emit(`for (${init}; ${cond}; ${post}) {`);
+        }
+        this.emitBlockContents(stmt.body);
+        this.emit('}');
+        this.scopes.pop();
+    }
+
+    private emitRange(stmt: GoRangeStmt): void {
+        if (stmt.tok === '=' && [stmt.key, stmt.value].some(e => e && !(e.kind === 'Ident' && e.name === '_'))) {
+            throw new UnsupportedConstructError('Range loops assigning to existing variables are not converted yet');
+        }
+        const table = this.options.junit ? JavaBodyGenerator.testTable(stmt.x) : undefined;
+        if (table) {
+            // The rows of a table ranged over in place get a local of their own
+            this.emitScoped(true, () => {
+                const name = this.freshName('tests');
+                this.emitTestTable(name, table);
+                this.emitRange({ ...stmt, x: { kind: 'Ident', name, pos: stmt.x.pos } });
+            });
+            return;
+        }
+        const rangeType = this.typeOf(stmt.x);
+        if (!rangeType) {
+            throw new UnsupportedConstructError('Range over a value with unknown type is not converted yet');
+        }
+        const key = this.rangeVariable(stmt.key);
+        const value = this.rangeVariable(stmt.value);
+        const x = this.expr(stmt.x, PRIMARY_PRECEDENCE);
+
+        this.scopes.push(new Map());
+        const bodyLines: string[] = [];
+        if (rangeType.isMap && rangeType.keyType && rangeType.valueType) {
+            const keyJava = JavaCodeGenerator.toJavaType(rangeType.keyType, this.options, true);
+            const valueJava = JavaCodeGenerator.toJavaType(rangeType.valueType, this.options, true);
+            if (key && value) {
+                const entry = this.freshName('entry');
+                this.emit(`for (Map.Entry<${keyJava}, ${valueJava}> ${entry} : ${x}.entrySet()) {`);
+                bodyLines.push(`${keyJava} ${this.declare(key, rangeType.keyType).javaName} = ${entry}.getKey();`);
+                bodyLines.push(`${valueJava} ${this.declare(value, rangeType.valueType).javaName} = ${entry}.getValue();`);
+            } else if (value) {
+                this.emit(`for (${valueJava} ${this.declare(value, rangeType.valueType).javaName} : ${x}.values()) {`);
+            } else {
+                const name = key ? this.declare(key, rangeType.keyType).javaName : this.freshName('ignored');
+                this.emit(`for (${keyJava} ${name} : ${x}.keySet()) {`);
+            }
+        } else if (rangeType.isSlice || this.isStringType(rangeType)) {
+            const elementType = rangeType.isSlice ? GoFunctionParser.elementTypeOf(rangeType) : this.simpleType('rune');
+            if (value && !key) {
+                // Value-only ranges need no index: use an enhanced for loop
+                const elements = rangeType.isSlice ? x : `${x}.toCharArray()`;
+                this.emit(`for (${this.javaType(elementType)} ${this.declare(value, elementType).javaName} : ${elements}) {`);
+            } else {
+                if (!rangeType.isSlice) {
+                    // Go yields byte offsets of runes, which differ from char indices for non-ASCII text
+                    throw new UnsupportedConstructError('Indexed range over a string is not converted yet');
+                }
+                const index = key ? this.declare(key, this.simpleType('int')).javaName : this.freshName('i');
+                this.emit(`for (int ${index} = 0; ${index} < ${this.len(stmt.x)}; ${index}++) {`);
+                if (value) {
+                    const element = this.isList(rangeType) ? `${x}.get(${index})` : `${x}[${index}]`;
+                    bodyLines.push(`${this.javaType(elementType)} ${this.declare(value, elementType).javaName} = ${element};`);
+                }
+            }
+        } else if (!value && INTEGER_RANGE_TYPES.has(rangeType.name) && !rangeType.isPointer) {
+            // Go 1.22 range over an integer counts from 0 to n-1, evaluating n once
+            const indexJava = this.javaType(rangeType);
+            const local = stmt.x.kind === 'Ident' ? this.lookup(stmt.x.name) : undefined;
+            const stable = this.isConstant(stmt.x)
+                || (!!local && !local.boxed && !JavaBodyGenerator.assignedNames(stmt.body.stmts).has((stmt.x as GoIdent).name));
+            // Each Go iteration has its own variable, so one the body changes or a closure
+            // captures is a copy of the counter
+            const copied = !!key && (JavaBodyGenerator.assignedNames(stmt.body.stmts).has(key)
+                || this.capturedIn(stmt.body.stmts, key));
+            const variable = key ? this.declare(key, rangeType).javaName : undefined;
+            const index = variable && !copied ? variable : this.freshName(variable || 'i');
+            const end = stable ? x : this.freshName('end');
+            const init = stable ? `${index} = 0` : `${index} = 0, ${end} = ${this.expr(stmt.x)}`;
+            this.emit(`for (${indexJava} ${init}; ${index} < ${end}; ${index}++) {`);
+            if (copied) {
+                bodyLines.push(`${indexJava} ${variable} = ${index};`);
+            }
+        } else if (GoFunctionParser.channelElement(rangeType) && !value) {
+            // Go's loop ends when the channel is closed; a queue has no end to reach
+            if (!this.options.experimentalConcurrency) {
+                throw new UnsupportedConstructError('Range over a channel is only converted with experimental concurrency support');
+            }
+            const elementType = GoFunctionParser.channelElement(rangeType)!;
+            this.reportDegraded('ChannelRange', 'A range over a channel ends when the channel is closed; the Java loop only ends at a break, return or interrupt', stmt.pos);
+            this.emit('// Warning: the loop only ends when interrupted; break on a sentinel value where the Go code closes the channel');
+            this.emit('while (!Thread.currentThread().isInterrupted()) {');
+            bodyLines.push(key ? `${this.javaType(elementType)} ${this.declare(key, elementType).javaName} = ${x}.take();` : `${x}.take();`);
+        } else {
+            throw new UnsupportedConstructError(`Range over ${rangeType.name} is not converted yet`);
+        }
+
+        this.depth++;
+        bodyLines.forEach(line => this.emit(line));
+        this.depth--;
+        this.emitBlockContents(stmt.body);
+        this.emit('}');
+        this.scopes.pop();
+    }
+
+    /**
+     * Name of a range key or value variable, or undefined when it is absent or blank
+     */
+    private rangeVariable(e?: GoExpr): string | undefined {
+        if (!e) {
+            return undefined;
+        }
+        if (e.kind !== 'Ident') {
+            throw new UnsupportedConstructError('Range loops assigning to existing variables are not converted yet');
+        }
+        return e.name === '_' ? undefined : e.name;
+    }
+
+    /**
+     * Run an emitter inside a Java block with its own scope when scoped is set
+     */
+    private emitScoped(scoped: boolean, emitter: () => void): void {
+        if (!scoped) {
+            emitter();
+            return;
+        }
+        this.emit('{');
+        this.depth++;
+        this.scopes.push(new Map());
+        emitter();
+        this.scopes.pop();
+        this.depth--;
+        this.emit('}');
+    }
+
+    /**
+     * A switch on a switchable value with constant cases becomes a Java switch; any other
+     * switch (no tag, computed cases, long or struct values) becomes an if/else chain
+     */
+    private emitSwitch(stmt: GoSwitchStmt): void {
+        const tagType = stmt.tag && this.typeOf(stmt.tag);
+        if (stmt.tag && tagType && SWITCHABLE_JAVA_TYPES.has(this.javaType(tagType))
+            && stmt.cases.every(c => c.list.every(e => this.isConstant(e)))) {
+            this.emitJavaSwitch(stmt.tag, stmt.cases);
+            return;
+        }
+        if (stmt.tag && tagType && !tagType.isPointer && !tagType.isSlice && !tagType.isMap
+            && stmt.cases.every(c => c.list.every(e => e.kind === 'Ident' && this.enumOf(e.name) === tagType.name))) {
+            // Java switch labels name enum constants without their type
+            this.emitJavaSwitch(stmt.tag, stmt.cases, e => this.enumConstant((e as GoIdent).name)!.javaName);
+            return;
+        }
+
+        this.checkSwitchChain(stmt.cases);
+        let tag = stmt.tag;
+        const needsCopy = !!tag && tag.kind !== 'Ident' && stmt.cases.some(c => !c.isDefault);
+        this.emitScoped(needsCopy, () => {
+            if (tag && needsCopy) {
+                // Go evaluates the tag once; copy it rather than repeat it in every condition
+                if (!tagType) {
+                    throw new UnsupportedConstructError('Switch on a value with unknown type is not converted yet');
+                }
+                const name = this.freshName('value');
+                this.emit(`${this.javaType(tagType)} ${this.declare(name, tagType).javaName} = ${this.expr(tag)};`);
+                tag = { kind: 'Ident', name, pos: tag.pos };
+            }
+            this.emitClauseChain(stmt.cases, e => tag ? { kind: 'Binary', op: '==', x: tag, y: e, pos: e.pos } : e);
+        });
+    }
+
+    /**
+     * Java switch with a break closing each case; a trailing fallthrough drops the break.
+     * Cases declaring variables get a block, since Java cases share one scope.
+     */
+    private emitJavaSwitch(tag: GoExpr, cases: GoCaseClause[], label = (e: GoExpr) => this.expr(e)): void {
+        this.emit(`switch (${this.expr(tag)}) {`);
+        this.depth++;
+        for (const clause of cases) {
+            const labels = clause.isDefault ? ['default:'] : clause.list.map(e => `case ${label(e)}:`);
+            const last = clause.body[clause.body.length - 1];
+            const fallsThrough = last?.kind === 'BranchStmt' && last.tok === 'fallthrough';
+            const body = fallsThrough ? clause.body.slice(0, -1) : clause.body;
+            const block = body.some(s => (s.kind === 'AssignStmt' && s.tok === ':=') || s.kind === 'DeclStmt');
+            labels.slice(0, -1).forEach(label => this.emit(label));
+            this.emit(block ? `${labels[labels.length - 1]} {` : labels[labels.length - 1]);
+            this.depth++;
+            this.scopes.push(new Map());
+            this.emitStmts(body);
+            if (!fallsThrough && !this.leaves(body)) {
+                this.emit('break;');
+            }
+            this.scopes.pop();
+            this.depth--;
+            if (block) {
+                this.emit('}');
+            }
+        }
+        this.depth--;
+        this.emit('}');
+    }
+
+    /**
+     * Go type switch as an if/else chain of instanceof checks, declaring the bound variable
+     * with a cast in single-type cases and with the switched value's type in the others
+     */
+    private emitTypeSwitch(stmt: GoTypeSwitchStmt): void {
+        this.checkSwitchChain(stmt.cases);
+        const valueType = this.typeOf(stmt.x);
+        const needsCopy = stmt.x.kind !== 'Ident' && stmt.cases.some(c => !c.isDefault);
+        this.emitScoped(needsCopy, () => {
+            let x = stmt.x;
+            if (needsCopy) {
+                const name = this.freshName('value');
+                const javaType = valueType ? this.javaType(valueType) : 'Object';
+                this.emit(`${javaType} ${this.declare(name, valueType).javaName} = ${this.expr(x)};`);
+                x = { kind: 'Ident', name, pos: x.pos };
+            }
+            const subject = x;
+            this.emitClauseChain(stmt.cases, e => e, (e: GoExpr) => {
+                if (e.kind !== 'TypeExpr') {
+                    return `${this.expr(subject, JAVA_PRECEDENCE['=='] + 1)} == null`;
+                }
+                return this.instanceOf(subject, e.type);
+            }, clause => {
+                if (!stmt.bind || !this.mentions(clause.body, stmt.bind)) {
+                    return;
+                }
+                const caseType = clause.list.length === 1 && clause.list[0].kind === 'TypeExpr' ? clause.list[0].type : undefined;
+                if (caseType) {
+                    this.emit(`${this.javaType(caseType)} ${this.declare(stmt.bind, caseType).javaName} = ${this.cast(subject, caseType)};`);
+                } else {
+                    const javaType = valueType ? this.javaType(valueType) : 'Object';
+                    this.emit(`${javaType} ${this.declare(stmt.bind, valueType).javaName} = ${this.expr(subject)};`);
+                }
+            });
+        });
+    }
+
+    /**
+     * Whether x holds a value of a Go type; instanceof takes the boxed raw type: List<String> → List
+     */
+    private instanceOf(x: GoExpr, type: GoType): string {
+        const boxed = JavaCodeGenerator.toJavaType(type, this.options, true).replace(/<.*>$/, '');
+        return `${this.expr(x, JAVA_PRECEDENCE['<'])} instanceof ${boxed}`;
+    }
+
+    /**
+     * Cast to the boxed form of a Go type, which unboxes where a primitive is expected
+     */
+    private cast(x: GoExpr, type: GoType): string {
+        return `(${JavaCodeGenerator.toJavaType(type, this.options, true)}) ${this.expr(x, UNARY_PRECEDENCE)}`;
+    }
+
+    /**
+     * Emit switch clauses as `if (...) {} else if (...) {} else {}`, the default last as in Go
+     * @param condition Go condition each case expression stands for
+     * @param test Java test for a case expression, when it is not a Go expression
+     * @param prologue Declarations opening each clause body
+     */
+    private emitClauseChain(
+        cases: GoCaseClause[],
+        condition: (e: GoExpr) => GoExpr,
+        test: (e: GoExpr) => string = e => this.expr(condition(e), JAVA_PRECEDENCE['||'] + 1),
+        prologue: (clause: GoCaseClause) => void = () => {}
+    ): void {
+        const ordered = [...cases.filter(c => !c.isDefault), ...cases.filter(c => c.isDefault)];
+        ordered.forEach((clause, i) => {
+            const keyword = i === 0 ? '' : '} else ';
+            if (clause.isDefault) {
+                this.emit(i === 0 ? '{' : '} else {');
+            } else {
+                this.emit(`${keyword}if (${clause.list.map(test).join(' || ')}) {`);
+            }
+            this.depth++;
+            this.scopes.push(new Map());
+            prologue(clause);
+            this.emitStmts(this.withoutFinalBreak(clause.body));
+            this.scopes.pop();
+            this.depth--;
+        });
+        if (ordered.length > 0) {
+            this.emit('}');
+        }
+    }
+
+    /**
+     * A clause body without the break ending it, which only restates the end of the clause
+     */
+    private withoutFinalBreak(body: GoStmt[]): GoStmt[] {
+        const last = body[body.length - 1];
+        return last?.kind === 'BranchStmt' && last.tok === 'break' && !last.label ? body.slice(0, -1) : body;
+    }
+
+    /**
+     * An if/else chain has nothing for fallthrough to fall into or a bare break to leave
+     * (other than one ending a clause)
+     */
+    private checkSwitchChain(cases: GoCaseClause[]): void {
+        if (cases.some(c => c.body.some(s => s.kind === 'BranchStmt' && s.tok === 'fallthrough'))) {
+            throw new UnsupportedConstructError('fallthrough in a switch without constant cases is not converted yet');
+        }
+        let breaks = false;
+        const visit = (stmts: GoStmt[]) => stmts.forEach(s => {
+            if (s.kind === 'BranchStmt' && s.tok === 'break' && !s.label) {
+                breaks = true;
+            } else if (s.kind === 'BlockStmt') {
+                visit(s.stmts);
+            } else if (s.kind === 'IfStmt') {
+                visit([s.body, ...(s.else ? [s.else] : [])]);
+            } else if (s.kind === 'LabeledStmt') {
+                visit([s.stmt]);
+            }
+            // Loops, switches and selects have breaks of their own
+        });
+        cases.forEach(c => visit(this.withoutFinalBreak(c.body)));
+        if (breaks) {
+            throw new UnsupportedConstructError("'break' out of a switch without constant cases is not converted yet");
+        }
+    }
+
+    /**
+     * Whether a case expression is a constant Java accepts as a case label
+     */
+    private isConstant(e: GoExpr): boolean {
+        switch (e.kind) {
+            case 'BasicLit':
+                return e.litKind !== 'IMAG';
+            case 'Paren':
+                return this.isConstant(e.x);
+            case 'Unary':
+                return e.op === '-' && this.isConstant(e.x);
+            case 'Ident':
+                // Members of an untyped string enum are read through getValue(), not a constant expression
+                return !this.lookup(e.name) && !!this.goFile?.constants.some(c => c.name === e.name)
+                    && !this.enumConstant(e.name)?.untyped;
+            default:
+                return false;
+        }
+    }
+
+    /**
+     * Whether a function literal in the statements mentions a Go identifier
+     */
+    private capturedIn(stmts: GoStmt[], name: string): boolean {
+        let captured = false;
+        walkExprs(stmts, e => {
+            if (e.kind === 'FuncLit' && this.mentions(e.body.stmts, name)) {
+                captured = true;
+            }
+        });
+        return captured;
+    }
+
+    /**
+     * Whether a Go identifier appears in the source of the statements
+     */
+    private mentions(stmts: GoStmt[], name: string): boolean {
+        return stmts.some(s => new RegExp(`\\b${name}\\b`).test(this.source.slice(s.span[0], s.span[1])));
+    }
+
+    /**
+     * Pick a Java name for a synthesized variable that does not shadow a visible one
+     */
+    private freshName(base: string): string {
+        let name = base;
+        for (let n = 2; this.lookup(name); n++) {
+            name = `${base}${n}`;
+        }
+        return name;
+    }
+
+    /**
+     * Translate a simple statement used inside a for header (no trailing semicolon)
+     */
+    private inlineStmt(stmt: GoStmt): string {
+        const mark = this.lines.length;
+        const depth = this.depth;
+        this.depth = 0;
+        this.emitStmtUnchecked(stmt);
+        this.depth = depth;
+        const emitted = this.lines.splice(mark).map(line => line.trim());
+        if (emitted.length === 1) {
+            return emitted[0].replace(/;$/, '');
+        }
+        // `i, j := 0, n` declares locals of one type, `i, j = i+1, j-1` assigns each in turn
+        const declarations = emitted.map(line => line.match(/^([\w.<>\[\], ?]+?) (\w+ = .*);$/));
+        const type = declarations[0]?.[1];
+        if (type && declarations.every(d => d?.[1] === type)) {
+            return `${type} ${declarations.map(d => d![2]).join(', ')}`;
+        }
+        if (emitted.length > 1 && emitted.every(line => line.endsWith(';') && !/^(\/\/|[\w.<>\[\], ?]+? \w+ =)/.test(line))) {
+            return emitted.map(line => line.replace(/;$/, '')).join(', ');
+        }
+        throw new UnsupportedConstructError('Complex for loop clauses are not converted yet');
+    }
+
+    /**
+     * Whether a rewriter handles stmt, leaving nothing emitted or reported; statements the
+     * converter would translate together with their neighbours ask first
+     */
+    private rewrites(stmt: GoStmt | undefined): boolean {
+        if (!stmt || !this.options.rewriters?.length) {
+            return false;
+        }
+        const mark = this.lines.length;
+        const reported = this.options.diagnostics?.length || 0;
+        try {
+            return this.rewrite(stmt) !== undefined;
+        } catch (error) {
+            if (!(error instanceof UnsupportedConstructError)) {
+                throw error;
+            }
+            // emitStmt reports it when the statement is translated on its own
+            return true;
+        } finally {
+            this.lines.length = mark;
+            this.truncateDiagnostics(reported);
+        }
+    }
+
+    /**
+     * The replacement of the first rewriter handling node, or undefined when none does
+     */
+    private rewrite(node: GoStmt | GoExpr): string | undefined {
+        const rewriters = this.options.rewriters || [];
+        if (rewriters.length === 0 || this.rewriting.has(node)) {
+            return undefined;
+        }
+        const context: RewriteContext = { expr: e => this.expr(e), typeOf: e => this.typeOf(e) };
+        this.rewriting.add(node);
+        try {
+            for (const rewriter of rewriters) {
+                const { replacement, handled } = rewriter.rewrite(node, context);
+                if (handled) {
+                    return replacement;
+                }
+            }
+            return undefined;
+        } finally {
+            this.rewriting.delete(node);
+        }
+    }
+
+    // ═══════════════════════════════════════════════════════════════
+    // Expressions
+    // ═══════════════════════════════════════════════════════════════
+
+    /**
+     * Translate an expression, parenthesising it when its Java precedence is below minPrec
+     */
+    private expr(e: GoExpr, minPrec: number = 0): string {
+        const [code, prec] = this.exprWithPrec(e);
+        return prec < minPrec ? `(${code})` : code;
+    }
+
+    private exprWithPrec(e: GoExpr): [string, number] {
+        const rewritten = this.rewrite(e);
+        if (rewritten !== undefined) {
+            // Anything but a call chain or literal is parenthesised wherever an operator binds it
+            return [rewritten, JavaBodyGenerator.isPrimaryTemplate(rewritten) ? PRIMARY_PRECEDENCE : 0];
+        }
+        switch (e.kind) {
+            case 'Ident':
+                return [this.identifier(e.name), PRIMARY_PRECEDENCE];
+            case 'BasicLit':
+                return [this.literal(e.litKind, e.value), PRIMARY_PRECEDENCE];
+            case 'Paren':
+                return [`(${this.expr(e.x)})`, PRIMARY_PRECEDENCE];
+            case 'Binary':
+                return this.binary(e.op, e.x, e.y);
+            case 'Unary':
+                if (e.op === '-' || e.op === '+' || e.op === '!') {
+                    return [`${e.op}${this.expr(e.x, UNARY_PRECEDENCE)}`, UNARY_PRECEDENCE];
+                }
+                if (e.op === '^') {
+                    return [`~${this.expr(e.x, UNARY_PRECEDENCE)}`, UNARY_PRECEDENCE];
+                }
+                if (e.op === '<-') {
+                    return [this.receive(e.x), PRIMARY_PRECEDENCE];
+                }
+                // &T{...} is a new T like T{...}: Java objects are references already
+                if (e.op === '&' && e.x.kind === 'CompositeLit') {
+                    return this.exprWithPrec(e.x);
+                }
+                throw new UnsupportedConstructError(`Unary '${e.op}' is not converted yet`);
+            case 'Selector': {
+                const field = this.rowField(e);
+                return field ? this.exprWithPrec(field) : [this.selector(e.x, e.sel), PRIMARY_PRECEDENCE];
+            }
+            case 'Call': {
+                // Java infers type arguments, so Map[int, string](xs, f) calls map(xs, f)
+                const generic = this.instantiation(e.fun);
+                if (generic) {
+                    return [this.call({ ...e, fun: generic.fun }), PRIMARY_PRECEDENCE];
+                }
+                return this.conversion(e) || [this.call(e), PRIMARY_PRECEDENCE];
+            }
+            case 'Index':
+                if (this.isStringType(this.typeOf(e.x))) {
+                    return [this.stringIndex(e.x, e.index), UNARY_PRECEDENCE];
+                }
+                return [this.index(e.x, e.index), PRIMARY_PRECEDENCE];
+            case 'SliceExpr':
+                if (this.isStringType(this.typeOf(e.x)) && !e.max) {
+                    return [this.substring(e.x, e.low, e.high), PRIMARY_PRECEDENCE];
+                }
+                throw new UnsupportedConstructError('Slice expressions are not converted yet');
+            case 'CompositeLit':
+                return [this.compositeLit(e, e.type?.type), PRIMARY_PRECEDENCE];
+            case 'FuncLit':
+                return [this.lambda(e), LAMBDA_PRECEDENCE];
+            case 'TypeAssert':
+                if (!e.type) {
+                    throw new UnsupportedConstructError('x.(type) outside a type switch');
+                }
+                // A failed assertion panics in Go and throws ClassCastException in Java
+                return [this.cast(e.x, e.type.type), UNARY_PRECEDENCE];
+            case 'Star':
+                return this.dereference(e);
+            case 'KeyValue':
+            case 'TypeExpr':
+                throw new UnsupportedConstructError('Unexpected type expression');
+        }
+    }
+
+    /**
+     * `*p` is just `p`: Java dereferences references by itself, and a pointer to a primitive
+     * is its boxed type (`*int` → `Integer`), unboxed where an int is needed
+     */
+    private dereference(e: GoStarExpr): [string, number] {
+        if (this.structOf(this.typeOf(e))) {
+            this.reportDegraded('Dereference', 'Go copies the struct a pointer points to; the Java value shares it', e.pos);
+        }
+        return this.exprWithPrec(e.x);
+    }
+
+    /**
+     * Translate a function literal into a lambda, typed by the java.util.function interface
+     * of its Go type: `func(x int) int { return x * 2 }` → `x -> x * 2`
+     */
+    private lambda(fn: GoFuncLit): string {
+        const results = fn.type.results || [];
+        if (!GoFunctionParser.functionalInterface(fn.type.type)) {
+            throw new UnsupportedConstructError('Function literals with more than two parameters or several results are not converted yet');
+        }
+        if (results.some(r => r.name === 'error')) {
+            throw new UnsupportedConstructError('Function literals returning error are not converted yet');
+        }
+        const declared = JavaBodyGenerator.declaredIn(fn);
+        walkExprs(fn.body.stmts, e => {
+            if (e.kind !== 'Ident' || declared.has(e.name)) {
+                return;
+            }
+            const variable = this.lookup(e.name);
+            if (variable && !variable.boxed && this.reassignedCaptures.has(e.name)) {
+                // Parameters, results and range variables are not boxed
+                throw new UnsupportedConstructError(`Closure captures '${e.name}', which is reassigned; Java lambdas need effectively final locals`);
+            }
+        });
+
+        const outer = { goFunc: this.goFunc, lines: this.lines, depth: this.depth, deferList: this.deferList };
+        // A defer in the literal runs when the literal returns
+        this.deferList = undefined;
+        this.scopes.push(new Map());
+        const params = (fn.type.params || []).map(p => {
+            const named = !!p.name && p.name !== '_';
+            // A lambda parameter may not shadow a local; the receiver is no local but this
+            const shadowed = named ? this.lookup(p.name) : undefined;
+            const javaName = named && (!shadowed || shadowed.javaName === 'this') ? p.name : this.freshName(named ? p.name : 'ignored');
+            this.scopes[this.scopes.length - 1].set(named ? p.name : javaName, { javaName, type: p.type });
+            return javaName;
+        });
+        this.goFunc = {
+            name: '',
+            parameters: fn.type.params || [],
+            returnTypes: results,
+            isMethod: false,
+            receiver: outer.goFunc.receiver,
+            hasErrorReturn: false
+        };
+
+        let body: string;
+        try {
+            const only = fn.body.stmts.length === 1 ? fn.body.stmts[0] : undefined;
+            if (only?.kind === 'ReturnStmt' && only.results.length === 1 && results.length === 1) {
+                body = this.expr(only.results[0]);
+            } else if (only?.kind === 'ExprStmt' && only.x.kind === 'Call' && results.length === 0) {
+                body = this.expr(only.x);
+            } else {
+                this.lines = [];
+                this.depth = outer.depth + 1;
+                this.emitStmts(fn.body.stmts);
+                if (this.lines[this.lines.length - 1] === `${INDENT.repeat(this.depth)}return;`) {
+                    this.lines.pop();
+                }
+                this.emitCommentsBefore(fn.body.span[1]);
+                body = this.lines.length === 0 ? '{}' : ['{', ...this.lines, `${INDENT.repeat(outer.depth)}}`].join('\n');
+            }
+        } finally {
+            this.goFunc = outer.goFunc;
+            this.lines = outer.lines;
+            this.depth = outer.depth;
+            this.deferList = outer.deferList;
+            this.scopes.pop();
+        }
+        return `${params.length === 1 ? params[0] : `(${params.join(', ')})`} -> ${body}`;
+    }
+
+    private identifier(name: string): string {
+        if (name === 'nil') {
+            return 'null';
+        }
+        const local = this.lookup(name);
+        if (local?.unconverted) {
+            throw new UnsupportedConstructError(`Uses '${name}', whose declaration was not converted`);
+        }
+        if (local && this.options.junit && JavaCodeGenerator.isTestingT(local.type)) {
+            throw new UnsupportedConstructError(`The *testing.T '${name}' is only converted in calls of its methods and of test helpers`);
+        }
+        if (local) {
+            return local.javaName;
+        }
+        const func = this.findFunction(name);
+        if (func) {
+            // Static factories are only in scope inside their own class
+            const owner = JavaCodeGenerator.factoryOwner(func, this.options, this.goFile);
+            const method = JavaCodeGenerator.memberName(name, this.options);
+            return owner && owner !== this.enclosingStruct() ? `${owner}.${method}` : method;
+        }
+        const enumConstant = this.enumConstant(name);
+        if (enumConstant) {
+            const constant = `${enumConstant.enumName}.${enumConstant.javaName}`;
+            return enumConstant.untyped ? `${constant}.getValue()` : constant;
+        }
+        if (this.goFile?.constants.some(c => c.name === name)) {
+            return JavaCodeGenerator.constantName(name, this.options);
+        }
+        if (this.goFile?.variables.some(v => v.name === name)) {
+            return JavaCodeGenerator.memberName(name, this.options);
+        }
+        return name;
+    }
+
+    /**
+     * Enum whose constant the name refers to, when the enums or stringEnums option turns
+     * its type into one
+     */
+    private enumOf(name: string): string | undefined {
+        const constant = this.enumConstant(name);
+        return constant && !constant.untyped ? constant.enumName : undefined;
+    }
+
+    /**
+     * Java enum constant a Go constant became, if any. Untyped string constants are
+     * still strings to the Go code using them.
+     */
+    private enumConstant(name: string): { enumName: string; javaName: string; untyped?: boolean } | undefined {
+        if (this.lookup(name)) {
+            return undefined;
+        }
+        for (const [enumName, members] of JavaCodeGenerator.enumTypes(this.options, this.goFile)) {
+            if (members.some(c => c.name === name)) {
+                return { enumName, javaName: JavaCodeGenerator.constantName(name, this.options) };
+            }
+        }
+        for (const stringEnum of JavaCodeGenerator.stringEnums(this.options, this.goFile)) {
+            const member = stringEnum.members.find(m => m.constant.name === name);
+            if (member) {
+                return { enumName: stringEnum.name, javaName: member.javaName, untyped: !stringEnum.typed };
+            }
+        }
+        return undefined;
+    }
+
+    /**
+     * Typed string enum a Go type became with the stringEnums option
+     */
+    private stringEnumType(type?: GoType): boolean {
+        return !!type && !type.isPointer && !type.isSlice && !type.isMap
+            && JavaCodeGenerator.stringEnums(this.options, this.goFile).some(e => e.typed && e.name === type.name);
+    }
+
+    /**
+     * Struct whose class holds the function being translated, if any
+     */
+    private enclosingStruct(): string | undefined {
+        if (this.enclosing.receiver) {
+            return this.enclosing.receiver.type.name;
+        }
+        return JavaCodeGenerator.factoryOwner(this.enclosing, this.options, this.goFile);
+    }
+
+    /**
+     * Translate an expression for a slot of the given type. Integer literals get the
+     * matching suffix, since boxing (e.g. into Result<Double>) does not widen them.
+     */
+    private exprAs(e: GoExpr, type: GoType): string {
+        if (e.kind === 'BasicLit' && e.litKind === 'INT') {
+            const javaType = this.javaType(type);
+            if (javaType === 'long' && !e.value.endsWith('L')) {
+                const literal = this.literal(e.litKind, e.value);
+                return literal.endsWith('L') ? literal : `${literal}L`;
+            }
+            if (/^\d+$/.test(e.value)) {
+                switch (javaType) {
+                    case 'double':
+                        return `${e.value}.0`;
+                    case 'float':
+                        return `${e.value}f`;
+                }
+            }
+        }
+        return this.constantAs(e, type);
+    }
+
+    /**
+     * Java for a value assigned to a variable of a type, where a Go constant takes that type
+     * and Java would keep the literal's own: `var f float32 = 1.5` → `1.5f`, `var n int = 1e3`
+     * → `1000`, and `var big int64 = 1 << 40` → `1L << 40`, which an int shift would overflow
+     */
+    private constantAs(e: GoExpr, type?: GoType): string {
+        const javaType = type && this.javaType(type);
+        const literal = e.kind === 'Unary' && (e.op === '-' || e.op === '+') ? e.x : e;
+        if (literal.kind === 'BasicLit' && literal.litKind === 'FLOAT') {
+            const sign = e.kind === 'Unary' ? e.op : '';
+            if (javaType === 'float') {
+                return `${sign}${this.literal(literal.litKind, literal.value)}f`;
+            }
+            const value = goFloatValue(literal.value);
+            if (javaType && ['byte', 'short', 'int', 'long', 'char'].includes(javaType) && Number.isInteger(value)) {
+                const integer = this.literal('INT', BigInt(value).toString());
+                return `${sign}${javaType === 'long' && !integer.endsWith('L') ? `${integer}L` : integer}`;
+            }
+        }
+        if (javaType === 'long' && e.kind !== 'BasicLit' && this.isIntegerConstant(e)) {
+            const saved = this.longLiterals;
+            this.longLiterals = true;
+            try {
+                return this.expr(e);
+            } finally {
+                this.longLiterals = saved;
+            }
+        }
+        return this.expr(e);
+    }
+
+    /**
+     * Whether an expression is integer literals combined by operators, as constant arithmetic
+     */
+    private isIntegerConstant(e: GoExpr): boolean {
+        switch (e.kind) {
+            case 'BasicLit':
+                return e.litKind === 'INT';
+            case 'Paren':
+                return this.isIntegerConstant(e.x);
+            case 'Unary':
+                return ['-', '+', '^'].includes(e.op) && this.isIntegerConstant(e.x);
+            case 'Binary':
+                return ['+', '-', '*', '/', '%', '<<', '>>', '&', '|', '^', '&^'].includes(e.op)
+                    && this.isIntegerConstant(e.x) && this.isIntegerConstant(e.y);
+            default:
+                return false;
+        }
+    }
+
+    private literal(kind: string, value: string): string {
+        if (kind === 'STRING' && value.startsWith('`')) {
+            // Raw strings: escape backslashes, quotes and newlines
+            const raw = value.slice(1, -1)
+                .replace(/\\/g, '\\\\')
+                .replace(/"/g, '\\"')
+                .replace(/\r?\n/g, '\\n');
+            return `"${raw}"`;
+        }
+        switch (kind) {
+            case 'IMAG':
+                throw new UnsupportedConstructError('Complex numbers have no Java equivalent');
+            case 'INT': {
+                const literal = javaIntegerLiteral(value);
+                if (literal === undefined) {
+                    throw new UnsupportedConstructError(`Integer constant ${value} does not fit in 64 bits`);
+                }
+                return this.longLiterals && !literal.endsWith('L') ? `${literal}L` : literal;
+            }
+            case 'FLOAT':
+                return javaFloatLiteral(value);
+            case 'CHAR':
+                // A rune past 16 bits is an int code point
+                return javaCharLiteral(value) ?? `0x${goRuneValue(value).toString(16).toUpperCase()}`;
+            case 'STRING': {
+                const literal = javaStringLiteral(value);
+                if (literal === undefined) {
+                    throw new UnsupportedConstructError('String literal holds bytes that are not UTF-8, which a Java String cannot');
+                }
+                return literal;
+            }
+        }
+        return value;
+    }
+
+    private binary(op: string, x: GoExpr, y: GoExpr): [string, number] {
+        const prec = JAVA_PRECEDENCE[op];
+
+        if ((op === '==' || op === '!=') && x.kind === 'Star' && y.kind === 'Star') {
+            // *p == *q compares values in Go; == on two Integers compares the objects
+            const equals = `${this.expr(x.x, PRIMARY_PRECEDENCE)}.equals(${this.expr(y.x)})`;
+            return op === '==' ? [equals, PRIMARY_PRECEDENCE] : [`!${equals}`, UNARY_PRECEDENCE];
+        }
+
+        if ((op === '==' || op === '!=') && (this.isNil(x) || this.isNil(y))) {
+            return this.nilComparison(op, this.isNil(y) ? x : y);
+        }
+
+        const emptiness = this.emptinessCheck(op, x, y);
+        if (emptiness) {
+            return emptiness;
+        }
+
+        const wrapper = this.wrapperType(this.typeOf(x)) || this.wrapperType(this.typeOf(y));
+        if ((op === '==' || op === '!=')
+            && (wrapper || this.isStringType(this.typeOf(x)) || this.isStringType(this.typeOf(y)))) {
+            // Java compares strings and wrapper objects by reference with ==
+            const equals = `${this.expr(x, PRIMARY_PRECEDENCE)}.equals(${this.expr(y)})`;
+            return op === '==' ? [equals, PRIMARY_PRECEDENCE] : [`!${equals}`, UNARY_PRECEDENCE];
+        }
+        if (wrapper) {
+            throw new UnsupportedConstructError(`'${op}' on values of wrapper type ${wrapper.name} is not converted yet`);
+        }
+
+        const typeParam = this.typeParameter(this.typeOf(x)) || this.typeParameter(this.typeOf(y));
+        if (typeParam && op !== '&&' && op !== '||') {
+            // A type argument is an object in Java, so == would compare references
+            if (op === '==' || op === '!=') {
+                const equals = `Objects.equals(${this.expr(x)}, ${this.expr(y)})`;
+                return op === '==' ? [equals, PRIMARY_PRECEDENCE] : [`!${equals}`, UNARY_PRECEDENCE];
+            }
+            // Ordered constraints are bound by Comparable<T>
+            if (['<', '<=', '>', '>='].includes(op) && ORDERED_CONSTRAINTS.has(typeParam.constraint)
+                && this.typeParameter(this.typeOf(x)) && this.typeParameter(this.typeOf(y))) {
+                return [`${this.expr(x, PRIMARY_PRECEDENCE)}.compareTo(${this.expr(y)}) ${op} 0`, prec];
+            }
+            throw new UnsupportedConstructError(`'${op}' on values of type parameter ${typeParam.name} is not converted yet; Java generics have no operators`);
+        }
+
+        if (['<', '<=', '>', '>='].includes(op) && this.enumMethods(this.typeOf(x)) && this.enumMethods(this.typeOf(y))) {
+            // Enums compare by ordinal, the order Go's iota gave the constants
+            return [`${this.expr(x, PRIMARY_PRECEDENCE)}.compareTo(${this.expr(y)}) ${op} 0`, prec];
+        }
+        if (!['==', '!=', '&&', '||'].includes(op) && (this.enumMethods(this.typeOf(x)) || this.enumMethods(this.typeOf(y)))) {
+            throw new UnsupportedConstructError(`'${op}' on enum values is not converted yet; Java enums have only their ordinal()`);
+        }
+        // A float literal takes the type of the other operand, as Go converts untyped constants:
+        // n + 1e3 stays an int and f * 1.5 a float
+        const isFloatLiteral = (e: GoExpr) => e.kind === 'BasicLit' && e.litKind === 'FLOAT';
+        const left = isFloatLiteral(x) && !isFloatLiteral(y) ? this.constantAs(x, this.typeOf(y)) : this.expr(x, prec);
+        // A shift count does not widen the shift, so it stays an int
+        const longLiterals = this.longLiterals;
+        this.longLiterals = longLiterals && op !== '<<' && op !== '>>';
+        const right = isFloatLiteral(y) && !isFloatLiteral(x) ? this.constantAs(y, this.typeOf(x)) : this.expr(y, prec + 1);
+        this.longLiterals = longLiterals;
+        if (op === '&^') {
+            return [`${left} & ~${this.expr(y, UNARY_PRECEDENCE)}`, prec];
+        }
+        const unsigned = this.unsignedDivision(op, x) || this.unsignedDivision(op, y);
+        if (unsigned) {
+            return [`${unsigned}(${this.expr(x)}, ${this.expr(y)})`, PRIMARY_PRECEDENCE];
+        }
+        return [`${left} ${this.shiftOperator(op, x)} ${right}`, prec];
+    }
+
+    /**
+     * The type parameter of the function, or of its receiver's generic type, that type names
+     */
+    private typeParameter(type: GoType | undefined): GoTypeParam | undefined {
+        if (!type || type.isPointer || type.isSlice || type.isMap || type.typeArgs?.length) {
+            return undefined;
+        }
+        const receiver = this.goFunc.receiver?.type.name.replace(/^\*/, '');
+        const receiverParams = receiver ? this.goFile?.structs.find(s => s.name === receiver)?.typeParams : undefined;
+        return [...(this.goFunc.typeParams || []), ...(receiverParams || [])].find(p => p.name === type.name);
+    }
+
+    /**
+     * `len(s) == 0` and `len(s) > 0` (or `!= 0`) of a slice or map read from a variable, field
+     * or element, guarded against null: Go's len of a nil slice or map is 0, where Java's
+     * size() would throw (`s == null || s.isEmpty()`)
+     */
+    private emptinessCheck(op: string, x: GoExpr, y: GoExpr): [string, number] | undefined {
+        const isZero = (e: GoExpr) => e.kind === 'BasicLit' && e.litKind === 'INT' && goIntegerValue(e.value) === 0n;
+        const [len, mirrored] = this.isBuiltinCall(x, 'len') && isZero(y) ? [x, false]
+            : this.isBuiltinCall(y, 'len') && isZero(x) ? [y, true]
+            : [undefined, false];
+        const value = len?.args.length === 1 ? len.args[0] : undefined;
+        const type = value && this.typeOf(value);
+        if (!value || !type || (!type.isSlice && !type.isMap)) {
+            return undefined;
+        }
+        const empty = op === '==' ? true : op === '!=' || op === (mirrored ? '<' : '>') ? false : undefined;
+        let reads = true;
+        walkExprs([{ kind: 'ExprStmt', x: value, pos: value.pos, span: [0, 0] }], e => {
+            reads = reads && e.kind !== 'Call';
+        });
+        if (empty === undefined || !reads) {
+            return undefined;
+        }
+        const operand = this.expr(value, JAVA_PRECEDENCE['=='] + 1);
+        const collection = this.expr(value, PRIMARY_PRECEDENCE);
+        const sized = type.isMap || this.isList(type);
+        return empty
+            ? [`${operand} == null || ${sized ? `${collection}.isEmpty()` : `${collection}.length == 0`}`, JAVA_PRECEDENCE['||']]
+            : [`${operand} != null && ${sized ? `!${collection}.isEmpty()` : `${collection}.length > 0`}`, JAVA_PRECEDENCE['&&']];
+    }
+
+    /**
+     * `x == nil` as `x == null`: pointers, maps, functions, interfaces and the error type are
+     * references in Java. With nilMatchesEmpty a slice read from a variable, field or element
+     * also counts as nil when it is empty (`s == null || s.isEmpty()`), as Go code rarely
+     * tells a nil slice from an empty one.
+     */
+    private nilComparison(op: '==' | '!=', value: GoExpr): [string, number] {
+        const type = this.typeOf(value);
+        if (type && !type.isPointer && !type.isSlice && !type.isMap
+            && [...JAVA_NUMERIC_TYPES, 'boolean'].includes(this.javaType(type))) {
+            throw new UnsupportedConstructError(`A value of type ${type.name} is never nil`);
+        }
+        const operand = this.expr(value, JAVA_PRECEDENCE[op] + 1);
+        let reads = true;
+        walkExprs([{ kind: 'ExprStmt', x: value, pos: value.pos, span: [0, 0] }], e => {
+            reads = reads && e.kind !== 'Call';
+        });
+        if (this.options.nilMatchesEmpty && type?.isSlice && reads) {
+            const slice = this.expr(value, PRIMARY_PRECEDENCE);
+            const list = this.isList(type);
+            return op === '=='
+                ? [`${operand} == null || ${list ? `${slice}.isEmpty()` : `${slice}.length == 0`}`, JAVA_PRECEDENCE['||']]
+                : [`${operand} != null && ${list ? `!${slice}.isEmpty()` : `${slice}.length > 0`}`, JAVA_PRECEDENCE['&&']];
+        }
+        return [`${operand} ${op} null`, JAVA_PRECEDENCE[op]];
+    }
+
+    /**
+     * `tt.want` on the row of a table-driven test run as a parameterized test, which reads
+     * the method's parameter `want`
+     */
+    private rowField(e: GoSelectorExpr): GoIdent | undefined {
+        return this.row && e.x.kind === 'Ident' && e.x.name === this.row && !this.lookup(this.row)
+            ? { kind: 'Ident', name: e.sel, pos: e.pos }
+            : undefined;
+    }
+
+    private selector(x: GoExpr, sel: string): string {
+        // Package-qualified identifiers (fmt.Println) are kept as written
+        if (x.kind === 'Ident' && !this.lookup(x.name) && this.isImportedPackage(x.name)) {
+            return `${x.name}.${sel}`;
+        }
+
+        // (*p).Name reads through the reference like p.Name
+        if (x.kind === 'Paren' && x.x.kind === 'Star') {
+            x = x.x.x;
+        }
+        const target = this.expr(x, PRIMARY_PRECEDENCE);
+        const struct = this.structOf(this.typeOf(x));
+        if (struct) {
+            return this.promotedSelector(target, struct, sel) || `${target}.${sel}`;
+        }
+        const type = this.typeOf(x);
+        if (this.goFile?.structs.some(s => s.fields.some(f => f.name === sel) || s.methods.some(m => m.name === sel))
+            || this.wrapperType(type)?.methods.some(m => m.name === sel)
+            || (type && !type.isSlice && !type.isMap && this.interfaceMethod(type.name, sel, new Set()))) {
+            return `${target}.${JavaCodeGenerator.memberName(sel, this.options)}`;
+        }
+        const enumMethods = this.enumMethods(this.typeOf(x));
+        const method = enumMethods?.find(m => m.name === sel);
+        if (method) {
+            // The enum prints through toString(), which String() becomes part of
+            return `${target}.${method === JavaCodeGenerator.stringMethod(enumMethods!) ? 'toString' : JavaCodeGenerator.memberName(sel, this.options)}`;
+        }
+        return `${target}.${sel}`;
+    }
+
+    /**
+     * Methods of the Go type an iota enum became, when type is one
+     */
+    private enumMethods(type?: GoType): GoFunction[] | undefined {
+        if (!type || type.isPointer || type.isSlice || type.isMap || !JavaCodeGenerator.enumTypes(this.options, this.goFile).has(type.name)) {
+            return undefined;
+        }
+        return this.goFile?.namedTypes.find(t => t.name === type.name)?.methods || [];
+    }
+
+    /**
+     * Java access to a field or method of struct, spelling out the embedded fields Go promotes
+     * it through: `a.Name` becomes `a.user.name`, or `a.name` when Admin extends User
+     */
+    private promotedSelector(target: string, struct: GoStruct, sel: string): string | undefined {
+        const promoted = this.promotion(struct, sel);
+        if (!promoted) {
+            return undefined;
+        }
+        let code = target;
+        let owner = struct;
+        for (const field of promoted.path) {
+            code += this.embeddedAccess(owner, field);
+            owner = this.findStruct(field.type.name)!;
+        }
+        const field = owner.fields.find(f => f.name === sel);
+        return field?.isEmbedded
+            ? code + this.embeddedAccess(owner, field)
+            : `${code}.${JavaCodeGenerator.memberName(sel, this.options)}`;
+    }
+
+    /**
+     * Selector step into an embedded field; a superclass needs none
+     */
+    private embeddedAccess(owner: GoStruct, field: GoField): string {
+        const superStruct = JavaCodeGenerator.superStruct(owner, this.options, this.goFile);
+        return field.type.name === superStruct?.name ? '' : `.${JavaCodeGenerator.memberName(field.name, this.options)}`;
+    }
+
+    /**
+     * Embedded fields through which Go promotes sel to struct, outermost first, and the struct
+     * declaring it. As in Go, the shallowest declaration wins.
+     */
+    private promotion(struct: GoStruct, sel: string): { path: GoField[]; owner: GoStruct } | undefined {
+        type Candidate = { path: GoField[]; owner: GoStruct };
+        const visited = new Set<GoStruct>();
+        let level: Candidate[] = [{ path: [], owner: struct }];
+        while (level.length > 0) {
+            const found = level.find(c => c.owner.fields.some(f => f.name === sel) || c.owner.methods.some(m => m.name === sel));
+            if (found) {
+                return found;
+            }
+            level.forEach(c => visited.add(c.owner));
+            level = level.flatMap(c => c.owner.fields
+                .filter(f => f.isEmbedded && !f.type.isSlice && !f.type.isMap)
+                .map(f => ({ path: [...c.path, f], owner: this.findStruct(f.type.name) }))
+                .filter((n): n is Candidate => !!n.owner && !visited.has(n.owner)));
+        }
+        return undefined;
+    }
+
+    private index(x: GoExpr, index: GoExpr): string {
+        const containerType = this.typeOf(x);
+        const container = this.expr(x, PRIMARY_PRECEDENCE);
+        if (containerType?.isMap && containerType.valueType) {
+            // Missing keys read as the zero value in Go; Java's get() would return null
+            const valueJava = JavaCodeGenerator.toJavaType(containerType.valueType, this.options);
+            const zero = BOXABLE_ZERO[valueJava] || JavaCodeGenerator.getDefaultValue(valueJava, this.options);
+            return zero === 'null'
+                ? `${container}.get(${this.expr(index)})`
+                : `${container}.getOrDefault(${this.expr(index)}, ${zero})`;
+        }
+        const position = this.intArgument(index);
+        if (containerType && this.isList(containerType)) {
+            return `${container}.get(${position})`;
+        }
+        if (containerType?.isMap) {
+            return `${container}.get(${this.expr(index)})`;
+        }
+        return `${container}[${position}]`;
+    }
+
+    /**
+     * make(chan T) as a SynchronousQueue, which like an unbuffered channel hands each value
+     * straight to a receiver, and make(chan T, n) as an ArrayBlockingQueue holding n values
+     */
+    private makeChannel(typeArg: GoTypeExpr, size?: GoExpr): string {
+        if (!this.options.experimentalConcurrency) {
+            throw new UnsupportedConstructError('Channels are only converted with experimental concurrency support');
+        }
+        this.reportDegraded('Channel', 'Channels become BlockingQueues, which cannot be closed and have no nil channels or select', typeArg.pos);
+        if (!size || (size.kind === 'BasicLit' && size.value === '0')) {
+            return 'new SynchronousQueue<>()';
+        }
+        return `new ArrayBlockingQueue<>(${this.intArgument(size)})`;
+    }
+
+    /**
+     * make([]T, n, c) and make(map[K]V, hint) as new collections. Go fills the n elements
+     * with zero values, so a list copies the zero value n times; with no length, the
+     * capacity or hint becomes the initial capacity.
+     */
+    private make(call: GoCallExpr): string {
+        const typeArg = call.args[0];
+        if (typeArg?.kind !== 'TypeExpr') {
+            throw new UnsupportedConstructError('make() of an unknown type is not converted yet');
+        }
+        if (typeArg.typeKind === 'chan') {
+            return this.makeChannel(typeArg, call.args[1]);
+        }
+        const [length, capacity] = call.args.slice(1).map(arg => this.intArgument(arg));
+        if (typeArg.typeKind === 'map') {
+            return length ? `new HashMap<>(${length})` : 'new HashMap<>()';
+        }
+        if (typeArg.typeKind !== 'slice' || !length) {
+            throw new UnsupportedConstructError(`make() of '${typeArg.text}' is not converted yet`);
+        }
+        const type = typeArg.type;
+        const element = GoFunctionParser.elementTypeOf(type);
+        const elementJava = this.javaType(element);
+        if (!this.isList(type)) {
+            if (elementJava.includes('<')) {
+                throw new UnsupportedConstructError('Java cannot create arrays of generic types');
+            }
+            // new int[n][] for [][]int: the length goes on the outermost dimension
+            const base = elementJava.replace(/(\[\])+$/, '');
+            return `new ${base}[${length}]${elementJava.slice(base.length)}`;
+        }
+        if (length === '0') {
+            return capacity ? `new ArrayList<>(${capacity})` : 'new ArrayList<>()';
+        }
+        return `new ArrayList<>(Collections.nCopies(${length}, ${this.zeroElement(elementJava)}))`;
+    }
+
+    /**
+     * The builtins with a Java counterpart: delete(m, k) → m.remove(k), clear, cap of an
+     * array, min and max through Math, println and print writing to System.err as Go's do,
+     * and new(T) of a struct. The others have no Java counterpart and are reported.
+     */
+    private builtin(name: string, call: GoCallExpr): string | undefined {
+        if (this.lookup(name)) {
+            return undefined;
+        }
+        const [first, second] = call.args;
+        const type = first && first.kind !== 'TypeExpr' ? this.typeOf(first) : undefined;
+        switch (name) {
+            case 'delete':
+                return `${this.expr(first, PRIMARY_PRECEDENCE)}.remove(${type?.isMap ? this.exprAs(second, type.keyType!) : this.expr(second)})`;
+            case 'clear': {
+                if (!type) {
+                    throw new UnsupportedConstructError('clear() of a value with unknown type is not converted yet');
+                }
+                if (type.isMap) {
+                    return `${this.expr(first, PRIMARY_PRECEDENCE)}.clear()`;
+                }
+                // Go zeroes a slice's elements and keeps its length
+                const zero = this.zeroElement(this.javaType(GoFunctionParser.elementTypeOf(type)));
+                return `${this.isList(type) ? 'Collections' : 'Arrays'}.fill(${this.expr(first)}, ${zero})`;
+            }
+            case 'cap':
+                if (type?.isSlice && !this.isList(type)) {
+                    return `${this.expr(first, PRIMARY_PRECEDENCE)}.length`;
+                }
+                throw new UnsupportedConstructError('cap() has no Java counterpart but for arrays');
+            case 'min':
+            case 'max': {
+                const result = this.typeOf(call);
+                const javaType = result ? this.javaType(result) : undefined;
+                if (!javaType || !JAVA_NUMERIC_TYPES.has(javaType)) {
+                    throw new UnsupportedConstructError(`${name}() of non-numeric values is not converted yet`);
+                }
+                const args = call.args.map(a => this.exprAs(a, result!));
+                const nested = args.reduceRight((rest, arg) => `Math.${name}(${arg}, ${rest})`);
+                // Math.min and Math.max widen byte, short and char to int
+                return ['byte', 'short', 'char'].includes(javaType) && args.length > 1 ? `(${javaType}) ${nested}` : nested;
+            }
+            case 'println':
+                return call.args.length === 0 ? 'System.err.println()' : `System.err.println(${this.spaceJoined(call.args)})`;
+            case 'print':
+                if (call.args.length !== 1) {
+                    throw new UnsupportedConstructError('print of several values is not converted yet');
+                }
+                return `System.err.print(${this.printed(first)})`;
+            case 'new': {
+                const target = first?.kind === 'TypeExpr' ? first.type : first?.kind === 'Ident' ? this.simpleType(first.name) : undefined;
+                const zero = target && this.structZero(target);
+                if (!zero) {
+                    throw new UnsupportedConstructError('new() of a type other than a struct is not converted yet');
+                }
+                return zero;
+            }
+            case 'copy':
+                throw new UnsupportedConstructError('copy() is not converted yet');
+            default:
+                throw new UnsupportedConstructError(`${name}() has no Java counterpart`);
+        }
+    }
+
+    /**
+     * A Go composite literal of the given type, which an element of an enclosing literal
+     * may leave out (`[]Point{{1, 2}}`). Slices become lists (`new ArrayList<>(List.of(a, b))`,
+     * or `List.of(a, b)` with immutableLiterals) or arrays (`new int[] {a, b}`), maps
+     * `new HashMap<>(Map.of(k, v))`, and structs a call of the all-args constructor in Go
+     * field order, the fields a keyed literal leaves out at their zero values
+     */
+    private compositeLit(e: GoCompositeLit, type: GoType | undefined): string {
+        if (!type) {
+            throw new UnsupportedConstructError('Composite literals of an unknown type are not converted yet');
+        }
+        const resolved = this.underlying(type);
+        const javaVersion = this.options.javaVersion || DEFAULT_JAVA_VERSION;
+        if (resolved.isMap) {
+            const entries = this.mapEntries(e, resolved);
+            if (entries.length === 0) {
+                return 'new HashMap<>()';
+            }
+            if (javaVersion < 9) {
+                throw new UnsupportedConstructError('Map literals need Java 9 or later');
+            }
+            // Map.of takes at most 10 entries
+            const map = entries.length <= 10
+                ? `Map.of(${entries.map(([k, v]) => `${k}, ${v}`).join(', ')})`
+                : `Map.ofEntries(${entries.map(([k, v]) => `Map.entry(${k}, ${v})`).join(', ')})`;
+            return this.immutableLiterals ? map : `new HashMap<>(${map})`;
+        }
+        if (resolved.isSlice) {
+            if (e.elts.some(elt => elt.kind === 'KeyValue')) {
+                throw new UnsupportedConstructError('Slice literals with indexed elements are not converted yet');
+            }
+            const element = GoFunctionParser.elementTypeOf(resolved);
+            const values = e.elts.map(elt => this.element(elt, element));
+            if (!this.isList(resolved)) {
+                const elementJava = this.javaType(element);
+                if (elementJava.includes('<')) {
+                    throw new UnsupportedConstructError('Java cannot create arrays of generic types');
+                }
+                return `new ${elementJava}[] {${values.join(', ')}}`;
+            }
+            if (values.length === 0) {
+                return 'new ArrayList<>()';
+            }
+            // List.of rejects null elements
+            const list = javaVersion >= 9 && !e.elts.some(elt => elt.kind === 'Ident' && elt.name === 'nil')
+                ? `List.of(${values.join(', ')})`
+                : `Arrays.asList(${values.join(', ')})`;
+            return this.immutableLiterals ? list : `new ArrayList<>(${list})`;
+        }
+        const struct = this.structOf(resolved);
+        if (!struct && e.type?.typeKind === 'struct' && !e.type.fields?.length && e.elts.length === 0) {
+            // struct{}{} carries no data, like the signals sent on a chan struct{}
+            return 'new Object()';
+        }
+        if (!struct) {
+            throw new UnsupportedConstructError(/^struct\s*\{/.test(resolved.name)
+                ? 'Literals of anonymous struct types are not converted yet'
+                : `Composite literals of '${type.name}' are not converted yet`);
+        }
+        if (JavaCodeGenerator.superStruct(struct, this.options, this.goFile)) {
+            throw new UnsupportedConstructError('Literals of a struct extending its embedded struct are not converted yet');
+        }
+        const keyed = e.elts.some(elt => elt.kind === 'KeyValue');
+        const values = new Map<string, GoExpr>();
+        e.elts.forEach((elt, i) => {
+            if (elt.kind === 'KeyValue' && elt.key.kind === 'Ident') {
+                values.set(elt.key.name, elt.value);
+            } else if (!keyed && i < struct.fields.length) {
+                values.set(struct.fields[i].name, elt);
+            } else {
+                throw new UnsupportedConstructError('Struct literal with a field it does not declare');
+            }
+        });
+        const typeArgs = type.typeArgs?.length ? '<>' : '';
+        const appended = this.goFile ? JavaBodyGenerator.appendedFields(this.goFile) : new Set<string>();
+        const args = struct.fields.map(f => values.has(f.name) ? this.element(values.get(f.name)!, f.type)
+            // A field grown in place starts empty, as its initializer does
+            : appended.has(f.name) && this.isList(f.type) ? 'new ArrayList<>()'
+            : this.fieldValue(undefined, f.type));
+        return `new ${struct.name}${typeArgs}(${args.join(', ')})`;
+    }
+
+    /**
+     * The type of a package variable's initializer when it is a composite literal
+     */
+    private literalType(text: string): GoType | undefined {
+        let expr: GoExpr;
+        try {
+            expr = GoBodyParser.parseExpression(text);
+        } catch (error) {
+            return undefined;
+        }
+        if (expr.kind === 'Unary' && expr.op === '&') {
+            expr = expr.x;
+        }
+        return expr.kind === 'CompositeLit' ? expr.type?.type : undefined;
+    }
+
+    /**
+     * Java keys and values of a map literal of the given map type
+     */
+    private mapEntries(e: GoCompositeLit, type: GoType): [string, string][] {
+        return e.elts.map(elt => {
+            if (elt.kind !== 'KeyValue') {
+                throw new UnsupportedConstructError('Map literal entry without a key');
+            }
+            return [this.element(elt.key, type.keyType!), this.element(elt.value, type.valueType!)];
+        });
+    }
+
+    /**
+     * An element of a composite literal, whose own literal may leave out its type
+     * (`{1, 2}` or `&{1, 2}` for a Point)
+     */
+    private element(e: GoExpr, type: GoType): string {
+        if (e.kind === 'CompositeLit' && !e.type) {
+            return this.compositeLit(e, { ...type, isPointer: false });
+        }
+        if (e.kind === 'Unary' && e.op === '&' && e.x.kind === 'CompositeLit' && !e.x.type) {
+            return this.compositeLit(e.x, { ...type, isPointer: false });
+        }
+        return this.exprAs(e, type);
+    }
+
+    /**
+     * Zero value of a list element, typed to box into the element type (`0L` for Long)
+     */
+    private zeroElement(javaType: string): string {
+        switch (javaType) {
+            case 'long': return '0L';
+            case 'float': return '0.0f';
+            case 'short':
+            case 'byte': return `(${javaType}) 0`;
+            // Go's zero string is empty, not nil
+            case 'String': return '""';
+            default: return JavaCodeGenerator.getDefaultValue(javaType);
+        }
+    }
+
+    /**
+     * A length or index argument; Java takes int, so a long one (intType long) is narrowed
+     */
+    private intArgument(e: GoExpr): string {
+        const type = this.typeOf(e);
+        return type && this.javaType(type) === 'long' ? `(int) ${this.expr(e, UNARY_PRECEDENCE)}` : this.expr(e);
+    }
+
+    /**
+     * s[i] reads a byte in Go; Java's charAt reads a UTF-16 char, which only agrees for ASCII text
+     */
+    private stringIndex(x: GoExpr, index: GoExpr): string {
+        this.reportDegraded('StringIndex', 'Go indexes strings by byte and Java by UTF-16 char; non-ASCII text gives different values', x.pos);
+        return `(byte) ${this.expr(x, PRIMARY_PRECEDENCE)}.charAt(${this.expr(index)})`;
+    }
+
+    /**
+     * s[lo:hi] as substring, whose char offsets match Go's byte offsets only for ASCII text
+     */
+    private substring(x: GoExpr, low?: GoExpr, high?: GoExpr): string {
+        this.reportDegraded('StringIndex', 'Go slices strings by byte offset and Java by UTF-16 char; non-ASCII text gives different substrings', x.pos);
+        const code = this.expr(x, PRIMARY_PRECEDENCE);
+        const start = low ? this.expr(low) : '0';
+        return high ? `${code}.substring(${start}, ${this.expr(high)})` : `${code}.substring(${start})`;
+    }
+
+    private call(call: GoCallExpr): string {
+        if (call.fun.kind === 'FuncLit' || (call.fun.kind === 'Paren' && call.fun.x.kind === 'FuncLit')) {
+            throw new UnsupportedConstructError('Immediately invoked function literals are not converted yet');
+        }
+        if (call.fun.kind === 'Ident' && call.fun.name === 'len' && call.args.length === 1 && !this.lookup('len')) {
+            return this.len(call.args[0]);
+        }
+        if (this.isBuiltinCall(call, 'append')) {
+            throw new UnsupportedConstructError('append whose result is not assigned back to the appended slice is not converted yet');
+        }
+        if (this.isBuiltinCall(call, 'make')) {
+            return this.make(call);
+        }
+        const builtin = call.fun.kind === 'Ident' && BUILTIN_FUNCTIONS.has(call.fun.name) && !this.resolveCallee(call.fun)
+            ? this.builtin(call.fun.name, call) : undefined;
+        if (builtin) {
+            return builtin;
+        }
+        if (this.isTestingCall(call, 'Error', 'Errorf', 'Fatal', 'Fatalf', 'Fail', 'FailNow')) {
+            // Go carries on after t.Error; a JUnit test stops at its first failure
+            return `fail(${this.failureMessage(call) || '""'})`;
+        }
+        if (this.isTestingCall(call)) {
+            throw new UnsupportedConstructError(`testing.T.${call.fun.sel} is not converted yet`);
+        }
+
+        if (this.isPackageCall(call, 'fmt', 'Sprintf') && call.args.length > 0 && !call.ellipsis) {
+            const [format, ...args] = call.args;
+            return this.stringFormat(format, args);
+        }
+
+        // errors.New and fmt.Errorf outside a return still construct an error value
+        const constructed = this.constructedError(call);
+        if (constructed) {
+            return constructed;
+        }
+
+        if (call.fun.kind === 'Selector' && call.fun.sel === 'Error' && call.args.length === 0 && this.isErrorValue(call.fun.x)) {
+            // An error is an Exception, which keeps its message in getMessage()
+            return this.errorMessage(call.fun.x);
+        }
+
+        const stdlib = this.stdlibCall(call);
+        if (stdlib) {
+            return stdlib;
+        }
+
+        const named = this.underlyingMethod(call.fun);
+        if (named && call.fun.kind === 'Selector') {
+            // The method is a static method taking the receiver first: c.Fahrenheit() → celsiusFahrenheit(c)
+            const receiver = [call.fun.x, ...call.args].map(a => this.expr(a));
+            return `${JavaCodeGenerator.memberName(named.type.name + named.method.name, this.options)}(${receiver.join(', ')})`;
+        }
+
+        const callee = this.resolveCallee(call.fun);
+        // Test helpers lose their *testing.T parameter in junit mode
+        const args = call.args
+            .filter((_, i) => !(callee && callee.parameters[i] && JavaCodeGenerator.isDroppedParameter(callee.parameters[i], this.options)))
+            .map(a => this.expr(a));
+
+        if (call.ellipsis && args.length > 0) {
+            // f(slice...) passes the slice as the varargs array
+            const spread = call.args[call.args.length - 1];
+            args[args.length - 1] = this.spreadArgument(spread, callee?.parameters);
+        }
+
+        // A func value is a functional interface object: f(x) → f.apply(x)
+        const funcType = callee ? undefined : this.typeOf(call.fun);
+        if (GoFunctionParser.funcSignature(funcType)) {
+            const method = GoFunctionParser.functionalMethod(this.javaType(funcType!));
+            if (!method) {
+                throw new UnsupportedConstructError('Calls of func values with more than two parameters or several results are not converted yet');
+            }
+            return `${this.expr(call.fun, PRIMARY_PRECEDENCE)}.${method}(${args.join(', ')})`;
+        }
+
+        return `${this.expr(call.fun, PRIMARY_PRECEDENCE)}(${args.join(', ')})`;
+    }
+
+    /**
+     * Translate a type conversion `T(x)`: numeric types become casts, string and
+     * []byte convert through String. Returns undefined for an ordinary call.
+     */
+    private conversion(call: GoCallExpr): [string, number] | undefined {
+        let target: GoType;
+        if (call.fun.kind === 'Ident' && !this.lookup(call.fun.name)) {
+            if (this.stringEnumType(this.simpleType(call.fun.name)) && call.args.length === 1) {
+                // Status(s) looks the constant up; Java has no enum value for other strings
+                return [`${call.fun.name}.fromValue(${this.expr(call.args[0])})`, PRIMARY_PRECEDENCE];
+            }
+            if (this.findStruct(call.fun.name)) {
+                throw new UnsupportedConstructError(`Conversion to user-defined type '${call.fun.name}' is not converted yet`);
+            }
+            const wrapper = this.wrapperType(this.simpleType(call.fun.name));
+            if (wrapper && call.args.length === 1) {
+                // Celsius(20) → new Celsius(20), converting to the wrapped type first
+                const value = this.conversionTo(call.args[0], JavaCodeGenerator.definedUnderlying(wrapper, this.goFile));
+                return [`new ${wrapper.name}(${value[0]})`, PRIMARY_PRECEDENCE];
+            }
+            if (!BUILTIN_TYPE_NAMES.has(call.fun.name) && !this.options.underlyingTypes?.has(call.fun.name)) {
+                return undefined;
+            }
+            target = this.underlying(this.simpleType(call.fun.name));
+        } else if (call.fun.kind === 'TypeExpr') {
+            target = call.fun.type;
+        } else {
+            return undefined;
+        }
+        if (call.args.length !== 1 || call.ellipsis) {
+            throw new UnsupportedConstructError('Malformed type conversion');
+        }
+        return this.conversionTo(call.args[0], target);
+    }
+
+    /**
+     * Convert arg to target, a type other than a wrapper, as target(arg) does
+     */
+    private conversionTo(arg: GoExpr, target: GoType): [string, number] {
+        // A wrapper converts by its value: float64(c) → c.value()
+        const wrapped = this.wrapperType(this.typeOf(arg));
+        const source = wrapped ? JavaCodeGenerator.definedUnderlying(wrapped, this.goFile) : this.typeOf(arg);
+        const code = (prec: number) => wrapped ? `${this.expr(arg, PRIMARY_PRECEDENCE)}.value()` : this.expr(arg, prec);
+        const javaTarget = this.javaType(target);
+        if (source && this.javaType(source) === javaTarget) {
+            return wrapped ? [code(PRIMARY_PRECEDENCE), PRIMARY_PRECEDENCE] : this.exprWithPrec(arg);
+        }
+
+        if (this.isStringType(target)) {
+            if (this.stringEnumType(source)) {
+                return [`${code(PRIMARY_PRECEDENCE)}.getValue()`, PRIMARY_PRECEDENCE];
+            }
+            if (source && GoFunctionParser.isByteSlice(source)) {
+                // Go strings hold UTF-8; Java's default charset depends on the platform
+                return [`new String(${code(0)}, StandardCharsets.UTF_8)`, PRIMARY_PRECEDENCE];
+            }
+            // string(r) encodes the code point r
+            const codePoint = source && !source.isSlice && !source.isMap ? this.javaType(source) : undefined;
+            if (codePoint === 'char') {
+                return [`String.valueOf(${code(0)})`, PRIMARY_PRECEDENCE];
+            }
+            if (codePoint === 'int') {
+                return [`new String(Character.toChars(${code(0)}))`, PRIMARY_PRECEDENCE];
+            }
+            throw new UnsupportedConstructError(`string() conversion of ${source ? `'${source.name}'` : 'a value with unknown type'} is not converted yet`);
+        }
+        if (GoFunctionParser.isByteSlice(target)) {
+            if (this.isStringType(source)) {
+                return [`${code(PRIMARY_PRECEDENCE)}.getBytes(StandardCharsets.UTF_8)`, PRIMARY_PRECEDENCE];
+            }
+            throw new UnsupportedConstructError(`[]byte() conversion of ${source ? `'${source.name}'` : 'a value with unknown type'} is not converted yet`);
+        }
+        if (JAVA_NUMERIC_TYPES.has(javaTarget) && this.enumMethods(source)) {
+            // The constants of an iota enum count from 0 like their ordinals
+            const ordinal = `${code(PRIMARY_PRECEDENCE)}.ordinal()`;
+            return javaTarget === 'int' ? [ordinal, PRIMARY_PRECEDENCE] : [`(${javaTarget}) ${ordinal}`, UNARY_PRECEDENCE];
+        }
+        if (JAVA_NUMERIC_TYPES.has(javaTarget)) {
+            return [`(${javaTarget}) ${code(UNARY_PRECEDENCE)}`, UNARY_PRECEDENCE];
+        }
+        if (target.name === 'any' || target.name === 'error') {
+            return wrapped ? [code(PRIMARY_PRECEDENCE), PRIMARY_PRECEDENCE] : this.exprWithPrec(arg);
+        }
+        throw new UnsupportedConstructError(`Conversion to ${javaTarget} is not converted yet`);
+    }
+
+    /**
+     * `len(x)` follows the Java representation of x
+     */
+    private len(arg: GoExpr): string {
+        const type = this.typeOf(arg);
+        const code = this.expr(arg, PRIMARY_PRECEDENCE);
+        if (!type) {
+            throw new UnsupportedConstructError('len() of a value with unknown type is not converted yet');
+        }
+        if (type.isMap || this.isList(type) || GoFunctionParser.channelElement(type)) {
+            return `${code}.size()`;
+        }
+        if (this.isStringType(type)) {
+            return `${code}.length()`;
+        }
+        return `${code}.length`;
+    }
+
+    /**
+     * Convert the argument of a spread call `f(xs...)` to something Java accepts as varargs
+     */
+    private spreadArgument(arg: GoExpr, parameters?: GoParameter[]): string {
+        const code = this.expr(arg, PRIMARY_PRECEDENCE);
+        const type = this.typeOf(arg);
+        if (!type || type.isVariadic || !this.isList(type)) {
+            return this.expr(arg);
+        }
+
+        const variadic = parameters && parameters.length > 0 ? parameters[parameters.length - 1].type : type;
+        const elementJava = this.javaType(GoFunctionParser.elementTypeOf(variadic));
+        switch (elementJava) {
+            case 'int':
+                return `${code}.stream().mapToInt(Integer::intValue).toArray()`;
+            case 'long':
+                return `${code}.stream().mapToLong(Long::longValue).toArray()`;
+            case 'double':
+                return `${code}.stream().mapToDouble(Double::doubleValue).toArray()`;
+        }
+        if (/^[a-z]/.test(elementJava)) {
+            throw new UnsupportedConstructError(`Spreading a List into ${elementJava}... varargs is not converted yet`);
+        }
+        return `${code}.toArray(new ${elementJava.replace(/<.*>$/, '')}[0])`;
+    }
+
+    /**
+     * Build the Java exception for a Go error value.
+     * errors.New and fmt.Errorf become a new instance of the configured exception class.
+     */
+    private exception(err: GoExpr): string {
+        const exceptionClass = JavaCodeGenerator.getExceptionClass(this.options);
+        const constructed = this.constructedError(err);
+        if (constructed) {
+            return constructed;
+        }
+
+        // An existing error value: rethrow it, or wrap it when a specific class is configured
+        const value = this.expr(err, PRIMARY_PRECEDENCE);
+        return exceptionClass === 'Exception' ? value : `new ${exceptionClass}(${value}.getMessage())`;
+    }
+
+    /**
+     * New exception for an error built in place with errors.New or fmt.Errorf, with the
+     * error fmt.Errorf wraps with %w as its cause
+     */
+    private constructedError(err: GoExpr): string | undefined {
+        const message = this.constructedErrorMessage(err);
+        if (!message) {
+            return undefined;
+        }
+        const cause = this.wrappedError(err);
+        return `new ${JavaCodeGenerator.getExceptionClass(this.options)}(${cause ? `${message}, ${cause}` : message})`;
+    }
+
+    /**
+     * The error `fmt.Errorf("...: %w", err)` wraps, or undefined. Go 1.20 wraps every %w
+     * argument; a Java exception has one cause, the first of them.
+     */
+    private wrappedError(err: GoExpr): string | undefined {
+        if (err.kind !== 'Call' || err.fun.kind !== 'Selector' || err.fun.sel !== 'Errorf' || err.args[0]?.kind !== 'BasicLit') {
+            return undefined;
+        }
+        let next = 0;
+        for (const match of this.expr(err.args[0]).matchAll(FORMAT_VERB)) {
+            if (match[5] === '%') {
+                continue;
+            }
+            const i = match[1] ? Number(match[1].slice(1, -1)) - 1 : next++;
+            if (match[5] === 'w') {
+                return err.args[i + 1] && this.expr(err.args[i + 1]);
+            }
+        }
+        return undefined;
+    }
+
+    /**
+     * Java String holding the message of a Go error value
+     */
+    private errorMessage(err: GoExpr): string {
+        return this.constructedErrorMessage(err) || `${this.expr(err, PRIMARY_PRECEDENCE)}.getMessage()`;
+    }
+
+    /**
+     * Message of an error built in place with errors.New or fmt.Errorf
+     */
+    private constructedErrorMessage(err: GoExpr): string | undefined {
+        if (err.kind !== 'Call' || err.fun.kind !== 'Selector' || err.fun.x.kind !== 'Ident' || this.lookup(err.fun.x.name)) {
+            return undefined;
+        }
+        const callee = `${err.fun.x.name}.${err.fun.sel}`;
+        if (callee === 'errors.New' && err.args.length === 1) {
+            return this.expr(err.args[0]);
+        }
+        if (callee === 'fmt.Errorf' && err.args.length > 0) {
+            const [format, ...args] = err.args;
+            return args.length === 0 ? this.expr(format) : this.stringFormat(format, args);
+        }
+        return undefined;
+    }
+
+    /**
+     * Translate a call into a standard library package through its mapping, configured
+     * (goToJava.stdlibCalls) or built in; fmt's print functions write to System.out.
+     * Returns undefined for calls outside the standard library, which are kept as written.
+     */
+    private stdlibCall(call: GoCallExpr): string | undefined {
+        const name = this.packageFunction(call.fun);
+        if (!name) {
+            return undefined;
+        }
+        const mapping = this.stdlibMapping(name);
+        if (mapping) {
+            return this.applyCallMapping(name, mapping, call);
+        }
+        const print = this.print(name, call);
+        if (print) {
+            return print;
+        }
+        // Without the imports, a package's path is unknown
+        if (this.goFile && isStdlibImport(name.slice(0, name.lastIndexOf('.')))) {
+            throw new UnsupportedConstructError(`${name} has no Java mapping yet`);
+        }
+        return undefined;
+    }
+
+    /**
+     * Import path and name of the package function fun refers to (`strings.ToUpper`,
+     * `path/filepath.Join`), following import aliases
+     */
+    private packageFunction(fun: GoExpr): string | undefined {
+        if (fun.kind !== 'Selector' || fun.x.kind !== 'Ident' || this.lookup(fun.x.name) || !this.isImportedPackage(fun.x.name)) {
+            return undefined;
+        }
+        const pkg = fun.x.name;
+        const imp = this.goFile?.imports.find(i => (i.alias || i.path.split('/').pop()) === pkg);
+        return `${imp ? imp.path : pkg}.${fun.sel}`;
+    }
+
+    /**
+     * Mapping of a standard library function, the configured one taking precedence
+     */
+    private stdlibMapping(name: string): StdlibCallMapping | undefined {
+        const configured = this.options.stdlibCalls?.[name];
+        if (configured !== undefined) {
+            return typeof configured === 'string' ? { java: configured } : configured;
+        }
+        return STDLIB_CALL_MAPPINGS.get(name);
+    }
+
+    /**
+     * Fill a mapping's Java template with the call's arguments. An argument the template
+     * calls a method on (`$1.trim()`) is parenthesized as a receiver needs.
+     */
+    private applyCallMapping(name: string, mapping: StdlibCallMapping, call: GoCallExpr): string {
+        if (call.ellipsis) {
+            throw new UnsupportedConstructError(`Spread arguments of ${name} are not converted yet`);
+        }
+        const template = this.options.intType === 'long' && mapping.javaForLong || mapping.java;
+        const code = template.replace(/\$(\d|\*)/g, (placeholder, index: string, offset: number) => {
+            if (index === '*') {
+                return call.args.map(a => this.expr(a)).join(', ');
+            }
+            const arg = call.args[Number(index) - 1];
+            if (!arg) {
+                throw new UnsupportedConstructError(`The mapping of ${name} uses ${placeholder}, but the call passes ${call.args.length} argument(s)`);
+            }
+            return /^[.[]/.test(template.slice(offset + placeholder.length)) ? this.expr(arg, PRIMARY_PRECEDENCE) : this.expr(arg);
+        });
+        return JavaBodyGenerator.isPrimaryTemplate(template) ? code : `(${code})`;
+    }
+
+    /**
+     * Whether a Java template is one primary expression (a call or a chain of them), so its
+     * result needs no parentheses: nothing but `new` is spaced apart outside brackets and literals
+     */
+    private static isPrimaryTemplate(template: string): boolean {
+        let depth = 0;
+        for (let i = 0; i < template.length; i++) {
+            const ch = template[i];
+            if (ch === '"' || ch === '\'') {
+                for (i++; i < template.length && template[i] !== ch; i++) {
+                    if (template[i] === '\\') i++;
+                }
+            } else if (ch === '(' || ch === '[') {
+                depth++;
+            } else if (ch === ')' || ch === ']') {
+                depth--;
+            } else if (depth === 0 && /\s/.test(ch) && !/\bnew$/.test(template.slice(0, i))) {
+                return false;
+            }
+        }
+        return true;
+    }
+
+    /**
+     * `fmt.Println(a, b)` → `System.out.println(a + " " + b)`, the operands joined with spaces
+     * as Go prints them; `fmt.Printf` formats like `fmt.Sprintf`, and `fmt.Sprint(x)` is
+     * `String.valueOf(x)`
+     */
+    private print(name: string, call: GoCallExpr): string | undefined {
+        if (!['fmt.Println', 'fmt.Print', 'fmt.Printf', 'fmt.Sprint'].includes(name)) {
+            return undefined;
+        }
+        if (call.ellipsis) {
+            throw new UnsupportedConstructError(`Spread arguments of ${name} are not converted yet`);
+        }
+        if (name === 'fmt.Printf') {
+            const [format, ...args] = call.args;
+            if (!format) {
+                throw new UnsupportedConstructError('fmt.Printf without a format is not converted yet');
+            }
+            return `System.out.print(${args.length === 0 ? this.expr(format) : this.stringFormat(format, args)})`;
+        }
+        // Go's Print and Sprint only space out operands that are not strings
+        if ((name === 'fmt.Print' || name === 'fmt.Sprint') && call.args.length !== 1) {
+            throw new UnsupportedConstructError(`${name} of several values is not converted yet`);
+        }
+        if (name === 'fmt.Sprint') {
+            return this.isStringType(this.typeOf(call.args[0])) ? this.expr(call.args[0]) : this.spaceJoined(call.args);
+        }
+        const method = name === 'fmt.Println' ? 'println' : 'print';
+        if (call.args.length === 1 && !this.isErrorValue(call.args[0])) {
+            return `System.out.${method}(${this.printed(call.args[0])})`;
+        }
+        return `System.out.${method}(${this.spaceJoined(call.args)})`;
+    }
+
+    private isPackageCall(call: GoCallExpr, pkg: string, name: string): boolean {
+        return call.fun.kind === 'Selector' && call.fun.sel === name
+            && call.fun.x.kind === 'Ident' && call.fun.x.name === pkg
+            && !this.lookup(pkg) && this.isImportedPackage(pkg);
+    }
+
+    /**
+     * `fmt.Sprintf(format, args...)` → `String.format(format, args...)`
+     */
+    private stringFormat(format: GoExpr, args: GoExpr[]): string {
+        const code = this.expr(format);
+        const javaFormat = format.kind === 'BasicLit' ? this.translateFormatVerbs(code) : code;
+        // Arguments only %c formats stay chars; Java's %d rejects a char where Go's formats a rune
+        const verbs = new Map<number, string[]>();
+        let next = 0;
+        for (const match of format.kind === 'BasicLit' ? code.matchAll(FORMAT_VERB) : []) {
+            if (match[5] !== '%') {
+                const i = match[1] ? Number(match[1].slice(1, -1)) - 1 : next++;
+                verbs.set(i, [...(verbs.get(i) || []), match[5]]);
+            }
+        }
+        const javaArgs = args.map((a, i) => verbs.get(i)?.every(verb => verb === 'c') ? this.expr(a) : this.printed(a));
+        return `String.format(${[javaFormat, ...javaArgs].join(', ')})`;
+    }
+
+    /**
+     * Java for a value Go prints or formats: a rune is its number, which Java prints for an
+     * int and not a char, and an error or recovered panic its message
+     */
+    private printed(e: GoExpr, minPrec = 0): string {
+        if (this.isErrorValue(e) || (e.kind === 'Ident' && this.lookup(e.name)?.recovered)) {
+            return `${this.expr(e, PRIMARY_PRECEDENCE)}.getMessage()`;
+        }
+        const type = this.typeOf(e);
+        if (!type || type.isPointer || type.isSlice || type.isMap || this.javaType(type) !== 'char') {
+            return this.expr(e, minPrec);
+        }
+        const cast = `(int) ${this.expr(e, UNARY_PRECEDENCE)}`;
+        return minPrec > UNARY_PRECEDENCE ? `(${cast})` : cast;
+    }
+
+    /**
+     * Rewrite the verbs of a Go format string literal (already in Java syntax) for String.format.
+     * Flags, width and precision mean the same in both; verbs without an equivalent are rejected.
+     */
+    private translateFormatVerbs(literal: string): string {
+        const verbs = [...literal.matchAll(FORMAT_VERB)];
+        const indexed = verbs.filter(m => m[5] !== '%' && m[1]);
+        if (indexed.length > 0 && indexed.length !== verbs.filter(m => m[5] !== '%').length) {
+            // Go's implicit indexes continue after an explicit one; Java's do not
+            throw new UnsupportedConstructError('Mixing explicit and implicit format argument indexes is not converted yet');
+        }
+
+        return literal.replace(FORMAT_VERB, (verbText, index, flags, width, precision, verb) => {
+            if (width === '*' || precision === '*') {
+                throw new UnsupportedConstructError(`Format width '${verbText}' taken from an argument is not converted yet`);
+            }
+            if (verb === 'v' && /[+#]/.test(flags)) {
+                throw new UnsupportedConstructError(`Format verb '${verbText}' has no String.format equivalent`);
+            }
+            const javaVerb = FORMAT_VERB_MAP[verb] || (JAVA_FORMAT_VERBS.has(verb) ? verb : undefined);
+            if (!javaVerb) {
+                throw new UnsupportedConstructError(`Format verb '${verbText}' has no String.format equivalent`);
+            }
+            const argIndex = index ? `${index.slice(1, -1)}$` : '';
+            return `%${argIndex}${flags}${width || ''}${precision !== undefined ? `.${precision}` : ''}${javaVerb}`;
+        });
+    }
+
+    // ═══════════════════════════════════════════════════════════════
+    // Symbols and types
+    // ═══════════════════════════════════════════════════════════════
+
+    private declare(name: string, type?: GoType): LocalVariable {
+        const variable: LocalVariable = { javaName: GoFunctionParser.escapeJavaKeyword(name), type };
+        this.scopes[this.scopes.length - 1].set(name, variable);
+        return variable;
+    }
+
+    private lookup(name: string): LocalVariable | undefined {
+        for (let i = this.scopes.length - 1; i >= 0; i--) {
+            const variable = this.scopes[i].get(name);
+            if (variable) {
+                return variable;
+            }
+        }
+        return undefined;
+    }
+
+    private toJavaLocalName(name: string): string {
+        return GoFunctionParser.toJavaMethodName(name);
+    }
+
+    private isImportedPackage(name: string): boolean {
+        if (!this.goFile) {
+            return /^[a-z]/.test(name);
+        }
+        return this.goFile.imports.some(imp => (imp.alias || imp.path.split('/').pop()) === name);
+    }
+
+    private findFunction(name: string): GoFunction | undefined {
+        if (!this.enclosing.isMethod && this.enclosing.name === name) {
+            return this.enclosing;
+        }
+        return this.goFile?.functions.find(f => f.name === name && !f.isMethod);
+    }
+
+    private findStruct(name: string): GoStruct | undefined {
+        return this.localStructs.find(s => s.name === name) || this.goFile?.structs.find(s => s.name === name);
+    }
+
+    /**
+     * The type Java writes for type, with aliases and defined types spelled as their
+     * underlying type resolved (a `Celsius` is a float64)
+     */
+    private underlying(type: GoType): GoType {
+        const bindings = this.options.underlyingTypes;
+        return bindings?.size ? GoFunctionParser.substituteTypeParams(type, bindings) : type;
+    }
+
+    /**
+     * The defined type behind type when the wrapper strategy makes it a class of its own
+     */
+    private wrapperType(type?: GoType): GoNamedType | undefined {
+        if (!type || type.isPointer || type.isSlice || type.isMap || this.options.definedTypes !== 'wrapper') {
+            return undefined;
+        }
+        const named = this.goFile?.namedTypes.find(t => t.name === type.name);
+        return named && JavaCodeGenerator.namedTypeForm(named, this.options, this.goFile) === 'wrapper' ? named : undefined;
+    }
+
+    /**
+     * The defined type and method of `x.M` when x's type is written as its underlying
+     * type, whose methods become static methods taking the receiver first
+     */
+    private underlyingMethod(fun: GoExpr): { type: GoNamedType; method: GoFunction } | undefined {
+        if (fun.kind !== 'Selector') {
+            return undefined;
+        }
+        const declared = this.declaredTypeOf(fun.x);
+        const type = declared && !declared.isSlice && !declared.isMap
+            ? this.goFile?.namedTypes.find(t => t.name === declared.name && !t.isAlias && this.options.underlyingTypes?.has(t.name))
+            : undefined;
+        const method = type?.methods.find(m => m.name === fun.sel);
+        return type && method ? { type, method } : undefined;
+    }
+
+    private structOf(type?: GoType): GoStruct | undefined {
+        if (!type || type.isSlice || type.isMap) {
+            return undefined;
+        }
+        return this.findStruct(type.name.replace(/^\*/, ''));
+    }
+
+    /**
+     * The signature of method name of the interface type named interfaceName, declared by
+     * the interface or one it embeds
+     */
+    private interfaceMethod(interfaceName: string, name: string, seen: Set<string>): GoMethodSignature | undefined {
+        const iface = this.goFile?.interfaces.find(t => t.name === interfaceName);
+        if (!iface || seen.has(interfaceName)) {
+            return undefined;
+        }
+        seen.add(interfaceName);
+        return iface.methods.find(m => m.name === name)
+            || (iface.embeddedInterfaces || []).map(embedded => this.interfaceMethod(embedded, name, seen)).find(m => m);
+    }
+
+    /**
+     * Find the Go function or method a call expression refers to
+     */
+    private resolveCallee(fun: GoExpr): GoFunction | undefined {
+        if (fun.kind === 'Ident' && !this.lookup(fun.name)) {
+            return this.findFunction(fun.name);
+        }
+        if (fun.kind === 'Selector') {
+            const named = this.underlyingMethod(fun);
+            if (named) {
+                return named.method;
+            }
+            const receiverType = this.typeOf(fun.x);
+            const struct = this.structOf(receiverType);
+            if (struct) {
+                return this.promotion(struct, fun.sel)?.owner.methods.find(m => m.name === fun.sel);
+            }
+            // An interface method is known by its signature alone, so it may always fail
+            const signature = receiverType && !receiverType.isSlice && !receiverType.isMap
+                ? this.interfaceMethod(receiverType.name, fun.sel, new Set()) : undefined;
+            if (signature) {
+                return {
+                    name: signature.name,
+                    parameters: signature.parameters,
+                    returnTypes: signature.returnTypes,
+                    isMethod: true,
+                    hasErrorReturn: signature.returnTypes.some(t => t.name === 'error'),
+                    namePosition: signature.namePosition,
+                    doc: signature.doc
+                };
+            }
+            if (this.enclosing.isMethod && this.enclosing.name === fun.sel) {
+                return this.enclosing;
+            }
+            // A mapped standard library function, as far as its results are known
+            const name = this.packageFunction(fun);
+            const returns = name && this.stdlibMapping(name)?.returns;
+            if (returns) {
+                return GoFunctionParser.parseFunction(`func ${fun.sel}() (${returns})`) || undefined;
+            }
+        }
+        return undefined;
+    }
+
+    private javaType(type: GoType): string {
+        if (type.isVariadic) {
+            // Varargs parameters are arrays inside the method body
+            return `${JavaCodeGenerator.toJavaType(GoFunctionParser.elementTypeOf(type), this.options)}[]`;
+        }
+        // Standard library types the file generator maps for fields too (time.Time → Instant)
+        const stdlib = !type.isSlice && !type.isMap ? lookupStdlibType(type.name) : undefined;
+        if (stdlib) {
+            return stdlib.javaType;
+        }
+        return JavaCodeGenerator.toJavaType(type, this.options);
+    }
+
+    private isList(type: GoType): boolean {
+        return type.isSlice && !type.isVariadic && this.options.sliceStrategy !== 'array'
+            && !GoFunctionParser.isByteSlice(type);
+    }
+
+    private isStringType(type?: GoType): boolean {
+        return !!type && type.name === 'string' && !type.isSlice && !type.isMap && !type.isPointer;
+    }
+
+    private isNil(e: GoExpr): boolean {
+        return e.kind === 'Ident' && e.name === 'nil' && !this.lookup('nil');
+    }
+
+    private simpleType(name: string): GoType {
+        return { name, isPointer: false, isSlice: false, isMap: false, isVariadic: false };
+    }
+
+    /**
+     * A generic function called with explicit type arguments (`Map[int, string]`):
+     * the function's name and the type arguments
+     */
+    private instantiation(fun: GoExpr): { fun: GoIdent; typeArgs: GoType[] } | undefined {
+        let name: string;
+        let typeArgs: GoType[];
+        if (fun.kind === 'Index' && fun.x.kind === 'Ident') {
+            name = fun.x.name;
+            typeArgs = [fun.index.kind === 'TypeExpr' ? fun.index.type
+                : this.simpleType(fun.index.kind === 'Ident' ? fun.index.name : '')];
+        } else if (fun.kind === 'TypeExpr' && fun.typeKind === 'named' && fun.type.typeArgs && !fun.type.name.includes('.')) {
+            name = fun.type.name;
+            typeArgs = fun.type.typeArgs;
+        } else {
+            return undefined;
+        }
+        if (this.lookup(name) || !this.findFunction(name)?.typeParams) {
+            return undefined;
+        }
+        return { fun: { kind: 'Ident', name, pos: fun.pos }, typeArgs };
+    }
+
+    /**
+     * Result type of a call to a generic function or to a method of a generic type,
+     * with its type parameters bound from explicit type arguments, the argument types
+     * and the receiver's type arguments
+     */
+    private genericResult(callee: GoFunction, call: GoCallExpr, result: GoType, typeArgs?: GoType[]): GoType | undefined {
+        const bindings = new Map<string, GoType | undefined>();
+        const receiverArgs = callee.receiver?.type.typeArgs;
+        if (receiverArgs && call.fun.kind === 'Selector') {
+            const owner = this.typeOf(call.fun.x);
+            receiverArgs.forEach((param, i) => bindings.set(param.name, owner?.typeArgs?.[i]));
+        }
+        if (callee.typeParams) {
+            callee.typeParams.forEach((param, i) => bindings.set(param.name, typeArgs?.[i]));
+            const inferred = new Map<string, GoType>();
+            const names = new Set(callee.typeParams.map(p => p.name));
+            callee.parameters.forEach((param, i) => {
+                const args = param.type.isVariadic && !call.ellipsis ? call.args.slice(i) : [call.args[i]];
+                const paramType = param.type.isVariadic && !call.ellipsis ? GoFunctionParser.elementTypeOf(param.type) : param.type;
+                for (const arg of args) {
+                    const argType = arg && this.typeOf(arg);
+                    if (argType) {
+                        GoFunctionParser.inferTypeArgs(paramType, argType, names, inferred);
+                    }
+                }
+            });
+            inferred.forEach((type, name) => bindings.set(name, bindings.get(name) || type));
+        }
+        return bindings.size === 0 ? result : this.substituteTypeParams(result, bindings);
+    }
+
+    /**
+     * Substitute type parameters; undefined when one it mentions is not bound
+     */
+    private substituteTypeParams(type: GoType, bindings: Map<string, GoType | undefined>): GoType | undefined {
+        const unbound = [...bindings].filter(([, bound]) => !bound).map(([name]) => name);
+        const substituted = GoFunctionParser.substituteTypeParams(type, new Map([...bindings].filter((b): b is [string, GoType] => !!b[1])));
+        return unbound.some(name => new RegExp(`\\b${name}\\b`).test(GoFunctionParser.typeText(substituted)))
+            ? undefined
+            : substituted;
+    }
+
+    /**
+     * Best-effort static type of a Go expression
+     */
+    private typeOf(e: GoExpr): GoType | undefined {
+        const type = this.declaredTypeOf(e);
+        return type && this.underlying(type);
+    }
+
+    /**
+     * Type of an expression as declared, before aliases and defined types written as
+     * their underlying type are resolved
+     */
+    private declaredTypeOf(e: GoExpr): GoType | undefined {
+        switch (e.kind) {
+            case 'Ident': {
+                const local = this.lookup(e.name);
+                if (local) {
+                    return local.type;
+                }
+                if (e.name === 'true' || e.name === 'false') {
+                    return this.simpleType('bool');
+                }
+                const global = this.goFile?.variables.find(v => v.name === e.name)
+                    || this.goFile?.constants.find(c => c.name === e.name);
+                // `var names = []string{...}` has the type its literal spells out
+                return global?.type || (global?.value?.includes('{') ? this.literalType(global.value) : undefined);
+            }
+            case 'BasicLit':
+                switch (e.litKind) {
+                    // Untyped constants past Java's int and char are long and int
+                    case 'INT': return this.simpleType(goIntegerValue(e.value) > 2147483647n ? 'int64' : 'int');
+                    case 'FLOAT': return this.simpleType('float64');
+                    case 'STRING': return this.simpleType('string');
+                    case 'CHAR': return this.simpleType(javaCharLiteral(e.value) === undefined ? 'int32' : 'rune');
+                    default: return undefined;
+                }
+            case 'Paren':
+                return this.typeOf(e.x);
+            case 'Binary':
+                if (['==', '!=', '<', '<=', '>', '>=', '&&', '||'].includes(e.op)) {
+                    return this.simpleType('bool');
+                }
+                if (e.op === '<<' || e.op === '>>') {
+                    return this.typeOf(e.x);
+                }
+                return this.typeOf(e.x) || this.typeOf(e.y);
+            case 'Unary':
+                if (e.op === '!') {
+                    return this.simpleType('bool');
+                }
+                if (e.op === '<-') {
+                    return this.channelElement(e.x);
+                }
+                return this.typeOf(e.x);
+            case 'Selector': {
+                const rowField = this.rowField(e);
+                if (rowField) {
+                    return this.typeOf(rowField);
+                }
+                const owner = this.typeOf(e.x);
+                const struct = this.structOf(owner);
+                const promoted = struct && this.promotion(struct, e.sel);
+                const field = promoted?.owner.fields.find(f => f.name === e.sel)?.type;
+                if (field && promoted!.owner === struct && struct.typeParams) {
+                    // Field of a generic struct: items []T of a Stack[int] is an []int
+                    const bindings = new Map(struct.typeParams.map((p, i): [string, GoType | undefined] => [p.name, owner!.typeArgs?.[i]]));
+                    return this.substituteTypeParams(field, bindings);
+                }
+                return field;
+            }
+            case 'SliceExpr':
+                return this.typeOf(e.x);
+            case 'Index': {
+                const container = this.typeOf(e.x);
+                if (!container) {
+                    return undefined;
+                }
+                if (container.isMap) {
+                    return container.valueType;
+                }
+                if (container.isSlice) {
+                    return GoFunctionParser.elementTypeOf(container);
+                }
+                if (this.isStringType(container)) {
+                    return this.simpleType('byte');
+                }
+                return undefined;
+            }
+            case 'Call': {
+                if (e.fun.kind === 'Ident' && !this.lookup(e.fun.name)) {
+                    if (e.fun.name === 'len' || e.fun.name === 'cap') {
+                        return this.simpleType('int');
+                    }
+                    if (e.fun.name === 'make' && e.args[0]?.kind === 'TypeExpr') {
+                        return e.args[0].type;
+                    }
+                    if ((e.fun.name === 'min' || e.fun.name === 'max') && !this.resolveCallee(e.fun)) {
+                        // Untyped constants take the type of the other operands
+                        const typed = e.args.find(a => !this.isConstant(a)) || e.args[0];
+                        return typed && this.typeOf(typed);
+                    }
+                    if (e.fun.name === 'new' && e.args[0] && !this.resolveCallee(e.fun)) {
+                        const target = e.args[0].kind === 'TypeExpr' ? e.args[0].type
+                            : e.args[0].kind === 'Ident' ? this.simpleType(e.args[0].name) : undefined;
+                        return target && { ...target, isPointer: true };
+                    }
+                    if (BUILTIN_TYPE_NAMES.has(e.fun.name) || this.goFile?.namedTypes.some(t => t.name === e.fun.name)) {
+                        return this.simpleType(e.fun.name);
+                    }
+                }
+                if (e.fun.kind === 'TypeExpr' && !this.instantiation(e.fun)) {
+                    return e.fun.type;
+                }
+                if (this.isPackageCall(e, 'fmt', 'Sprintf')) {
+                    return this.simpleType('string');
+                }
+                if (this.constructedErrorMessage(e)) {
+                    return this.simpleType('error');
+                }
+                const generic = this.instantiation(e.fun);
+                const callee = this.resolveCallee(generic?.fun || e.fun);
+                const results = (callee?.returnTypes || GoFunctionParser.funcSignature(this.typeOf(e.fun))?.results || [])
+                    .filter(t => t.name !== 'error');
+                if (results.length !== 1) {
+                    return undefined;
+                }
+                return callee ? this.genericResult(callee, e, results[0], generic?.typeArgs) : results[0];
+            }
+            case 'FuncLit':
+                return e.type.type;
+            case 'Star': {
+                const pointer = this.typeOf(e.x);
+                return pointer?.isPointer ? { ...pointer, isPointer: false } : undefined;
+            }
+            case 'CompositeLit':
+                return e.type?.type;
+            case 'TypeAssert':
+                return e.type?.type;
+            default:
+                return undefined;
+        }
+    }
+}
@@ -1,7 +1,10 @@
-import { GoFile, GoStruct, GoInterface, GoVariable, GoField } from './goFileParser';
-import { GoFunction, GoFunctionParser, GoType } from './goParser';
-import { JavaCodeGenerator, JavaGenerationOptions } from './javaGenerator';
+import { GoFile, GoStruct, GoInterface, GoMethodSignature, GoNamedType, GoVariable, GoField, parseStructTag, evaluateIntegerConstant } from './goFileParser';
+import { GoFunction, GoFunctionParser, GoType, JAVA_KEYWORDS } from './goParser';
+import { DEFAULT_JAVA_VERSION, JavaCodeGenerator, JavaGenerationOptions, StringEnum } from './javaGenerator';
+import { JavaBodyGenerator } from './javaBodyGenerator';
 import { ConversionContext, lookupStdlibType, StdlibTypeMapping, createConversionContext } from './conversionContext';
+import { collectJavaImports, remainingGoPackages, usesJUnitAssertions } from './javaImports';
+import { formatJava, JavaFormat } from './javaFormatter';
 
 export interface JavaFileGenerationOptions extends JavaGenerationOptions {
     packageName?: string;
@@ -13,8 +16,49 @@ export interface JavaFileGenerationOptions extends JavaGenerationOptions {
     includeExternalTypes?: boolean;
     /** Add educational notes about stdlib type mappings */
     addStdlibNotes?: boolean;
+    /** Emit Jackson annotations for `json` struct tags */
+    includeJsonAnnotations?: boolean;
+    /** JavaBeans accessors for exported fields (isX() for booleans) */
+    javaBeans?: boolean;
+    /** Classes whose static members are imported on demand (sibling files of a package) */
+    staticImports?: string[];
+    /** When the file declares exactly one type, make it the public top-level class instead of nesting it */
+    topLevelType?: boolean;
+    /** Give structs value semantics: equals/hashCode/toString, or a record on Java 17+ */
+    valueMethods?: boolean;
+    /** Declare `implements` for the interfaces in scope whose whole method set a struct has */
+    inferImplements?: boolean;
+    /** Declare time.Duration constants and vars as a long of milliseconds instead of a java.time.Duration */
+    durationMillis?: boolean;
+    /** Give structs with at least builderMinFields fields a nested Builder, and make their constructor package-private */
+    builder?: boolean;
+    /** Fewest fields a struct needs for a builder (default: 4) */
+    builderMinFields?: number;
+    /** Layout of the finished file: 'none' keeps it as emitted, otherwise formatJava reindents it */
+    format?: JavaFormat;
 }
 
+const DEFAULT_BUILDER_MIN_FIELDS = 4;
+
+/** Nanoseconds in each unit constant of Go's time package */
+const TIME_UNITS: { [unit: string]: bigint } = {
+    Nanosecond: 1n,
+    Microsecond: 1000n,
+    Millisecond: 1000000n,
+    Second: 1000000000n,
+    Minute: 60000000000n,
+    Hour: 3600000000000n
+};
+
+/** Duration factory for the largest unit dividing a duration, largest first */
+const DURATION_FACTORIES: [string, bigint][] = [
+    ['ofHours', TIME_UNITS.Hour],
+    ['ofMinutes', TIME_UNITS.Minute],
+    ['ofSeconds', TIME_UNITS.Second],
+    ['ofMillis', TIME_UNITS.Millisecond],
+    ['ofNanos', TIME_UNITS.Nanosecond]
+];
+
 export class JavaFileGenerator {
     /**
      * Generate a complete Java file from a parsed Go file
@@ -32,29 +76,31 @@ export class JavaFileGenerator {
             includeGettersSetters: true,
             includeComments: true,
             includeExternalTypes: true,
-            addStdlibNotes: true
+            addStdlibNotes: true,
+            includeJsonAnnotations: true
         },
         context?: ConversionContext
     ): string {
         const lines: string[] = [];
-        
-        // Create context if not provided
-        const ctx = context || createConversionContext(goFile);
 
-        // Determine class name from package name or option
-        const className = options.className || this.toJavaClassName(goFile.packageName) || 'GoConverter';
+        // Copies of structs join the structs; the other named types resolve through options
+        ({ goFile, options } = this.withNamedTypes(goFile, options));
 
-        // Collect required Java imports
-        const javaImports = this.collectJavaImports(goFile, ctx);
-        
-        // Add imports
-        for (const imp of javaImports) {
-            lines.push(`import ${imp};`);
+        // Create context if not provided
+        const ctx = context ? { ...context, mainFile: goFile } : createConversionContext(goFile);
+
+        if (options.packageName && !this.isValidPackageName(options.packageName)) {
+            throw new Error(`Invalid Java package name '${options.packageName}'`);
         }
-        lines.push('');
 
-        // Add file-level comment
-        if (options.includeComments) {
+        // A lone type can stand in for the wrapper class
+        const promoted = this.promotedType(goFile, options);
+
+        // Determine class name from the promoted type, the option or the package name
+        const className = promoted?.name || options.className || this.toJavaClassName(goFile.packageName) || 'GoConverter';
+
+        // Add file-level comment (a promoted type carries its own)
+        if (options.includeComments && !promoted) {
             lines.push('/**');
             lines.push(` * Converted from Go package: ${goFile.packageName || 'main'}`);
             lines.push(' *');
@@ -63,33 +109,81 @@ export class JavaFileGenerator {
             lines.push(' * - Go interfaces → Java interfaces');
             lines.push(' * - Package-level functions → Static methods');
             lines.push(' * - Package-level variables → Static fields');
-            lines.push(' * - Function bodies → TODO stubs (manual implementation needed)');
+            lines.push(' * - Function bodies → translated statement by statement (unsupported constructs left as TODO comments)');
             lines.push(' *');
             lines.push(' * This is an educational tool to help Java developers understand Go code.');
             lines.push(' * The generated Java is a structural equivalent, not a direct translation.');
             lines.push(' */');
         }
 
-        // Main class declaration
-        lines.push(`public class ${className} {`);
+        // Main class declaration; a promoted type supplies its Javadoc, header and members
+        let promotedMembers: string[] = [];
+        if (promoted) {
+            const declaration = (goFile.structs.length === 1
+                ? this.generateJavaClass(goFile.structs[0], options, ctx)
+                : this.generateJavaInterface(goFile.interfaces[0], options, ctx)
+            ).split('\n');
+            const header = declaration.findIndex(line => line.startsWith('public '));
+            lines.push(...declaration.slice(0, header));
+            lines.push(declaration[header].replace('public static class ', 'public class '));
+            promotedMembers = declaration.slice(header + 1, -1);
+            while (promotedMembers.length > 0 && promotedMembers[0] === '') {
+                promotedMembers.shift();
+            }
+        } else {
+            lines.push(`public class ${className} {`);
+        }
         lines.push('');
 
+        // Goroutines of the file run as tasks of one executor, which its nested classes share
+        const functions = [...goFile.functions, ...goFile.structs.flatMap(s => s.methods), ...goFile.namedTypes.flatMap(t => t.methods)];
+        if (options.experimentalConcurrency && functions.some(f => JavaBodyGenerator.startsGoroutines(f))) {
+            const executor = (options.javaVersion || DEFAULT_JAVA_VERSION) >= 21 ? 'newVirtualThreadPerTaskExecutor' : 'newCachedThreadPool';
+            lines.push(`    private static final ExecutorService executor = Executors.${executor}();`);
+            lines.push('');
+        }
+
         // Generate static fields from package variables and constants
         if (goFile.variables.length > 0 || goFile.constants.length > 0) {
             lines.push('    // Package-level variables and constants');
-            for (const constant of goFile.constants) {
-                const javaField = this.generateStaticField(constant, true);
+            // Enums are found package-wide, where their String() may be declared, and emitted with their constants
+            const enums = new Map([...JavaCodeGenerator.enumTypes(options, options.packageFile || goFile)]
+                .filter(([, members]) => goFile.constants.some(c => c.name === members[0].name)));
+            for (const [name, members] of enums) {
+                lines.push(...this.generateEnum(name, members, options, ctx).map(l => '    ' + l));
+            }
+            const stringEnums = JavaCodeGenerator.stringEnums(options, goFile);
+            for (const stringEnum of stringEnums) {
+                lines.push(...this.generateStringEnum(stringEnum, options).map(l => '    ' + l));
+            }
+            const enumMembers = new Set([...[...enums.values()].flat(), ...stringEnums.flatMap(e => e.members.map(m => m.constant))]);
+            for (const constant of goFile.constants.filter(c => !enumMembers.has(c))) {
+                const javaField = this.generateStaticField(constant, true, options, goFile);
                 lines.push(javaField);
             }
             for (const variable of goFile.variables) {
-                const javaField = this.generateStaticField(variable, false);
+                const javaField = this.generateStaticField(variable, false, options, goFile);
                 lines.push(javaField);
             }
             lines.push('');
         }
 
+        // init functions run once the fields are set, as Go runs them after initializing the vars
+        const inits = goFile.functions.filter(f => JavaCodeGenerator.isInitFunction(f));
+        if (inits.length > 0) {
+            this.generateStaticInitializer(inits, options, goFile).forEach(line => lines.push('    ' + line));
+            lines.push('');
+        }
+
+        if (promotedMembers.length > 0) {
+            lines.push(...promotedMembers);
+            if (promotedMembers[promotedMembers.length - 1] !== '') {
+                lines.push('');
+            }
+        }
+
         // Generate inner classes from structs
-        for (const struct of goFile.structs) {
+        for (const struct of promoted ? [] : goFile.structs) {
             const javaClass = this.generateJavaClass(struct, options, ctx);
             javaClass.split('\n').forEach(line => {
                 lines.push('    ' + line);
@@ -97,8 +191,8 @@ export class JavaFileGenerator {
             lines.push('');
         }
 
-        // Generate inner interfaces
-        for (const iface of goFile.interfaces) {
+        // Generate inner interfaces; constraint interfaces live on as type parameter bounds
+        for (const iface of promoted ? [] : goFile.interfaces.filter(i => !i.typeTerms)) {
             const javaInterface = this.generateJavaInterface(iface, options, ctx);
             javaInterface.split('\n').forEach(line => {
                 lines.push('    ' + line);
@@ -106,6 +200,13 @@ export class JavaFileGenerator {
             lines.push('');
         }
 
+        // Aliases and defined types that are not structs or enums
+        const namedTypes = goFile.namedTypes.map(t => this.generateNamedType(t, options, ctx)).filter(t => t.length > 0);
+        for (const namedType of namedTypes) {
+            namedType.forEach(line => lines.push('    ' + line));
+            lines.push('');
+        }
+
         // Generate external types from dependencies (if enabled)
         if (options.includeExternalTypes && ctx.externalStructs.length > 0) {
             lines.push('    // ═══════════════════════════════════════════════════════════════');
@@ -132,15 +233,16 @@ export class JavaFileGenerator {
             }
         }
 
-        // Generate static methods from package-level functions
-        if (goFile.functions.length > 0) {
+        // Generate static methods from package-level functions; factories live in their struct
+        const packageFunctions = goFile.functions
+            .filter(f => !JavaCodeGenerator.isInitFunction(f) && !JavaCodeGenerator.factoryOwner(f, options, options.packageFile || goFile));
+        if (packageFunctions.length > 0) {
             lines.push('    // Package-level functions');
-            for (const func of goFile.functions) {
-                const javaMethod = JavaCodeGenerator.generateJavaMethod(func, {
-                    ...options,
-                    isStatic: true,
-                    addComments: true
-                });
+            for (const func of packageFunctions) {
+                const methodOptions = { ...options, isStatic: true, addComments: true };
+                const javaMethod = options.junit && JavaCodeGenerator.isTestFunction(func)
+                    ? JavaCodeGenerator.generateTestMethod(func, methodOptions, goFile)
+                    : JavaCodeGenerator.generateJavaMethod(func, methodOptions, goFile);
                 javaMethod.split('\n').forEach(line => {
                     lines.push('    ' + line);
                 });
@@ -148,50 +250,235 @@ export class JavaFileGenerator {
             }
         }
 
+        // Result records for (value, error) returns
+        const records = this.collectResultRecords(goFile, options);
+        if (records.length > 0) {
+            lines.push('    // Result records for (value, error) returns');
+            records.forEach(record => lines.push('    ' + record));
+            lines.push('');
+        }
+
         lines.push('}');
 
-        return lines.join('\n');
+        // Imports are collected from the finished class, so they list exactly the types it uses
+        const header: string[] = options.packageName ? [`package ${options.packageName};`, ''] : [];
+        const imports = [
+            ...collectJavaImports(lines.join('\n'), options.junit, options.nullability).map(imp => `import ${imp};`),
+            ...(options.staticImports || []).map(imp => `import static ${imp}.*;`),
+            ...(options.junit && usesJUnitAssertions(lines.join('\n')) ? ['import static org.junit.jupiter.api.Assertions.*;'] : [])
+        ];
+        if (imports.length > 0) {
+            header.push(...imports, '');
+        }
+
+        // Go imports only carry over where a use was left untranslated
+        const goPackages = remainingGoPackages(lines.join('\n'), goFile.imports);
+        if (goPackages.length > 0) {
+            const packages = `Go package${goPackages.length > 1 ? 's' : ''} ${goPackages.join(', ')}`;
+            header.push(`// TODO: Uses of the ${packages} have no Java translation and are kept as written`, '');
+            goPackages.forEach(name => options.diagnostics?.push({
+                severity: 'unsupported',
+                construct: 'GoImport',
+                message: `Uses of Go package '${name}' have no Java translation and are kept as written`
+            }));
+        }
+
+        const source = [...header, ...lines].join('\n');
+        return options.format === 'none' ? source : formatJava(source);
+    }
+
+    /**
+     * The file and options with its aliases and defined types resolved: defined types over
+     * structs join the structs as copies, methods of enum types move to the enum wherever
+     * its constants are declared (string enums hold none, so theirs stay package functions),
+     * and underlyingTypes maps the types written as their underlying type
+     */
+    private static withNamedTypes(goFile: GoFile, options: JavaFileGenerationOptions): { goFile: GoFile; options: JavaFileGenerationOptions } {
+        const scope = options.packageFile || goFile;
+        if (scope.namedTypes.length === 0) {
+            return { goFile, options };
+        }
+        const resolve = (file: GoFile): GoFile => {
+            const form = (t: GoNamedType) => JavaCodeGenerator.namedTypeForm(t, options, scope);
+            const copies = file.namedTypes.filter(t => form(t) === 'copy').map((t): GoStruct => {
+                const struct = scope.structs.find(s => s.name === JavaCodeGenerator.definedUnderlying(t, scope).name)!;
+                return { ...struct, name: t.name, methods: t.methods, namePosition: t.namePosition, range: undefined, doc: t.doc };
+            });
+            // The enum, declared wherever its constants are, holds the methods
+            const enums = JavaCodeGenerator.enumTypes(options, scope);
+            const enumMethods = file.namedTypes.filter(t => form(t) === 'enum' && !enums.has(t.name)).flatMap(t => t.methods);
+            return { ...file, structs: [...file.structs, ...copies], functions: [...file.functions, ...enumMethods] };
+        };
+        const packageFile = options.packageFile && resolve(options.packageFile);
+        const resolved = resolve(goFile);
+        return {
+            goFile: resolved,
+            options: {
+                ...options,
+                packageFile,
+                underlyingTypes: JavaCodeGenerator.underlyingTypeBindings(options, packageFile || resolved)
+            }
+        };
     }
 
     /**
-     * Collect all required Java imports based on types used
+     * Java for an alias or defined type that is neither a struct copy nor an enum. A type
+     * written as its underlying type gets a comment saying so, and its methods become
+     * static methods taking the receiver first (`Celsius.Fahrenheit` → `celsiusFahrenheit(c)`);
+     * a wrapper becomes a final class, or a record on Java 17+, holding the value.
      */
-    private static collectJavaImports(goFile: GoFile, ctx: ConversionContext): string[] {
-        const imports = new Set<string>();
-        imports.add('java.util.*');
+    private static generateNamedType(named: GoNamedType, options: JavaFileGenerationOptions, ctx: ConversionContext): string[] {
+        const scope = options.packageFile || ctx.mainFile;
+        const form = JavaCodeGenerator.namedTypeForm(named, options, scope);
+        if (form === 'copy' || form === 'enum') {
+            return [];
+        }
+        const underlying = named.isAlias ? named.underlying : JavaCodeGenerator.definedUnderlying(named, scope);
+        const javaType = JavaCodeGenerator.toJavaType(underlying, options);
+        const lines: string[] = [];
+        const methodOptions = { ...options, addComments: true };
 
-        // Scan all types for stdlib mappings that require imports
-        const allTypes: GoType[] = [];
-        
-        for (const struct of goFile.structs) {
-            for (const field of struct.fields) {
-                allTypes.push(field.type);
+        if (form === 'underlying') {
+            if (options.includeComments || named.methods.length > 0) {
+                const declaration = `${named.name}${named.isAlias ? ' =' : ''} ${GoFunctionParser.typeText(named.underlying)}`;
+                lines.push(`// Go ${named.isAlias ? 'alias' : 'type'} ${declaration} is written as ${javaType}`);
             }
+            for (const method of named.methods) {
+                const receiver = method.receiver!;
+                const func: GoFunction = {
+                    ...method,
+                    name: named.name + method.name,
+                    isMethod: false,
+                    receiver: undefined,
+                    parameters: [{ ...receiver, name: receiver.name || 'value', type: { ...receiver.type, name: named.name, isPointer: false } }, ...method.parameters],
+                    doc: method.doc || `Go method ${named.name}.${method.name}, taking the receiver as its first parameter`
+                };
+                lines.push('');
+                lines.push(...JavaCodeGenerator.generateJavaMethod(func, { ...methodOptions, isStatic: true }, ctx.mainFile).split('\n'));
+            }
+            return lines;
         }
-        
-        for (const iface of goFile.interfaces) {
-            for (const method of iface.methods) {
-                allTypes.push(...method.parameters.map(p => p.type));
-                allTypes.push(...method.returnTypes);
+
+        if (options.includeComments) {
+            lines.push(...this.javadoc(named.doc || `Go type ${named.name} ${GoFunctionParser.typeText(named.underlying)}, wrapped to stay a type of its own`));
+        } else if (named.doc) {
+            lines.push(...this.javadoc(named.doc));
+        }
+        const stringer = JavaCodeGenerator.stringMethod(named.methods);
+        const toString = [
+            '    @Override',
+            '    public String toString() {',
+            `        return ${stringer ? `${JavaCodeGenerator.memberName(stringer.name, options)}()` : 'String.valueOf(value)'};`,
+            '    }'
+        ];
+        if ((options.javaVersion || DEFAULT_JAVA_VERSION) >= 17) {
+            lines.push(`public record ${named.name}(${javaType} value) {`);
+            if (stringer) {
+                lines.push(...toString, '');
             }
+        } else {
+            lines.push(
+                `public static final class ${named.name} {`,
+                `    private final ${javaType} value;`,
+                '',
+                `    public ${named.name}(${javaType} value) {`,
+                '        this.value = value;',
+                '    }',
+                '',
+                `    public ${javaType} value() {`,
+                '        return value;',
+                '    }',
+                '',
+                '    @Override',
+                '    public boolean equals(Object o) {',
+                `        return o instanceof ${named.name} && Objects.equals(value, ((${named.name}) o).value);`,
+                '    }',
+                '',
+                '    @Override',
+                '    public int hashCode() {',
+                '        return Objects.hashCode(value);',
+                '    }',
+                '',
+                ...toString,
+                ''
+            );
         }
-        
-        for (const func of goFile.functions) {
-            allTypes.push(...func.parameters.map(p => p.type));
-            allTypes.push(...func.returnTypes);
+        for (const method of named.methods) {
+            lines.push(...JavaCodeGenerator.generateJavaMethod(method, { ...methodOptions, isStatic: false }, ctx.mainFile).split('\n').map(l => '    ' + l));
+            lines.push('');
+        }
+        if (lines[lines.length - 1] === '') {
+            lines.pop();
         }
+        lines.push('}');
+        return lines;
+    }
 
-        // Check each type for stdlib mapping
-        for (const type of allTypes) {
-            const mapping = lookupStdlibType(type.name, type.packagePath);
-            if (mapping?.javaImport) {
-                imports.add(mapping.javaImport);
-            }
+    /**
+     * The type that becomes the top-level class in topLevelType mode: the file's only
+     * struct or interface. Interfaces are not promoted over mutable package variables,
+     * which have no place in a Java interface.
+     */
+    static promotedType(goFile: GoFile, options: JavaFileGenerationOptions): GoStruct | GoInterface | undefined {
+        if (!options.topLevelType || goFile.structs.length + goFile.interfaces.length !== 1) {
+            return undefined;
         }
+        if (goFile.structs.length === 1) {
+            return goFile.structs[0];
+        }
+        return goFile.variables.length === 0 ? goFile.interfaces[0] : undefined;
+    }
+
+    /**
+     * Whether name is a valid Java package name: dot-separated identifiers, none of them reserved
+     */
+    static isValidPackageName(name: string): boolean {
+        return name.split('.').every(segment =>
+            /^[A-Za-z_$][A-Za-z0-9_$]*$/.test(segment) && !JAVA_KEYWORDS.has(segment));
+    }
+
+    /**
+     * Wrapper class name for a Go source file: test-sample.go → TestSample
+     */
+    static classNameForFile(fileName: string): string {
+        const className = fileName
+            .replace(/\.go$/, '')
+            .split(/[^A-Za-z0-9]+/)
+            .filter(Boolean)
+            .map(part => part.charAt(0).toUpperCase() + part.slice(1))
+            .join('');
+        return /^[A-Za-z]/.test(className) ? className : `Go${className}`;
+    }
 
-        return Array.from(imports);
+    /**
+     * Record declarations needed by functions, methods and interfaces in errorResultRecords mode
+     */
+    private static collectResultRecords(goFile: GoFile, options: JavaFileGenerationOptions): string[] {
+        if (!options.errorResultRecords) {
+            return [];
+        }
+        const funcs = [...goFile.functions, ...goFile.structs.flatMap(s => s.methods)];
+        const records = funcs
+            .filter(f => JavaCodeGenerator.usesResultRecord(f, options))
+            .map(f => JavaCodeGenerator.generateResultRecord(f, options));
+        const ownRecords = records.filter(r => r);
+        const needsShared = records.some(r => !r)
+            || goFile.interfaces.some(i => i.methods.some(m => this.interfaceResultType(m, options)));
+        return needsShared ? [JavaCodeGenerator.SHARED_RESULT_RECORD, ...ownRecords] : ownRecords;
+    }
+
+    /**
+     * Shared Result<T> type for an interface method returning (T, error) in errorResultRecords mode
+     */
+    private static interfaceResultType(method: GoMethodSignature, options: JavaFileGenerationOptions): string | undefined {
+        const returnTypes = method.returnTypes;
+        if (!options.errorResultRecords || returnTypes.length !== 2 || returnTypes[1].name !== 'error' || returnTypes[0].name === 'error') {
+            return undefined;
+        }
+        return `Result<${JavaCodeGenerator.toJavaType(returnTypes[0], options, true)}>`;
     }
 
+
     /**
      * Generate a Java inner class from a Go struct
      */
@@ -202,10 +489,16 @@ export class JavaFileGenerator {
         isExternal: boolean = false
     ): string {
         const lines: string[] = [];
+        const scope = options.packageFile || ctx?.mainFile;
+        const superStruct = isExternal ? undefined : JavaCodeGenerator.superStruct(struct, options, scope);
 
         // Class JavaDoc
         if (options.includeComments) {
             lines.push('/**');
+            if (struct.doc) {
+                lines.push(...JavaCodeGenerator.formatDocComment(struct.doc));
+                lines.push(' *');
+            }
             if (isExternal) {
                 lines.push(` * External type from imported package`);
             }
@@ -214,10 +507,11 @@ export class JavaFileGenerator {
             // Add embedded type info if present
             if (struct.embeddedTypes && struct.embeddedTypes.length > 0) {
                 lines.push(' *');
-                lines.push(' * Embedded types (Go embedding → Java composition):');
+                lines.push(` * Embedded types (Go embedding → Java ${superStruct ? 'inheritance' : 'composition'}):`);
                 for (const embedded of struct.embeddedTypes) {
-                    const javaType = this.convertTypeToJavaWithContext(embedded, ctx);
-                    lines.push(` * - ${embedded.name} → ${javaType}`);
+                    const javaType = this.convertTypeToJavaWithContext(embedded, options, ctx);
+                    const role = !superStruct ? '' : embedded.name === superStruct.name ? ' (superclass)' : ' (delegating field)';
+                    lines.push(` * - ${embedded.name} → ${javaType}${role}`);
                 }
             }
             
@@ -226,25 +520,117 @@ export class JavaFileGenerator {
                 lines.push(' * Fields:');
                 for (const field of struct.fields) {
                     if (field.isEmbedded) continue; // Already shown above
-                    const javaType = this.convertTypeToJavaWithContext(field.type, ctx);
+                    const javaType = this.convertTypeToJavaWithContext(field.type, options, ctx);
                     const stdlibNote = this.getStdlibNote(field.type, ctx);
                     lines.push(` * - ${field.name}: ${javaType}${field.tag ? ` (tag: ${field.tag})` : ''}${stdlibNote}`);
                 }
             }
             lines.push(' */');
+        } else if (struct.doc) {
+            lines.push(...this.javadoc(struct.doc));
         }
 
-        // Class declaration
-        lines.push(`public static class ${struct.name} {`);
+        // Class declaration; value types become records on Java 17+
+        const typeParams = JavaCodeGenerator.typeParameterList(struct.typeParams, options, scope);
+        const warnings = isExternal ? [] : this.embeddingWarnings(struct, options, scope, superStruct);
+        const implemented = options.inferImplements && !isExternal ? this.implementedInterfaces(struct, options, scope) : [];
+        const implementsClause = implemented.length > 0 ? ` implements ${implemented.map(i => i.name).join(', ')}` : '';
+        // Methods an implemented interface declares, by signature
+        const overrides = new Set(implemented.flatMap(i => i.signatures));
+        if (!isExternal) {
+            this.reportStruct(struct, warnings, options);
+        }
+        if (!isExternal && this.usesRecord(struct, options, scope)) {
+            const names = this.fieldNames(struct, options);
+            const components = struct.fields.map((f, i) => {
+                const annotations = options.includeJsonAnnotations && !f.isEmbedded ? this.generateJsonAnnotations(f) : [];
+                const javaType = this.convertTypeToJavaWithContext(f.type, options, ctx);
+                const nullability = JavaCodeGenerator.nullabilityAnnotation(f.type, javaType, options, ctx?.mainFile);
+                return [...annotations, `${nullability}${javaType} ${names[i]}`].join(' ');
+            });
+            lines.push(`public record ${struct.name}${typeParams}(${components.join(', ')})${implementsClause} {`);
+            warnings.forEach(w => lines.push(`    // ${w}`));
+        } else {
+            lines.push(`public static class ${struct.name}${typeParams}${superStruct ? ` extends ${superStruct.name}` : ''}${implementsClause} {`);
+            warnings.forEach(w => lines.push(`    // ${w}`));
+            lines.push(...this.generateClassMembers(struct, options, ctx, isExternal ? undefined : scope, overrides));
+        }
+
+        if (!isExternal && this.usesBuilder(struct, options, ctx, scope)) {
+            lines.push('');
+            this.generateBuilder(struct, options, ctx, scope).forEach(line => lines.push('    ' + line));
+        }
+
+        // NewT functions become static factories of T
+        const factories = isExternal ? [] : (scope?.functions || [])
+            .filter(f => JavaCodeGenerator.factoryOwner(f, options, scope) === struct.name);
+        for (const factory of factories) {
+            lines.push('');
+            const javaMethod = JavaCodeGenerator.generateJavaMethod(factory, {
+                ...options,
+                isStatic: true,
+                addComments: true
+            }, ctx?.mainFile);
+            javaMethod.split('\n').forEach(line => lines.push('    ' + line));
+        }
+
+        // Value semantics: equals, hashCode and toString over every field
+        if (options.valueMethods && !isExternal && !this.usesRecord(struct, options, scope)) {
+            for (const method of this.generateValueMethods(struct, options, ctx, superStruct)) {
+                lines.push('');
+                method.split('\n').forEach(line => lines.push('    ' + line));
+            }
+        }
+
+        // Generate methods (from Go methods with receiver)
+        if (struct.methods.length > 0) {
+            lines.push('');
+            lines.push('    // Methods');
+            for (const method of struct.methods) {
+                const javaMethod = JavaCodeGenerator.generateJavaMethod(method, {
+                    ...options,
+                    isStatic: false,
+                    addComments: true,
+                    override: overrides.has(this.methodSignature(method))
+                }, ctx?.mainFile);
+                javaMethod.split('\n').forEach(line => {
+                    lines.push('    ' + line);
+                });
+                lines.push('');
+            }
+        }
+
+        lines.push('}');
+
+        return lines.join('\n');
+    }
+
+    /**
+     * Fields, constructors and accessors of a struct declared as a class
+     */
+    private static generateClassMembers(
+        struct: GoStruct,
+        options: JavaFileGenerationOptions,
+        ctx?: ConversionContext,
+        scope?: GoFile,
+        overrides: Set<string> = new Set()
+    ): string[] {
+        const lines: string[] = [];
+        const superStruct = JavaCodeGenerator.superStruct(struct, options, scope);
+        // Subclasses reach inherited fields directly
+        const visibility = this.isExtended(struct, options, scope) ? 'protected' : 'private';
+        const fields = struct.fields.filter(f => !(f.isEmbedded && f.type.name === superStruct?.name));
 
         // Generate embedded fields first (composition pattern)
-        if (struct.embeddedTypes && struct.embeddedTypes.length > 0) {
+        const delegates = (struct.embeddedTypes || []).filter(t => t.name !== superStruct?.name);
+        if (delegates.length > 0) {
             lines.push('');
             lines.push('    // Embedded types (Go embedding → Java composition)');
-            for (const embedded of struct.embeddedTypes) {
-                const javaType = this.convertTypeToJavaWithContext(embedded, ctx);
-                const fieldName = GoFunctionParser.toJavaMethodName(embedded.name.replace('*', ''));
-                lines.push(`    private ${javaType} ${fieldName};`);
+            for (const embedded of delegates) {
+                const javaType = this.convertTypeToJavaWithContext(embedded, options, ctx);
+                const fieldName = JavaCodeGenerator.memberName(embedded.name.replace('*', ''), options);
+                const annotation = JavaCodeGenerator.nullabilityAnnotation(embedded, javaType, options, ctx?.mainFile);
+                lines.push(`    ${visibility} ${annotation}${javaType} ${fieldName};`);
             }
         }
 
@@ -254,56 +640,428 @@ export class JavaFileGenerator {
             if (regularFields.length > 0) {
                 lines.push('');
                 for (const field of regularFields) {
-                    const javaField = this.generateClassFieldWithContext(field, ctx);
+                    if (field.doc) {
+                        this.javadoc(field.doc).forEach(l => lines.push('    ' + l));
+                    }
+                    if (options.includeJsonAnnotations) {
+                        this.generateJsonAnnotations(field).forEach(a => lines.push('    ' + a));
+                    }
+                    const javaField = this.generateClassFieldWithContext(field, options, ctx, visibility);
                     lines.push('    ' + javaField);
                 }
             }
         }
 
-        // Generate default constructor
+        // Generate constructors: all-args in Go field order, plus a no-arg one for JavaBeans.
+        // Inherited fields come first and are handed to the superclass constructor.
         if (options.includeConstructors) {
-            lines.push('');
-            lines.push('    /**');
-            lines.push('     * Default constructor');
-            lines.push('     */');
-            lines.push(`    public ${struct.name}() {}`);
+            const inherited = superStruct ? this.constructorParameters(superStruct, options, ctx, scope) : [];
+            const own = this.constructorParameters(struct, options, ctx, scope).slice(inherited.length);
+            const params = [...inherited, ...own];
+            if (params.length === 0 || options.javaBeans) {
+                lines.push('');
+                lines.push('    /**');
+                lines.push('     * Default constructor');
+                lines.push('     */');
+                lines.push(`    public ${struct.name}() {}`);
+            }
+            if (params.length > 0) {
+                lines.push('');
+                lines.push('    /**');
+                lines.push('     * Constructor setting every field, in Go declaration order');
+                lines.push('     */');
+                // With a builder, build() is the way in from other packages; struct literals in
+                // this one still call the constructor
+                const access = this.usesBuilder(struct, options, ctx, scope) ? '' : 'public ';
+                lines.push(`    ${access}${struct.name}(${params.map(p => `${p.annotation}${p.type} ${p.name}`).join(', ')}) {`);
+                if (superStruct) {
+                    lines.push(`        super(${inherited.map(p => p.name).join(', ')});`);
+                }
+                own.forEach(p => lines.push(`        this.${p.name} = ${p.name};`));
+                lines.push('    }');
+            }
         }
 
+        // Explicit Go methods (e.g. SetName) take precedence over generated accessors
+        const methodNames = new Set(struct.methods.map(m => JavaCodeGenerator.memberName(m.name, options)));
+
         // Generate getters and setters
-        if (options.includeGettersSetters && struct.fields.length > 0) {
-            lines.push('');
-            for (const field of struct.fields) {
-                const getter = this.generateGetterWithContext(field, ctx);
-                const setter = this.generateSetterWithContext(field, ctx);
-                getter.split('\n').forEach(line => lines.push('    ' + line));
-                lines.push('');
-                setter.split('\n').forEach(line => lines.push('    ' + line));
-                if (field !== struct.fields[struct.fields.length - 1]) {
-                    lines.push('');
+        if (options.includeGettersSetters || options.javaBeans) {
+            const accessors: string[] = [];
+            for (const field of fields) {
+                if (options.javaBeans && !field.exported) {
+                    continue;
+                }
+                if (!methodNames.has(this.getterName(field, options))) {
+                    accessors.push(this.generateGetterWithContext(field, options, ctx));
+                }
+                if (!methodNames.has(this.setterName(field))) {
+                    accessors.push(this.generateSetterWithContext(field, options, ctx));
                 }
             }
+            for (const accessor of accessors) {
+                lines.push('');
+                accessor.split('\n').forEach(line => lines.push('    ' + line));
+            }
+            fields.forEach(f => methodNames.add(this.getterName(f, options)).add(this.setterName(f)));
         }
 
-        // Generate methods (from Go methods with receiver)
-        if (struct.methods.length > 0) {
+        // Promoted accessors and methods of delegating fields, so Java callers can use them as Go callers do;
+        // inherited members are already callable
+        if (superStruct) {
+            superStruct.methods.forEach(m => methodNames.add(JavaCodeGenerator.memberName(m.name, options)));
+            superStruct.fields.forEach(f => methodNames.add(this.getterName(f, options)).add(this.setterName(f)));
+        }
+        const forwarders = this.generateForwarders(struct, delegates, methodNames, options, ctx, scope, overrides);
+        if (forwarders.length > 0) {
             lines.push('');
-            lines.push('    // Methods');
-            for (const method of struct.methods) {
-                const javaMethod = JavaCodeGenerator.generateJavaMethod(method, {
-                    ...options,
-                    isStatic: false,
-                    addComments: true
+            lines.push('    // Forwarded to embedded types');
+            forwarders.forEach((forwarder, i) => {
+                if (i > 0) {
+                    lines.push('');
+                }
+                forwarder.split('\n').forEach(line => lines.push('    ' + line));
+            });
+        }
+
+        return lines;
+    }
+
+    /**
+     * Whether a struct gets a builder: enabled, and it has enough constructor parameters.
+     * A superclass has none: its subclasses' builder() could not hide its own, whose
+     * return type differs, and they need its constructor.
+     */
+    private static usesBuilder(struct: GoStruct, options: JavaFileGenerationOptions, ctx?: ConversionContext, scope?: GoFile): boolean {
+        const record = this.usesRecord(struct, options, scope);
+        return !!options.builder && (record || !!options.includeConstructors) && !this.isExtended(struct, options, scope)
+            && this.constructorParameters(struct, options, ctx, scope).length >= (options.builderMinFields ?? DEFAULT_BUILDER_MIN_FIELDS);
+    }
+
+    /**
+     * A static builder() and the nested Builder it returns: a fluent `withX` setter per
+     * constructor parameter, and build() calling the all-args constructor
+     * (`User.builder().withName("x").withAge(5).build()`). Unset fields keep their zero values.
+     */
+    private static generateBuilder(struct: GoStruct, options: JavaFileGenerationOptions, ctx?: ConversionContext, scope?: GoFile): string[] {
+        const params = this.constructorParameters(struct, options, ctx, scope);
+        const typeParams = JavaCodeGenerator.typeParameterList(struct.typeParams, options, scope);
+        const typeArgs = struct.typeParams?.length ? `<${struct.typeParams.map(p => p.name).join(', ')}>` : '';
+        const builder = `Builder${typeArgs}`;
+        const lines = [
+            `public static ${typeParams ? `${typeParams} ` : ''}${builder} builder() {`,
+            `    return new Builder${typeArgs ? '<>' : ''}();`,
+            '}',
+            '',
+            '/**',
+            ` * Builder for ${struct.name}; fields left unset keep their zero values`,
+            ' */',
+            `public static class Builder${typeParams} {`
+        ];
+        for (const p of params) {
+            lines.push(`    private ${p.type} ${p.name}${p.initializer ? ` = ${p.initializer}` : ''};`);
+        }
+        for (const p of params) {
+            lines.push(
+                '',
+                `    public ${builder} with${p.name.charAt(0).toUpperCase()}${p.name.slice(1)}(${p.annotation}${p.type} ${p.name}) {`,
+                `        this.${p.name} = ${p.name};`,
+                '        return this;',
+                '    }'
+            );
+        }
+        lines.push(
+            '',
+            `    public ${struct.name}${typeArgs} build() {`,
+            `        return new ${struct.name}${typeArgs ? '<>' : ''}(${params.map(p => p.name).join(', ')});`,
+            '    }',
+            '}'
+        );
+        return lines;
+    }
+
+    /**
+     * Type and name of each all-args constructor parameter, inherited fields first
+     */
+    private static constructorParameters(
+        struct: GoStruct,
+        options: JavaFileGenerationOptions,
+        ctx?: ConversionContext,
+        scope?: GoFile
+    ): { type: string; name: string; annotation: string; initializer?: string }[] {
+        const superStruct = JavaCodeGenerator.superStruct(struct, options, scope);
+        const names = this.fieldNames(struct, options);
+        const own = struct.fields
+            .map((f, i) => ({ field: f, name: names[i] }))
+            .filter(({ field }) => !(field.isEmbedded && field.type.name === superStruct?.name))
+            .map(({ field, name }) => {
+                const type = this.convertTypeToJavaWithContext(field.type, options, ctx);
+                return {
+                    type,
+                    name,
+                    annotation: JavaCodeGenerator.nullabilityAnnotation(field.type, type, options, ctx?.mainFile),
+                    initializer: this.fieldInitializer(field, type, options, scope)
+                };
+            });
+        return superStruct ? [...this.constructorParameters(superStruct, options, ctx, scope), ...own] : own;
+    }
+
+    /**
+     * Whether another struct's class extends this one in inheritance mode
+     */
+    private static isExtended(struct: GoStruct, options: JavaFileGenerationOptions, scope?: GoFile): boolean {
+        return !!scope?.structs.some(s => s !== struct && JavaCodeGenerator.superStruct(s, options, scope) === struct);
+    }
+
+    /**
+     * Interfaces declared in scope that a struct satisfies, as Go does implicitly: each of
+     * their methods, embedded ones included, matches a method of the struct or of a struct
+     * it embeds by name, parameter types and result types. Interfaces another one listed
+     * already extends are left out, and so are generic and constraint interfaces and ones
+     * embedding an interface declared elsewhere, whose method sets are unknown.
+     * Each comes with the signatures of its whole method set, which the struct's methods override.
+     */
+    private static implementedInterfaces(
+        struct: GoStruct,
+        options: JavaFileGenerationOptions,
+        scope?: GoFile
+    ): { name: string; signatures: string[] }[] {
+        if (!scope) {
+            return [];
+        }
+        const promoted = this.embeddedStructs(struct, struct.embeddedTypes || [], options, scope).flatMap(e => e.struct.methods);
+        const methods = new Set([...struct.methods, ...promoted].map(m => this.methodSignature(m)));
+
+        // Method signatures of an interface and the interfaces it embeds, transitively
+        const methodSets = new Map<string, { signatures: string[]; embeds: Set<string> } | undefined>();
+        const methodSet = (name: string): { signatures: string[]; embeds: Set<string> } | undefined => {
+            if (methodSets.has(name)) {
+                return methodSets.get(name);
+            }
+            methodSets.set(name, undefined);
+            const iface = scope.interfaces.find(i => i.name === name);
+            if (!iface || iface.typeParams?.length || iface.typeTerms?.length) {
+                return undefined;
+            }
+            const set = { signatures: iface.methods.map(m => this.methodSignature(m)), embeds: new Set<string>() };
+            for (const embedded of iface.embeddedInterfaces || []) {
+                const inner = methodSet(embedded);
+                if (!inner) {
+                    return undefined;
+                }
+                set.signatures.push(...inner.signatures);
+                set.embeds.add(embedded);
+                inner.embeds.forEach(e => set.embeds.add(e));
+            }
+            methodSets.set(name, set);
+            return set;
+        };
+
+        const matched = scope.interfaces
+            .map(i => ({ name: i.name, set: methodSet(i.name) }))
+            .filter(m => m.set && m.set.signatures.length > 0 && m.set.signatures.every(s => methods.has(s)));
+        return matched
+            .filter(m => !matched.some(other => other.set!.embeds.has(m.name)))
+            .map(m => ({ name: m.name, signatures: m.set!.signatures }));
+    }
+
+    /**
+     * A method's name with its Go parameter and result types, which interface conformance compares
+     */
+    private static methodSignature(m: GoMethodSignature | GoFunction): string {
+        return `${m.name}(${m.parameters.map(p => GoFunctionParser.typeText(p.type)).join(', ')}) (${m.returnTypes.map(t => GoFunctionParser.typeText(t)).join(', ')})`;
+    }
+
+    /**
+     * Structs declared in scope that struct embeds, with the Java field holding each
+     */
+    private static embeddedStructs(
+        struct: GoStruct,
+        embedded: GoType[],
+        options: JavaFileGenerationOptions,
+        scope?: GoFile
+    ): { struct: GoStruct; fieldName: string }[] {
+        return embedded
+            .map(t => ({ struct: scope?.structs.find(s => s.name === t.name), fieldName: JavaCodeGenerator.memberName(t.name, options) }))
+            .filter((e): e is { struct: GoStruct; fieldName: string } => !!e.struct && e.struct !== struct);
+    }
+
+    /**
+     * Accessors and methods forwarding to the delegating fields. Names the class already
+     * declares are skipped, and so are names two embedded types both provide, which Go
+     * rejects as ambiguous.
+     */
+    private static generateForwarders(
+        struct: GoStruct,
+        delegates: GoType[],
+        taken: Set<string>,
+        options: JavaFileGenerationOptions,
+        ctx?: ConversionContext,
+        scope?: GoFile,
+        overrides: Set<string> = new Set()
+    ): string[] {
+        const candidates: { name: string; code: string }[] = [];
+        for (const { struct: embedded, fieldName } of this.embeddedStructs(struct, delegates, options, scope)) {
+            if (options.includeGettersSetters || options.javaBeans) {
+                for (const field of embedded.fields) {
+                    if (options.javaBeans && !field.exported) {
+                        continue;
+                    }
+                    const javaType = this.convertTypeToJavaWithContext(field.type, options, ctx);
+                    const name = JavaCodeGenerator.memberName(field.name, options);
+                    const getter = this.getterName(field, options);
+                    const setter = this.setterName(field);
+                    candidates.push({ name: getter, code: `public ${javaType} ${getter}() {\n    return ${fieldName}.${getter}();\n}` });
+                    candidates.push({ name: setter, code: `public void ${setter}(${javaType} ${name}) {\n    ${fieldName}.${setter}(${name});\n}` });
+                }
+            }
+            for (const method of embedded.methods) {
+                candidates.push({
+                    name: JavaCodeGenerator.memberName(method.name, options),
+                    code: JavaCodeGenerator.generateForwardingMethod(method, fieldName,
+                        { ...options, isStatic: false, override: overrides.has(this.methodSignature(method)) }, ctx?.mainFile)
                 });
-                javaMethod.split('\n').forEach(line => {
-                    lines.push('    ' + line);
+            }
+        }
+        return candidates
+            .filter(c => !taken.has(c.name) && candidates.filter(other => other.name === c.name).length === 1)
+            .map(c => c.code);
+    }
+
+    /**
+     * Name clashes Go resolves silently or rejects: an embedding struct's own field or method
+     * hiding a promoted one, and one name promoted from two embedded types
+     */
+    private static embeddingWarnings(
+        struct: GoStruct,
+        options: JavaFileGenerationOptions,
+        scope: GoFile | undefined,
+        superStruct: GoStruct | undefined
+    ): string[] {
+        const warnings: string[] = [];
+        const own = new Set([...struct.fields.filter(f => !f.isEmbedded).map(f => f.name), ...struct.methods.map(m => m.name)]);
+        const promotedFrom = new Map<string, string>();
+        for (const { struct: embedded } of this.embeddedStructs(struct, struct.embeddedTypes || [], options, scope)) {
+            const members = [
+                ...embedded.fields.map(f => ({ name: f.name, isMethod: false })),
+                ...embedded.methods.map(m => ({ name: m.name, isMethod: true }))
+            ];
+            for (const member of members) {
+                if (own.has(member.name)) {
+                    const kept = options.embedding === 'inheritance' && !superStruct && !member.isMethod
+                        ? `; ${embedded.name} is kept as a field instead of a superclass`
+                        : embedded === superStruct && member.isMethod ? '; in Java it overrides it instead' : '';
+                    warnings.push(`Warning: ${struct.name}.${member.name} hides ${embedded.name}.${member.name}${kept}`);
+                } else if (promotedFrom.has(member.name) && promotedFrom.get(member.name) !== embedded.name) {
+                    warnings.push(`Warning: ${member.name} is promoted from both ${promotedFrom.get(member.name)} and ${embedded.name}; `
+                        + `Go rejects it unless qualified, e.g. x.${embedded.name}.${member.name}`);
+                } else {
+                    promotedFrom.set(member.name, embedded.name);
+                }
+            }
+        }
+        return warnings;
+    }
+
+    /**
+     * Report a struct's lossy unsigned fields and embedding clashes as degraded conversions
+     */
+    private static reportStruct(struct: GoStruct, warnings: string[], options: JavaFileGenerationOptions): void {
+        for (const field of struct.fields) {
+            if (GoFunctionParser.isLossyUnsigned(field.type)) {
+                options.diagnostics?.push({
+                    severity: 'degraded',
+                    construct: 'UnsignedType',
+                    message: `Unsigned ${field.type.name} maps to a signed Java type; values above its signed range wrap`,
+                    pos: field.namePosition || struct.namePosition,
+                    scope: `${struct.name}.${field.name}`
                 });
-                lines.push('');
             }
         }
+        for (const warning of warnings) {
+            options.diagnostics?.push({
+                severity: 'degraded',
+                construct: 'EmbeddedField',
+                message: warning.replace(/^Warning: /, ''),
+                pos: struct.namePosition,
+                scope: struct.name
+            });
+        }
+    }
 
-        lines.push('}');
+    /**
+     * Whether a struct becomes a record: value methods on Java 17+, no method assigns
+     * to its receiver's fields (record components are final) and no field is an array
+     * (records compare arrays by reference). Records can neither extend nor be extended.
+     */
+    private static usesRecord(struct: GoStruct, options: JavaFileGenerationOptions, scope?: GoFile): boolean {
+        return !!options.valueMethods
+            && (options.javaVersion || DEFAULT_JAVA_VERSION) >= 17
+            && !JavaCodeGenerator.superStruct(struct, options, scope)
+            && !this.isExtended(struct, options, scope)
+            && !struct.methods.some(m => JavaBodyGenerator.assignsToReceiver(m))
+            && !struct.fields.some(f => JavaCodeGenerator.toJavaType(f.type, options).endsWith('[]'));
+    }
 
-        return lines.join('\n');
+    /**
+     * Java field names of a struct in declaration order; embedded types are named after the type
+     */
+    private static fieldNames(struct: GoStruct, options: JavaFileGenerationOptions): string[] {
+        return struct.fields.map(f => JavaCodeGenerator.memberName(f.isEmbedded ? f.type.name.replace('*', '') : f.name, options));
+    }
+
+    /**
+     * equals(Object), hashCode() and toString() comparing, hashing and printing every field.
+     * A Go String() method is used for toString() instead.
+     */
+    private static generateValueMethods(
+        struct: GoStruct,
+        options: JavaFileGenerationOptions,
+        ctx?: ConversionContext,
+        superStruct?: GoStruct
+    ): string[] {
+        const names = this.fieldNames(struct, options);
+        const isArray = struct.fields.map(f => this.convertTypeToJavaWithContext(f.type, options, ctx).endsWith('[]'));
+        // The superclass compares, hashes and prints the inherited fields
+        const isSuper = struct.fields.map(f => f.isEmbedded && f.type.name === superStruct?.name);
+
+        const comparisons = names.map((name, i) => isSuper[i]
+            ? 'super.equals(o)'
+            : isArray[i]
+            ? `Arrays.equals(${name}, other.${name})`
+            : `Objects.equals(${name}, other.${name})`);
+        const equals = [
+            '@Override',
+            'public boolean equals(Object o) {',
+            '    if (this == o) return true;',
+            `    if (!(o instanceof ${struct.name})) return false;`,
+            ...(names.length > 0
+                ? [`    ${struct.name} other = (${struct.name}) o;`, `    return ${comparisons.join('\n        && ')};`]
+                : ['    return true;']),
+            '}'
+        ];
+
+        const hashed = names.map((name, i) => isSuper[i] ? 'super.hashCode()' : isArray[i] ? `Arrays.hashCode(${name})` : name);
+        const hashCode = [
+            '@Override',
+            'public int hashCode() {',
+            `    return Objects.hash(${hashed.join(', ')});`,
+            '}'
+        ];
+
+        const stringer = JavaCodeGenerator.stringMethod(struct.methods);
+        const printed = names.map((name, i) => `${i === 0 ? '' : ', '}${name}=" + ${
+            isSuper[i] ? 'super.toString()' : isArray[i] ? `Arrays.toString(${name})` : name}`);
+        const toString = [
+            '@Override',
+            'public String toString() {',
+            stringer
+                ? `    return ${JavaCodeGenerator.memberName(stringer.name, options)}();`
+                : `    return "${struct.name}{${printed.map(p => p + ' + "').join('')}}";`,
+            '}'
+        ];
+
+        return [equals, hashCode, toString].map(method => method.join('\n'));
     }
 
     /**
@@ -320,6 +1078,10 @@ export class JavaFileGenerator {
         // Interface JavaDoc
         if (options.includeComments) {
             lines.push('/**');
+            if (iface.doc) {
+                lines.push(...JavaCodeGenerator.formatDocComment(iface.doc));
+                lines.push(' *');
+            }
             if (isExternal) {
                 lines.push(` * External interface from imported package`);
             }
@@ -331,41 +1093,62 @@ export class JavaFileGenerator {
                 lines.push(' * Embeds interfaces: ' + iface.embeddedInterfaces.join(', '));
             }
             lines.push(' */');
+        } else if (iface.doc) {
+            lines.push(...this.javadoc(iface.doc));
         }
 
-        // Interface declaration with extends for embedded interfaces
+        // Interface declaration with extends for embedded interfaces; those of other packages
+        // (io.Reader) have no Java counterpart
         let extendsClause = '';
-        if (iface.embeddedInterfaces && iface.embeddedInterfaces.length > 0) {
-            extendsClause = ` extends ${iface.embeddedInterfaces.join(', ')}`;
+        const extended = (iface.embeddedInterfaces || []).filter(name => !name.includes('.'));
+        if (extended.length > 0) {
+            extendsClause = ` extends ${extended.join(', ')}`;
         }
-        lines.push(`public interface ${iface.name}${extendsClause} {`);
+        const typeParams = JavaCodeGenerator.typeParameterList(iface.typeParams, options, options.packageFile || ctx?.mainFile);
+        lines.push(`public interface ${iface.name}${typeParams}${extendsClause} {`);
 
         // Generate method signatures
         if (iface.methods.length > 0) {
             for (const method of iface.methods) {
                 lines.push('');
+                const resultType = this.interfaceResultType(method, options);
+                const returnType = resultType || this.getReturnTypeWithContext(method.returnTypes, options, ctx);
+                const returned = method.returnTypes.filter(t => t.name !== 'error');
 
                 // Method JavaDoc
                 if (options.includeComments) {
                     lines.push('    /**');
-                    lines.push(`     * ${method.name}`);
+                    if (method.doc) {
+                        JavaCodeGenerator.formatDocComment(method.doc).forEach(l => lines.push('    ' + l));
+                    } else {
+                        lines.push(`     * ${method.name}`);
+                    }
                     if (method.parameters.length > 0) {
                         for (const param of method.parameters) {
                             const stdlibNote = this.getStdlibNote(param.type, ctx);
-                            lines.push(`     * @param ${param.name}${stdlibNote}`);
+                            lines.push(`     * @param ${GoFunctionParser.toJavaMethodName(param.name)}${stdlibNote}`);
                         }
                     }
-                    if (method.returnTypes.length > 0) {
-                        lines.push('     * @return result');
+                    // (T, error) returns T and throws; a lone error return is void
+                    if (returned.length > 0) {
+                        lines.push(`     * @return ${JavaCodeGenerator.returnDescription(returnType, returned.length, !!resultType)}`);
+                    }
+                    if (method.returnTypes.some(t => t.name === 'error') && !this.interfaceResultType(method, options)) {
+                        lines.push(`     * @throws ${JavaCodeGenerator.getExceptionClass(options)} if the Go method returns an error`);
                     }
                     lines.push('     */');
+                } else if (method.doc) {
+                    this.javadoc(method.doc).forEach(l => lines.push('    ' + l));
                 }
 
                 // Method signature
-                const returnType = this.getReturnTypeWithContext(method.returnTypes, ctx);
-                const params = this.generateParameterListWithContext(method.parameters, ctx);
-                const throwsClause = method.returnTypes.some(t => t.name === 'error') ? ' throws Exception' : '';
-                lines.push(`    ${returnType} ${GoFunctionParser.toJavaMethodName(method.name)}(${params})${throwsClause};`);
+                const annotation = !resultType && returned.length === 1
+                    ? JavaCodeGenerator.nullabilityAnnotation(returned[0], returnType, options, ctx?.mainFile) : '';
+                const params = this.generateParameterListWithContext(method.parameters, options, ctx);
+                const throwsClause = !resultType && method.returnTypes.some(t => t.name === 'error')
+                    ? ` throws ${JavaCodeGenerator.getExceptionClass(options)}`
+                    : '';
+                lines.push(`    ${annotation}${returnType} ${JavaCodeGenerator.memberName(method.name, options)}(${params})${throwsClause};`);
             }
         }
 
@@ -374,16 +1157,241 @@ export class JavaFileGenerator {
         return lines.join('\n');
     }
 
+    /**
+     * The `static { ... }` block running a package's init functions in declaration order.
+     * Several inits each get a nested block, so that their locals do not clash.
+     */
+    private static generateStaticInitializer(inits: GoFunction[], options: JavaFileGenerationOptions, goFile: GoFile): string[] {
+        const lines = ['static {'];
+        const methodOptions = { ...options, isStatic: true };
+        for (const init of inits) {
+            const body = (JavaBodyGenerator.generateBody(init, methodOptions, goFile) || []).map(l => l.replace(/^ {4}/, ''));
+            if (inits.length === 1) {
+                lines.push(...body.map(l => '    ' + l));
+            } else if (body.length > 0) {
+                lines.push(JavaCodeGenerator.withOrigin('    {', init.namePosition, options));
+                lines.push(...body.map(l => '        ' + l));
+                lines.push('    }');
+            }
+        }
+        lines.push('}');
+        return lines;
+    }
+
     /**
      * Generate a static field from a Go variable or constant
      */
-    private static generateStaticField(variable: GoVariable, isFinal: boolean): string {
-        const modifiers = isFinal ? 'public static final' : 'public static';
-        const javaType = variable.type ? GoFunctionParser.convertGoTypeToJava(variable.type) : 'Object';
-        const name = this.toJavaFieldName(variable.name, isFinal);
-        const value = variable.value ? ` = ${this.convertValue(variable.value)}` : ' /* TODO: Initialize */';
+    private static generateStaticField(
+        variable: GoVariable,
+        isFinal: boolean,
+        options: JavaFileGenerationOptions,
+        goFile: GoFile
+    ): string {
+        // Constants are always public; variables follow Go's exported/unexported visibility.
+        // When the package spans several classes, unexported vars must stay visible to the siblings.
+        const visibility = isFinal || variable.exported ? 'public ' : options.packageFile ? '' : 'private ';
+        const modifiers = isFinal ? `${visibility}static final` : `${visibility}static`;
+        const duration = variable.value !== undefined && (!variable.type || variable.type.name === 'time.Duration')
+            ? this.durationValue(variable.value, options, goFile)
+            : undefined;
+        if (duration) {
+            const field = `    ${modifiers} ${duration.javaType} ${isFinal ? JavaCodeGenerator.constantName(variable.name, options) : JavaCodeGenerator.memberName(variable.name, options)} = ${duration.code};`;
+            return variable.doc ? [...this.javadoc(variable.doc).map(l => '    ' + l), field].join('\n') : field;
+        }
+        // Slices and maps of a composite literal nothing changes are built unmodifiable (List.of),
+        // and a map too big for Map.of or changed later is filled by a static initializer block
+        const scope = options.packageFile || goFile;
+        const composite = !isFinal && variable.value !== undefined && variable.value.includes('{');
+        const immutable = composite && !JavaBodyGenerator.modifiesPackageVariable(variable.name, scope);
+        const map = composite ? JavaBodyGenerator.translateMapEntries(variable.value!, options, goFile) : undefined;
+        const filled = map && map.entries.length > 0
+            && (!immutable || map.entries.length > 10 || (options.javaVersion || DEFAULT_JAVA_VERSION) < 9) ? map : undefined;
+        const translated = filled
+            ? { code: 'new HashMap<>()', type: filled.type }
+            : variable.value !== undefined
+                ? JavaBodyGenerator.translateExpression(variable.value, options, goFile, immutable, variable.type)
+                : undefined;
+        // Untyped declarations take their type from the initializer
+        const type = variable.type || translated?.type;
+        // Standard library types as the body generator types locals (time.Now() → Instant)
+        const stdlib = type && !type.isSlice && !type.isMap ? lookupStdlibType(type.name) : undefined;
+        const javaType = stdlib ? stdlib.javaType : type ? JavaCodeGenerator.toJavaType(type, options) : 'Object';
+        const name = isFinal ? JavaCodeGenerator.constantName(variable.name, options) : JavaCodeGenerator.memberName(variable.name, options);
+
+        let javaValue: string | undefined;
+        if (variable.value !== undefined) {
+            javaValue = translated ? translated.code : this.convertValue(variable.value);
+            // Values of a wrapper type are objects: `Boiling Celsius = 100` → new Celsius(100)
+            const named = type && !type.isPointer && !type.isSlice && !type.isMap && scope.namedTypes.find(t => t.name === type.name);
+            if (named && translated?.type?.name !== named.name && JavaCodeGenerator.namedTypeForm(named, options, scope) === 'wrapper') {
+                javaValue = `new ${named.name}(${javaValue})`;
+            }
+            if (!translated) {
+                options.diagnostics?.push({
+                    severity: 'unsupported',
+                    construct: 'PackageVariable',
+                    message: 'Initializer could not be translated and is copied verbatim',
+                    pos: variable.namePosition,
+                    scope: variable.name
+                });
+            }
+        } else if (type) {
+            // `var x T` starts at T's zero value
+            javaValue = JavaBodyGenerator.structZeroValue(type, options, scope) ?? JavaCodeGenerator.getDefaultValue(javaType, options);
+        }
+        const value = javaValue ? ` = ${javaValue}` : ' /* TODO: Initialize */';
+
+        const unsigned = type && GoFunctionParser.isLossyUnsigned(type) ? `  // unsigned ${type.name} in Go` : '';
+        if (unsigned) {
+            options.diagnostics?.push({
+                severity: 'degraded',
+                construct: 'UnsignedType',
+                message: `Unsigned ${type!.name} maps to a signed Java type; values above its signed range wrap`,
+                pos: variable.namePosition,
+                scope: variable.name
+            });
+        }
+        const field = [`    ${modifiers} ${javaType} ${name}${value};${unsigned}`];
+        if (filled) {
+            field.push('    static {', ...filled.entries.map(([k, v]) => `        ${name}.put(${k}, ${v});`), '    }');
+        }
+        if (!variable.doc) {
+            return field.join('\n');
+        }
+        return [...this.javadoc(variable.doc).map(l => '    ' + l), ...field].join('\n');
+    }
 
-        return `    ${modifiers} ${javaType} ${name}${isFinal ? value : ' /* TODO: Initialize */'};`;
+    /**
+     * A duration built from the unit constants of the time package, `30 * time.Second` or
+     * `1*time.Hour + 30*time.Minute`, as `Duration.ofSeconds(30)` in the largest unit that
+     * divides it, or with durationMillis as a long of milliseconds (`30000L`)
+     */
+    private static durationValue(
+        value: string,
+        options: JavaFileGenerationOptions,
+        goFile: GoFile
+    ): { javaType: string; code: string } | undefined {
+        const time = goFile.imports.find(imp => imp.path === 'time');
+        const unit = new RegExp(`\\b${time?.alias || 'time'}\\.(${Object.keys(TIME_UNITS).join('|')})\\b`, 'g');
+        if (!time || !value.match(unit)) {
+            return undefined;
+        }
+        const nanos = evaluateIntegerConstant(value.replace(unit, (_, name: string) => `(${TIME_UNITS[name]})`));
+        if (nanos === undefined) {
+            return undefined;
+        }
+        const literal = (n: bigint) => n > 2147483647n || n < -2147483648n ? `${n}L` : `${n}`;
+        // Sub-millisecond durations keep the Duration form
+        if (options.durationMillis && nanos % TIME_UNITS.Millisecond === 0n) {
+            return { javaType: 'long', code: `${nanos / TIME_UNITS.Millisecond}L` };
+        }
+        if (nanos === 0n) {
+            return { javaType: 'Duration', code: 'Duration.ZERO' };
+        }
+        const [factory, size] = DURATION_FACTORIES.find(([, size]) => nanos % size === 0n)!;
+        return { javaType: 'Duration', code: `Duration.${factory}(${literal(nanos / size)})` };
+    }
+
+    /**
+     * Generate a Java enum from the iota sequence of constants of a Go type, with the
+     * methods of the type. A String() method that only looks the constant up becomes the
+     * display name each constant is constructed with, which toString() returns; any other
+     * String() stays a method that toString() calls.
+     */
+    private static generateEnum(name: string, members: GoVariable[], options: JavaFileGenerationOptions, ctx: ConversionContext): string[] {
+        const methods = (options.packageFile || ctx.mainFile).namedTypes.find(t => t.name === name)?.methods || [];
+        const stringer = JavaCodeGenerator.stringMethod(methods);
+        const displayNames = stringer && JavaBodyGenerator.enumDisplayNames(stringer, members, options.packageFile || ctx.mainFile);
+        const lines = [`public enum ${name} {`];
+        members.forEach((member, i) => {
+            if (member.doc) {
+                lines.push(...this.javadoc(member.doc).map(l => '    ' + l));
+            }
+            const separator = i < members.length - 1 ? ',' : methods.length > 0 ? ';' : '';
+            const displayName = displayNames?.get(member);
+            const argument = displayName ? `(${JavaBodyGenerator.translateExpression(displayName, options)?.code || displayName})` : '';
+            lines.push(`    ${JavaCodeGenerator.constantName(member.name, options)}${argument}${separator}`);
+        });
+        if (displayNames) {
+            lines.push(
+                '',
+                '    private final String displayName;',
+                '',
+                `    ${name}(String displayName) {`,
+                '        this.displayName = displayName;',
+                '    }'
+            );
+        }
+        if (stringer) {
+            lines.push(
+                '',
+                '    @Override',
+                '    public String toString() {',
+                `        return ${displayNames ? 'displayName' : `${JavaCodeGenerator.memberName(stringer.name, options)}()`};`,
+                '    }'
+            );
+        }
+        for (const method of methods.filter(m => !(displayNames && m === stringer))) {
+            lines.push('');
+            lines.push(...JavaCodeGenerator.generateJavaMethod(method, { ...options, isStatic: false, addComments: true }, ctx.mainFile).split('\n').map(l => '    ' + l));
+        }
+        lines.push('}');
+        return lines;
+    }
+
+    /**
+     * Generate a Java enum from a group of string constants, holding each string
+     * with a getter, and fromValue to look a constant up by its string
+     */
+    private static generateStringEnum(stringEnum: StringEnum, options: JavaFileGenerationOptions): string[] {
+        const { name, members } = stringEnum;
+        const lines = [`public enum ${name} {`];
+        members.forEach(({ constant, javaName }, i) => {
+            if (constant.doc) {
+                lines.push(...this.javadoc(constant.doc).map(l => '    ' + l));
+            }
+            // Raw strings need escaping in Java
+            const value = JavaBodyGenerator.translateExpression(constant.value!, options)?.code || constant.value!;
+            lines.push(`    ${javaName}(${value})${i < members.length - 1 ? ',' : ';'}`);
+        });
+        lines.push(
+            '',
+            '    private final String value;',
+            '',
+            `    ${name}(String value) {`,
+            '        this.value = value;',
+            '    }',
+            '',
+            '    public String getValue() {',
+            '        return value;',
+            '    }',
+            '',
+            '    /**',
+            `     * The ${name} constant whose value is the given string`,
+            '     *',
+            '     * @throws IllegalArgumentException if there is none; Go accepts any string',
+            '     */',
+            `    public static ${name} fromValue(String value) {`,
+            `        for (${name} constant : values()) {`,
+            '            if (constant.value.equals(value)) {',
+            '                return constant;',
+            '            }',
+            '        }',
+            `        throw new IllegalArgumentException("Unknown ${name}: " + value);`,
+            '    }',
+            '}'
+        );
+        return lines;
+    }
+
+    /**
+     * Javadoc block holding only a Go doc comment; one-line comments stay on one line
+     */
+    private static javadoc(doc: string): string[] {
+        if (!doc.includes('\n')) {
+            return [`/** ${doc.replace(/\*\//g, '*&#47;')} */`];
+        }
+        return ['/**', ...JavaCodeGenerator.formatDocComment(doc), ' */'];
     }
 
     /**
@@ -418,11 +1426,24 @@ export class JavaFileGenerator {
     private static generateSetter(field: GoField, struct: GoStruct, knownTypes: string[]): string {
         const javaType = this.convertTypeToJava(field.type, struct, knownTypes);
         const fieldName = GoFunctionParser.toJavaMethodName(field.name);
-        const methodName = 'set' + field.name.charAt(0).toUpperCase() + field.name.slice(1);
+        const methodName = this.setterName(field);
 
         return `public void ${methodName}(${javaType} ${fieldName}) {\n    this.${fieldName} = ${fieldName};\n}`;
     }
 
+    /**
+     * Accessor names; JavaBeans mode uses isX() for boolean fields
+     */
+    private static getterName(field: GoField, options: JavaFileGenerationOptions): string {
+        const isBoolean = field.type.name === 'bool' && !field.type.isPointer && !field.type.isSlice && !field.type.isMap;
+        const prefix = options.javaBeans && isBoolean ? 'is' : 'get';
+        return prefix + GoFunctionParser.toJavaClassName(field.name);
+    }
+
+    private static setterName(field: GoField): string {
+        return 'set' + GoFunctionParser.toJavaClassName(field.name);
+    }
+
     /**
      * Convert Go type to Java type with context awareness
      * Uses semantic information from gopls when available
@@ -483,17 +1504,6 @@ export class JavaFileGenerator {
         }).join(', ');
     }
 
-    /**
-     * Convert Go field/variable name to Java field name
-     */
-    private static toJavaFieldName(name: string, isConstant: boolean): string {
-        if (isConstant) {
-            // Convert to UPPER_SNAKE_CASE
-            return name.replace(/([A-Z])/g, '_$1').toUpperCase().replace(/^_/, '');
-        }
-        return GoFunctionParser.toJavaMethodName(name);
-    }
-
     /**
      * Convert Go class name to Java class name
      */
@@ -526,9 +1536,9 @@ export class JavaFileGenerator {
     /**
      * Convert Go type to Java type using conversion context (with stdlib mapping)
      */
-    private static convertTypeToJavaWithContext(goType: GoType, ctx?: ConversionContext): string {
+    private static convertTypeToJavaWithContext(goType: GoType, options: JavaFileGenerationOptions, ctx?: ConversionContext): string {
         if (!ctx) {
-            return GoFunctionParser.convertGoTypeToJava(goType, goType.isSlice || goType.isMap);
+            return JavaCodeGenerator.toJavaType(goType, options, goType.isSlice || goType.isMap);
         }
 
         // Check for stdlib mapping first
@@ -554,7 +1564,7 @@ export class JavaFileGenerator {
         }
 
         // Use base type conversion
-        return GoFunctionParser.convertGoTypeToJava(goType, goType.isSlice || goType.isMap);
+        return JavaCodeGenerator.toJavaType(goType, options, goType.isSlice || goType.isMap);
     }
 
     /**
@@ -573,9 +1583,40 @@ export class JavaFileGenerator {
     /**
      * Generate a class field with context-aware type conversion
      */
-    private static generateClassFieldWithContext(field: GoField, ctx?: ConversionContext): string {
-        const javaType = this.convertTypeToJavaWithContext(field.type, ctx);
-        const fieldName = GoFunctionParser.toJavaMethodName(field.name);
+    /**
+     * Translate a field's `json` struct tag into Jackson annotations
+     */
+    private static generateJsonAnnotations(field: GoField): string[] {
+        if (!field.tag) {
+            return [];
+        }
+        const json = parseStructTag(field.tag).get('json');
+        if (json === undefined) {
+            return [];
+        }
+        if (json === '-') {
+            return ['@JsonIgnore'];
+        }
+
+        const [name, ...flags] = json.split(',');
+        const annotations: string[] = [];
+        if (name) {
+            annotations.push(`@JsonProperty("${name}")`);
+        }
+        if (flags.includes('omitempty')) {
+            annotations.push('@JsonInclude(JsonInclude.Include.NON_NULL)');
+        }
+        return annotations;
+    }
+
+    private static generateClassFieldWithContext(
+        field: GoField,
+        options: JavaFileGenerationOptions,
+        ctx?: ConversionContext,
+        visibility: string = 'private'
+    ): string {
+        const javaType = this.convertTypeToJavaWithContext(field.type, options, ctx);
+        const fieldName = JavaCodeGenerator.memberName(field.name, options);
         let comment = '';
 
         if (field.tag) {
@@ -590,35 +1631,60 @@ export class JavaFileGenerator {
             }
         }
 
-        return `private ${javaType} ${fieldName};${comment}`;
+        if (GoFunctionParser.isLossyUnsigned(field.type)) {
+            comment += comment ? `; unsigned ${field.type.name} in Go` : `  // unsigned ${field.type.name} in Go`;
+        }
+
+        const zero = this.fieldInitializer(field, javaType, options, options.packageFile || ctx?.mainFile);
+        const initializer = zero ? ` = ${zero}` : '';
+
+        const annotation = JavaCodeGenerator.nullabilityAnnotation(field.type, javaType, options, ctx?.mainFile);
+        return `${visibility} ${annotation}${javaType} ${fieldName}${initializer};${comment}`;
+    }
+
+    /**
+     * Initial value of a field, or undefined where Java's own zeroing matches Go. Java zeroes
+     * primitives and nulls references, so only empty collections, lists grown in place
+     * (Java cannot add to null) and structs held by value need one.
+     */
+    private static fieldInitializer(field: GoField, javaType: string, options: JavaFileGenerationOptions, scope?: GoFile): string | undefined {
+        const struct = JavaBodyGenerator.structZeroValue(field.type, options, scope);
+        if (struct) {
+            return struct;
+        }
+        const appended = !!scope && JavaBodyGenerator.appendedFields(scope).has(field.name) && javaType.startsWith('List<');
+        const zero = JavaCodeGenerator.getDefaultValue(javaType, appended ? { ...options, emptyCollections: true } : options);
+        return (options.emptyCollections || appended) && zero.startsWith('new ') ? zero : undefined;
     }
 
     /**
      * Generate a getter method with context-aware type conversion
      */
-    private static generateGetterWithContext(field: GoField, ctx?: ConversionContext): string {
-        const javaType = this.convertTypeToJavaWithContext(field.type, ctx);
-        const fieldName = GoFunctionParser.toJavaMethodName(field.name);
-        const methodName = 'get' + field.name.charAt(0).toUpperCase() + field.name.slice(1);
+    private static generateGetterWithContext(field: GoField, options: JavaFileGenerationOptions, ctx?: ConversionContext): string {
+        const javaType = this.convertTypeToJavaWithContext(field.type, options, ctx);
+        const fieldName = JavaCodeGenerator.memberName(field.name, options);
+        const methodName = this.getterName(field, options);
+        const annotation = JavaCodeGenerator.nullabilityAnnotation(field.type, javaType, options, ctx?.mainFile);
 
-        return `public ${javaType} ${methodName}() {\n    return ${fieldName};\n}`;
+        return `public ${annotation}${javaType} ${methodName}() {\n    return ${fieldName};\n}`;
     }
 
     /**
      * Generate a setter method with context-aware type conversion
      */
-    private static generateSetterWithContext(field: GoField, ctx?: ConversionContext): string {
-        const javaType = this.convertTypeToJavaWithContext(field.type, ctx);
-        const fieldName = GoFunctionParser.toJavaMethodName(field.name);
+    private static generateSetterWithContext(field: GoField, options: JavaFileGenerationOptions, ctx?: ConversionContext): string {
+        const javaType = this.convertTypeToJavaWithContext(field.type, options, ctx);
+        const fieldName = JavaCodeGenerator.memberName(field.name, options);
         const methodName = 'set' + field.name.charAt(0).toUpperCase() + field.name.slice(1);
+        const annotation = JavaCodeGenerator.nullabilityAnnotation(field.type, javaType, options, ctx?.mainFile);
 
-        return `public void ${methodName}(${javaType} ${fieldName}) {\n    this.${fieldName} = ${fieldName};\n}`;
+        return `public void ${methodName}(${annotation}${javaType} ${fieldName}) {\n    this.${fieldName} = ${fieldName};\n}`;
     }
 
     /**
      * Get Java return type with context-aware conversion
      */
-    private static getReturnTypeWithContext(returnTypes: GoType[], ctx?: ConversionContext): string {
+    private static getReturnTypeWithContext(returnTypes: GoType[], options: JavaFileGenerationOptions, ctx?: ConversionContext): string {
         if (returnTypes.length === 0) {
             return 'void';
         }
@@ -631,7 +1697,7 @@ export class JavaFileGenerator {
         }
 
         if (nonErrorTypes.length === 1) {
-            return this.convertTypeToJavaWithContext(nonErrorTypes[0], ctx);
+            return this.convertTypeToJavaWithContext(nonErrorTypes[0], options, ctx);
         }
 
         // Multiple return values would need Result class (handled elsewhere)
@@ -641,11 +1707,11 @@ export class JavaFileGenerator {
     /**
      * Generate parameter list with context-aware type conversion
      */
-    private static generateParameterListWithContext(parameters: any[], ctx?: ConversionContext): string {
+    private static generateParameterListWithContext(parameters: any[], options: JavaFileGenerationOptions, ctx?: ConversionContext): string {
         return parameters.map(param => {
-            const javaType = this.convertTypeToJavaWithContext(param.type, ctx);
+            const javaType = this.convertTypeToJavaWithContext(param.type, options, ctx);
             const paramName = GoFunctionParser.toJavaMethodName(param.name);
-            return `${javaType} ${paramName}`;
+            return `${JavaCodeGenerator.nullabilityAnnotation(param.type, javaType, options, ctx?.mainFile)}${javaType} ${paramName}`;
         }).join(', ');
     }
 }
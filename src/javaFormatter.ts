@@ -0,0 +1,108 @@
+import { stripCommentsAndLiterals } from './javaImports';
+
+/** How converted files are laid out: the internal formatter, google-java-format, or as emitted */
+export type JavaFormat = 'internal' | 'google-java-format' | 'none';
+
+const INDENT = '    ';
+
+/**
+ * An open bracket: a block, a type body, a switch body, the block of a case,
+ * or a parenthesis or square bracket spanning lines
+ */
+type OpenBracket = '{' | 'type' | 'switch' | 'case' | '(';
+
+/**
+ * Reindent generated Java the way google-java-format's AOSP style lays it out: four
+ * spaces per block, case labels one level inside their switch, eight spaces for lines
+ * continuing an open parenthesis, one blank line between members and none before a
+ * closing brace, and a single newline at the end. A line starting with an operator or a
+ * `.` continues the statement above it and is indented eight spaces past it; the lines of
+ * a text block are its content and are kept as written. Only brackets outside comments
+ * and literals count, and the input's own indentation is ignored, so formatting is
+ * idempotent: formatting the output again yields it unchanged.
+ */
+export function formatJava(source: string): string {
+    const lines = source.split(/\r?\n/);
+    const code = stripCommentsAndLiterals(lines.join('\n')).split('\n').map(line => line.trim());
+    const open: OpenBracket[] = [];
+    const output: string[] = [];
+    let blank = false;
+    let inTextBlock = false;
+
+    const track = (i: number, from: number, caseLabel: boolean) => {
+        for (let c = from; c < code[i].length; c++) {
+            const ch = code[i][c];
+            if (ch === '{') {
+                open.push(c === code[i].length - 1 ? blockKind(code[i], caseLabel) : '{');
+            } else if (ch === '(' || ch === '[') {
+                open.push('(');
+            } else if (/[})\]]/.test(ch)) {
+                open.pop();
+            }
+        }
+    };
+
+    lines.forEach((raw, i) => {
+        const line = raw.trim();
+        if (inTextBlock) {
+            output.push(raw);
+            inTextBlock = !/(^|[^\\])"""/.test(raw);
+            if (!inTextBlock) {
+                // What follows the closing delimiter (`""");`) still opens and closes brackets
+                track(i, 0, false);
+            }
+            return;
+        }
+        if (!line) {
+            blank = output.length > 0;
+            return;
+        }
+
+        // Closing brackets first dedent the line they start
+        let leading = 0;
+        let closedCase = false;
+        while (leading < code[i].length && /[})\]]/.test(code[i][leading])) {
+            closedCase = open.pop() === 'case' || closedCase;
+            leading++;
+        }
+        const top = open[open.length - 1];
+        const caseLabel = top === 'switch' && /^(case\b|default\s*:)/.test(code[i]);
+        const continues = top !== '(' && /^(&&|\|\||[?:.]|[+\-*\/%](?![+\-=]))/.test(code[i]);
+        const levels = open.reduce((n, bracket) => n + (bracket === 'switch' ? 2 : bracket === '(' || bracket === 'case' ? 0 : 1), 0)
+            - (caseLabel || (closedCase && top === 'switch') ? 1 : 0)
+            + (top === '(' || continues ? 2 : 0);
+
+        if (blank && !code[i].startsWith('}')) {
+            output.push('');
+        }
+        // A comment line inside a Javadoc or block comment lines its `*` up under the opening `/*`
+        const continuesComment = !code[i] && line.startsWith('*');
+        output.push(INDENT.repeat(Math.max(levels, 0)) + (continuesComment ? ' ' : '') + line);
+        blank = false;
+
+        track(i, leading, caseLabel);
+        inTextBlock = code[i].endsWith('""') && /"""\s*$/.test(line);
+        // A member's closing brace is followed by a blank line
+        if (leading > 0 && code[i].startsWith('}') && open[open.length - 1] === 'type') {
+            blank = true;
+        }
+    });
+
+    return output.join('\n') + '\n';
+}
+
+/**
+ * What a brace ending a line opens: a type body, a switch body, a case block or a plain block
+ */
+function blockKind(line: string, caseLabel: boolean): OpenBracket {
+    if (caseLabel) {
+        return 'case';
+    }
+    if (/^(\w+:\s*)?switch\s*\(/.test(line)) {
+        return 'switch';
+    }
+    if (/\b(class|interface|enum|record)\s+\w+/.test(line) || /\bnew\s+[\w.]+(<.*>)?\s*\(.*\)\s*\{$/.test(line)) {
+        return 'type';
+    }
+    return '{';
+}
@@ -1,4 +1,46 @@
-import { GoFunction, GoFunctionParser } from './goParser';
+import { GoFunction, GoFunctionParser, GoParameter, GoType, GoTypeParam, IntType, SliceStrategy, SourcePosition } from './goParser';
+import { GoConstant, GoFile, GoNamedType, GoStruct } from './goFileParser';
+import { JavaBodyGenerator, ResolvedBody, Rewriter } from './javaBodyGenerator';
+import { NULLABILITY_ANNOTATIONS, Nullability, collectJavaImports } from './javaImports';
+import { StdlibCallMappings } from './conversionContext';
+
+/** Java types that hold no null */
+const JAVA_PRIMITIVES = new Set(['byte', 'short', 'int', 'long', 'float', 'double', 'boolean', 'char', 'void']);
+
+/** Java release targeted when none is configured */
+export const DEFAULT_JAVA_VERSION = 11;
+
+/** Java form of an embedded struct: a delegating field, or a superclass */
+export type EmbeddingStrategy = 'composition' | 'inheritance';
+
+/** Java form of a Go defined type over a primitive or string: its underlying type, or a wrapper class */
+export type DefinedTypeStrategy = 'underlying' | 'wrapper';
+
+/**
+ * String constants grouped into a Java enum by the stringEnums option. A typed group
+ * holds every constant of a named string type and stands for that type; an untyped
+ * group is a run of string constants sharing a name prefix, which Go code keeps
+ * using as strings.
+ */
+export interface StringEnum {
+    name: string;
+    typed: boolean;
+    members: { constant: GoConstant; javaName: string }[];
+}
+
+/**
+ * A Go construct that was not converted, or was converted with different behavior
+ */
+export interface ConversionDiagnostic {
+    severity: 'unsupported' | 'degraded';
+    /** Statement kind from the body AST (GoStmt, DeferStmt, ...) or a declaration-level construct (ValueReceiver, UnsignedType, ...) */
+    construct: string;
+    message: string;
+    /** Zero-based position in the Go source, when known */
+    pos?: SourcePosition;
+    /** Declaration it belongs to: Func, Type.Method, Type.Field or a package variable */
+    scope?: string;
+}
 
 export interface JavaGenerationOptions {
     className?: string;
@@ -7,6 +49,64 @@ export interface JavaGenerationOptions {
     handleErrorsAsExceptions: boolean;
     addLearningHints?: boolean;
     includeResultClass?: boolean;
+    /** Exception class thrown in place of Go error returns (default: Exception) */
+    exceptionClass?: string;
+    /** Java representation of Go slices (default: list) */
+    sliceStrategy?: SliceStrategy;
+    /** Java type for Go int and uint (default: int) */
+    intType?: IntType;
+    /** Declarations of the whole Go package, for resolving names defined in sibling files */
+    packageFile?: GoFile;
+    /** Return `(T, error)` as a record of the value and the error message instead of throwing */
+    errorResultRecords?: boolean;
+    /** With errorResultRecords, functions returning one value share a generic Result<T> record */
+    sharedResultRecord?: boolean;
+    /** Move `NewT` constructor functions into class T as static factories */
+    staticFactories?: boolean;
+    /** Start nil slices and maps as empty collections instead of null */
+    emptyCollections?: boolean;
+    /** Let `s == nil` on a slice also hold for an empty one (`s == null || s.isEmpty()`) */
+    nilMatchesEmpty?: boolean;
+    /** Convert goroutines and channels to an ExecutorService and BlockingQueues */
+    experimentalConcurrency?: boolean;
+    /** Java form of embedded structs (default: composition) */
+    embedding?: EmbeddingStrategy;
+    /** Java form of defined types over primitives and strings (default: underlying) */
+    definedTypes?: DefinedTypeStrategy;
+    /**
+     * Aliases and defined types written as their underlying type, by name; the file
+     * generator fills this in from the declarations in scope
+     */
+    underlyingTypes?: Map<string, GoType>;
+    /** Declare typed iota constant groups as Java enums */
+    enums?: boolean;
+    /** Declare groups of string constants (same named type, or a shared name prefix) as Java enums with a String value */
+    stringEnums?: boolean;
+    /**
+     * Rename to Java conventions: camelCase methods and fields, SCREAMING_SNAKE_CASE
+     * constants (default: false). Off keeps every Go name as written.
+     */
+    javaNaming?: boolean;
+    /** Targeted Java release (default: 11) */
+    javaVersion?: number;
+    /** Turn Go tests (`func TestX(t *testing.T)`) into JUnit 5 test methods, t.Error and t.Fatal into assertions */
+    junit?: boolean;
+    /** End each translated statement and method signature with the Go line it came from: `// go:user.go:42` */
+    annotateOrigin?: boolean;
+    /** Name of the Go file being converted, for annotateOrigin */
+    sourceFile?: string;
+    /** Java translations of standard library calls, over the built-in STDLIB_CALL_MAPPINGS */
+    stdlibCalls?: StdlibCallMappings;
+    /** Custom translations consulted in order before the default one for each body statement and expression */
+    rewriters?: Rewriter[];
+    /** Annotate the method with @Override; set per method where it implements an interface method */
+    override?: boolean;
+    /** Annotate pointer types @Nullable and values Go never has as nil non-null (default: none) */
+    nullability?: Nullability;
+    /** Receives a diagnostic for every construct that does not convert cleanly */
+    diagnostics?: ConversionDiagnostic[];
+    /** Receives every function body translated, parsed and with the types of its expressions */
+    resolvedBodies?: ResolvedBody[];
 }
 
 export class JavaCodeGenerator {
@@ -15,73 +115,207 @@ export class JavaCodeGenerator {
         addComments: true,
         handleErrorsAsExceptions: true,
         includeResultClass: true
-    }): string {
+    }, goFile?: GoFile): string {
         const lines: string[] = [];
 
-        if (options.addComments) {
-            lines.push(this.generateJavaDoc(goFunc));
+        if (options.addComments || goFunc.doc) {
+            lines.push(this.generateJavaDoc(goFunc, options, goFile));
+        }
+
+        if (options.override) {
+            lines.push('    @Override');
         }
+        const signature = this.generateMethodSignature(goFunc, options, goFile);
+        lines.push(this.withOrigin(signature, goFunc.namePosition, options));
 
-        const signature = this.generateMethodSignature(goFunc, options);
-        lines.push(signature);
-        lines.push("    // TODO: Implement method logic");
-        lines.push(this.generateMethodBody(goFunc, options));
+        // Translate the Go body when it was parsed; signatures alone get a stub
+        const body = JavaBodyGenerator.generateBody(goFunc, options, goFile);
+        if (body) {
+            // Java objects are references, so a value receiver's copy semantics are lost
+            if (JavaBodyGenerator.mutatesValueReceiver(goFunc)) {
+                lines.push('    // value receiver: mutations not reflected in caller');
+                options.diagnostics?.push({
+                    severity: 'degraded',
+                    construct: 'ValueReceiver',
+                    message: 'Value receiver mutation not preserved: the Java method changes the caller\'s object, the Go method only its copy',
+                    pos: goFunc.namePosition,
+                    scope: this.scopeName(goFunc)
+                });
+            }
+            lines.push(...body);
+            lines.push('}');
+        } else {
+            lines.push("    // TODO: Implement method logic");
+            lines.push(this.generateMethodBody(goFunc, options));
+        }
 
         return lines.join('\n');
     }
 
-    private static generateJavaDoc(goFunc: GoFunction): string {
+    /**
+     * JUnit 5 test method for a Go test in junit mode. A table-driven test becomes a
+     * `@ParameterizedTest` fed by a static `<name>Cases()` method; any other test an
+     * instance `@Test` method taking no parameters.
+     */
+    static generateTestMethod(goFunc: GoFunction, options: JavaGenerationOptions, goFile?: GoFile): string {
+        const lines: string[] = [];
+
+        if (options.addComments || goFunc.doc) {
+            lines.push(this.generateJavaDoc(goFunc, options, goFile));
+        }
+
+        const name = this.memberName(goFunc.name, options);
+        const throwsClause = this.throwsClause(goFunc, options, goFile);
+        const table = JavaBodyGenerator.generateParameterizedTest(goFunc, options, goFile);
+        if (!table) {
+            lines.push('    @Test');
+            lines.push(this.withOrigin(`    void ${name}()${throwsClause} {`, goFunc.namePosition, options));
+            lines.push(...(JavaBodyGenerator.generateBody(goFunc, options, goFile) || []));
+            lines.push('}');
+            return lines.join('\n');
+        }
+
+        lines.push(table.nameIndex !== undefined ? `    @ParameterizedTest(name = "{${table.nameIndex}}")` : '    @ParameterizedTest');
+        lines.push(`    @MethodSource("${name}Cases")`);
+        lines.push(this.withOrigin(`    void ${name}(${table.parameters.join(', ')})${throwsClause} {`, goFunc.namePosition, options));
+        lines.push(...table.body);
+        lines.push('}');
+        lines.push('');
+        lines.push(`    static Stream<Arguments> ${name}Cases() {`);
+        lines.push('    return Stream.of(');
+        table.rows.forEach((row, i) => lines.push(`            Arguments.of(${row.join(', ')})${i < table.rows.length - 1 ? ',' : ''}`));
+        lines.push('    );');
+        lines.push('}');
+        return lines.join('\n');
+    }
+
+    /**
+     * A Java line ending in the Go line it came from, with annotateOrigin and a known position
+     */
+    static withOrigin(line: string, pos: SourcePosition | undefined, options: JavaGenerationOptions): string {
+        if (!options.annotateOrigin || !pos) {
+            return line;
+        }
+        return `${line} // go:${options.sourceFile ? `${options.sourceFile}:` : ''}${pos.line + 1}`;
+    }
+
+    /**
+     * Whether a function is a Go test: `TestXxx(t *testing.T)`
+     */
+    static isTestFunction(goFunc: GoFunction): boolean {
+        return !goFunc.isMethod && /^Test(?![a-z])/.test(goFunc.name) && !goFunc.typeParams?.length
+            && goFunc.parameters.length === 1 && this.isTestingT(goFunc.parameters[0].type)
+            && goFunc.returnTypes.length === 0;
+    }
+
+    /**
+     * Whether a function is a package initializer, `func init()`, which Go runs at package
+     * load and which no code can call
+     */
+    static isInitFunction(goFunc: GoFunction): boolean {
+        return !goFunc.isMethod && goFunc.name === 'init' && !goFunc.typeParams?.length
+            && goFunc.parameters.length === 0 && goFunc.returnTypes.length === 0;
+    }
+
+    /**
+     * Whether a type is `*testing.T`
+     */
+    static isTestingT(type?: GoType): boolean {
+        return !!type && type.name === 'testing.T' && type.isPointer && !type.isSlice && !type.isMap;
+    }
+
+    /**
+     * Whether a parameter is left out of the Java signature: in junit mode the `*testing.T`
+     * of tests and test helpers, whose role JUnit's static assertions take over
+     */
+    static isDroppedParameter(param: GoParameter, options: JavaGenerationOptions): boolean {
+        return !!options.junit && this.isTestingT(param.type);
+    }
+
+    /**
+     * Name of a function in diagnostics: `Func`, or `Type.Method` for methods
+     */
+    static scopeName(goFunc: GoFunction): string {
+        return goFunc.receiver ? `${goFunc.receiver.type.name}.${goFunc.name}` : goFunc.name;
+    }
+
+    /**
+     * Render Go doc comment text as Javadoc lines (" * ..."), keeping the Go line breaks
+     */
+    static formatDocComment(doc: string): string[] {
+        return doc.split('\n').map(line => line.trim() ? ` * ${line.replace(/\*\//g, '*&#47;')}` : ' *');
+    }
+
+    private static generateJavaDoc(goFunc: GoFunction, options: JavaGenerationOptions, goFile?: GoFile): string {
+        // Without addComments only the Go doc comment is kept, plus the tags it talks about
+        const mentions = (pattern: RegExp) => options.addComments || (!!goFunc.doc && pattern.test(goFunc.doc));
         const lines: string[] = ['    /**'];
-        lines.push(`     * Converted from Go function: ${goFunc.name}`);
+        if (goFunc.doc) {
+            lines.push(...this.formatDocComment(goFunc.doc).map(line => '    ' + line));
+        } else {
+            lines.push(`     * Converted from Go function: ${goFunc.name}`);
+        }
 
-        if (goFunc.parameters.length > 0) {
+        const params = goFunc.parameters.filter(p => p.name && !this.isDroppedParameter(p, options)
+            && mentions(new RegExp(`\\b${p.name}\\b`)));
+        if (params.length > 0) {
             lines.push('     *');
-            for (const param of goFunc.parameters) {
-                const javaType = GoFunctionParser.convertGoTypeToJava(param.type);
-                lines.push(`     * @param ${param.name} ${this.getParameterDescription(param)}`);
+            for (const param of params) {
+                lines.push(`     * @param ${this.toJavaParameterName(param.name)} ${this.getParameterDescription(param, options)}`);
             }
         }
 
-        if (goFunc.returnTypes.length > 0) {
+        // (T, error) returns T and throws; a lone error return is void
+        const returned = goFunc.returnTypes.filter(t => t.name !== 'error');
+        if (returned.length > 0 && mentions(/\breturn/i)) {
             lines.push('     *');
-            if (goFunc.returnTypes.length === 1) {
-                const javaType = GoFunctionParser.convertGoTypeToJava(goFunc.returnTypes[0]);
-                if (goFunc.returnTypes[0].name !== 'error') {
-                    lines.push(`     * @return ${javaType} value`);
-                }
-            } else {
-                lines.push(`     * @return Result object containing multiple return values`);
-            }
+            const record = this.usesResultRecord(goFunc, options);
+            lines.push(`     * @return ${this.returnDescription(this.getReturnType(goFunc, options), returned.length, record)}`);
         }
 
-        if (goFunc.hasErrorReturn) {
-            lines.push('     * @throws Exception if operation fails');
+        if (this.throwsErrors(goFunc, options, goFile) && mentions(/\berr/i)) {
+            lines.push(`     * @throws ${this.getExceptionClass(options)} if operation fails`);
         }
 
         lines.push('     */');
         return lines.join('\n');
     }
 
-    private static getParameterDescription(param: any): string {
+    /**
+     * Javadoc `@return` text for a method returning javaType, which holds `values` Go
+     * results other than the error, or is the result record holding them and the error
+     */
+    static returnDescription(javaType: string, values: number, record: boolean): string {
+        if (record) {
+            return `${javaType} with the ${values === 1 ? 'value' : 'values'}, or the error message (null on success)`;
+        }
+        return values === 1 ? `${javaType} value` : `${javaType} holding the ${values} return values`;
+    }
+
+    /** Javadoc `@param` text naming the Java type the parameter is declared with */
+    private static getParameterDescription(param: any, options: JavaGenerationOptions): string {
         if (param.type.isSlice) {
-            return `list of ${param.type.name} values`;
+            const array = options.sliceStrategy === 'array' || GoFunctionParser.isByteSlice(param.type);
+            const element = this.toJavaType(GoFunctionParser.elementTypeOf(param.type), options, !array);
+            return `${array ? 'array' : 'list'} of ${element} values`;
         }
-        if (param.type.isMap) {
-            return `map with ${param.type.keyType?.name} keys and ${param.type.valueType?.name} values`;
+        if (param.type.isMap && param.type.keyType && param.type.valueType) {
+            return `map with ${this.toJavaType(param.type.keyType, options, true)} keys and ${this.toJavaType(param.type.valueType, options, true)} values`;
         }
+        const javaType = this.toJavaType(param.type, options);
         if (param.type.isPointer) {
-            return `${param.type.name} reference`;
+            return `${javaType} reference`;
         }
         if (param.type.isInterface) {
-            return `${param.type.name} interface implementation`;
+            return `${javaType} interface implementation`;
         }
         if (param.type.isStruct) {
-            return `${param.type.name} object`;
+            return `${javaType} object`;
         }
-        return `${param.type.name} value`;
+        return `${javaType} value`;
     }
 
-    private static generateMethodSignature(goFunc: GoFunction, options: JavaGenerationOptions): string {
+    private static generateMethodSignature(goFunc: GoFunction, options: JavaGenerationOptions, goFile?: GoFile): string {
         const parts: string[] = [];
 
         if (options.isStatic && !goFunc.isMethod) {
@@ -92,18 +326,428 @@ export class JavaCodeGenerator {
             parts.push('public');
         }
 
+        if (goFunc.typeParams?.length) {
+            parts.push(this.typeParameterList(goFunc.typeParams, options, goFile));
+        }
+
         const returnType = this.getReturnType(goFunc, options);
+        const returned = goFunc.returnTypes.filter(t => t.name !== 'error');
+        const annotation = returned.length === 1 && !this.usesResultRecord(goFunc, options)
+            ? this.nullabilityAnnotation(returned[0], returnType, options, goFile) : '';
+        if (annotation) {
+            // Ahead of the modifiers, where it may stand before type parameters
+            parts.unshift(annotation.trim());
+        }
         parts.push(returnType);
 
-        const methodName = GoFunctionParser.toJavaMethodName(goFunc.name);
-        const params = this.generateParameterList(goFunc);
+        const methodName = this.memberName(goFunc.name, options);
+        const params = this.generateParameterList(goFunc, options, goFile);
         parts.push(`${methodName}(${params})`);
 
-        if (goFunc.hasErrorReturn && options.handleErrorsAsExceptions) {
-            parts.push('throws Exception');
+        return `    ${parts.join(' ')}${this.throwsClause(goFunc, options, goFile)} {`;
+    }
+
+    /**
+     * Method of an embedding class that forwards a promoted Go method to the embedded field
+     * @param goFile Enclosing file, for the throws clause
+     */
+    static generateForwardingMethod(goFunc: GoFunction, fieldName: string, options: JavaGenerationOptions, goFile?: GoFile): string {
+        const signature = this.generateMethodSignature(goFunc, options, goFile).trim();
+        const args = goFunc.parameters.map(p => this.toJavaParameterName(p.name)).join(', ');
+        const call = `${fieldName}.${this.memberName(goFunc.name, options)}(${args});`;
+        return [...(options.override ? ['@Override'] : []), signature, `    ${this.getReturnType(goFunc, options) === 'void' ? call : `return ${call}`}`, '}'].join('\n');
+    }
+
+    /**
+     * Convert a Go type honoring the configured slice strategy
+     */
+    static toJavaType(goType: GoType, options: JavaGenerationOptions, needsBoxing: boolean = false): string {
+        const type = options.underlyingTypes?.size ? GoFunctionParser.substituteTypeParams(goType, options.underlyingTypes) : goType;
+        return GoFunctionParser.convertGoTypeToJava(type, needsBoxing, options.sliceStrategy, options.intType);
+    }
+
+    /**
+     * Java type parameter list for Go type parameters, or '' when there are none:
+     * `any` gives an unbounded type variable, `comparable` and `cmp.Ordered` a
+     * `Comparable<T>` bound, numeric type sets `Number` and interfaces themselves.
+     * Other type sets (`~string | ~[]byte`) have no Java bound and stay unbounded.
+     * @param goFile Declarations to search for constraint interfaces
+     */
+    static typeParameterList(typeParams: GoTypeParam[] | undefined, options: JavaGenerationOptions, goFile?: GoFile): string {
+        if (!typeParams?.length) {
+            return '';
+        }
+        const file = options.packageFile || goFile;
+        // A Go type of the same name would shadow java.lang's
+        const javaLang = (type: string) => file?.structs.some(s => s.name === type) || file?.namedTypes.some(t => t.name === type)
+            || file?.interfaces.some(i => i.name === type && !i.typeTerms) ? `java.lang.${type}` : type;
+        const bound = ({ name, constraint }: GoTypeParam): string | undefined => {
+            if (constraint === 'any' || /^interface\s*\{\s*\}$/.test(constraint)) {
+                return undefined;
+            }
+            if (['comparable', 'cmp.Ordered', 'constraints.Ordered'].includes(constraint)) {
+                return `${javaLang('Comparable')}<${name}>`;
+            }
+            if (['constraints.Integer', 'constraints.Signed', 'constraints.Unsigned', 'constraints.Float'].includes(constraint)) {
+                return javaLang('Number');
+            }
+            const declared = file?.interfaces.find(i => i.name === constraint);
+            const terms = /[~|]/.test(constraint) || GoFunctionParser.isBuiltinType(constraint)
+                ? constraint.split('|').map(t => t.trim())
+                : declared?.typeTerms;
+            if (terms) {
+                const numeric = terms.every(t => GoFunctionParser.isNumericType(t.replace(/^~/, '')));
+                return numeric ? javaLang('Number') : undefined;
+            }
+            if (constraint.startsWith('interface')) {
+                return undefined;
+            }
+            return this.toJavaType(GoFunctionParser['parseType'](constraint), options, true);
+        };
+        return `<${typeParams.map(p => {
+            const javaBound = bound(p);
+            return javaBound ? `${p.name} extends ${javaBound}` : p.name;
+        }).join(', ')}>`;
+    }
+
+    /**
+     * Java name of a Go function, method, field or variable: camelCase
+     * (`GetFullInfo` → `getFullInfo`), or the Go name when javaNaming is off
+     */
+    static memberName(goName: string, options: JavaGenerationOptions): string {
+        return options.javaNaming ? GoFunctionParser.toJavaMethodName(goName) : GoFunctionParser.escapeJavaKeyword(goName);
+    }
+
+    /**
+     * Java name of a Go constant: SCREAMING_SNAKE_CASE (`MaxRetries` → `MAX_RETRIES`),
+     * or the Go name when javaNaming is off
+     */
+    static constantName(goName: string, options: JavaGenerationOptions): string {
+        return options.javaNaming ? GoFunctionParser.toJavaConstantName(goName) : GoFunctionParser.escapeJavaKeyword(goName);
+    }
+
+    /**
+     * Exception class used for Go error returns
+     */
+    static getExceptionClass(options: JavaGenerationOptions): string {
+        return options.exceptionClass || 'Exception';
+    }
+
+    /**
+     * Struct whose class holds goFunc as a static factory in staticFactories mode:
+     * `NewUser` (or `newUser`) returning `User` or `*User` moves into `User`
+     * @param goFile Declarations to search, normally the whole package
+     */
+    static factoryOwner(goFunc: GoFunction, options: JavaGenerationOptions, goFile?: GoFile): string | undefined {
+        if (!options.staticFactories || goFunc.isMethod || !/^[Nn]ew/.test(goFunc.name)) {
+            return undefined;
+        }
+        const result = goFunc.returnTypes.find(t => t.name !== 'error');
+        const owner = goFile?.structs.find(s => s.name === result?.name);
+        if (!owner || result!.isSlice || result!.isMap || goFunc.name.slice(3) !== GoFunctionParser.toJavaClassName(owner.name)) {
+            return undefined;
+        }
+        return owner.name;
+    }
+
+    /**
+     * Struct whose class the class of struct extends in inheritance mode: its first embedded
+     * struct declared in goFile. Redeclaring one of that struct's fields keeps it a field,
+     * since the constructor and field accesses could not tell the two apart.
+     * @param goFile Declarations to search, normally the whole package
+     */
+    static superStruct(struct: GoStruct, options: JavaGenerationOptions, goFile?: GoFile): GoStruct | undefined {
+        if (options.embedding !== 'inheritance') {
+            return undefined;
+        }
+        const first = struct.fields.find(f => f.isEmbedded && goFile?.structs.some(s => s.name === f.type.name));
+        const embedded = first && goFile!.structs.find(s => s.name === first.type.name);
+        if (!embedded || first!.type.isSlice || first!.type.isMap) {
+            return undefined;
+        }
+        const hidden = embedded.fields.some(ef => struct.fields.some(f => !f.isEmbedded && f.name === ef.name));
+        // Mutually embedded pointers would make a cycle of superclasses
+        let ancestor: GoStruct | undefined = embedded;
+        for (let depth = 0; ancestor && depth < goFile!.structs.length; depth++) {
+            if (ancestor === struct) {
+                return undefined;
+            }
+            const next = ancestor.fields.find(f => f.isEmbedded && goFile!.structs.some(s => s.name === f.type.name));
+            ancestor = next && goFile!.structs.find(s => s.name === next.type.name);
+        }
+        return hidden ? undefined : embedded;
+    }
+
+    /**
+     * Go types that become Java enums, with their constants in order: named non-struct types
+     * whose constants are a single iota sequence 0, 1, 2, ... (`Sunday Weekday = iota; Monday;
+     * Tuesday`). With the enums option every such type does; without it only those with a
+     * String() method, which reads best as an enum's toString(). Untyped iota groups have no
+     * type to name the enum after and stay int constants.
+     * @param goFile Declarations to search, normally the whole package
+     */
+    static enumTypes(options: JavaGenerationOptions, goFile?: GoFile): Map<string, GoConstant[]> {
+        const enums = new Map<string, GoConstant[]>();
+        if (!goFile) {
+            return enums;
+        }
+        for (const constant of goFile.constants) {
+            const type = constant.type;
+            if (!type || type.isPointer || type.isSlice || type.isMap || type.name.includes('.')
+                || GoFunctionParser.isBuiltinType(type.name)
+                || goFile.structs.some(s => s.name === type.name)
+                || goFile.interfaces.some(i => i.name === type.name)) {
+                continue;
+            }
+            if (!options.enums && !this.stringMethod(goFile.namedTypes.find(t => t.name === type.name)?.methods || [])) {
+                continue;
+            }
+            enums.set(type.name, [...(enums.get(type.name) || []), constant]);
+        }
+        for (const [name, members] of enums) {
+            if (!members.every((constant, i) => constant.iota === i)) {
+                enums.delete(name);
+            }
+        }
+        return enums;
+    }
+
+    /**
+     * A type's Go String() method, which Java code calls toString()
+     */
+    static stringMethod(methods: GoFunction[]): GoFunction | undefined {
+        return methods.find(m => m.name === 'String' && m.parameters.length === 0);
+    }
+
+    /**
+     * Java form of an alias or defined type. Aliases and most defined types are written
+     * as their underlying type ('underlying'); defined types over a struct become a class
+     * with the struct's fields but only their own methods ('copy'), as Go gives them none
+     * of the struct's; with the wrapper strategy, defined types over a primitive or string
+     * become a class holding the value ('wrapper'); and enums declares the enums ('enum').
+     * @param goFile Declarations to search, normally the whole package
+     */
+    static namedTypeForm(named: GoNamedType, options: JavaGenerationOptions, goFile?: GoFile): 'underlying' | 'copy' | 'wrapper' | 'enum' {
+        if (named.isAlias) {
+            return 'underlying';
+        }
+        if (this.enumTypes(options, goFile).has(named.name)
+            || this.stringEnums(options, goFile).some(e => e.typed && e.name === named.name)) {
+            return 'enum';
+        }
+        const underlying = this.definedUnderlying(named, goFile);
+        const simple = !underlying.isPointer && !underlying.isSlice && !underlying.isMap && !underlying.typeArgs;
+        if (simple && goFile?.structs.some(s => s.name === underlying.name)) {
+            return 'copy';
+        }
+        if (options.definedTypes === 'wrapper' && simple && GoFunctionParser.isBuiltinType(underlying.name)
+            && !['error', 'any'].includes(underlying.name)) {
+            return 'wrapper';
+        }
+        return 'underlying';
+    }
+
+    /**
+     * Underlying type of a defined type, following the names it is defined over:
+     * `type Kelvin Celsius` over `type Celsius float64` is a float64
+     */
+    static definedUnderlying(named: GoNamedType, goFile?: GoFile): GoType {
+        const seen = new Set([named.name]);
+        let underlying = named.underlying;
+        let next: GoNamedType | undefined;
+        while (!underlying.isPointer && !underlying.isSlice && !underlying.isMap
+            && (next = goFile?.namedTypes.find(t => t.name === underlying.name)) && !seen.has(next.name)) {
+            seen.add(next.name);
+            underlying = next.underlying;
         }
+        return underlying;
+    }
+
+    /**
+     * The aliases and defined types written as their underlying type, for underlyingTypes:
+     * each maps to a type naming none of them
+     * @param goFile Declarations to search, normally the whole package
+     */
+    static underlyingTypeBindings(options: JavaGenerationOptions, goFile?: GoFile): Map<string, GoType> {
+        const bindings = new Map<string, GoType>();
+        for (const named of goFile?.namedTypes || []) {
+            if (this.namedTypeForm(named, options, goFile) === 'underlying') {
+                bindings.set(named.name, named.isAlias ? named.underlying : this.definedUnderlying(named, goFile));
+            }
+        }
+        // Aliases of aliases, and composites of them (`type IDs []ID`), resolve in as many rounds as there are names
+        for (let round = 0; round < bindings.size; round++) {
+            for (const [name, type] of bindings) {
+                bindings.set(name, GoFunctionParser.substituteTypeParams(type, bindings));
+            }
+        }
+        return bindings;
+    }
+
+    /**
+     * Groups of string constants that become Java enums with the stringEnums option.
+     * Each named non-struct type whose constants all have string literal values is one
+     * (`type Status string; const StatusActive Status = "active"`), and so is each run of
+     * two or more consecutive untyped string constants whose names share leading words
+     * (`StatusActive = "active"; StatusInactive = "inactive"` → enum Status). Members are
+     * named without the shared prefix (ACTIVE, INACTIVE). Groups that do not map cleanly,
+     * with repeated values, a member name that is not a Java identifier or an enum name
+     * already taken, stay String constants.
+     * @param goFile Declarations to search, normally the whole package
+     */
+    static stringEnums(options: JavaGenerationOptions, goFile?: GoFile): StringEnum[] {
+        if (!options.stringEnums || !goFile) {
+            return [];
+        }
+        const isStringLiteral = (c: GoConstant) => c.value !== undefined && /^(?:"(?:[^"\\\n]|\\.)*"|`[^`]*`)$/.test(c.value.trim());
+        const words = (name: string) => name.match(/[A-Z]+(?![a-z])|[A-Z]?[a-z]+|\d+/g) || [name];
+        const sharedPrefix = (names: string[]) => {
+            const split = names.map(words);
+            let n = 0;
+            while (split.every(w => n < w.length - 1 && w[n] === split[0][n])) {
+                n++;
+            }
+            return split[0].slice(0, n).join('');
+        };
+
+        const candidates: { name: string; typed: boolean; constants: GoConstant[]; prefix: string }[] = [];
+        const typed = new Map<string, GoConstant[]>();
+        for (const constant of goFile.constants) {
+            const type = constant.type;
+            if (type && !type.isPointer && !type.isSlice && !type.isMap && !type.name.includes('.')
+                && !GoFunctionParser.isBuiltinType(type.name) && !goFile.structs.some(s => s.name === type.name)) {
+                typed.set(type.name, [...(typed.get(type.name) || []), constant]);
+            }
+        }
+        for (const [name, constants] of typed) {
+            if (constants.every(isStringLiteral)) {
+                const prefix = constants.every(c => c.name.startsWith(name) && /^[A-Z_\d]/.test(c.name.slice(name.length))) ? name : '';
+                candidates.push({ name, typed: true, constants, prefix });
+            }
+        }
+        let run: GoConstant[] = [];
+        const closeRun = () => {
+            const prefix = run.length > 1 ? sharedPrefix(run.map(c => c.name)) : '';
+            if (prefix) {
+                candidates.push({ name: prefix.charAt(0).toUpperCase() + prefix.slice(1), typed: false, constants: run, prefix });
+            }
+            run = [];
+        };
+        for (const constant of goFile.constants) {
+            if (constant.type?.name !== 'string' || !isStringLiteral(constant)) {
+                closeRun();
+                continue;
+            }
+            if (run.length > 0 && !sharedPrefix([...run, constant].map(c => c.name))) {
+                closeRun();
+            }
+            run.push(constant);
+        }
+        closeRun();
+
+        const taken = new Set([
+            ...goFile.structs.map(s => s.name),
+            ...goFile.interfaces.map(i => i.name),
+            ...goFile.constants.map(c => c.name),
+            ...this.enumTypes(options, goFile).keys()
+        ]);
+        const enums: StringEnum[] = [];
+        for (const candidate of candidates) {
+            const members = candidate.constants.map(constant => ({
+                constant,
+                javaName: this.constantName(constant.name.slice(candidate.prefix.length).replace(/^_/, ''), options)
+            }));
+            const clean = members.every(m => /^[A-Za-z_$][\w$]*$/.test(m.javaName))
+                && new Set(members.map(m => m.javaName)).size === members.length
+                && new Set(members.map(m => m.constant.value!.trim())).size === members.length
+                && (candidate.typed || !taken.has(candidate.name));
+            if (clean) {
+                taken.add(candidate.name);
+                enums.push({ name: candidate.name, typed: candidate.typed, members });
+            }
+        }
+        return enums;
+    }
+
+    /**
+     * The ` throws ...` of a method, or '': its exception class when throwsErrors, and with
+     * experimental concurrency the InterruptedException of the channels it waits on
+     */
+    static throwsClause(goFunc: GoFunction, options: JavaGenerationOptions, goFile?: GoFile): string {
+        const thrown = this.throwsErrors(goFunc, options, goFile) ? [this.getExceptionClass(options)] : [];
+        const covered = thrown[0] === 'Exception' || thrown[0] === 'Throwable';
+        if (options.experimentalConcurrency && !covered && JavaBodyGenerator.blocksOnChannels(goFunc, options.packageFile || goFile)) {
+            thrown.push('InterruptedException');
+        }
+        return thrown.length > 0 ? ` throws ${thrown.join(', ')}` : '';
+    }
 
-        return `    ${parts.join(' ')} {`;
+    /**
+     * Whether the Java method declares `throws`: its Go errors become exceptions and its body
+     * can actually produce one
+     * @param goFile Enclosing file, whose functions are followed for calls that can throw
+     */
+    static throwsErrors(goFunc: GoFunction, options: JavaGenerationOptions, goFile?: GoFile): boolean {
+        return goFunc.hasErrorReturn
+            && options.handleErrorsAsExceptions
+            && !this.usesResultRecord(goFunc, options)
+            && JavaBodyGenerator.canThrow(goFunc, options.packageFile || goFile);
+    }
+
+    /**
+     * Whether a function's `(T..., error)` result is returned as a record rather than thrown
+     */
+    static usesResultRecord(goFunc: GoFunction, options: JavaGenerationOptions): boolean {
+        const returnTypes = goFunc.returnTypes;
+        return !!options.errorResultRecords
+            && returnTypes.length > 1
+            && returnTypes[returnTypes.length - 1].name === 'error'
+            && returnTypes.slice(0, -1).every(t => t.name !== 'error');
+    }
+
+    /**
+     * Whether the result record is the shared Result<T>. Methods always share it so that
+     * implementations agree with the interface methods they satisfy.
+     */
+    private static usesSharedResultRecord(goFunc: GoFunction, options: JavaGenerationOptions): boolean {
+        return goFunc.returnTypes.length === 2 && (!!options.sharedResultRecord || goFunc.isMethod);
+    }
+
+    /**
+     * Java type of a function's result record (requires usesResultRecord)
+     */
+    static getResultRecordType(goFunc: GoFunction, options: JavaGenerationOptions): string {
+        if (this.usesSharedResultRecord(goFunc, options)) {
+            return `Result<${this.toJavaType(goFunc.returnTypes[0], options, true)}>`;
+        }
+        const receiver = goFunc.isMethod && goFunc.receiver
+            ? GoFunctionParser.toJavaClassName(goFunc.receiver.type.name.replace('*', ''))
+            : '';
+        return `${receiver}${GoFunctionParser.toJavaClassName(goFunc.name)}Result`;
+    }
+
+    /**
+     * Constructor expression prefix for a result record (`new DivideResult`, `new Result<>`)
+     */
+    static getResultRecordConstructor(goFunc: GoFunction, options: JavaGenerationOptions): string {
+        return this.usesSharedResultRecord(goFunc, options)
+            ? 'new Result<>'
+            : `new ${this.getResultRecordType(goFunc, options)}`;
+    }
+
+    /** Generic record shared by all single-value functions in sharedResultRecord mode */
+    static readonly SHARED_RESULT_RECORD = 'public record Result<T>(T value, String error) {}';
+
+    /**
+     * Declaration of a function's own result record, or '' when it uses the shared Result<T>
+     */
+    static generateResultRecord(goFunc: GoFunction, options: JavaGenerationOptions): string {
+        if (!this.usesResultRecord(goFunc, options) || this.usesSharedResultRecord(goFunc, options)) {
+            return '';
+        }
+        const values = goFunc.returnTypes.slice(0, -1);
+        const components = values.map((t, i) => `${this.toJavaType(t, options)} ${values.length === 1 ? 'value' : `value${i + 1}`}`);
+        return `public record ${this.getResultRecordType(goFunc, options)}(${[...components, 'String error'].join(', ')}) {}`;
     }
 
     private static getReturnType(goFunc: GoFunction, options: JavaGenerationOptions): string {
@@ -113,6 +757,10 @@ export class JavaCodeGenerator {
             return 'void';
         }
 
+        if (this.usesResultRecord(goFunc, options)) {
+            return this.getResultRecordType(goFunc, options);
+        }
+
         // Filter out error types for return type
         const nonErrorTypes = goFunc.returnTypes.filter(t => t.name !== 'error');
         
@@ -121,12 +769,12 @@ export class JavaCodeGenerator {
         }
 
         if (nonErrorTypes.length === 1) {
-            return GoFunctionParser.convertGoTypeToJava(nonErrorTypes[0]);
+            return this.toJavaType(nonErrorTypes[0], options);
         }
 
         return includeResultClass
             ? this.generateResultClassName(goFunc, options)
-            : GoFunctionParser.convertGoTypeToJava(nonErrorTypes[0]);
+            : this.toJavaType(nonErrorTypes[0], options);
     }
 
     private static generateResultClassName(goFunc: GoFunction, options: JavaGenerationOptions): string {
@@ -136,32 +784,69 @@ export class JavaCodeGenerator {
         return `${GoFunctionParser.toJavaClassName(goFunc.name)}Result`;
     }
 
-    private static generateParameterList(goFunc: GoFunction): string {
+    private static generateParameterList(goFunc: GoFunction, options: JavaGenerationOptions, goFile?: GoFile): string {
         const params: string[] = [];
 
         for (let i = 0; i < goFunc.parameters.length; i++) {
             const param = goFunc.parameters[i];
             const isLast = i === goFunc.parameters.length - 1;
-            
+            if (this.isDroppedParameter(param, options)) {
+                continue;
+            }
+
             if (param.type.isVariadic && isLast) {
-                const baseType = GoFunctionParser.convertGoTypeToJava(
-                    { ...param.type, isSlice: false, isVariadic: false }
-                );
+                const baseType = this.toJavaType(GoFunctionParser.elementTypeOf(param.type), options);
                 const paramName = this.toJavaParameterName(param.name);
                 params.push(`${baseType}... ${paramName}`);
             } else {
-                const javaType = GoFunctionParser.convertGoTypeToJava(param.type);
+                const javaType = this.toJavaType(param.type, options);
                 const paramName = this.toJavaParameterName(param.name);
-                params.push(`${javaType} ${paramName}`);
+                params.push(`${this.nullabilityAnnotation(param.type, javaType, options, goFile)}${javaType} ${paramName}`);
             }
         }
 
         return params.join(', ');
     }
 
+    /**
+     * Nullability annotation, with a trailing space, for a Java type written for a Go type:
+     * nullable for pointers and non-null for values Go never has as nil (strings, numbers
+     * boxed in generics, structs, enums). Slices, maps, interfaces and functions can be nil
+     * without being pointers and primitives cannot be null, so they get none, as do
+     * types declared elsewhere.
+     * @param goFile Declarations to search for structs and defined types
+     */
+    static nullabilityAnnotation(goType: GoType, javaType: string, options: JavaGenerationOptions, goFile?: GoFile): string {
+        const flavor = options.nullability && options.nullability !== 'none' ? NULLABILITY_ANNOTATIONS[options.nullability] : undefined;
+        if (!flavor || JAVA_PRIMITIVES.has(javaType)) {
+            return '';
+        }
+        if (goType.isPointer && !goType.isSlice && !goType.isMap) {
+            return `@${flavor.nullable} `;
+        }
+        return this.neverNil(goType, options.packageFile || goFile) ? `@${flavor.nonNull} ` : '';
+    }
+
+    private static neverNil(goType: GoType, goFile?: GoFile, seen = new Set<string>()): boolean {
+        if (goType.isPointer || goType.isSlice || goType.isMap || goType.isVariadic) {
+            return false;
+        }
+        if (GoFunctionParser.isBuiltinType(goType.name)) {
+            return !['error', 'any', 'interface{}'].includes(goType.name);
+        }
+        if (goFile?.structs.some(s => s.name === goType.name)) {
+            return true;
+        }
+        const named = goFile?.namedTypes.find(t => t.name === goType.name);
+        if (!named || seen.has(named.name)) {
+            return false;
+        }
+        seen.add(named.name);
+        return this.neverNil(named.underlying, goFile, seen);
+    }
+
     private static toJavaParameterName(goName: string): string {
-        if (goName.length === 0) return goName;
-        return goName.charAt(0).toLowerCase() + goName.slice(1);
+        return GoFunctionParser.toJavaMethodName(goName);
     }
 
     private static generateMethodBody(goFunc: GoFunction, options: JavaGenerationOptions): string {
@@ -171,23 +856,26 @@ export class JavaCodeGenerator {
         if (goFunc.returnTypes.length === 0) {
             if (goFunc.hasErrorReturn && options.handleErrorsAsExceptions) {
                 lines.push('    // Handle error case');
-                lines.push('    throw new Exception("Not implemented");');
+                lines.push(`    throw new ${this.getExceptionClass(options)}("Not implemented");`);
             } else {
                 lines.push('    // Method implementation');
             }
+        } else if (this.usesResultRecord(goFunc, options)) {
+            const defaults = goFunc.returnTypes.slice(0, -1).map(t => this.getDefaultValue(this.toJavaType(t, options), options));
+            lines.push(`    return ${this.getResultRecordConstructor(goFunc, options)}(${[...defaults, 'null'].join(', ')});`);
         } else {
             const nonErrorTypes = goFunc.returnTypes.filter(t => t.name !== 'error');
 
             if (nonErrorTypes.length === 0) {
                 if (options.handleErrorsAsExceptions) {
-                    lines.push('    throw new Exception("Not implemented");');
+                    lines.push(`    throw new ${this.getExceptionClass(options)}("Not implemented");`);
                 } else {
                     lines.push('    // TODO: Implement method logic');
                 }
             } else if (nonErrorTypes.length === 1 || !includeResultClass) {
                 const targetType = nonErrorTypes[0];
-                const javaType = GoFunctionParser.convertGoTypeToJava(targetType);
-                const defaultValue = this.getDefaultValue(javaType);
+                const javaType = this.toJavaType(targetType, options);
+                const defaultValue = this.getDefaultValue(javaType, options);
                 lines.push(`    return ${defaultValue};`);
             } else {
                 const resultClass = this.generateResultClassName(goFunc, options);
@@ -200,7 +888,11 @@ export class JavaCodeGenerator {
         return lines.join('\n');
     }
 
-    private static getDefaultValue(javaType: string): string {
+    /**
+     * Java counterpart of Go's zero value for a variable of the given Java type.
+     * Strings and boxed types start as null; slices and maps are null unless emptyCollections is set.
+     */
+    static getDefaultValue(javaType: string, options?: JavaGenerationOptions): string {
         if (javaType === 'int' || javaType === 'long' || javaType === 'short' || javaType === 'byte') {
             return '0';
         }
@@ -213,14 +905,17 @@ export class JavaCodeGenerator {
         if (javaType === 'char') {
             return "'\\0'";
         }
-        if (javaType === 'String') {
-            return '""';
-        }
-        if (javaType.startsWith('List<')) {
-            return 'new ArrayList<>()';
-        }
-        if (javaType.startsWith('Map<')) {
-            return 'new HashMap<>()';
+        if (options?.emptyCollections) {
+            if (javaType.startsWith('List<')) {
+                return 'new ArrayList<>()';
+            }
+            if (javaType.startsWith('Map<')) {
+                return 'new HashMap<>()';
+            }
+            // Generic array creation is not allowed
+            if (javaType.endsWith('[]') && !javaType.includes('<')) {
+                return `new ${javaType.slice(0, -2)}[0]`;
+            }
         }
         return 'null';
     }
@@ -243,7 +938,7 @@ export class JavaCodeGenerator {
             const returnType = goFunc.returnTypes[i];
             if (returnType.name === 'error') continue;
 
-            const javaType = GoFunctionParser.convertGoTypeToJava(returnType);
+            const javaType = this.toJavaType(returnType, options);
             const fieldName = `value${i + 1}`;
             lines.push(`    private ${javaType} ${fieldName};`);
         }
@@ -254,7 +949,7 @@ export class JavaCodeGenerator {
             const returnType = goFunc.returnTypes[i];
             if (returnType.name === 'error') continue;
 
-            const javaType = GoFunctionParser.convertGoTypeToJava(returnType);
+            const javaType = this.toJavaType(returnType, options);
             const fieldName = `value${i + 1}`;
             const methodName = `getValue${i + 1}`;
 
@@ -281,21 +976,27 @@ export class JavaCodeGenerator {
     static generateFullJavaClass(goFunc: GoFunction, className: string = 'GoConverter', options?: JavaGenerationOptions): string {
         const lines: string[] = [];
 
-        lines.push('import java.util.*;');
-        lines.push('');
-        
         if (options?.addLearningHints) {
             lines.push('/**');
             lines.push(' * Go to Java Conversion Notes:');
             const includeResultClass = options.includeResultClass ?? true;
-            if (goFunc.returnTypes.length > 1 && includeResultClass) {
-                lines.push(' * - Go supports multiple return values; Java uses a Result class to achieve this');
+            if (this.usesResultRecord(goFunc, options)) {
+                lines.push(` * - Go's (value, error) results are returned together as a ${this.getResultRecordType(goFunc, options)} record`);
+            } else {
+                if (goFunc.returnTypes.length > 1 && includeResultClass) {
+                    lines.push(' * - Go supports multiple return values; Java uses a Result class to achieve this');
+                }
+                if (goFunc.hasErrorReturn) {
+                    lines.push(` * - Go's error type is mapped to Java exceptions (throws ${this.getExceptionClass(options)})`);
+                }
             }
-            if (goFunc.hasErrorReturn) {
-                lines.push(' * - Go\'s error type is mapped to Java exceptions (throws Exception)');
+            if (goFunc.parameters.some(p => GoFunctionParser.isByteSlice(p.type))) {
+                lines.push(' * - Go []byte is mapped to Java byte[]');
             }
-            if (goFunc.parameters.some(p => p.type.isSlice)) {
-                lines.push(' * - Go slices are mapped to Java List<T> (dynamic arrays)');
+            if (goFunc.parameters.some(p => p.type.isSlice && !p.type.isVariadic && !GoFunctionParser.isByteSlice(p.type))) {
+                lines.push(options.sliceStrategy === 'array'
+                    ? ' * - Go slices are mapped to Java arrays T[] (fixed size, unlike growable Go slices)'
+                    : ' * - Go slices are mapped to Java List<T> (dynamic arrays)');
             }
             if (goFunc.parameters.some(p => p.type.isMap)) {
                 lines.push(' * - Go maps are mapped to Java Map<K,V>');
@@ -325,20 +1026,29 @@ export class JavaCodeGenerator {
             addComments: true,
             handleErrorsAsExceptions: true,
             addLearningHints: options?.addLearningHints,
-            includeResultClass: options?.includeResultClass
+            includeResultClass: options?.includeResultClass,
+            exceptionClass: options?.exceptionClass,
+            sliceStrategy: options?.sliceStrategy,
+            errorResultRecords: options?.errorResultRecords,
+            sharedResultRecord: options?.sharedResultRecord
         };
 
         lines.push(this.generateJavaMethod(goFunc, methodOptions));
         lines.push('');
 
-        const includeResultClass = methodOptions.includeResultClass ?? true;
-        const resultClass = includeResultClass ? this.generateResultClass(goFunc, methodOptions) : '';
-        if (includeResultClass && resultClass) {
-            lines.push(resultClass);
+        if (this.usesResultRecord(goFunc, methodOptions)) {
+            lines.push('    ' + (this.generateResultRecord(goFunc, methodOptions) || this.SHARED_RESULT_RECORD));
+        } else {
+            const includeResultClass = methodOptions.includeResultClass ?? true;
+            const resultClass = includeResultClass ? this.generateResultClass(goFunc, methodOptions) : '';
+            if (includeResultClass && resultClass) {
+                lines.push(resultClass);
+            }
         }
 
         lines.push('}');
 
-        return lines.join('\n');
+        const imports = collectJavaImports(lines.join('\n'), false, options?.nullability).map(imp => `import ${imp};`);
+        return [...imports, ...(imports.length > 0 ? [''] : []), ...lines].join('\n');
     }
 }
@@ -0,0 +1,131 @@
+import { STDLIB_TYPE_MAPPINGS } from './conversionContext';
+import { GoImport } from './goFileParser';
+
+/**
+ * Library types the generated Java can refer to by simple name, with their
+ * qualified names. Anything in java.lang (String, Integer, Runnable, ...) needs
+ * no import and is left out.
+ */
+const LIBRARY_TYPES: Map<string, string> = new Map([
+    ...['List', 'ArrayList', 'Map', 'HashMap', 'LinkedHashMap', 'Set', 'HashSet', 'Arrays', 'Objects',
+        'Collections', 'Iterator', 'Optional', 'Scanner'].map(name => [name, `java.util.${name}`] as [string, string]),
+    ['StandardCharsets', 'java.nio.charset.StandardCharsets'],
+    ...['ExecutorService', 'Executors', 'Callable', 'BlockingQueue', 'SynchronousQueue', 'ArrayBlockingQueue']
+        .map(name => [name, `java.util.concurrent.${name}`] as [string, string]),
+    ...['Supplier', 'Consumer', 'BiConsumer', 'Function', 'BiFunction', 'Predicate', 'BiPredicate']
+        .map(name => [name, `java.util.function.${name}`] as [string, string]),
+    ...['JsonProperty', 'JsonIgnore', 'JsonInclude']
+        .map(name => [name, `com.fasterxml.jackson.annotation.${name}`] as [string, string]),
+    ...[...STDLIB_TYPE_MAPPINGS.values()]
+        .filter(mapping => mapping.javaImport)
+        .map(mapping => [mapping.javaType, mapping.javaImport!] as [string, string])
+]);
+
+/**
+ * Annotations of each nullability flavor: the one for values that may be null, the one
+ * for values that may not, and their package
+ */
+export const NULLABILITY_ANNOTATIONS = {
+    jsr305: { nullable: 'Nullable', nonNull: 'Nonnull', packageName: 'javax.annotation' },
+    jetbrains: { nullable: 'Nullable', nonNull: 'NotNull', packageName: 'org.jetbrains.annotations' }
+};
+
+/** Nullability annotations on fields, parameters and return types, or none */
+export type Nullability = keyof typeof NULLABILITY_ANNOTATIONS | 'none';
+
+/**
+ * JUnit 5 annotations and the types of parameterized test sources, only looked up in
+ * test classes: names as plain as `Test` and `Stream` would clash with ordinary types
+ */
+const JUNIT_TYPES: Map<string, string> = new Map([
+    ['Test', 'org.junit.jupiter.api.Test'],
+    ['ParameterizedTest', 'org.junit.jupiter.params.ParameterizedTest'],
+    ['MethodSource', 'org.junit.jupiter.params.provider.MethodSource'],
+    ['Arguments', 'org.junit.jupiter.params.provider.Arguments'],
+    ['Stream', 'java.util.stream.Stream']
+]);
+
+/**
+ * Imports needed by a generated Java compilation unit, deduplicated and sorted.
+ * Runs over the finished source, so it reflects exactly the library types the code
+ * uses; names inside comments and string literals, qualified names (`Map.Entry`
+ * needs only `Map`) and types the unit declares itself are not imported.
+ * @param junit Also import the JUnit types a converted test class uses
+ * @param nullability Flavor of the nullability annotations the code uses
+ */
+export function collectJavaImports(javaSource: string, junit = false, nullability: Nullability = 'none'): string[] {
+    const code = stripCommentsAndLiterals(javaSource);
+    const declared = new Set([...code.matchAll(/\b(?:class|interface|enum|record)\s+([A-Za-z_]\w*)/g)].map(m => m[1]));
+    const flavor = nullability === 'none' ? undefined : NULLABILITY_ANNOTATIONS[nullability];
+    const imports = new Set<string>();
+    for (const match of code.matchAll(/(?<![.\w])([A-Z]\w*)\b/g)) {
+        const annotation = flavor && [flavor.nullable, flavor.nonNull].includes(match[1]) && code[match.index! - 1] === '@'
+            ? `${flavor.packageName}.${match[1]}` : undefined;
+        const qualified = annotation || LIBRARY_TYPES.get(match[1]) || (junit ? JUNIT_TYPES.get(match[1]) : undefined);
+        if (qualified && !declared.has(match[1])) {
+            imports.add(qualified);
+        }
+    }
+    return [...imports].sort();
+}
+
+/**
+ * Whether generated code calls JUnit's assertions (`fail`, `assertEquals`, ...) unqualified
+ */
+export function usesJUnitAssertions(javaSource: string): boolean {
+    return /(?<![.\w])(fail|assert[A-Z]\w*)\(/.test(stripCommentsAndLiterals(javaSource));
+}
+
+/**
+ * Go packages the generated Java still refers to, by the name the Go source uses:
+ * selectors with no translation are kept as written (`strings.ToUpper(s)`). An import
+ * whose every use was rewritten (`errors.New` → `new Exception`) is not among them.
+ */
+export function remainingGoPackages(javaSource: string, goImports: GoImport[]): string[] {
+    const code = stripCommentsAndLiterals(javaSource);
+    const referenced = new Set([...code.matchAll(/(?<![.\w])([a-z]\w*)\s*\./g)].map(m => m[1]));
+    const names = goImports
+        .map(imp => imp.alias || imp.path.split('/').pop()!)
+        .filter(name => name !== '_' && name !== '.' && referenced.has(name));
+    return [...new Set(names)].sort();
+}
+
+/**
+ * Blank out comments and string and char literals, keeping line structure: a text block
+ * becomes `""` followed by the lines it spans, left blank
+ */
+export function stripCommentsAndLiterals(source: string): string {
+    let result = '';
+    for (let i = 0; i < source.length; i++) {
+        const ch = source[i];
+        if (source.startsWith('"""', i)) {
+            let end = i + 3;
+            while (end < source.length && !source.startsWith('"""', end)) {
+                end += source[end] === '\\' ? 2 : 1;
+            }
+            const block = source.slice(i, end + 3);
+            result += '""' + block.slice(2).replace(/[^\n]/g, ' ');
+            i += block.length - 1;
+        } else if (ch === '/' && source[i + 1] === '/') {
+            while (i < source.length && source[i] !== '\n') i++;
+            result += '\n';
+        } else if (ch === '/' && source[i + 1] === '*') {
+            const end = source.indexOf('*/', i + 2);
+            const comment = source.slice(i, end < 0 ? source.length : end + 2);
+            result += comment.replace(/[^\n]/g, ' ');
+            i += comment.length - 1;
+        } else if (ch === '"' || ch === '\'') {
+            i++;
+            while (i < source.length && source[i] !== ch && source[i] !== '\n') {
+                if (source[i] === '\\') i++;
+                i++;
+            }
+            result += ch + ch;
+            // An unclosed literal ends at the line break, which is kept
+            if (source[i] === '\n') i--;
+        } else {
+            result += ch;
+        }
+    }
+    return result;
+}
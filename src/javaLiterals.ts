@@ -0,0 +1,187 @@
+/**
+ * Java spellings of Go's numeric, rune and string literals. Java shares most of Go's
+ * literal syntax (hex, binary, underscores between digits, exponents, hex floats), so a
+ * literal is kept as written wherever Java reads it the same, and only what Java lacks
+ * or reads differently is rewritten: `0o17` is `017`, `\x41` is spelled `A`.
+ */
+
+const INT_MAX = 2147483647n;
+const LONG_MAX = 9223372036854775807n;
+const UINT64_MAX = 18446744073709551615n;
+
+/** Go escapes of one character, by the letter after the backslash */
+const SIMPLE_ESCAPES: { [letter: string]: number } = {
+    'a': 0x07, 'b': 0x08, 'f': 0x0C, 'n': 0x0A, 'r': 0x0D, 't': 0x09, 'v': 0x0B, '\\': 0x5C, '\'': 0x27, '"': 0x22
+};
+
+/**
+ * Java escapes for characters that are written escaped in any literal. Java turns `\uXXXX`
+ * into the character before reading the literal, so these can only be spelled this way.
+ */
+const JAVA_ESCAPES: { [codePoint: number]: string } = {
+    0x08: '\\b', 0x09: '\\t', 0x0A: '\\n', 0x0C: '\\f', 0x0D: '\\r', 0x5C: '\\\\'
+};
+
+/** One escape sequence or a run of other characters of a Go literal */
+const LITERAL_PART = /\\(?:x[\da-fA-F]{2}|[0-7]{3}|u[\da-fA-F]{4}|U[\da-fA-F]{8}|[\s\S])|[^\\]+/g;
+
+/**
+ * Value of a Go integer literal: decimal, `0x`, `0b`, `0o` or legacy `0` octal, with underscores
+ */
+export function goIntegerValue(text: string): bigint {
+    const digits = text.replace(/_/g, '');
+    return /^0[0-7]+$/.test(digits) ? BigInt(`0o${digits.slice(1)}`) : BigInt(digits);
+}
+
+/**
+ * Java spelling of a Go integer literal, or undefined when its value does not fit 64 bits.
+ * Values past Java's int take an `L`; a hex literal above 0x7FFFFFFF would be a negative int.
+ * Values past Long.MAX_VALUE, which only a uint64 holds, become the hex literal of the same bits.
+ */
+export function javaIntegerLiteral(text: string): string | undefined {
+    const value = goIntegerValue(text);
+    if (value > UINT64_MAX) {
+        return undefined;
+    }
+    if (value > LONG_MAX) {
+        return `0x${value.toString(16).toUpperCase()}L`;
+    }
+    // Java has no 0o prefix and no underscore right after a prefix
+    const java = text.replace(/^0([xXbBoO])_/, '0$1').replace(/^0[oO]/, '0');
+    return value > INT_MAX ? `${java}L` : java;
+}
+
+/**
+ * Java spelling of a Go floating-point literal, a double like Go's untyped float constants
+ */
+export function javaFloatLiteral(text: string): string {
+    return text.replace(/^0([xX])_/, '0$1');
+}
+
+/**
+ * Value of a Go floating-point literal, decimal or hex (`0x1p-2`)
+ */
+export function goFloatValue(text: string): number {
+    const digits = text.replace(/_/g, '');
+    const hex = /^0[xX]([\da-fA-F]*)(?:\.([\da-fA-F]*))?[pP]([+-]?\d+)$/.exec(digits);
+    if (!hex) {
+        return Number(digits);
+    }
+    const fraction = hex[2] || '';
+    const mantissa = parseInt(`${hex[1]}${fraction}` || '0', 16) / Math.pow(16, fraction.length);
+    return mantissa * Math.pow(2, parseInt(hex[3], 10));
+}
+
+/**
+ * Code point of a Go rune literal (`'A'`, `'\n'`, `'\x41'`, `'é'`, `'😀'`)
+ */
+export function goRuneValue(text: string): number {
+    const body = text.slice(1, -1);
+    return body.startsWith('\\') ? parseEscape(body).value : body.codePointAt(0)!;
+}
+
+/**
+ * Java char literal for a Go rune literal, or undefined for a rune beyond the 16 bits of a
+ * char (`'😀'`), which Java can only hold as an int
+ */
+export function javaCharLiteral(text: string): string | undefined {
+    const body = text.slice(1, -1);
+    if (!body.startsWith('\\')) {
+        return body.codePointAt(0)! > 0xFFFF ? undefined : text;
+    }
+    const escape = parseEscape(body);
+    if (escape.value > 0xFFFF) {
+        return undefined;
+    }
+    return `'${javaEscape(body, escape.value, '\'')}'`;
+}
+
+/**
+ * Java string literal for an interpreted Go string literal, or undefined when its byte
+ * escapes (`\xff`) are not UTF-8, as a Java String holds characters and not bytes
+ */
+export function javaStringLiteral(text: string): string | undefined {
+    const parts = text.slice(1, -1).match(LITERAL_PART) || [];
+    const java: string[] = [];
+    // Byte escapes above 0x7F are decoded together, as the UTF-8 they spell (`\xc3\xa9` is é)
+    let bytes: number[] = [];
+    const flush = (): boolean => {
+        if (bytes.length === 0) {
+            return true;
+        }
+        let decoded: string;
+        try {
+            decoded = new TextDecoder('utf-8', { fatal: true }).decode(Uint8Array.from(bytes));
+        } catch {
+            return false;
+        }
+        bytes = [];
+        for (const char of decoded) {
+            java.push(unicodeEscape(char.codePointAt(0)!));
+        }
+        return true;
+    };
+    for (const part of parts) {
+        const escape = part.startsWith('\\') ? parseEscape(part) : undefined;
+        if (escape?.isByte && escape.value > 0x7F) {
+            bytes.push(escape.value);
+            continue;
+        }
+        if (!flush()) {
+            return undefined;
+        }
+        java.push(escape ? javaEscape(part, escape.value, '"') : part);
+    }
+    return flush() ? `"${java.join('')}"` : undefined;
+}
+
+/**
+ * The code point of a Go escape sequence, and whether it is a byte (`\xff`, `\377`)
+ */
+function parseEscape(escape: string): { value: number; isByte: boolean } {
+    const letter = escape.charAt(1);
+    if (letter === 'x' || letter === 'u' || letter === 'U') {
+        return { value: parseInt(escape.slice(2), 16), isByte: letter === 'x' };
+    }
+    if (/[0-7]/.test(letter)) {
+        return { value: parseInt(escape.slice(1), 8), isByte: true };
+    }
+    return { value: SIMPLE_ESCAPES[letter] ?? escape.codePointAt(1)!, isByte: false };
+}
+
+/**
+ * Java for a Go escape in a literal quoted by quote: kept where Java reads it the same,
+ * otherwise the character itself or the escape Java has for it
+ */
+function javaEscape(escape: string, value: number, quote: '"' | '\''): string {
+    const letter = escape.charAt(1);
+    if ('bfnrt\\'.includes(letter) || letter === quote) {
+        return escape;
+    }
+    if (/[0-7]/.test(letter) && value <= 0x7F) {
+        return escape;
+    }
+    if (value === quote.charCodeAt(0)) {
+        return `\\${quote}`;
+    }
+    if (letter === 'u' && !(value in JAVA_ESCAPES) && value !== 0x22 && value !== 0x27) {
+        return escape;
+    }
+    return value >= 0x20 && value < 0x7F && value !== 0x5C ? String.fromCharCode(value) : unicodeEscape(value);
+}
+
+/**
+ * `\uXXXX` for a code point, a surrogate pair beyond 16 bits, or Java's own escape where
+ * a `\u` escape would end the literal or the line
+ */
+function unicodeEscape(value: number): string {
+    if (value in JAVA_ESCAPES) {
+        return JAVA_ESCAPES[value];
+    }
+    if (value === 0x22 || value === 0x27) {
+        return `\\${String.fromCharCode(value)}`;
+    }
+    return String.fromCodePoint(value).split('')
+        .map(unit => `\\u${unit.charCodeAt(0).toString(16).toUpperCase().padStart(4, '0')}`)
+        .join('');
+}
@@ -2,11 +2,14 @@ import * as vscode from 'vscode';
 import * as path from 'path';
 import { TextDecoder } from 'util';
 import { GoFileParser } from './goFileParser';
+import { IntType, SliceStrategy } from './goParser';
 import { JavaFileGenerator, JavaFileGenerationOptions } from './javaFileGenerator';
+import { ConversionDiagnostic, DefinedTypeStrategy, EmbeddingStrategy } from './javaGenerator';
+import { Nullability } from './javaImports';
 import * as TreeSitterGoParser from './treeSitterGoParser';
 import { TypeEnricher } from './typeEnricher';
 import { TypeDependencyResolver, createTypeDependencyResolver } from './typeDependencyResolver';
-import { createConversionContext, ConversionContext } from './conversionContext';
+import { createConversionContext, ConversionContext, StdlibCallMappings } from './conversionContext';
 
 /**
  * Provider for Java preview content
@@ -18,13 +21,18 @@ export class JavaPreviewProvider implements vscode.TextDocumentContentProvider {
     private _onDidChange = new vscode.EventEmitter<vscode.Uri>();
     readonly onDidChange = this._onDidChange.event;
 
+    /**
+     * @param diagnostics Receives, per Go file, the constructs its preview could not convert cleanly
+     */
+    constructor(private readonly diagnostics?: vscode.DiagnosticCollection) {}
+
     /**
      * Provide the Java content for a given preview URI
      */
     async provideTextDocumentContent(uri: vscode.Uri): Promise<string> {
+        // Decode the source file URI from the preview URI
+        const sourceUri = this.decodeSourceUri(uri);
         try {
-            // Decode the source file URI from the preview URI
-            const sourceUri = this.decodeSourceUri(uri);
 
             // Read the Go file content
             const goContent = await this.readGoFile(sourceUri);
@@ -75,16 +83,49 @@ export class JavaPreviewProvider implements vscode.TextDocumentContentProvider {
 
             // Get configuration options
             const options = this.getGenerationOptions(sourceUri);
+            const diagnostics: ConversionDiagnostic[] = [];
 
             // Generate Java code with context
-            const javaCode = JavaFileGenerator.generateJavaFile(goFile, options, context);
+            const javaCode = JavaFileGenerator.generateJavaFile(goFile, { ...options, diagnostics }, context);
 
+            this.showDiagnostics(sourceDocument, config.get('preview.showDiagnostics', true) ? diagnostics : []);
             return javaCode;
         } catch (error) {
+            this.diagnostics?.delete(sourceUri);
             return this.generateErrorContent(error);
         }
     }
 
+    /**
+     * Mark the Go source where the preview left a TODO (warnings) or behaves differently
+     * (information), from the start of the construct to the end of its line
+     */
+    private showDiagnostics(document: vscode.TextDocument, diagnostics: ConversionDiagnostic[]): void {
+        if (!this.diagnostics) {
+            return;
+        }
+        this.diagnostics.set(document.uri, diagnostics.map(diagnostic => {
+            const line = Math.min(diagnostic.pos?.line ?? 0, document.lineCount - 1);
+            const end = document.lineAt(line).range.end;
+            const start = diagnostic.pos ? new vscode.Position(line, Math.min(diagnostic.pos.character, end.character)) : end.with({ character: 0 });
+            const entry = new vscode.Diagnostic(
+                new vscode.Range(start, end),
+                diagnostic.message,
+                diagnostic.severity === 'unsupported' ? vscode.DiagnosticSeverity.Warning : vscode.DiagnosticSeverity.Information
+            );
+            entry.source = 'Go to Java';
+            entry.code = diagnostic.construct;
+            return entry;
+        }));
+    }
+
+    /**
+     * Drop the diagnostics of a Go file whose preview is gone
+     */
+    clearDiagnostics(sourceUri: vscode.Uri): void {
+        this.diagnostics?.delete(sourceUri);
+    }
+
     /**
      * Update the preview for a given URI
      */
@@ -135,8 +176,8 @@ export class JavaPreviewProvider implements vscode.TextDocumentContentProvider {
         const config = vscode.workspace.getConfiguration('goToJava');
 
         // Derive class name from file name
-        const fileName = path.basename(sourceUri.fsPath, '.go');
-        const className = fileName.charAt(0).toUpperCase() + fileName.slice(1);
+        const className = JavaFileGenerator.classNameForFile(path.basename(sourceUri.fsPath));
+        const javaPackage = config.get<string>('javaPackage', '').trim();
 
         return {
             isStatic: true,
@@ -144,9 +185,38 @@ export class JavaPreviewProvider implements vscode.TextDocumentContentProvider {
             handleErrorsAsExceptions: true,
             includeConstructors: true,
             includeGettersSetters: config.get('preview.includeGettersSetters', true),
+            includeJsonAnnotations: config.get('jsonAnnotations', true),
+            javaBeans: config.get('javaBeans', false),
+            sliceStrategy: config.get<SliceStrategy>('sliceStrategy', 'list'),
+            intType: config.get<IntType>('intType', 'int'),
+            errorResultRecords: config.get('errorResultRecords', false),
+            sharedResultRecord: config.get('sharedResultRecord', false),
+            emptyCollections: config.get('emptyCollections', false),
+            nilMatchesEmpty: config.get('nilMatchesEmpty', false),
+            experimentalConcurrency: config.get('experimentalConcurrency', false),
+            embedding: config.get<EmbeddingStrategy>('embedding', 'composition'),
+            definedTypes: config.get<DefinedTypeStrategy>('definedTypes', 'underlying'),
+            nullability: config.get<Nullability>('nullability', 'none'),
+            enums: config.get('enums', false),
+            stringEnums: config.get('stringEnums', false),
+            junit: config.get('junit', false),
+            javaNaming: config.get('javaNaming', false),
+            inferImplements: config.get('inferImplements', false),
+            durationMillis: config.get('durationMillis', false),
+            builder: config.get('builder', false),
+            builderMinFields: config.get<number>('builderMinFields', 4),
+            annotateOrigin: config.get('annotateOrigin', false),
+            sourceFile: path.basename(sourceUri.fsPath),
+            stdlibCalls: config.get<StdlibCallMappings>('stdlibCalls', {}),
             includeComments: true,
             className: className,
-            addLearningHints: true
+            packageName: javaPackage || undefined,
+            topLevelType: config.get('topLevelType', false),
+            staticFactories: config.get('staticFactories', false),
+            valueMethods: config.get('valueMethods', false),
+            javaVersion: config.get<number>('javaVersion', 11),
+            addLearningHints: true,
+            exceptionClass: config.get<string>('exceptionClass', 'Exception')
         };
     }
 
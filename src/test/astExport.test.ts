@@ -0,0 +1,113 @@
+import * as assert from 'assert/strict';
+import * as fs from 'fs';
+import * as os from 'os';
+import * as path from 'path';
+import { test } from 'node:test';
+import type { TestContext } from 'node:test';
+import { exportAst } from '../api';
+import { AST_SCHEMA_VERSION, AstDocument, AstNode } from '../astExport';
+import { main } from '../cli';
+
+// External tools read these documents: a change to what these tests pin raises AST_SCHEMA_VERSION
+
+const SHAPES = `package shapes
+
+const Max = 10
+
+type Point struct {
+	X int
+}
+
+func Sum(xs []int) int {
+	total := 0
+	for _, x := range xs {
+		total += x
+	}
+	return total
+}
+`;
+
+const INT = { name: 'int', isPointer: false, isSlice: false, isMap: false, isVariadic: false, java: 'int' };
+const INT_SLICE = { ...INT, isSlice: true, elementType: INT, java: 'List<Integer>' };
+
+/** The document convert-dir --emit-ast json prints for one file in a directory */
+async function emitAst(t: TestContext, goSource: string): Promise<AstDocument> {
+    const root = fs.mkdtempSync(path.join(os.tmpdir(), 'go-to-java-ast-'));
+    t.after(() => fs.rmSync(root, { recursive: true, force: true }));
+    fs.writeFileSync(path.join(root, 'shapes.go'), goSource);
+    const printed: string[] = [];
+    t.mock.method(console, 'log', (line: string) => printed.push(line));
+    const code = await main(['convert-dir', root, '--parser', 'regex', '--emit-ast', 'json']);
+    t.mock.restoreAll();
+    assert.equal(code, 0);
+    return JSON.parse(printed.join('\n'));
+}
+
+function declarations(document: AstDocument): AstNode {
+    return document.packages[0].files[0].file;
+}
+
+test('the schema version is 1', () => {
+    assert.equal(AST_SCHEMA_VERSION, 1);
+});
+
+test('a document lists packages, their files and the diagnostics of each', async t => {
+    const document = await emitAst(t, SHAPES);
+    assert.equal(document.schemaVersion, AST_SCHEMA_VERSION);
+    assert.deepEqual(Object.keys(document), ['schemaVersion', 'packages']);
+    assert.equal(document.packages.length, 1);
+    const [pkg] = document.packages;
+    assert.deepEqual({ name: pkg.name, dir: pkg.dir }, { name: 'shapes', dir: '' });
+    assert.deepEqual(pkg.files.map(f => Object.keys(f)), [['path', 'file', 'diagnostics']]);
+    assert.equal(pkg.files[0].path, 'shapes.go');
+    assert.deepEqual(pkg.files[0].diagnostics, []);
+    assert.deepEqual(Object.keys(declarations(document)),
+        ['packageName', 'imports', 'structs', 'interfaces', 'namedTypes', 'functions', 'variables', 'constants']);
+});
+
+test('declarations carry their Java names and types', async t => {
+    const file = declarations(await emitAst(t, SHAPES));
+    const [point] = file.structs as AstNode[];
+    assert.deepEqual((point.fields as AstNode[]).map(f => [f.name, f.javaName, f.type]), [['X', 'X', INT]]);
+
+    const [sum] = file.functions as AstNode[];
+    assert.equal(sum.javaName, 'Sum');
+    assert.deepEqual(sum.parameters, [{ name: 'xs', type: INT_SLICE }]);
+    assert.deepEqual(sum.returnTypes, [INT]);
+
+    const [max] = file.constants as AstNode[];
+    assert.equal(max.javaName, 'Max');
+    assert.deepEqual(max.valueExpr, { kind: 'BasicLit', litKind: 'INT', value: '10', pos: { line: 0, character: 0 }, goType: INT });
+});
+
+test('function bodies are syntax trees with the Go type of each expression', async t => {
+    const [sum] = declarations(await emitAst(t, SHAPES)).functions as AstNode[];
+    const body = sum.body as { source: string; stmts: AstNode[] };
+    assert.equal(body.source, SHAPES.slice(SHAPES.indexOf('{', SHAPES.indexOf('func Sum')) + 1, SHAPES.lastIndexOf('}')));
+    assert.deepEqual(body.stmts.map(s => s.kind), ['AssignStmt', 'RangeStmt', 'ReturnStmt']);
+    assert.deepEqual(body.stmts[0], {
+        kind: 'AssignStmt',
+        lhs: [{ kind: 'Ident', name: 'total', pos: { line: 9, character: 1 } }],
+        tok: ':=',
+        rhs: [{ kind: 'BasicLit', litKind: 'INT', value: '0', pos: { line: 9, character: 10 }, goType: INT }],
+        pos: { line: 9, character: 1 },
+        span: [2, 12]
+    });
+    assert.deepEqual((body.stmts[1].x as AstNode).goType, INT_SLICE);
+});
+
+test('exportAst documents one file as a package in the current directory', async t => {
+    try {
+        require.resolve('tree-sitter-go/tree-sitter-go.wasm');
+    } catch {
+        t.skip('the tree-sitter-go grammar is not installed');
+        return;
+    }
+    const document = await exportAst(SHAPES);
+    assert.equal(document.schemaVersion, AST_SCHEMA_VERSION);
+    assert.deepEqual(document.packages.map(p => ({ name: p.name, dir: p.dir, files: p.files.length })), [{ name: 'shapes', dir: '', files: 1 }]);
+    const [file] = document.packages[0].files;
+    assert.equal(file.path, undefined);
+    const [sum] = file.file.functions as AstNode[];
+    assert.deepEqual([sum.javaName, sum.parameters, sum.returnTypes], ['Sum', [{ name: 'xs', type: INT_SLICE }], [INT]]);
+});
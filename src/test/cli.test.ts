@@ -0,0 +1,235 @@
+import * as assert from 'assert/strict';
+import * as fs from 'fs';
+import * as os from 'os';
+import * as path from 'path';
+import { test } from 'node:test';
+import type { TestContext } from 'node:test';
+import { main } from '../cli';
+
+/**
+ * Write Go sources, given by their path under the module directory, into a new temporary
+ * directory, returning it
+ */
+function goTree(sources: { [relativePath: string]: string }): string {
+    const root = fs.mkdtempSync(path.join(os.tmpdir(), 'go-to-java-cli-'));
+    for (const [relativePath, content] of Object.entries(sources)) {
+        const file = path.join(root, 'mod', relativePath);
+        fs.mkdirSync(path.dirname(file), { recursive: true });
+        fs.writeFileSync(file, content);
+    }
+    return root;
+}
+
+/**
+ * Run convert-dir on the module of a tree made by goTree, writing into its out directory
+ * with the declaration parser, and return the exit code with what was printed
+ */
+async function convertDir(t: TestContext, root: string, ...args: string[]): Promise<{ code: number; printed: string[] }> {
+    const printed: string[] = [];
+    t.mock.method(console, 'log', (line: string) => printed.push(line));
+    const code = await main(['convert-dir', path.join(root, 'mod'), '--out', path.join(root, 'out'), '--parser', 'regex', ...args]);
+    t.mock.restoreAll();
+    return { code, printed };
+}
+
+/** Java files written under the out directory of a tree, by their path relative to it */
+function javaFiles(root: string): string[] {
+    const out = path.join(root, 'out');
+    return fs.readdirSync(out, { recursive: true, encoding: 'utf8' })
+        .filter(file => file.endsWith('.java'))
+        .map(file => file.split(path.sep).join('/'))
+        .sort();
+}
+
+function readOutput(root: string, file: string): string {
+    return fs.readFileSync(path.join(root, 'out', file), 'utf8');
+}
+
+const COLOR = `package shapes
+
+type Color int
+
+const (
+	Red Color = iota
+	Green
+	Blue
+)
+
+func (c Color) String() string {
+	switch c {
+	case Red:
+		return "red"
+	case Green:
+		return "green"
+	}
+	return "blue"
+}
+
+func Favorite() Color {
+	return Green
+}
+`;
+
+test('an enum is declared with its constants in the package class, holding its methods', async t => {
+    const root = goTree({ 'shapes/color.go': COLOR });
+    t.after(() => fs.rmSync(root, { recursive: true, force: true }));
+    assert.equal((await convertDir(t, root)).code, 0);
+
+    // The file class may not share the enum's name
+    assert.deepEqual(javaFiles(root), ['mod/shapes/ColorFile.java', 'mod/shapes/ShapesPackage.java']);
+    const packageClass = readOutput(root, 'mod/shapes/ShapesPackage.java');
+    assert.match(packageClass, /public enum Color \{\n {8}Red,\n {8}Green,\n {8}Blue;\n/);
+    assert.match(packageClass, /public String toString\(\) \{\n {12}return String\(\);/);
+    assert.match(packageClass, /public String String\(\) \{\n {12}switch \(this\) \{/);
+    assert.doesNotMatch(packageClass, /static final Color/);
+
+    const fileClass = readOutput(root, 'mod/shapes/ColorFile.java');
+    assert.match(fileClass, /import static mod\.shapes\.ShapesPackage\.\*;/);
+    assert.match(fileClass, /public static Color Favorite\(\) \{\n {8}return Color\.Green;/);
+    assert.doesNotMatch(fileClass, /String\(\)/);
+});
+
+test('an enum whose String() is declared in another file of the package still holds it', async t => {
+    const root = goTree({
+        'days/weekday.go': `package days
+
+type Weekday int
+
+func (d Weekday) String() string {
+	return [...]string{"Sun", "Mon"}[d]
+}
+`,
+        'days/consts.go': `package days
+
+const (
+	Sunday Weekday = iota
+	Monday
+)
+`
+    });
+    t.after(() => fs.rmSync(root, { recursive: true, force: true }));
+    assert.equal((await convertDir(t, root, '--enums')).code, 0);
+
+    const packageClass = readOutput(root, 'mod/days/DaysPackage.java');
+    assert.match(packageClass, /public enum Weekday \{\n {8}Sunday\("Sun"\),\n {8}Monday\("Mon"\);/);
+    assert.doesNotMatch(readOutput(root, 'mod/days/WeekdayFile.java'), /String\(\)/);
+});
+
+const APP = {
+    'main.go': `package main
+
+import "fmt"
+
+func main() {
+	fmt.Println(Greeting)
+}
+`,
+    'config.go': `package main
+
+const Greeting = "hello"
+
+var counter int
+
+func init() {
+	counter = 1
+}
+`,
+    'store/user.go': `package store
+
+type User struct {
+	Name string
+}
+
+type role struct {
+	id int
+}
+
+func Lookup(name string) User {
+	return User{Name: name}
+}
+`
+};
+
+test('the mirror layout follows the input tree under --java-package', async t => {
+    const root = goTree(APP);
+    t.after(() => fs.rmSync(root, { recursive: true, force: true }));
+    const { code, printed } = await convertDir(t, root, '--java-package', 'com.example');
+    assert.equal(code, 0);
+    assert.deepEqual(printed, [`Converted 3 of 3 Go files into 4 Java files in ${path.join(root, 'out')}`]);
+    assert.deepEqual(javaFiles(root), [
+        'com/example/Config.java',
+        'com/example/Main.java',
+        'com/example/MainPackage.java',
+        'com/example/store/UserFile.java'
+    ]);
+    const userFile = readOutput(root, 'com/example/store/UserFile.java');
+    assert.match(userFile, /^package com\.example\.store;\n/);
+    assert.match(userFile, /public static class User \{/);
+    assert.match(userFile, /public static class role \{/);
+});
+
+test('the maven layout gives each exported type a file of its own', async t => {
+    const root = goTree(APP);
+    t.after(() => fs.rmSync(root, { recursive: true, force: true }));
+    assert.equal((await convertDir(t, root, '--layout', 'maven')).code, 0);
+    assert.deepEqual(javaFiles(root), [
+        'src/main/java/mod/Config.java',
+        'src/main/java/mod/Main.java',
+        'src/main/java/mod/MainPackage.java',
+        'src/main/java/mod/store/User.java',
+        'src/main/java/mod/store/UserFile.java'
+    ]);
+    assert.match(readOutput(root, 'src/main/java/mod/store/User.java'), /^public class User \{/m);
+    // Unexported types stay nested in the class of their file, with its functions
+    const userFile = readOutput(root, 'src/main/java/mod/store/UserFile.java');
+    assert.match(userFile, /public static class role \{/);
+    assert.match(userFile, /public static User Lookup\(String name\) \{/);
+});
+
+test('the package class holds the constants, vars and inits of every file', async t => {
+    const root = goTree(APP);
+    t.after(() => fs.rmSync(root, { recursive: true, force: true }));
+    assert.equal((await convertDir(t, root)).code, 0);
+    const packageClass = readOutput(root, 'mod/MainPackage.java');
+    assert.match(packageClass, /public static final String Greeting = "hello";\n {4}static int counter = 0;\n\n {4}static \{\n {8}counter = 1;\n {4}\}/);
+    const main = readOutput(root, 'mod/Main.java');
+    assert.match(main, /import static mod\.Config\.\*;\nimport static mod\.MainPackage\.\*;/);
+    assert.match(main, /System\.out\.println\(Greeting\);/);
+    assert.doesNotMatch(readOutput(root, 'mod/Config.java'), /Greeting|counter/);
+});
+
+test('packages unchanged since the last run are skipped', async t => {
+    const root = goTree(APP);
+    t.after(() => fs.rmSync(root, { recursive: true, force: true }));
+    const out = path.join(root, 'out');
+    await convertDir(t, root);
+    const written = readOutput(root, 'mod/store/UserFile.java');
+
+    assert.deepEqual((await convertDir(t, root)).printed, [`Converted 0 of 3 Go files into 0 Java files in ${out}; 3 unchanged since the last run`]);
+    assert.equal(readOutput(root, 'mod/store/UserFile.java'), written);
+
+    // A changed file converts its package again, and only that package
+    fs.appendFileSync(path.join(root, 'mod', 'store', 'user.go'), '\nfunc Anonymous() User {\n\treturn User{}\n}\n');
+    assert.deepEqual((await convertDir(t, root)).printed, [`Converted 1 of 3 Go files into 1 Java files in ${out}; 2 unchanged since the last run`]);
+    assert.match(readOutput(root, 'mod/store/UserFile.java'), /public static User Anonymous\(\) \{/);
+
+    // So does a changed setting, and --force converts everything
+    assert.deepEqual((await convertDir(t, root, '--java-naming')).printed, [`Converted 3 of 3 Go files into 4 Java files in ${out}`]);
+    assert.deepEqual((await convertDir(t, root, '--java-naming', '--force')).printed, [`Converted 3 of 3 Go files into 4 Java files in ${out}`]);
+});
+
+test('a package converted again deletes the files it no longer generates', async t => {
+    const root = goTree(APP);
+    t.after(() => fs.rmSync(root, { recursive: true, force: true }));
+    await convertDir(t, root, '--layout', 'maven');
+    fs.writeFileSync(path.join(root, 'mod', 'store', 'user.go'), 'package store\n\nfunc Lookup(name string) string {\n\treturn name\n}\n');
+    await convertDir(t, root, '--layout', 'maven');
+    // Without the User type, the file class is named User in place of UserFile
+    assert.deepEqual(javaFiles(root).filter(file => file.includes('/store/')), ['src/main/java/mod/store/User.java']);
+    assert.match(readOutput(root, 'src/main/java/mod/store/User.java'), /public static String Lookup\(String name\) \{/);
+
+    // A package whose sources are all gone leaves nothing behind
+    fs.rmSync(path.join(root, 'mod', 'store'), { recursive: true });
+    await convertDir(t, root, '--layout', 'maven');
+    assert.deepEqual(javaFiles(root).filter(file => file.includes('/store/')), []);
+});
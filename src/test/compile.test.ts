@@ -0,0 +1,74 @@
+import * as assert from 'assert/strict';
+import { test } from 'node:test';
+import { assertCompiles, convertGo } from './helpers';
+
+// The declarations of test-sample.go, each converted on its own and compiled
+
+test('User struct compiles', t => {
+    const java = convertGo(`package main
+
+// User represents a user in the system
+type User struct {
+	Name   string \`json:"name"\`
+	Age    int    \`json:"age"\`
+	Email  string \`json:"email,omitempty"\`
+	Active bool
+}
+
+func (u *User) SetName(name string) {
+	u.Name = name
+}
+
+func (u User) GetFullInfo() string {
+	return fmt.Sprintf("%s (%d)", u.Name, u.Age)
+}
+`);
+    assert.match(java, /public static class User \{/);
+    assertCompiles(t, java);
+});
+
+test('Reader interface compiles', t => {
+    const java = convertGo(`package main
+
+// Reader is an interface for reading data
+type Reader interface {
+	Read(data []byte) (int, error)
+	Close() error
+}
+`);
+    assert.match(java, /int read\(byte\[\] data\) throws Exception;/);
+    assertCompiles(t, java);
+});
+
+test('Divide compiles', t => {
+    const java = convertGo(`package main
+
+import "errors"
+
+// Divide divides two numbers and returns error if divisor is zero
+func Divide(a, b float64) (float64, error) {
+	if b == 0 {
+		return 0, errors.New("division by zero")
+	}
+	return a / b, nil
+}
+`);
+    assert.match(java, /public static double divide\(double a, double b\) throws Exception \{/);
+    assertCompiles(t, java);
+});
+
+test('ProcessItems compiles', t => {
+    const java = convertGo(`package main
+
+// ProcessItems processes a slice of items with metadata
+func ProcessItems(items []string, metadata map[string]int) (int, error) {
+	count := len(items)
+	for _, v := range metadata {
+		count += v
+	}
+	return count, nil
+}
+`);
+    assert.match(java, /public static int processItems\(List<String> items, Map<String, Integer> metadata\) \{/);
+    assertCompiles(t, java);
+});
@@ -0,0 +1,38 @@
+import * as assert from 'assert/strict';
+import * as fs from 'fs';
+import * as os from 'os';
+import * as path from 'path';
+import { test } from 'node:test';
+import { loadConfigFile } from '../config';
+
+function loadYaml(text: string) {
+    const dir = fs.mkdtempSync(path.join(os.tmpdir(), 'go-to-java-config-'));
+    try {
+        const file = path.join(dir, '.go2java.yaml');
+        fs.writeFileSync(file, text);
+        return loadConfigFile(file);
+    } finally {
+        fs.rmSync(dir, { recursive: true, force: true });
+    }
+}
+
+test('comments are stripped from YAML lines', () => {
+    const config = loadYaml('# converted tree\njavaPackage: com.example  # trailing\n');
+    assert.equal(config.javaPackage, 'com.example');
+});
+
+test('a # inside a quoted value is kept', () => {
+    const config = loadYaml([
+        'exceptionClass: "App#Error"  # comment',
+        'stdlibCalls:',
+        '  strings.Fields: \'split #here\'',
+        '  strings.Join: "say \\" #this" # comment',
+        ''
+    ].join('\n'));
+    assert.equal(config.exceptionClass, 'App#Error');
+    assert.deepEqual(config.stdlibCalls, { 'strings.Fields': 'split #here', 'strings.Join': 'say " #this' });
+});
+
+test('a # not after whitespace is part of an unquoted value', () => {
+    assert.deepEqual(loadYaml('stdlibCalls:\n  strings.Repeat: it\'s#fine\n').stdlibCalls, { 'strings.Repeat': 'it\'s#fine' });
+});
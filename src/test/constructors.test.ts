@@ -0,0 +1,31 @@
+import * as assert from 'assert/strict';
+import { test } from 'node:test';
+import { assertCompiles, convertGo, methodBody } from './helpers';
+
+const USER = `package main
+
+type User struct {
+	Name  string
+	Tags  []string
+	Attrs map[string]int
+}
+
+func NewUser(name string) *User {
+	return &User{Name: name}
+}
+`;
+
+test('the all-args constructor takes the fields in declaration order', () => {
+    assert.match(convertGo(USER), /public User\(String name, List<String> tags, Map<String, Integer> attrs\) \{/);
+});
+
+test('a static factory passes null for the collections it leaves out', () => {
+    assert.deepEqual(methodBody(convertGo(USER, { staticFactories: true }), 'newUser'), ['return new User(name, null, null);']);
+});
+
+test('a static factory passes empty collections with emptyCollections', t => {
+    const java = convertGo(USER, { staticFactories: true, emptyCollections: true });
+    assert.match(java, /private List<String> tags = new ArrayList<>\(\);/);
+    assert.deepEqual(methodBody(java, 'newUser'), ['return new User(name, new ArrayList<>(), new HashMap<>());']);
+    assertCompiles(t, java);
+});
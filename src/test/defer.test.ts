@@ -0,0 +1,159 @@
+import * as assert from 'assert/strict';
+import { test } from 'node:test';
+import { assertCompiles, convertGo, methodBody } from './helpers';
+
+test('a defer at function level wraps the rest of the body in try/finally', () => {
+    assert.deepEqual(methodBody(convertGo(`package main
+
+import "fmt"
+
+func Run() {
+	defer fmt.Println("done")
+	fmt.Println("running")
+}
+`), 'run'), [
+        'try {',
+        'System.out.println("running");',
+        '} finally {',
+        'System.out.println("done");',
+        '}'
+    ]);
+});
+
+test('defers in a loop are collected and run last to first', t => {
+    const java = convertGo(`package main
+
+import "fmt"
+
+func Count(n int) {
+	defer fmt.Println("done")
+	for i := 0; i < n; i++ {
+		defer fmt.Println(i)
+	}
+}
+`);
+    assert.deepEqual(methodBody(java, 'count'), [
+        'List<Runnable> deferred = new ArrayList<>();',
+        'try {',
+        'deferred.add(() -> System.out.println("done"));',
+        'for (int i = 0; i < n; i++) {',
+        // Go evaluates i at the defer, so each call keeps its own
+        'final int arg = i;',
+        'deferred.add(() -> System.out.println(arg));',
+        '}',
+        '} finally {',
+        'for (int i = deferred.size() - 1; i >= 0; i--) {',
+        'deferred.get(i).run();',
+        '}',
+        '}'
+    ]);
+    assertCompiles(t, java);
+});
+
+test('deferred calls of a throwing method are Callables', t => {
+    const java = convertGo(`package main
+
+import "errors"
+
+type File struct {
+	Name string
+}
+
+func (f *File) Close() error {
+	if f.Name == "" {
+		return errors.New("no name")
+	}
+	return nil
+}
+
+func Open(name string) (*File, error) {
+	if name == "" {
+		return nil, errors.New("empty")
+	}
+	return &File{Name: name}, nil
+}
+
+func CloseAll(names []string) error {
+	for _, name := range names {
+		f, err := Open(name)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+	}
+	return nil
+}
+`);
+    const body = methodBody(java, 'closeAll');
+    assert.equal(body[0], 'List<Callable<Void>> deferred = new ArrayList<>();');
+    assert.ok(body.includes('deferred.get(i).call();'), body.join('\n'));
+    assert.match(java, /import java\.util\.concurrent\.Callable;/);
+    assertCompiles(t, java);
+});
+
+test('recovering in a function with unnamed results returns their zero values', t => {
+    const java = convertGo(`package main
+
+import (
+	"errors"
+	"fmt"
+)
+
+func Parse(s string) (int, error) {
+	defer func() {
+		if r := recover(); r != nil {
+			fmt.Println("recovered", r)
+		}
+	}()
+	if s == "" {
+		return 0, errors.New("empty")
+	}
+	return len(s), nil
+}
+`);
+    assert.deepEqual(methodBody(java, 'parse'), [
+        'try {',
+        'if (s.equals("")) {',
+        'throw new Exception("empty");',
+        '}',
+        'return s.length();',
+        '} catch (RuntimeException r) {',
+        'System.out.println("recovered " + r.getMessage());',
+        '}',
+        'return 0;'
+    ]);
+    assertCompiles(t, java);
+});
+
+test('a recovered panic is formatted by its message', t => {
+    const java = convertGo(`package main
+
+import "fmt"
+
+func Safe(n int) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("recovered: %v", r)
+		}
+	}()
+	if n < 0 {
+		panic("negative")
+	}
+	return nil
+}
+`);
+    assert.deepEqual(methodBody(java, 'safe'), [
+        'Exception err = null;',
+        'try {',
+        'if (n < 0) {',
+        'throw new RuntimeException("negative");',
+        '}',
+        '} catch (RuntimeException r) {',
+        'err = new Exception(String.format("recovered: %s", r.getMessage()));',
+        '}',
+        'if (err != null) {',
+        'throw err;',
+        '}'
+    ]);
+    assertCompiles(t, java);
+});
@@ -0,0 +1,80 @@
+import * as assert from 'assert/strict';
+import { test } from 'node:test';
+import { assertCompiles, convertGo, methodBody } from './helpers';
+
+const DESCRIBE = `package main
+
+import (
+	"errors"
+	"strconv"
+)
+
+func Parse(s string) (int, error) {
+	if s == "" {
+		return 0, errors.New("empty")
+	}
+	return strconv.Atoi(s)
+}
+
+func Describe(s string) string {
+	n, err := Parse(s)
+	if err != nil {
+		return "error: " + err.Error()
+	}
+	return strconv.Itoa(n)
+}
+`;
+
+test('the message of an error is its getMessage()', t => {
+    const java = convertGo(DESCRIBE);
+    assert.deepEqual(methodBody(java, 'describe'), [
+        'int n;',
+        'try {',
+        'n = parse(s);',
+        '} catch (Exception err) {',
+        'return "error: " + err.getMessage();',
+        '}',
+        'return Integer.toString(n);'
+    ]);
+    assertCompiles(t, java);
+});
+
+test('an error formatted by fmt is its message', () => {
+    assert.deepEqual(methodBody(convertGo(`package main
+
+import "fmt"
+
+func Show(err error) string {
+	return fmt.Sprintf("got %v", err)
+}
+`), 'show'), ['return String.format("got %s", err.getMessage());']);
+});
+
+test('an error wrapped with %w is the cause of the new exception', t => {
+    const java = convertGo(`package main
+
+import (
+	"errors"
+	"fmt"
+)
+
+func Load(name string) error {
+	return errors.New("missing " + name)
+}
+
+func Wrap(name string) error {
+	if err := Load(name); err != nil {
+		return fmt.Errorf("load %s: %w", name, err)
+	}
+	return nil
+}
+`);
+    assert.deepEqual(methodBody(java, 'wrap'), [
+        'try {',
+        'load(name);',
+        '} catch (Exception err) {',
+        'throw new Exception(String.format("load %s: %s", name, err.getMessage()), err);',
+        '}'
+    ]);
+    assertCompiles(t, java);
+});
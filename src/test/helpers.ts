@@ -0,0 +1,67 @@
+import * as assert from 'assert/strict';
+import { spawnSync } from 'child_process';
+import * as fs from 'fs';
+import * as os from 'os';
+import * as path from 'path';
+import type { TestContext } from 'node:test';
+import { GoFileParser } from '../goFileParser';
+import { JavaFileGenerationOptions, JavaFileGenerator } from '../javaFileGenerator';
+
+/**
+ * Options the tests generate with unless they set their own: those of the API without
+ * annotations needing libraries, with Java naming on
+ */
+const TEST_OPTIONS: JavaFileGenerationOptions = {
+    isStatic: true,
+    addComments: true,
+    handleErrorsAsExceptions: true,
+    includeConstructors: true,
+    includeGettersSetters: true,
+    includeComments: true,
+    javaNaming: true
+};
+
+/**
+ * Convert the source of a Go file to Java with the declaration parser, which needs no
+ * tree-sitter grammar to load
+ */
+export function convertGo(goSource: string, options: Partial<JavaFileGenerationOptions> = {}): string {
+    return JavaFileGenerator.generateJavaFile(GoFileParser.parseFile(goSource), { ...TEST_OPTIONS, ...options });
+}
+
+/**
+ * Statements of the first method named name in generated Java, one per line without indentation
+ */
+export function methodBody(java: string, name: string): string[] {
+    const lines = java.split('\n');
+    const start = lines.findIndex(line => new RegExp(`\\s${name}\\(.*\\{$`).test(line));
+    assert.ok(start >= 0, `no method ${name} in:\n${java}`);
+    const indent = lines[start].length - lines[start].trimStart().length;
+    const end = lines.findIndex((line, i) => i > start && line === `${' '.repeat(indent)}}`);
+    return lines.slice(start + 1, end).map(line => line.trim()).filter(line => line !== '');
+}
+
+/**
+ * Compile a generated Java file with javac, failing the test with the compiler's output when
+ * it does not compile. The test is skipped when javac is not on PATH.
+ */
+export function assertCompiles(t: TestContext, java: string): void {
+    const version = spawnSync('javac', ['-version'], { encoding: 'utf8' });
+    if (version.error) {
+        t.skip('javac is not on PATH');
+        return;
+    }
+    // javac wants a public top-level class in a file of its name
+    const className = /^public\s+(?:final\s+|abstract\s+)*(?:class|interface|record|enum)\s+(\w+)/m.exec(java)?.[1] || 'Main';
+    const dir = fs.mkdtempSync(path.join(os.tmpdir(), 'go-to-java-test-'));
+    try {
+        const file = path.join(dir, `${className}.java`);
+        fs.writeFileSync(file, java);
+        const result = spawnSync('javac', ['-d', dir, file], { encoding: 'utf8' });
+        if (result.status !== 0) {
+            assert.fail(`javac failed:\n${result.stderr}${result.stdout}\n${java}`);
+        }
+    } finally {
+        fs.rmSync(dir, { recursive: true, force: true });
+    }
+}
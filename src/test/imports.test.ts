@@ -0,0 +1,57 @@
+import * as assert from 'assert/strict';
+import { test } from 'node:test';
+import { convertGo } from './helpers';
+
+function importsOf(java: string): string[] {
+    return java.split('\n').filter(line => line.startsWith('import '));
+}
+
+test('ProcessItems imports List and Map', () => {
+    const java = convertGo(`package main
+
+// ProcessItems processes a slice of items with metadata
+func ProcessItems(items []string, metadata map[string]int) (int, error) {
+	count := len(items)
+	for _, v := range metadata {
+		count += v
+	}
+	return count, nil
+}
+`);
+    // String and Integer are java.lang's
+    assert.deepEqual(importsOf(java), ['import java.util.List;', 'import java.util.Map;']);
+});
+
+test('imports are sorted and listed once', () => {
+    const java = convertGo(`package main
+
+func Merge(a []string, b []string, m map[string][]int) map[string][]int {
+	return m
+}
+
+func Copy(xs []int) []int {
+	return xs
+}
+`);
+    assert.deepEqual(importsOf(java), ['import java.util.List;', 'import java.util.Map;']);
+});
+
+test('annotations import their library', () => {
+    const java = convertGo(`package main
+
+type User struct {
+	Name string \`json:"name"\`
+	Tags []string \`json:"tags"\`
+}
+`, { includeJsonAnnotations: true });
+    assert.deepEqual(importsOf(java), ['import com.fasterxml.jackson.annotation.JsonProperty;', 'import java.util.List;']);
+});
+
+test('a file using no library type has no imports', () => {
+    assert.deepEqual(importsOf(convertGo(`package main
+
+func Add(a, b int) int {
+	return a + b
+}
+`)), []);
+});
@@ -0,0 +1,52 @@
+import * as assert from 'assert/strict';
+import { test } from 'node:test';
+import { assertCompiles, convertGo } from './helpers';
+
+/** Go integer type, the Java type of a field of it, and whether it is marked unsigned */
+const WIDTHS: [string, string, boolean][] = [
+    ['int8', 'byte', false],
+    ['int16', 'short', false],
+    ['int32', 'int', false],
+    ['int64', 'long', false],
+    ['int', 'int', false],
+    ['byte', 'byte', false],
+    ['uint8', 'byte', true],
+    ['uint16', 'int', false],
+    ['uint32', 'long', false],
+    ['uint64', 'long', true],
+    ['uint', 'int', true]
+];
+
+function fieldOf(goType: string, options = {}): string {
+    const java = convertGo(`package main
+
+type Holder struct {
+	Value ${goType}
+}
+`, options);
+    const field = java.split('\n').find(line => /private .* value;/.test(line));
+    assert.ok(field, java);
+    return field.trim();
+}
+
+for (const [goType, javaType, unsigned] of WIDTHS) {
+    test(`${goType} field becomes ${javaType}`, () => {
+        const field = fieldOf(goType);
+        assert.equal(field, `private ${javaType} value;${unsigned ? `  // unsigned ${goType} in Go` : ''}`);
+    });
+}
+
+test('int and uint become long with intType long', () => {
+    assert.equal(fieldOf('int', { intType: 'long' }), 'private long value;');
+    assert.equal(fieldOf('uint', { intType: 'long' }), 'private long value;  // unsigned uint in Go');
+});
+
+test('struct with a field of every width compiles', t => {
+    const fields = WIDTHS.map(([goType], i) => `\tF${i} ${goType}`).join('\n');
+    assertCompiles(t, convertGo(`package main
+
+type Sizes struct {
+${fields}
+}
+`));
+});
@@ -0,0 +1,117 @@
+import * as assert from 'assert/strict';
+import { test } from 'node:test';
+import { formatJava } from '../javaFormatter';
+import { convertGo } from './helpers';
+
+const SAMPLE = `package main
+
+// Shape has an area
+type Shape interface {
+	Area() float64
+}
+
+// Point is a point in the plane
+type Point struct {
+	X    int
+	Y    int
+	Name string
+}
+
+func (p Point) Quadrant() int {
+	switch {
+	case p.X >= 0 && p.Y >= 0:
+		return 1
+	case p.X < 0:
+		return 2
+	default:
+		return 3
+	}
+}
+
+func Sum(xs []int) int {
+	total := 0
+	for _, x := range xs {
+		if x > 0 {
+			total += x
+		}
+	}
+	return total
+}
+`;
+
+test('formatting formatted output leaves it unchanged', () => {
+    for (const options of [{}, { valueMethods: true }, { includeGettersSetters: false, includeComments: false }]) {
+        const java = convertGo(SAMPLE, options);
+        assert.equal(formatJava(java), java);
+    }
+});
+
+test('the input indentation is ignored', () => {
+    const java = convertGo(SAMPLE);
+    const flattened = java.split('\n').map(line => line.trim()).join('\n');
+    assert.equal(formatJava(flattened), java);
+});
+
+test('blocks are indented four spaces and case labels sit inside their switch', () => {
+    assert.equal(formatJava('class A {\nvoid f(int x) {\nswitch (x) {\ncase 1:\nreturn;\ndefault:\nbreak;\n}\n}\n}'), [
+        'class A {',
+        '    void f(int x) {',
+        '        switch (x) {',
+        '            case 1:',
+        '                return;',
+        '            default:',
+        '                break;',
+        '        }',
+        '    }',
+        '}',
+        ''
+    ].join('\n'));
+});
+
+test('members are separated by one blank line and the file ends in one newline', () => {
+    assert.equal(formatJava('class A {\nvoid f() {\n}\n\n\n\nvoid g() {\n}\n\n}\n\n'), [
+        'class A {',
+        '    void f() {',
+        '    }',
+        '',
+        '    void g() {',
+        '    }',
+        '}',
+        ''
+    ].join('\n'));
+});
+
+test('brackets in strings and comments are not counted', () => {
+    const java = 'class A {\n    // a { brace\n    String s = "}";\n    char c = \'{\';\n}\n';
+    assert.equal(formatJava(java), java);
+});
+
+test('the lines of a text block are kept as written', () => {
+    const java = [
+        'class A {',
+        '    String query() {',
+        '        return """',
+        '            SELECT *',
+        '',
+        '              FROM t { x }',
+        '            """;',
+        '    }',
+        '}',
+        ''
+    ].join('\n');
+    assert.equal(formatJava(java), java);
+    assert.equal(formatJava(java.replace('        return', 'return')), java);
+});
+
+test('a line continuing a statement is indented eight spaces past it', () => {
+    const java = convertGo(`package main
+
+type Point struct {
+	X    int
+	Y    int
+	Name string
+}
+`, { valueMethods: true });
+    assert.match(java, /\n {12}return Objects\.equals\(x, other\.x\)\n {20}&& Objects\.equals\(y, other\.y\)\n {20}&& Objects\.equals\(name, other\.name\);\n/);
+    assert.equal(formatJava(java), java);
+});
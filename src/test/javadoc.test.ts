@@ -0,0 +1,67 @@
+import * as assert from 'assert/strict';
+import { test } from 'node:test';
+import { convertGo } from './helpers';
+
+function tags(java: string): string[] {
+    return java.split('\n').map(line => line.trim()).filter(line => /^\* @(param|return|throws)\b/.test(line));
+}
+
+test('parameters are documented by their Java names', () => {
+    assert.deepEqual(tags(convertGo(`package main
+
+// Scale multiplies Value by Factor
+func Scale(Value int, Factor int) int {
+	return Value * Factor
+}
+`)), ['* @param value int value', '* @param factor int value', '* @return int value']);
+});
+
+test('a throwing method returning one value documents its type', () => {
+    assert.deepEqual(tags(convertGo(`package main
+
+import "errors"
+
+// Divide divides two numbers and returns error if divisor is zero
+func Divide(a, b float64) (float64, error) {
+	if b == 0 {
+		return 0, errors.New("division by zero")
+	}
+	return a / b, nil
+}
+`)), ['* @param a double value', '* @param b double value', '* @return double value', '* @throws Exception if operation fails']);
+});
+
+test('several results are documented as the class holding them', () => {
+    assert.ok(tags(convertGo(`package main
+
+// DivMod returns the quotient and remainder
+func DivMod(a, b int) (int, int) {
+	return a / b, a % b
+}
+`)).includes('* @return DivModResult holding the 2 return values'));
+});
+
+test('interface methods document their Java parameter names and return type', () => {
+    assert.deepEqual(tags(convertGo(`package main
+
+// Reader is an interface for reading data
+type Reader interface {
+	Read(Data []byte) (int, error)
+}
+`)), ['* @param data', '* @return int value', '* @throws Exception if the Go method returns an error']);
+});
+
+test('parameters are described by their Java types', () => {
+    assert.deepEqual(tags(convertGo(`package main
+
+// Count counts the names in xs found in seen
+func Count(xs []string, seen map[string]bool, data []byte) int64 {
+	return 0
+}
+`)), [
+        '* @param xs list of String values',
+        '* @param seen map with String keys and Boolean values',
+        '* @param data array of byte values',
+        '* @return long value'
+    ]);
+});
@@ -0,0 +1,63 @@
+import * as assert from 'assert/strict';
+import { test } from 'node:test';
+import { javaCharLiteral, javaFloatLiteral, javaIntegerLiteral, javaStringLiteral } from '../javaLiterals';
+import { convertGo, methodBody } from './helpers';
+
+function convertDeclaration(declaration: string): string {
+    const body = methodBody(convertGo(`package main
+
+func Use() {
+	${declaration}
+	_ = v
+}
+`), 'use');
+    return body[0];
+}
+
+/** Go literal and the Java declaration a variable initialized with it becomes */
+const LITERALS: [string, string][] = [
+    ['0x1F', 'int v = 0x1F;'],
+    ['0b1010', 'int v = 0b1010;'],
+    ['0o17', 'int v = 017;'],
+    ['017', 'int v = 017;'],
+    ['1_000_000', 'int v = 1_000_000;'],
+    ['3000000000', 'long v = 3000000000L;'],
+    ['1e3', 'double v = 1e3;'],
+    ['0x1p-2', 'double v = 0x1p-2;'],
+    ['\'A\'', 'char v = \'A\';'],
+    ['\'\\n\'', 'char v = \'\\n\';'],
+    ['"tab\\there"', 'String v = "tab\\there";']
+];
+
+for (const [go, java] of LITERALS) {
+    test(`${go} becomes ${java}`, () => {
+        assert.equal(convertDeclaration(`v := ${go}`), java);
+    });
+}
+
+test('integer literals Java spells differently', () => {
+    assert.equal(javaIntegerLiteral('0o_17'), '017');
+    assert.equal(javaIntegerLiteral('0x_FF'), '0xFF');
+    assert.equal(javaIntegerLiteral('0x80000000'), '0x80000000L');
+    // Past Long.MAX_VALUE only a uint64 holds it, as the same bits
+    assert.equal(javaIntegerLiteral('18446744073709551615'), '0xFFFFFFFFFFFFFFFFL');
+    assert.equal(javaIntegerLiteral('18446744073709551616'), undefined);
+});
+
+test('float literals keep their spelling', () => {
+    assert.equal(javaFloatLiteral('1_000.5'), '1_000.5');
+    assert.equal(javaFloatLiteral('0x_1p4'), '0x1p4');
+});
+
+test('rune literals beyond a char have no char literal', () => {
+    assert.equal(javaCharLiteral('\'é\''), '\'é\'');
+    assert.equal(javaCharLiteral('\'\\x41\''), '\'A\'');
+    assert.equal(javaCharLiteral('\'😀\''), undefined);
+});
+
+test('string escapes Java lacks are rewritten', () => {
+    assert.equal(javaStringLiteral('"\\x41\\a"'), '"A\\u0007"');
+    assert.equal(javaStringLiteral('"\\xc3\\xa9"'), '"\\u00E9"');
+    // Not UTF-8, so no Java String holds these bytes
+    assert.equal(javaStringLiteral('"\\xff"'), undefined);
+});
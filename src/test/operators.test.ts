@@ -0,0 +1,53 @@
+import * as assert from 'assert/strict';
+import { test } from 'node:test';
+import { convertGo, methodBody } from './helpers';
+
+function convertStatement(statement: string, params = 'a int, b int'): string {
+    const body = methodBody(convertGo(`package main
+
+func Use(${params}) {
+	${statement}
+}
+`), 'use');
+    assert.equal(body.length, 1, body.join('\n'));
+    return body[0];
+}
+
+/** Go statement and the Java statement it becomes, for int operands */
+const COMPOUND: [string, string][] = [
+    ['a += b', 'a += b;'],
+    ['a -= b', 'a -= b;'],
+    ['a *= b', 'a *= b;'],
+    ['a /= b', 'a /= b;'],
+    ['a %= b', 'a %= b;'],
+    ['a <<= 2', 'a <<= 2;'],
+    ['a >>= 1', 'a >>= 1;'],
+    ['a &= b', 'a &= b;'],
+    ['a |= b', 'a |= b;'],
+    ['a ^= b', 'a ^= b;'],
+    // Java has no and-not operator
+    ['a &^= b', 'a &= ~b;'],
+    ['a++', 'a++;'],
+    ['b--', 'b--;']
+];
+
+for (const [go, java] of COMPOUND) {
+    test(`${go} becomes ${java}`, () => {
+        assert.equal(convertStatement(go), java);
+    });
+}
+
+test('a right shift of an unsigned operand is logical', () => {
+    assert.equal(convertStatement('u >>= 3', 'u uint64'), 'u >>>= 3;');
+});
+
+test('uint64 division and remainder are unsigned', () => {
+    assert.equal(convertStatement('u /= v', 'u uint64, v uint64'), 'u = Long.divideUnsigned(u, v);');
+    assert.equal(convertStatement('u %= v', 'u uint64, v uint64'), 'u = Long.remainderUnsigned(u, v);');
+    assert.equal(convertStatement('q := u / v\n\t_ = q', 'u uint64, v uint64'), 'long q = Long.divideUnsigned(u, v);');
+    assert.equal(convertStatement('r := u % 10\n\t_ = r', 'u uint64'), 'long r = Long.remainderUnsigned(u, 10);');
+});
+
+test('uint division uses Integer when uint is an int', () => {
+    assert.equal(convertStatement('q := u / v\n\t_ = q', 'u uint, v uint'), 'int q = Integer.divideUnsigned(u, v);');
+});
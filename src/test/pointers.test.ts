@@ -0,0 +1,54 @@
+import * as assert from 'assert/strict';
+import { test } from 'node:test';
+import { assertCompiles, convertGo, methodBody } from './helpers';
+
+// Java references stand in for Go pointers: *T becomes T, and a pointer to a primitive its box
+
+const NODE = `package main
+
+type Node struct {
+	Value int
+	Next  *Node
+	Count *int
+}
+
+func (n *Node) Last() *Node {
+	for n.Next != nil {
+		n = n.Next
+	}
+	return n
+}
+
+func Deref(p *Node) Node {
+	return *p
+}
+`;
+
+test('a self-referential pointer field has the struct type', () => {
+    const java = convertGo(NODE);
+    assert.match(java, /private Node next;/);
+    assert.match(java, /public Node\(int value, Node next, Integer count\) \{/);
+    assert.match(java, /public Node getNext\(\) \{/);
+});
+
+test('a pointer to a primitive is boxed', () => {
+    assert.match(convertGo(NODE), /private Integer count;/);
+});
+
+test('walking the pointer chain compares with null', () => {
+    assert.deepEqual(methodBody(convertGo(NODE), 'last'), [
+        'Node n = this;',
+        'while (n.next != null) {',
+        'n = n.next;',
+        '}',
+        'return n;'
+    ]);
+});
+
+test('dereferencing a pointer is the reference itself', () => {
+    assert.deepEqual(methodBody(convertGo(NODE), 'deref'), ['return p;']);
+});
+
+test('self-referential struct compiles', t => {
+    assertCompiles(t, convertGo(NODE));
+});
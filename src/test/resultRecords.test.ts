@@ -0,0 +1,34 @@
+import * as assert from 'assert/strict';
+import { test } from 'node:test';
+import { assertCompiles, convertGo, methodBody } from './helpers';
+
+const PROCESS = `package main
+
+import "errors"
+
+func ProcessItems(items []string) (int, error) {
+	if len(items) == 0 {
+		return 0, errors.New("no items")
+	}
+	return len(items), nil
+}
+
+func Process(items []string) (int, error) {
+	return ProcessItems(items)
+}
+`;
+
+test('returning a call result copies the components of the callee record', t => {
+    const java = convertGo(PROCESS, { errorResultRecords: true, javaVersion: 17 });
+    assert.deepEqual(methodBody(java, 'process'), [
+        'ProcessItemsResult result = processItems(items);',
+        'return new ProcessResult(result.value(), result.error());'
+    ]);
+    assertCompiles(t, java);
+});
+
+test('returning a call result with the same record returns it as it is', t => {
+    const java = convertGo(PROCESS, { errorResultRecords: true, sharedResultRecord: true, javaVersion: 17 });
+    assert.deepEqual(methodBody(java, 'process'), ['return processItems(items);']);
+    assertCompiles(t, java);
+});
@@ -0,0 +1,115 @@
+import * as assert from 'assert/strict';
+import { test } from 'node:test';
+import type { GoExpr } from '../goBodyParser';
+import type { Rewriter } from '../javaBodyGenerator';
+import { convertGo, methodBody } from './helpers';
+
+const RUN = `package main
+
+import "logging"
+
+type File struct {
+	Name string
+}
+
+func Open(path string) (*File, error) {
+	return &File{Name: path}, nil
+}
+
+func (f *File) Close() {
+}
+
+func Run(path string) error {
+	logging.Info("opening", path)
+	f, err := Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	logging.Debug(f.Name)
+	return nil
+}
+`;
+
+const isLogCall = (e: GoExpr) => e.kind === 'Call' && e.fun.kind === 'Selector'
+    && e.fun.x.kind === 'Ident' && e.fun.x.name === 'logging';
+
+/** The README's example, turning an in-house logging library into SLF4J calls */
+const slf4j: Rewriter = {
+    rewrite(node, context) {
+        if (node.kind === 'Call' && isLogCall(node) && node.fun.kind === 'Selector') {
+            const args = node.args.map(arg => context.expr(arg)).join(', ');
+            return { replacement: `LOG.${node.fun.sel.toLowerCase()}(${args})`, handled: true };
+        }
+        return { replacement: '', handled: false };
+    }
+};
+
+test('an expression rewriter replaces the call and keeps the default translation of the rest', () => {
+    assert.deepEqual(methodBody(convertGo(RUN, { rewriters: [slf4j] }), 'run'), [
+        'LOG.info("opening", path);',
+        'File f = open(path);',
+        'try {',
+        'LOG.debug(f.name);',
+        '} finally {',
+        'f.close();',
+        '}'
+    ]);
+});
+
+test('the first rewriter handling a node wins, and handled: false passes it on', () => {
+    const seen: string[] = [];
+    const upper: Rewriter = {
+        rewrite: node => node.kind === 'BasicLit' && node.litKind === 'STRING'
+            ? { replacement: node.value.toUpperCase(), handled: true }
+            : { replacement: '', handled: false }
+    };
+    const never: Rewriter = {
+        rewrite: node => {
+            if (node.kind === 'BasicLit') {
+                seen.push(node.value);
+            }
+            return { replacement: '', handled: false };
+        }
+    };
+    assert.equal(methodBody(convertGo(RUN, { rewriters: [upper, slf4j, never] }), 'run')[0], 'LOG.info("OPENING", path);');
+    assert.deepEqual(seen, []);
+});
+
+test('a rewriter handling a statement the converter would join to its neighbours keeps it apart', () => {
+    const keepDefers: Rewriter = {
+        rewrite: node => node.kind === 'DeferStmt'
+            ? { replacement: '// f is closed by the caller', handled: true }
+            : { replacement: '', handled: false }
+    };
+    assert.deepEqual(methodBody(convertGo(RUN, { rewriters: [keepDefers, slf4j] }), 'run'), [
+        'LOG.info("opening", path);',
+        'File f = open(path);',
+        '// f is closed by the caller',
+        'LOG.debug(f.name);'
+    ]);
+});
+
+test('an empty replacement drops the statement', () => {
+    const dropLogs: Rewriter = {
+        rewrite: node => node.kind === 'ExprStmt' && isLogCall(node.x)
+            ? { replacement: '', handled: true }
+            : { replacement: '', handled: false }
+    };
+    assert.deepEqual(methodBody(convertGo(RUN, { rewriters: [dropLogs] }), 'run'), [
+        'File f = open(path);',
+        'try {',
+        '} finally {',
+        'f.close();',
+        '}'
+    ]);
+});
+
+test('a rewriter asked about the node it rewrites gets the default translation', () => {
+    const logged: Rewriter = {
+        rewrite: (node, context) => node.kind === 'Call' && node.fun.kind === 'Ident' && node.fun.name === 'Open'
+            ? { replacement: `traced(${context.expr(node)})`, handled: true }
+            : { replacement: '', handled: false }
+    };
+    assert.equal(methodBody(convertGo(RUN, { rewriters: [logged, slf4j] }), 'run')[1], 'File f = traced(open(path));');
+});
@@ -0,0 +1,55 @@
+import * as assert from 'assert/strict';
+import { test } from 'node:test';
+import { ConversionDiagnostic } from '../javaGenerator';
+import { convertGo, methodBody } from './helpers';
+
+// Go measures and indexes strings in UTF-8 bytes, Java in UTF-16 chars. The conversion keeps
+// the Java forms, so for text beyond ASCII these tests pin down what differs.
+
+function convertBody(body: string, diagnostics?: ConversionDiagnostic[]): string[] {
+    const java = convertGo(`package main
+
+func Use(s string, b []byte) {
+${body}
+}
+`, { diagnostics });
+    return methodBody(java, 'use');
+}
+
+test('len of a multibyte string counts chars, not bytes', () => {
+    assert.deepEqual(convertBody('\tn := len("héllo")'), ['int n = "héllo".length();']);
+    // What Go's len gives and what Java's length() gives for the same text
+    assert.equal(Buffer.byteLength('héllo', 'utf8'), 6);
+    assert.equal('héllo'.length, 5);
+    // Outside the Basic Multilingual Plane, one character is two chars to Java and four bytes to Go
+    assert.equal('😀'.length, 2);
+    assert.equal(Buffer.byteLength('😀', 'utf8'), 4);
+});
+
+test('indexing a multibyte string is reported as degraded', () => {
+    const diagnostics: ConversionDiagnostic[] = [];
+    const body = convertBody('\tg := "héllo, 世界"\n\tc := g[2]\n\t_ = c', diagnostics);
+    assert.deepEqual(body.slice(0, 2), ['String g = "héllo, 世界";', 'byte c = (byte) g.charAt(2);']);
+    // Go's g[2] is 0xA9, the second byte of é; Java's charAt(2) is 'l'
+    assert.equal(Buffer.from('héllo, 世界', 'utf8')[2], 0xA9);
+    assert.equal('héllo, 世界'.charAt(2), 'l');
+    assert.deepEqual(diagnostics.map(d => [d.severity, d.construct]), [['degraded', 'StringIndex']]);
+});
+
+test('slicing a multibyte string is reported as degraded', () => {
+    const diagnostics: ConversionDiagnostic[] = [];
+    const body = convertBody('\tsub := "世界"[0:3]\n\t_ = sub', diagnostics);
+    assert.equal(body[0], 'String sub = "世界".substring(0, 3);');
+    assert.deepEqual(diagnostics.map(d => [d.severity, d.construct]), [['degraded', 'StringIndex']]);
+});
+
+test('a byte read from a string used as a number is cast to int', () => {
+    assert.deepEqual(convertBody('\tx := int(s[0]) + 1\n\t_ = x').slice(0, 1), ['int x = (int) (byte) s.charAt(0) + 1;']);
+});
+
+test('byte slice round trips go through UTF-8', () => {
+    assert.deepEqual(convertBody('\tt := string(b)\n\tu := []byte(s)\n\t_, _ = t, u').slice(0, 2), [
+        'String t = new String(b, StandardCharsets.UTF_8);',
+        'byte[] u = s.getBytes(StandardCharsets.UTF_8);'
+    ]);
+});
@@ -6,9 +6,13 @@ import {
     GoField,
     GoInterface,
     GoMethodSignature,
-    GoVariable
+    GoVariable,
+    GoValueSpecText,
+    expandValueSpecs,
+    extractDocComment
 } from './goFileParser';
-import { GoType, GoFunction, GoParameter } from './goParser';
+import { GoType, GoFunction, GoFunctionParser, GoParameter, GoTypeParam } from './goParser';
+import { GoSyntaxError } from './goBodyParser';
 
 type SyntaxNode = any;
 type TSParser = any;
@@ -42,8 +46,10 @@ function parseTypeNode(node: SyntaxNode, source: string): GoType {
         case 'pointer_type':
             const pointee = node.childForFieldName('type') || node.child(1);
             return { ...parseTypeNode(pointee, source), isPointer: true };
-        case 'slice_type':
-            return { ...parseTypeNode(node.childForFieldName('element')!, source), isSlice: true };
+        case 'slice_type': {
+            const element = parseTypeNode(node.childForFieldName('element')!, source);
+            return { name: element.name, isPointer: false, isSlice: true, isMap: false, isVariadic: false, elementType: element };
+        }
         case 'map_type': {
             const key = parseTypeNode(node.childForFieldName('key')!, source);
             const value = parseTypeNode(node.childForFieldName('value')!, source);
@@ -57,8 +63,17 @@ function parseTypeNode(node: SyntaxNode, source: string): GoType {
                 valueType: value
             };
         }
-        case 'variadic_parameter':
-            return { ...parseTypeNode(node.childForFieldName('type')!, source), isVariadic: true, isSlice: true };
+        case 'variadic_parameter': {
+            const element = parseTypeNode(node.childForFieldName('type')!, source);
+            return { name: element.name, isPointer: false, isSlice: true, isMap: false, isVariadic: true, elementType: element };
+        }
+        case 'generic_type': {
+            const args = node.childForFieldName('type_arguments');
+            const typeArgs = args
+                ? args.namedChildren.map((arg: SyntaxNode) => parseTypeNode(arg.type === 'type_elem' ? arg.namedChildren[0] : arg, source))
+                : [];
+            return { ...parseTypeNode(node.childForFieldName('type'), source), typeArgs };
+        }
         case 'qualified_type':
         case 'type_identifier':
         case 'package_identifier':
@@ -85,10 +100,10 @@ function parseParameters(node: SyntaxNode | null | undefined, source: string): G
                 .map((c: SyntaxNode) => textOf(c, source));
 
             const typeNode = paramNode.childForFieldName('type') || paramNode;
-            const goType = {
-                ...parseTypeNode(typeNode, source),
-                isVariadic: paramNode.type === 'variadic_parameter_declaration' ? true : parseTypeNode(typeNode, source).isVariadic
-            };
+            const parsedType = parseTypeNode(typeNode, source);
+            const goType: GoType = paramNode.type === 'variadic_parameter_declaration'
+                ? { name: parsedType.name, isPointer: false, isSlice: true, isMap: false, isVariadic: true, elementType: parsedType }
+                : parsedType;
             if (names.length === 0) {
                 params.push({ name: '', type: goType });
             } else {
@@ -106,12 +121,26 @@ function parseResultTypes(resultNode: SyntaxNode | null | undefined, source: str
     return [parseTypeNode(resultNode, source)];
 }
 
+/** Type parameters of a generic declaration; undefined when it has none */
+function parseTypeParams(node: SyntaxNode | null | undefined, source: string): GoTypeParam[] | undefined {
+    return node ? GoFunctionParser.parseTypeParams(textOf(node, source).slice(1, -1)) : undefined;
+}
+
+/** Names of a `(n int, err error)` result list; undefined when the results are unnamed */
+function parseResultNames(resultNode: SyntaxNode | null | undefined, source: string): string[] | undefined {
+    if (!resultNode || resultNode.type !== 'parameter_list') return undefined;
+    const names = parseParameters(resultNode, source).map((p) => p.name);
+    return names.some((n) => n) ? names : undefined;
+}
+
 function buildFunction(fnNode: SyntaxNode, source: string, isMethod: boolean): GoFunction | null {
     const nameNode = fnNode.childForFieldName('name');
     if (!nameNode) return null;
     const name = textOf(nameNode, source);
     const params = parseParameters(fnNode.childForFieldName('parameters'), source);
+    GoFunctionParser.validateVariadicParameters(name, params);
     const returnTypes = parseResultTypes(fnNode.childForFieldName('result'), source);
+    const returnNames = parseResultNames(fnNode.childForFieldName('result'), source);
 
     let receiver: GoParameter | undefined;
     if (isMethod) {
@@ -120,40 +149,76 @@ function buildFunction(fnNode: SyntaxNode, source: string, isMethod: boolean): G
         receiver = recvParams[0];
     }
 
+    // Only keep complete bodies; a bare signature ending in '{' yields an unterminated block
+    const bodyNode = fnNode.childForFieldName('body');
+    const hasBody = !!bodyNode && source[bodyNode.endIndex - 1] === '}';
+
     return {
         name,
         parameters: params,
         returnTypes,
+        returnNames,
         isMethod,
         receiver,
-        hasErrorReturn: returnTypes.some((t) => t.name === 'error')
+        hasErrorReturn: returnTypes.some((t) => t.name === 'error'),
+        namePosition: { line: nameNode.startPosition.row, character: nameNode.startPosition.column },
+        body: hasBody ? source.slice(bodyNode.startIndex + 1, bodyNode.endIndex - 1) : undefined,
+        bodyPosition: hasBody
+            ? { line: bodyNode.startPosition.row, character: bodyNode.startPosition.column + 1 }
+            : undefined,
+        doc: docOf(fnNode, source),
+        typeParams: parseTypeParams(fnNode.childForFieldName('type_parameters'), source)
     };
 }
 
-function parseFieldDeclaration(node: SyntaxNode, source: string): GoField | null {
+/**
+ * Doc comment above a node, read from the source lines
+ */
+function docOf(node: SyntaxNode, source: string): string | undefined {
+    return extractDocComment(source.split('\n'), node.startPosition.row);
+}
+
+/**
+ * Fields of one declaration: `A, B int` declares two; an embedded `User` or `*pkg.User`
+ * declares one named after its type
+ */
+function parseFieldDeclaration(node: SyntaxNode, source: string): GoField[] {
     const typeNode = node.childForFieldName('type');
+    if (!typeNode) return [];
     const names = node.children.filter((c: SyntaxNode) => c.type === 'field_identifier').map((c: SyntaxNode) => textOf(c, source));
-    if (!typeNode || names.length === 0) return null;
     const tagNode = node.childForFieldName('tag');
+    const tag = tagNode ? textOf(tagNode, source).replace(/`/g, '') : undefined;
+    const doc = docOf(node, source);
+    if (names.length === 0) {
+        // The grammar keeps the `*` of an embedded pointer as a separate token
+        const isPointer = typeNode.type === 'pointer_type' || node.children.some((c: SyntaxNode) => c.type === '*');
+        const goType = { ...parseTypeNode(typeNode, source), isPointer };
+        const name = goType.name.split('.').pop()!;
+        return [{ name, type: goType, tag, exported: /^[A-Z]/.test(name), isEmbedded: true, doc }];
+    }
     const goType = parseTypeNode(typeNode, source);
-    return {
-        name: names[0],
+    return names.map((name: string) => ({
+        name,
         type: goType,
-        tag: tagNode ? textOf(tagNode, source).replace(/`/g, '') : undefined,
-        exported: /^[A-Z]/.test(names[0])
-    };
+        tag,
+        exported: /^[A-Z]/.test(name),
+        doc
+    }));
 }
 
 function parseStruct(typeName: string, node: SyntaxNode, source: string): GoStruct {
-    const fields: GoField[] = [];
-    const fieldNodes = typeof node.descendantsOfType === 'function'
-        ? node.descendantsOfType('field_declaration')
+    const fieldList = node.namedChildren.find((c: SyntaxNode) => c.type === 'field_declaration_list');
+    // Only direct fields: nested anonymous structs declare fields of their own
+    const fieldNodes = fieldList
+        ? fieldList.namedChildren.filter((c: SyntaxNode) => c.type === 'field_declaration')
         : [];
-    fieldNodes.forEach((f: SyntaxNode) => {
-        const field = parseFieldDeclaration(f, source);
-        if (field) fields.push(field);
-    });
-    return { name: typeName, fields, methods: [] };
+    const fields: GoField[] = fieldNodes.flatMap((f: SyntaxNode) => parseFieldDeclaration(f, source));
+    return {
+        name: typeName,
+        fields,
+        methods: [],
+        embeddedTypes: fields.filter(f => f.isEmbedded).map(f => f.type)
+    };
 }
 
 function parseInterface(typeName: string, node: SyntaxNode, source: string): GoInterface {
@@ -166,9 +231,23 @@ function parseInterface(typeName: string, node: SyntaxNode, source: string): GoI
         if (!nameNode) return;
         const params = parseParameters(m.childForFieldName('parameters'), source);
         const returns = parseResultTypes(m.childForFieldName('result'), source);
-        methods.push({ name: textOf(nameNode, source), parameters: params, returnTypes: returns });
+        methods.push({ name: textOf(nameNode, source), parameters: params, returnTypes: returns, doc: docOf(m, source) });
     });
-    return { name: typeName, methods };
+    // Type terms of a constraint (~int | ~float64); an embedded interface is a type element too
+    const elements = node.namedChildren
+        .filter((c: SyntaxNode) => ['type_elem', 'constraint_elem', 'interface_type_name', 'qualified_type', 'type_identifier'].includes(c.type))
+        .map((c: SyntaxNode) => textOf(c, source));
+    const isTerm = (text: string) => /[~|]/.test(text) || GoFunctionParser.isBuiltinType(text);
+    const typeTerms = elements
+        .filter(isTerm)
+        .flatMap((text: string) => text.split('|').map((t: string) => t.trim()));
+    const embeddedInterfaces = elements.filter((text: string) => !isTerm(text));
+    return {
+        name: typeName,
+        methods,
+        ...(embeddedInterfaces.length > 0 ? { embeddedInterfaces } : {}),
+        ...(typeTerms.length > 0 ? { typeTerms } : {})
+    };
 }
 
 function parseImports(node: SyntaxNode, source: string): GoImport[] {
@@ -187,42 +266,27 @@ function parseImports(node: SyntaxNode, source: string): GoImport[] {
     return imports;
 }
 
-function inferTypeFromValue(value: string): GoType | undefined {
-    if (/^".*"$/.test(value)) {
-        return { name: 'string', isPointer: false, isSlice: false, isMap: false, isVariadic: false };
-    }
-    if (/^(true|false)$/.test(value)) {
-        return { name: 'bool', isPointer: false, isSlice: false, isMap: false, isVariadic: false };
-    }
-    if (/^\d+$/.test(value)) {
-        return { name: 'int', isPointer: false, isSlice: false, isMap: false, isVariadic: false };
-    }
-    if (/^\d+\.\d+$/.test(value)) {
-        return { name: 'float64', isPointer: false, isSlice: false, isMap: false, isVariadic: false };
-    }
-    return undefined;
-}
-
 function parseVars(node: SyntaxNode, source: string, isConst: boolean): GoVariable[] {
-    const vars: GoVariable[] = [];
-    node.namedChildren
+    const specs: GoValueSpecText[] = node.namedChildren
         .filter((child: SyntaxNode) => child.type === (isConst ? 'const_spec' : 'var_spec'))
-        .forEach((spec: SyntaxNode) => {
-            const names = spec.namedChildren.filter((c: SyntaxNode) => c.type === 'identifier');
+        .map((spec: SyntaxNode) => {
+            const nameNodes = spec.namedChildren.filter((c: SyntaxNode) => c.type === 'identifier');
+            const names = nameNodes.map((c: SyntaxNode) => textOf(c, source));
             const typeNode = spec.childForFieldName('type');
             const valueNode = spec.childForFieldName('value');
-
-            names.forEach((n: SyntaxNode) => {
-                const name = textOf(n, source);
-                const exported = /^[A-Z]/.test(name);
-                const value = valueNode ? textOf(valueNode, source) : undefined;
-                const type = typeNode
-                    ? parseTypeNode(typeNode, source)
-                    : value ? inferTypeFromValue(value) : undefined;
-                vars.push({ name, type, isConst, exported, value });
-            });
+            const valueNodes = !valueNode
+                ? []
+                : valueNode.type === 'expression_list' ? valueNode.namedChildren : [valueNode];
+            return {
+                names,
+                type: typeNode ? parseTypeNode(typeNode, source) : undefined,
+                values: valueNodes.map((v: SyntaxNode) => textOf(v, source)),
+                namePositions: nameNodes.map((c: SyntaxNode) => ({ line: c.startPosition.row, character: c.startPosition.column })),
+                // An ungrouped spec shares its line with the const/var keyword
+                doc: docOf(spec.startPosition.row === node.startPosition.row ? node : spec, source)
+            };
         });
-    return vars;
+    return expandValueSpecs(specs, isConst);
 }
 
 function inferPackage(tree: Parser.Tree, source: string): string {
@@ -252,6 +316,7 @@ export async function parseFile(content: string): Promise<GoFile> {
         imports: [],
         structs: [],
         interfaces: [],
+        namedTypes: [],
         functions: [],
         variables: [],
         constants: []
@@ -274,16 +339,28 @@ export async function parseFile(content: string): Promise<GoFile> {
             }
             case 'type_declaration':
                 child.namedChildren
-                    .filter((c: SyntaxNode) => c.type === 'type_spec')
+                    .filter((c: SyntaxNode) => c.type === 'type_spec' || c.type === 'type_alias')
                     .forEach((spec: SyntaxNode) => {
                         const nameNode = spec.childForFieldName('name');
                         const typeNode = spec.childForFieldName('type');
                         if (!nameNode || !typeNode) return;
                         const typeName = textOf(nameNode, content);
+                        // `type X struct` documents the declaration; grouped `type (...)` specs carry their own doc
+                        const doc = docOf(spec.startPosition.row === child.startPosition.row ? child : spec, content);
+                        const typeParams = parseTypeParams(spec.childForFieldName('type_parameters'), content);
                         if (typeNode.type === 'struct_type') {
-                            goFile.structs.push(parseStruct(typeName, typeNode, content));
+                            goFile.structs.push({ ...parseStruct(typeName, typeNode, content), doc, typeParams });
                         } else if (typeNode.type === 'interface_type') {
-                            goFile.interfaces.push(parseInterface(typeName, typeNode, content));
+                            goFile.interfaces.push({ ...parseInterface(typeName, typeNode, content), doc, typeParams });
+                        } else if (!typeParams) {
+                            goFile.namedTypes.push({
+                                name: typeName,
+                                underlying: parseTypeNode(typeNode, content),
+                                isAlias: spec.type === 'type_alias',
+                                methods: [],
+                                namePosition: { line: nameNode.startPosition.row, character: nameNode.startPosition.column },
+                                doc
+                            });
                         }
                     });
                 break;
@@ -296,13 +373,14 @@ export async function parseFile(content: string): Promise<GoFile> {
         }
     });
 
-    // Attach methods to structs by receiver type name (best-effort)
+    // Attach methods to structs and defined types by receiver type name (best-effort)
     goFile.functions = goFile.functions.filter((fn) => {
         if (fn.isMethod && fn.receiver) {
             const receiverType = fn.receiver.type.name.replace(/^\*/, '');
-            const struct = goFile.structs.find((s) => s.name === receiverType);
-            if (struct) {
-                struct.methods.push(fn);
+            const owner = goFile.structs.find((s) => s.name === receiverType)
+                || goFile.namedTypes.find((t) => t.name === receiverType && !t.isAlias);
+            if (owner) {
+                owner.methods.push(fn);
                 return false;
             }
         }
@@ -311,3 +389,30 @@ export async function parseFile(content: string): Promise<GoFile> {
 
     return goFile;
 }
+
+/**
+ * Throw a GoSyntaxError for the first syntax error in a Go file, if any.
+ * parseFile() itself is lenient and skips over nodes it cannot read.
+ */
+export async function checkSyntax(content: string): Promise<void> {
+    const parser = await getParser();
+    const tree = parser.parse(content);
+    if (!tree.rootNode.hasError) {
+        return;
+    }
+
+    let node: SyntaxNode = tree.rootNode;
+    // Descend to the outermost-first error so the position points at the offending token
+    while (node && !(node.type === 'ERROR' || node.isMissing)) {
+        node = node.children.find((c: SyntaxNode) => c.hasError || c.isMissing);
+    }
+    if (!node) {
+        throw new GoSyntaxError('syntax error', { line: 0, character: 0 });
+    }
+    const pos = { line: node.startPosition.row, character: node.startPosition.column };
+    if (node.isMissing) {
+        throw new GoSyntaxError(`syntax error: missing ${node.type}`, pos);
+    }
+    const snippet = textOf(node, content).split('\n')[0].slice(0, 40);
+    throw new GoSyntaxError(`syntax error: unexpected ${JSON.stringify(snippet)}`, pos);
+}